@@ -916,12 +916,12 @@ type ` + name + ` struct {
 }
 
 // ToMail returns the mail notification for ` + name + `
-func (n *` + name + `) ToMail() *notifications.Mail {
+func (n *` + name + `) ToMail(lang string) *notifications.Mail {
 	return notifications.NewMail().
-		Subject("").
-		Greeting("Hi ").
-		Line("").
-		Action("", "")
+		Subject(i18n.T(lang, "")).
+		Greeting(i18n.T(lang, "mail.greeting", i18n.Params{"name": ""})).
+		Line(i18n.T(lang, "")).
+		Action(i18n.T(lang, ""), "")
 }
 
 // ToDB returns the ` + name + ` notification in a format which can be saved in the db
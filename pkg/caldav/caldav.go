@@ -64,6 +64,9 @@ type Todo struct {
 	DueDate  time.Time
 	Duration time.Duration
 
+	// An RFC 5545 recurrence rule, without the "RRULE:" prefix
+	RepeatRule string
+
 	Created time.Time
 	Updated time.Time // last-mod
 }
@@ -219,6 +222,11 @@ DURATION:PT` + fmt.Sprintf("%.6f", t.Duration.Hours()) + `H` + fmt.Sprintf("%.6f
 PRIORITY:` + strconv.Itoa(mapPriorityToCaldav(t.Priority))
 		}
 
+		if t.RepeatRule != "" {
+			caldavtodos += `
+RRULE:` + t.RepeatRule
+		}
+
 		caldavtodos += `
 LAST-MODIFIED:` + makeCalDavTimeFromTimeStamp(t.Updated)
 
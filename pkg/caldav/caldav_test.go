@@ -408,6 +408,37 @@ DESCRIPTION:Lorem Ipsum
 PRIORITY:9
 LAST-MODIFIED:00010101T000000
 END:VTODO
+END:VCALENDAR`,
+		},
+		{
+			name: "with repeat rule",
+			args: args{
+				config: &Config{
+					Name:   "test",
+					ProdID: "RandomProdID which is not random",
+				},
+				todos: []*Todo{
+					{
+						Summary:    "Todo #1",
+						UID:        "randommduid",
+						RepeatRule: "FREQ=WEEKLY;INTERVAL=2;BYDAY=TU",
+						Timestamp:  time.Unix(1543626724, 0).In(config.GetTimeZone()),
+					},
+				},
+			},
+			wantCaldavtasks: `BEGIN:VCALENDAR
+VERSION:2.0
+METHOD:PUBLISH
+X-PUBLISHED-TTL:PT4H
+X-WR-CALNAME:test
+PRODID:-//RandomProdID which is not random//EN
+BEGIN:VTODO
+UID:randommduid
+DTSTAMP:20181201T011204
+SUMMARY:Todo #1
+RRULE:FREQ=WEEKLY;INTERVAL=2;BYDAY=TU
+LAST-MODIFIED:00010101T000000
+END:VTODO
 END:VCALENDAR`,
 		},
 	}
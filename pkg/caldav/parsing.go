@@ -23,6 +23,7 @@ import (
 
 	"code.vikunja.io/api/pkg/log"
 	"code.vikunja.io/api/pkg/models"
+	"code.vikunja.io/api/pkg/modules/rrule"
 	"github.com/laurent22/ical-go"
 )
 
@@ -48,6 +49,8 @@ func GetCaldavTodosForTasks(list *models.ListWithTasksAndBuckets, listTasks []*m
 			Updated:  t.Updated,
 			DueDate:  t.DueDate,
 			Duration: duration,
+
+			RepeatRule: getRepeatRuleForTask(&t.Task),
 		})
 	}
 
@@ -110,9 +113,51 @@ func ParseTaskFromVTODO(content string) (vTask *models.Task, err error) {
 		vTask.EndDate = vTask.StartDate.Add(duration)
 	}
 
+	if rule, has := task["RRULE"]; has {
+		if _, err := rrule.Parse(rule); err != nil {
+			log.Warningf("Ignoring unsupported recurrence rule %s of caldav task %s: %s", rule, vTask.UID, err)
+		} else {
+			vTask.RepeatRule = rule
+		}
+	}
+
 	return
 }
 
+// Tasks which repeat after a fixed amount of seconds or every month don't have a repeat rule,
+// but we can create one for them so caldav clients know about it.
+func getRepeatRuleForTask(t *models.Task) string {
+	if t.RepeatRule != "" {
+		return t.RepeatRule
+	}
+
+	rule := &rrule.Rule{Interval: 1, WeekStart: time.Monday}
+	switch {
+	case t.RepeatMode == models.TaskRepeatModeMonth:
+		rule.Freq = rrule.FrequencyMonthly
+	case t.RepeatMode != models.TaskRepeatModeDefault || t.RepeatAfter == 0:
+		// Repeating from the current date can't be expressed with a rule
+		return ""
+	case t.RepeatAfter%(60*60*24) == 0:
+		rule.Freq = rrule.FrequencyDaily
+		rule.Interval = int(t.RepeatAfter / (60 * 60 * 24))
+	case t.RepeatAfter%(60*60) == 0:
+		rule.Freq = rrule.FrequencyHourly
+		rule.Interval = int(t.RepeatAfter / (60 * 60))
+	case t.RepeatAfter%60 == 0:
+		rule.Freq = rrule.FrequencyMinutely
+		rule.Interval = int(t.RepeatAfter / 60)
+	default:
+		return ""
+	}
+
+	if rule.Interval > rrule.MaxInterval {
+		return ""
+	}
+
+	return rule.String()
+}
+
 // https://tools.ietf.org/html/rfc5545#section-3.3.5
 func caldavTimeToTimestamp(tstring string) time.Time {
 	if tstring == "" {
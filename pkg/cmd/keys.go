@@ -0,0 +1,101 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"os"
+	"time"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/initialize"
+	"code.vikunja.io/api/pkg/log"
+	"code.vikunja.io/api/pkg/models"
+	"code.vikunja.io/api/pkg/modules/auth"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	keysCmd.AddCommand(keysListCmd, keysRotateCmd)
+	rootCmd.AddCommand(keysCmd)
+}
+
+var keysCmd = &cobra.Command{
+	Use:   "keys",
+	Short: "Manage the keys used to sign jwt tokens.",
+}
+
+var keysListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Shows a list of all keys used to sign or verify jwt tokens.",
+	PreRun: func(cmd *cobra.Command, args []string) {
+		initialize.FullInit()
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		s := db.NewSession()
+		defer s.Close()
+
+		keys, err := models.GetJWTKeys(s, time.Time{})
+		if err != nil {
+			_ = s.Rollback()
+			log.Fatalf("Error getting keys: %s", err)
+		}
+
+		if err := s.Commit(); err != nil {
+			log.Fatalf("Error getting keys: %s", err)
+		}
+
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{
+			"ID",
+			"Algorithm",
+			"Created",
+			"Retired",
+		})
+
+		for _, key := range keys {
+			retired := "-"
+			if !key.RetiredAt.IsZero() {
+				retired = key.RetiredAt.Format(time.RFC3339)
+			}
+			table.Append([]string{
+				key.ID,
+				key.Algorithm,
+				key.Created.Format(time.RFC3339),
+				retired,
+			})
+		}
+
+		table.Render()
+	},
+}
+
+var keysRotateCmd = &cobra.Command{
+	Use:   "rotate",
+	Short: "Creates a new key to sign jwt tokens. Tokens signed with the previous keys stay valid until they expire.",
+	PreRun: func(cmd *cobra.Command, args []string) {
+		initialize.FullInit()
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		key, err := auth.RotateJWTKeys()
+		if err != nil {
+			log.Fatalf("Error rotating keys: %s", err)
+		}
+
+		log.Infof("Created new %s key %s, all new tokens will be signed with it.", key.Algorithm, key.ID)
+	},
+}
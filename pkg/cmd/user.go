@@ -71,7 +71,7 @@ func init() {
 	// User deletion flags
 	userDeleteCmd.Flags().BoolVarP(&userFlagDeleteNow, "now", "n", false, "If provided, deletes the user immediately instead of sending them an email first.")
 
-	userCmd.AddCommand(userListCmd, userCreateCmd, userUpdateCmd, userResetPasswordCmd, userChangeEnabledCmd, userDeleteCmd)
+	userCmd.AddCommand(userListCmd, userCreateCmd, userUpdateCmd, userResetPasswordCmd, userResetTOTPCmd, userChangeEnabledCmd, userDeleteCmd)
 	rootCmd.AddCommand(userCmd)
 }
 
@@ -268,6 +268,34 @@ var userResetPasswordCmd = &cobra.Command{
 	},
 }
 
+var userResetTOTPCmd = &cobra.Command{
+	Use:   "reset-totp [user id]",
+	Short: "Disable totp for a user and remove their recovery codes, for example when they lost their totp device.",
+	PreRun: func(cmd *cobra.Command, args []string) {
+		initialize.FullInit()
+	},
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		s := db.NewSession()
+		defer s.Close()
+
+		u := getUserFromArg(s, args[0])
+
+		// This also revokes all sessions of the user
+		err := user.DisableTOTP(s, u)
+		if err != nil {
+			_ = s.Rollback()
+			log.Fatalf("Could not reset totp: %s", err)
+		}
+
+		if err := s.Commit(); err != nil {
+			log.Fatalf("Error saving everything: %s", err)
+		}
+
+		fmt.Println("TOTP reset successfully. The user can now log in with their password only.")
+	},
+}
+
 var userChangeEnabledCmd = &cobra.Command{
 	Use:   "change-status [user id]",
 	Short: "Enable or disable a user. Will toggle the current status if no flag (--enable or --disable) is provided.",
@@ -281,21 +309,16 @@ var userChangeEnabledCmd = &cobra.Command{
 
 		u := getUserFromArg(s, args[0])
 
-		if userFlagEnableUser {
-			u.Status = user.StatusActive
-		} else if userFlagDisableUser {
-			u.Status = user.StatusDisabled
-		} else {
-			if u.Status == user.StatusActive {
-				u.Status = user.StatusDisabled
-			} else {
-				u.Status = user.StatusActive
-			}
+		status := user.Status(user.StatusActive)
+		if !userFlagEnableUser && (userFlagDisableUser || u.Status == user.StatusActive) {
+			status = user.StatusDisabled
 		}
-		_, err := user.UpdateUser(s, u)
+
+		// Disabling a user revokes all their sessions
+		err := u.SetStatus(s, status)
 		if err != nil {
 			_ = s.Rollback()
-			log.Fatalf("Could not enable the user")
+			log.Fatalf("Could not change the user status: %s", err)
 		}
 
 		if err := s.Commit(); err != nil {
@@ -38,6 +38,8 @@ const (
 	// #nosec
 	ServiceJWTSecret       Key = `service.JWTSecret`
 	ServiceJWTTTL          Key = `service.jwtttl`
+	ServiceJWTTTLShort     Key = `service.jwtttlshort`
+	ServiceJWTAlgorithm    Key = `service.jwtalgorithm`
 	ServiceInterface       Key = `service.interface`
 	ServiceUnixSocket      Key = `service.unixsocket`
 	ServiceUnixSocketMode  Key = `service.unixsocketmode`
@@ -54,16 +56,39 @@ const (
 	ServiceTimeZone              Key = `service.timezone`
 	ServiceEnableTaskComments    Key = `service.enabletaskcomments`
 	ServiceEnableTotp            Key = `service.enabletotp`
+	ServiceEnableWebAuthn        Key = `service.enablewebauthn`
+	ServiceEnableOAuth2Server    Key = `service.enableoauth2server`
 	ServiceSentryDsn             Key = `service.sentrydsn`
 	ServiceTestingtoken          Key = `service.testingtoken`
 	ServiceEnableEmailReminders  Key = `service.enableemailreminders`
 	ServiceEnableUserDeletion    Key = `service.enableuserdeletion`
+	ServiceTrashRetentionDays    Key = `service.trashretentiondays`
 
 	AuthLocalEnabled      Key = `auth.local.enabled`
 	AuthOpenIDEnabled     Key = `auth.openid.enabled`
 	AuthOpenIDRedirectURL Key = `auth.openid.redirecturl`
 	AuthOpenIDProviders   Key = `auth.openid.providers`
 
+	AuthLDAPEnabled              Key = `auth.ldap.enabled`
+	AuthLDAPHost                 Key = `auth.ldap.host`
+	AuthLDAPPort                 Key = `auth.ldap.port`
+	AuthLDAPUseTLS               Key = `auth.ldap.usetls`
+	AuthLDAPVerifyTLS            Key = `auth.ldap.verifytls`
+	AuthLDAPBaseDN               Key = `auth.ldap.basedn`
+	AuthLDAPBindDN               Key = `auth.ldap.binddn`
+	AuthLDAPBindPassword         Key = `auth.ldap.bindpassword`
+	AuthLDAPUserFilter           Key = `auth.ldap.userfilter`
+	AuthLDAPAttributeUsername    Key = `auth.ldap.attribute.username`
+	AuthLDAPAttributeEmail       Key = `auth.ldap.attribute.email`
+	AuthLDAPAttributeDisplayname Key = `auth.ldap.attribute.displayname`
+	AuthLDAPAttributeMemberOf    Key = `auth.ldap.attribute.memberof`
+	AuthLDAPGroupSyncEnabled     Key = `auth.ldap.groupsyncenabled`
+
+	AuthProxyEnabled        Key = `auth.proxy.enabled`
+	AuthProxyTrustedProxies Key = `auth.proxy.trustedproxies`
+	AuthProxyUserHeader     Key = `auth.proxy.userheader`
+	AuthProxyEmailHeader    Key = `auth.proxy.emailheader`
+
 	LegalImprintURL Key = `legal.imprinturl`
 	LegalPrivacyURL Key = `legal.privacyurl`
 
@@ -152,6 +177,14 @@ const (
 	MetricsEnabled  Key = `metrics.enabled`
 	MetricsUsername Key = `metrics.username`
 	MetricsPassword Key = `metrics.password`
+
+	WebhooksEnabled        Key = `webhooks.enabled`
+	WebhooksTimeoutSeconds Key = `webhooks.timeoutseconds`
+	WebhooksAllowedHosts   Key = `webhooks.allowedhosts`
+
+	NotificationChannelsEnabled        Key = `notificationchannels.enabled`
+	NotificationChannelsTimeoutSeconds Key = `notificationchannels.timeoutseconds`
+	NotificationChannelsAllowedHosts   Key = `notificationchannels.allowedhosts`
 )
 
 // GetString returns a string config value
@@ -228,6 +261,8 @@ func InitDefaultConfig() {
 	// Service
 	ServiceJWTSecret.setDefault(random)
 	ServiceJWTTTL.setDefault(259200)
+	ServiceJWTTTLShort.setDefault(600)
+	ServiceJWTAlgorithm.setDefault("HS256")
 	ServiceInterface.setDefault(":3456")
 	ServiceUnixSocket.setDefault("")
 	ServiceFrontendurl.setDefault("")
@@ -248,12 +283,29 @@ func InitDefaultConfig() {
 	ServiceTimeZone.setDefault("GMT")
 	ServiceEnableTaskComments.setDefault(true)
 	ServiceEnableTotp.setDefault(true)
+	ServiceEnableWebAuthn.setDefault(false)
+	ServiceEnableOAuth2Server.setDefault(false)
 	ServiceEnableEmailReminders.setDefault(true)
 	ServiceEnableUserDeletion.setDefault(true)
+	ServiceTrashRetentionDays.setDefault(30)
 
 	// Auth
 	AuthLocalEnabled.setDefault(true)
 	AuthOpenIDEnabled.setDefault(false)
+	AuthLDAPEnabled.setDefault(false)
+	AuthLDAPPort.setDefault(389)
+	AuthLDAPUseTLS.setDefault(false)
+	AuthLDAPVerifyTLS.setDefault(true)
+	AuthLDAPUserFilter.setDefault("(&(objectclass=inetOrgPerson)(uid=%[1]s))")
+	AuthLDAPAttributeUsername.setDefault("uid")
+	AuthLDAPAttributeEmail.setDefault("mail")
+	AuthLDAPAttributeDisplayname.setDefault("displayName")
+	AuthLDAPAttributeMemberOf.setDefault("memberOf")
+	AuthLDAPGroupSyncEnabled.setDefault(false)
+	AuthProxyEnabled.setDefault(false)
+	AuthProxyTrustedProxies.setDefault([]string{})
+	AuthProxyUserHeader.setDefault("X-Forwarded-User")
+	AuthProxyEmailHeader.setDefault("X-Forwarded-Email")
 
 	// Database
 	DatabaseType.setDefault("sqlite")
@@ -327,6 +379,14 @@ func InitDefaultConfig() {
 	KeyvalueType.setDefault("memory")
 	// Metrics
 	MetricsEnabled.setDefault(false)
+	// Webhooks
+	WebhooksEnabled.setDefault(true)
+	WebhooksTimeoutSeconds.setDefault(30)
+	WebhooksAllowedHosts.setDefault([]string{})
+	// Notification channels
+	NotificationChannelsEnabled.setDefault(true)
+	NotificationChannelsTimeoutSeconds.setDefault(10)
+	NotificationChannelsAllowedHosts.setDefault([]string{})
 }
 
 // InitConfig initializes the config, sets defaults etc.
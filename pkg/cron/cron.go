@@ -17,6 +17,8 @@
 package cron
 
 import (
+	"time"
+
 	"github.com/robfig/cron/v3"
 )
 
@@ -34,6 +36,16 @@ func Schedule(schedule string, f func()) (err error) {
 	return
 }
 
+// Next returns the next time after the given time a cron expression matches.
+// The expression is evaluated in the location of after.
+func Next(schedule string, after time.Time) (next time.Time, err error) {
+	sched, err := cron.ParseStandard(schedule)
+	if err != nil {
+		return
+	}
+	return sched.Next(after), nil
+}
+
 // Stop stops the cron scheduler
 func Stop() {
 	c.Stop()
@@ -0,0 +1,75 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package i18n
+
+import (
+	"math"
+	"strings"
+	"time"
+)
+
+// FormatDate formats a date with the time in a language, for example "October 3, 2021 14:30 CEST" in english
+// or "3. Oktober 2021, 14:30 CEST" in german. The date is shown in the location it has.
+func FormatDate(lang string, date time.Time) string {
+	return T(lang, "date.format", Params{
+		"day":   date.Day(),
+		"month": T(lang, "date.month."+strings.ToLower(date.Month().String())),
+		"year":  date.Year(),
+		"time":  date.Format("15:04 MST"),
+	})
+}
+
+// HumanizeDuration formats a time.Duration in a human-friendly format in a language.
+// It works like utils.HumanizeDuration but the units are translated.
+func HumanizeDuration(lang string, duration time.Duration) string {
+	years := int64(duration.Hours() / 24 / 365)
+	days := int64(duration.Hours()/24) - years*365
+	weeks := days / 7
+	days -= weeks * 7
+
+	hours := int64(math.Mod(duration.Hours(), 24))
+	minutes := int64(math.Mod(duration.Minutes(), 60))
+
+	chunks := []struct {
+		unit   string
+		amount int64
+	}{
+		{"year", years},
+		{"week", weeks},
+		{"day", days},
+		{"hour", hours},
+		{"minute", minutes},
+	}
+
+	parts := []string{}
+
+	for _, chunk := range chunks {
+		if chunk.amount == 0 {
+			continue
+		}
+		parts = append(parts, TN(lang, "duration."+chunk.unit, chunk.amount))
+	}
+
+	if len(parts) > 1 {
+		return T(lang, "duration.list", Params{
+			"items": strings.Join(parts[:len(parts)-1], ", "),
+			"last":  parts[len(parts)-1],
+		})
+	}
+
+	return strings.Join(parts, ", ")
+}
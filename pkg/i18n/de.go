@@ -0,0 +1,154 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package i18n
+
+func init() {
+	register("de", &catalog{
+		plural: pluralOneOther,
+		translations: map[string]string{
+			"date.format":          "{day}. {month} {year}, {time}",
+			"date.month.january":   "Januar",
+			"date.month.february":  "Februar",
+			"date.month.march":     "März",
+			"date.month.april":     "April",
+			"date.month.may":       "Mai",
+			"date.month.june":      "Juni",
+			"date.month.july":      "Juli",
+			"date.month.august":    "August",
+			"date.month.september": "September",
+			"date.month.october":   "Oktober",
+			"date.month.november":  "November",
+			"date.month.december":  "Dezember",
+
+			// Durations are only used after "seit", hence the dative.
+			"duration.year.one":     "einem Jahr",
+			"duration.year.other":   "{count} Jahren",
+			"duration.week.one":     "einer Woche",
+			"duration.week.other":   "{count} Wochen",
+			"duration.day.one":      "einem Tag",
+			"duration.day.other":    "{count} Tagen",
+			"duration.hour.one":     "einer Stunde",
+			"duration.hour.other":   "{count} Stunden",
+			"duration.minute.one":   "einer Minute",
+			"duration.minute.other": "{count} Minuten",
+			"duration.list":         "{items} und {last}",
+
+			"mail.greeting":        "Hallo {name},",
+			"mail.have_a_nice_day": "Einen schönen Tag noch!",
+			"mail.action_fallback": "Falls der Button oben nicht funktioniert, kopiere die URL unten und füge sie in die Adresszeile deines Browsers ein:",
+
+			"notifications.open_vikunja": "Vikunja öffnen",
+			"notifications.task.open":    "Aufgabe öffnen",
+			"notifications.task.view":    "Aufgabe ansehen",
+			"notifications.list.view":    "Liste ansehen",
+			"notifications.team.view":    "Team ansehen",
+
+			"notifications.task.reminder.subject": "Erinnerung an „{task}“",
+			"notifications.task.reminder.message": "Dies ist eine freundliche Erinnerung an die Aufgabe „{task}“.",
+			"notifications.task.reminder.due":     "Sie ist am {date} fällig.",
+
+			"notifications.task.comment.subject":           "Re: {task}",
+			"notifications.task.comment.mentioned_subject": "{doer} hat dich in einem Kommentar in „{task}“ erwähnt",
+			"notifications.task.comment.mentioned_message": "**{doer}** hat dich in einem Kommentar erwähnt:",
+			"notifications.task.comment.digest":            "{doer} hat kommentiert: {comment}",
+
+			"notifications.task.assigned.subject": "{task}({identifier}) wurde {assignee} zugewiesen",
+			"notifications.task.assigned.message": "{doer} hat diese Aufgabe {assignee} zugewiesen.",
+
+			"notifications.task.deleted.subject": "{task}({identifier}) wurde gelöscht",
+			"notifications.task.deleted.message": "{doer} hat die Aufgabe {task}({identifier}) gelöscht",
+			"notifications.task.deleted.digest":  "{doer} hat diese Aufgabe gelöscht.",
+
+			"notifications.list.created.subject": "{doer} hat die Liste „{list}“ erstellt",
+			"notifications.list.created.digest":  "{doer} hat diese Liste erstellt.",
+
+			"notifications.team.member_added.subject": "{doer} hat dich in Vikunja zum Team {team} hinzugefügt",
+			"notifications.team.member_added.message": "{doer} hat dich gerade in Vikunja zum Team {team} hinzugefügt.",
+
+			"notifications.task.overdue.subject": "Die Aufgabe „{task}“ ist überfällig",
+			"notifications.task.overdue.message": "Dies ist eine freundliche Erinnerung an die Aufgabe „{task}“, die seit {duration} überfällig und noch nicht erledigt ist.",
+			"notifications.task.overdue.due":     "Sie war am {date} fällig.",
+			"notifications.task.overdue.line":    "* [{task}]({url}), seit {duration} überfällig (fällig am {date})",
+
+			"notifications.tasks.overdue.subject": "Deine überfälligen Aufgaben",
+			"notifications.tasks.overdue.message": "Du hast die folgenden überfälligen Aufgaben:",
+
+			"notifications.task.mentioned.subject":     "{doer} hat dich in der Aufgabe „{task}“ erwähnt",
+			"notifications.task.mentioned.subject_new": "{doer} hat dich in der neuen Aufgabe „{task}“ erwähnt",
+			"notifications.task.mentioned.message":     "**{doer}** hat dich in einer Aufgabe erwähnt:",
+			"notifications.task.mentioned.digest":      "{doer} hat dich in dieser Aufgabe erwähnt.",
+
+			"notifications.data_export.subject": "Dein Vikunja-Datenexport ist bereit",
+			"notifications.data_export.message": "Dein Vikunja-Datenexport steht zum Herunterladen bereit. Klicke auf den Button unten, um ihn herunterzuladen:",
+			"notifications.data_export.action":  "Herunterladen",
+			"notifications.data_export.valid":   "Der Download ist für die nächsten 7 Tage verfügbar.",
+
+			"notifications.digest.subject":       "Deine Vikunja-Zusammenfassung",
+			"notifications.digest.message":       "Das ist seit deiner letzten Zusammenfassung passiert:",
+			"notifications.digest.overdue_tasks": "**Deine überfälligen Aufgaben**",
+
+			"notifications.email_confirm.subject":     "{name}, bitte bestätige deine E-Mail-Adresse bei Vikunja",
+			"notifications.email_confirm.subject_new": "{name} + Vikunja = <3",
+			"notifications.email_confirm.welcome":     "Willkommen bei Vikunja!",
+			"notifications.email_confirm.message":     "Um deine E-Mail-Adresse zu bestätigen, klicke auf den Link unten:",
+			"notifications.email_confirm.action":      "E-Mail-Adresse bestätigen",
+
+			"notifications.password_changed.subject": "Dein Passwort bei Vikunja wurde geändert",
+			"notifications.password_changed.message": "Das Passwort deines Kontos wurde erfolgreich geändert.",
+			"notifications.password_changed.not_you": "Falls du das nicht warst, hat möglicherweise jemand Zugriff auf dein Konto erlangt. Wende dich in diesem Fall an die Administration deines Servers.",
+
+			"notifications.password_reset.subject": "Setze dein Passwort bei Vikunja zurück",
+			"notifications.password_reset.message": "Um dein Passwort zurückzusetzen, klicke auf den Link unten:",
+			"notifications.password_reset.action":  "Passwort zurücksetzen",
+			"notifications.link_valid_24_hours":    "Dieser Link ist 24 Stunden lang gültig.",
+
+			"notifications.totp_invalid.subject": "Jemand hat gerade erfolglos versucht, sich bei deinem Vikunja-Konto anzumelden",
+			"notifications.totp_invalid.message": "Jemand hat gerade versucht, sich mit korrektem Benutzernamen und Passwort, aber einem falschen TOTP-Code bei deinem Konto anzumelden.",
+			"notifications.totp_invalid.warning": "**Falls du das nicht warst, kennt jemand anderes dein Passwort. Du solltest sofort ein neues festlegen!**",
+
+			"notifications.account_locked.subject":  "Wir haben dein Konto bei Vikunja deaktiviert",
+			"notifications.account_locked.message":  "Jemand hat versucht, sich mit deinen Zugangsdaten anzumelden, konnte aber keinen gültigen TOTP-Code angeben.",
+			"notifications.account_locked.disabled": "Nach 10 fehlgeschlagenen Versuchen haben wir dein Konto deaktiviert und dein Passwort zurückgesetzt. Um ein neues festzulegen, folge den Anweisungen in der E-Mail zum Zurücksetzen, die wir dir gerade geschickt haben.",
+			"notifications.account_locked.reset":    "Falls du keine E-Mail mit Anweisungen zum Zurücksetzen erhalten hast, kannst du unter [{url}]({url}) jederzeit eine neue anfordern.",
+
+			"notifications.failed_login.subject": "Jemand hat gerade versucht, sich bei deinem Vikunja-Konto anzumelden, aber ein falsches Passwort angegeben",
+			"notifications.failed_login.message": "Jemand hat gerade dreimal hintereinander versucht, sich mit einem falschen Passwort bei deinem Konto anzumelden.",
+			"notifications.failed_login.not_you": "Falls du das nicht warst, versucht möglicherweise jemand anderes, in dein Konto einzudringen.",
+			"notifications.failed_login.advice":  "Um die Sicherheit deines Kontos zu erhöhen, kannst du in den Einstellungen ein stärkeres Passwort festlegen oder die TOTP-Authentifizierung aktivieren:",
+			"notifications.failed_login.action":  "Zu den Einstellungen",
+
+			"notifications.account_deletion_confirm.subject":      "Bitte bestätige die Löschung deines Vikunja-Kontos",
+			"notifications.account_deletion_confirm.message":      "Du hast die Löschung deines Kontos angefordert. Um dies zu bestätigen, klicke bitte auf den Link unten:",
+			"notifications.account_deletion_confirm.action":       "Löschung meines Kontos bestätigen",
+			"notifications.account_deletion_confirm.schedule":     "Sobald du die Löschung bestätigst, planen wir die Löschung deines Kontos in drei Tagen und schicken dir bis dahin eine weitere E-Mail.",
+			"notifications.account_deletion_confirm.consequences": "Wenn du mit der Löschung deines Kontos fortfährst, entfernen wir alle Namespaces, Listen und Aufgaben, die du erstellt hast. Alles, was du mit anderen Nutzern oder Teams geteilt hast, geht in deren Besitz über.",
+			"notifications.account_deletion_confirm.ignore":       "Falls du die Löschung nicht angefordert oder es dir anders überlegt hast, kannst du diese E-Mail einfach ignorieren.",
+
+			"notifications.account_deletion.when.one":   "morgen",
+			"notifications.account_deletion.when.other": "in {count} Tagen",
+			"notifications.account_deletion.subject":    "Dein Vikunja-Konto wird {when} gelöscht",
+			"notifications.account_deletion.message":    "Du hast kürzlich die Löschung deines Vikunja-Kontos angefordert.",
+			"notifications.account_deletion.scheduled":  "Wir werden dein Konto {when} löschen.",
+			"notifications.account_deletion.abort_info": "Falls du es dir anders überlegt hast, klicke einfach auf den Link unten, um die Löschung abzubrechen, und folge den Anweisungen dort:",
+			"notifications.account_deletion.action":     "Löschung abbrechen",
+
+			"notifications.account_deleted.subject":   "Dein Vikunja-Konto wurde gelöscht",
+			"notifications.account_deleted.message":   "Wie gewünscht haben wir dein Vikunja-Konto gelöscht.",
+			"notifications.account_deleted.permanent": "Diese Löschung ist endgültig. Falls du kein Backup erstellt hast und deine Daten jetzt zurück brauchst, wende dich an die Administration.",
+		},
+	})
+}
@@ -0,0 +1,153 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package i18n
+
+func init() {
+	register("en", &catalog{
+		plural: pluralOneOther,
+		translations: map[string]string{
+			"date.format":          "{month} {day}, {year} {time}",
+			"date.month.january":   "January",
+			"date.month.february":  "February",
+			"date.month.march":     "March",
+			"date.month.april":     "April",
+			"date.month.may":       "May",
+			"date.month.june":      "June",
+			"date.month.july":      "July",
+			"date.month.august":    "August",
+			"date.month.september": "September",
+			"date.month.october":   "October",
+			"date.month.november":  "November",
+			"date.month.december":  "December",
+
+			"duration.year.one":     "one year",
+			"duration.year.other":   "{count} years",
+			"duration.week.one":     "one week",
+			"duration.week.other":   "{count} weeks",
+			"duration.day.one":      "one day",
+			"duration.day.other":    "{count} days",
+			"duration.hour.one":     "one hour",
+			"duration.hour.other":   "{count} hours",
+			"duration.minute.one":   "one minute",
+			"duration.minute.other": "{count} minutes",
+			"duration.list":         "{items} and {last}",
+
+			"mail.greeting":        "Hi {name},",
+			"mail.have_a_nice_day": "Have a nice day!",
+			"mail.action_fallback": "If the button above doesn't work, copy the url below and paste it in your browsers address bar:",
+
+			"notifications.open_vikunja": "Open Vikunja",
+			"notifications.task.open":    "Open Task",
+			"notifications.task.view":    "View Task",
+			"notifications.list.view":    "View List",
+			"notifications.team.view":    "View Team",
+
+			"notifications.task.reminder.subject": `Reminder for "{task}"`,
+			"notifications.task.reminder.message": `This is a friendly reminder of the task "{task}".`,
+			"notifications.task.reminder.due":     "It is due on {date}.",
+
+			"notifications.task.comment.subject":           "Re: {task}",
+			"notifications.task.comment.mentioned_subject": `{doer} mentioned you in a comment in "{task}"`,
+			"notifications.task.comment.mentioned_message": "**{doer}** mentioned you in a comment:",
+			"notifications.task.comment.digest":            "{doer} commented: {comment}",
+
+			"notifications.task.assigned.subject": "{task}({identifier}) has been assigned to {assignee}",
+			"notifications.task.assigned.message": "{doer} has assigned this task to {assignee}.",
+
+			"notifications.task.deleted.subject": "{task}({identifier}) has been deleted",
+			"notifications.task.deleted.message": "{doer} has deleted the task {task}({identifier})",
+			"notifications.task.deleted.digest":  "{doer} has deleted this task.",
+
+			"notifications.list.created.subject": `{doer} created the list "{list}"`,
+			"notifications.list.created.digest":  "{doer} created this list.",
+
+			"notifications.team.member_added.subject": "{doer} added you to the {team} team in Vikunja",
+			"notifications.team.member_added.message": "{doer} has just added you to the {team} team in Vikunja.",
+
+			"notifications.task.overdue.subject": `Task "{task}" is overdue`,
+			"notifications.task.overdue.message": `This is a friendly reminder of the task "{task}" which is overdue since {duration} and not yet done.`,
+			"notifications.task.overdue.due":     "It was due on {date}.",
+			"notifications.task.overdue.line":    "* [{task}]({url}), overdue since {duration} (due {date})",
+
+			"notifications.tasks.overdue.subject": "Your overdue tasks",
+			"notifications.tasks.overdue.message": "You have the following overdue tasks:",
+
+			"notifications.task.mentioned.subject":     `{doer} mentioned you in a task "{task}"`,
+			"notifications.task.mentioned.subject_new": `{doer} mentioned you in a new task "{task}"`,
+			"notifications.task.mentioned.message":     "**{doer}** mentioned you in a task:",
+			"notifications.task.mentioned.digest":      "{doer} mentioned you in this task.",
+
+			"notifications.data_export.subject": "Your Vikunja Data Export is ready",
+			"notifications.data_export.message": "Your Vikunja Data Export is ready for you to download. Click the button below to download it:",
+			"notifications.data_export.action":  "Download",
+			"notifications.data_export.valid":   "The download will be available for the next 7 days.",
+
+			"notifications.digest.subject":       "Your Vikunja digest",
+			"notifications.digest.message":       "This is what happened since your last digest:",
+			"notifications.digest.overdue_tasks": "**Your overdue tasks**",
+
+			"notifications.email_confirm.subject":     "{name}, please confirm your email address at Vikunja",
+			"notifications.email_confirm.subject_new": "{name} + Vikunja = <3",
+			"notifications.email_confirm.welcome":     "Welcome to Vikunja!",
+			"notifications.email_confirm.message":     "To confirm your email address, click the link below:",
+			"notifications.email_confirm.action":      "Confirm your email address",
+
+			"notifications.password_changed.subject": "Your Password on Vikunja was changed",
+			"notifications.password_changed.message": "Your account password was successfully changed.",
+			"notifications.password_changed.not_you": "If this wasn't you, it could mean someone compromised your account. In this case contact your server's administrator.",
+
+			"notifications.password_reset.subject": "Reset your password on Vikunja",
+			"notifications.password_reset.message": "To reset your password, click the link below:",
+			"notifications.password_reset.action":  "Reset your password",
+			"notifications.link_valid_24_hours":    "This link will be valid for 24 hours.",
+
+			"notifications.totp_invalid.subject": "Someone just tried to login to your Vikunja account, but failed",
+			"notifications.totp_invalid.message": "Someone just tried to log in into your account with correct username and password but a wrong TOTP passcode.",
+			"notifications.totp_invalid.warning": "**If this was not you, someone else knows your password. You should set a new one immediately!**",
+
+			"notifications.account_locked.subject":  "We've disabled your account on Vikunja",
+			"notifications.account_locked.message":  "Someone tried to log in with your credentials but failed to provide a valid TOTP passcode.",
+			"notifications.account_locked.disabled": "After 10 failed attempts, we've disabled your account and reset your password. To set a new one, follow the instructions in the reset email we just sent you.",
+			"notifications.account_locked.reset":    "If you did not receive an email with reset instructions, you can always request a new one at [{url}]({url}).",
+
+			"notifications.failed_login.subject": "Someone just tried to login to your Vikunja account, but failed to provide a correct password",
+			"notifications.failed_login.message": "Someone just tried to log in into your account with a wrong password three times in a row.",
+			"notifications.failed_login.not_you": "If this was not you, this could be someone else trying to break into your account.",
+			"notifications.failed_login.advice":  "To enhance the security of you account you may want to set a stronger password or enable TOTP authentication in the settings:",
+			"notifications.failed_login.action":  "Go to settings",
+
+			"notifications.account_deletion_confirm.subject":      "Please confirm the deletion of your Vikunja account",
+			"notifications.account_deletion_confirm.message":      "You have requested the deletion of your account. To confirm this, please click the link below:",
+			"notifications.account_deletion_confirm.action":       "Confirm the deletion of my account",
+			"notifications.account_deletion_confirm.schedule":     "Once you confirm the deletion we will schedule the deletion of your account in three days and send you another email until then.",
+			"notifications.account_deletion_confirm.consequences": "If you proceed with the deletion of your account, we will remove all of your namespaces, lists and tasks you created. Everything you shared with another user or team will transfer ownership to them.",
+			"notifications.account_deletion_confirm.ignore":       "If you did not requested the deletion or changed your mind, you can simply ignore this email.",
+
+			"notifications.account_deletion.when.one":   "tomorrow",
+			"notifications.account_deletion.when.other": "in {count} days",
+			"notifications.account_deletion.subject":    "Your Vikunja account will be deleted {when}",
+			"notifications.account_deletion.message":    "You recently requested the deletion of your Vikunja account.",
+			"notifications.account_deletion.scheduled":  "We will delete your account {when}.",
+			"notifications.account_deletion.abort_info": "If you changed your mind, simply click the link below to cancel the deletion and follow the instructions there:",
+			"notifications.account_deletion.action":     "Abort the deletion",
+
+			"notifications.account_deleted.subject":   "Your Vikunja Account has been deleted",
+			"notifications.account_deleted.message":   "As requested, we've deleted your Vikunja account.",
+			"notifications.account_deleted.permanent": "This deletion is permanent. If did not create a backup and need your data back now, talk to your administrator.",
+		},
+	})
+}
@@ -0,0 +1,141 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package i18n
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DefaultLanguage is the language used when a user did not set one or when a translation is missing.
+const DefaultLanguage = "en"
+
+// Params are the values of the placeholders in a translation. A placeholder looks like {name}.
+type Params map[string]interface{}
+
+type catalog struct {
+	// plural returns the plural form ("one" or "other") to use for a count.
+	plural       func(count int64) string
+	translations map[string]string
+}
+
+var catalogs = map[string]*catalog{}
+
+func register(lang string, c *catalog) {
+	catalogs[lang] = c
+}
+
+// pluralOneOther is the plural rule for languages which only distinguish between one and everything else,
+// like english and german.
+func pluralOneOther(count int64) string {
+	if count == 1 {
+		return "one"
+	}
+	return "other"
+}
+
+// normalize returns the language code of the catalog to use for a language.
+// Regional variants like "de-DE" or "de_CH" use the catalog of their base language.
+func normalize(lang string) string {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	if _, exists := catalogs[lang]; exists {
+		return lang
+	}
+
+	if i := strings.IndexAny(lang, "-_"); i > 0 {
+		if _, exists := catalogs[lang[:i]]; exists {
+			return lang[:i]
+		}
+	}
+
+	return ""
+}
+
+// HasLanguage checks if there is a translation catalog for a language.
+func HasLanguage(lang string) bool {
+	return normalize(lang) != ""
+}
+
+// GetAvailableLanguages returns the codes of all languages with a translation catalog.
+func GetAvailableLanguages() []string {
+	langs := make([]string, 0, len(catalogs))
+	for lang := range catalogs {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+func lookup(lang, key string) (string, bool) {
+	if c, exists := catalogs[normalize(lang)]; exists {
+		if t, has := c.translations[key]; has {
+			return t, true
+		}
+	}
+
+	t, has := catalogs[DefaultLanguage].translations[key]
+	return t, has
+}
+
+// replaceParams replaces all placeholders in one pass so placeholders in the values themselves stay untouched.
+func replaceParams(translation string, params []Params) string {
+	oldnew := []string{}
+	for _, p := range params {
+		for name, value := range p {
+			oldnew = append(oldnew, "{"+name+"}", fmt.Sprint(value))
+		}
+	}
+	if len(oldnew) == 0 {
+		return translation
+	}
+	return strings.NewReplacer(oldnew...).Replace(translation)
+}
+
+// T returns the translation of a key in a language with all placeholders replaced.
+// If the language or the key does not exist, the english translation is used. If that does not exist
+// either, the key itself is returned.
+func T(lang, key string, params ...Params) string {
+	translation, has := lookup(lang, key)
+	if !has {
+		return key
+	}
+
+	return replaceParams(translation, params)
+}
+
+// TN returns the plural form of a translation matching count. The plural forms are saved with the key
+// and the plural form as suffix, for example "duration.day.one" and "duration.day.other".
+// The count is available as the {count} placeholder.
+func TN(lang, key string, count int64, params ...Params) string {
+	form := pluralOneOther(count)
+	if c, exists := catalogs[normalize(lang)]; exists {
+		form = c.plural(count)
+	}
+
+	params = append(params, Params{"count": count})
+
+	translation, has := lookup(lang, key+"."+form)
+	if !has {
+		translation, has = lookup(DefaultLanguage, key+"."+pluralOneOther(count))
+	}
+	if !has {
+		return key
+	}
+
+	return replaceParams(translation, params)
+}
@@ -0,0 +1,89 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package i18n
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestT(t *testing.T) {
+	t.Run("english", func(t *testing.T) {
+		assert.Equal(t, "Hi Frederick,", T("en", "mail.greeting", Params{"name": "Frederick"}))
+	})
+	t.Run("german", func(t *testing.T) {
+		assert.Equal(t, "Hallo Frederick,", T("de", "mail.greeting", Params{"name": "Frederick"}))
+	})
+	t.Run("regional variant", func(t *testing.T) {
+		assert.Equal(t, "Hallo Frederick,", T("de-DE", "mail.greeting", Params{"name": "Frederick"}))
+	})
+	t.Run("unknown language falls back to english", func(t *testing.T) {
+		assert.Equal(t, "Hi Frederick,", T("xx", "mail.greeting", Params{"name": "Frederick"}))
+		assert.Equal(t, "Hi Frederick,", T("", "mail.greeting", Params{"name": "Frederick"}))
+	})
+	t.Run("missing key falls back to english", func(t *testing.T) {
+		delete(catalogs["de"].translations, "mail.greeting")
+		defer func() { catalogs["de"].translations["mail.greeting"] = "Hallo {name}," }()
+
+		assert.Equal(t, "Hi Frederick,", T("de", "mail.greeting", Params{"name": "Frederick"}))
+	})
+	t.Run("unknown key", func(t *testing.T) {
+		assert.Equal(t, "some.unknown.key", T("de", "some.unknown.key"))
+	})
+	t.Run("placeholders in values", func(t *testing.T) {
+		assert.Equal(t, "Hi {name},", T("en", "mail.greeting", Params{"name": "{name}"}))
+	})
+}
+
+func TestTN(t *testing.T) {
+	assert.Equal(t, "tomorrow", TN("en", "notifications.account_deletion.when", 1))
+	assert.Equal(t, "in 3 days", TN("en", "notifications.account_deletion.when", 3))
+	assert.Equal(t, "morgen", TN("de", "notifications.account_deletion.when", 1))
+	assert.Equal(t, "in 3 Tagen", TN("de", "notifications.account_deletion.when", 3))
+	assert.Equal(t, "in 0 Tagen", TN("de", "notifications.account_deletion.when", 0))
+}
+
+func TestHasLanguage(t *testing.T) {
+	assert.True(t, HasLanguage("en"))
+	assert.True(t, HasLanguage("de"))
+	assert.True(t, HasLanguage("de-CH"))
+	assert.False(t, HasLanguage("xx"))
+	assert.False(t, HasLanguage(""))
+	assert.Equal(t, []string{"de", "en"}, GetAvailableLanguages())
+}
+
+func TestFormatDate(t *testing.T) {
+	date := time.Date(2021, time.March, 3, 14, 30, 0, 0, time.UTC)
+
+	assert.Equal(t, "March 3, 2021 14:30 UTC", FormatDate("en", date))
+	assert.Equal(t, "3. März 2021, 14:30 UTC", FormatDate("de", date))
+}
+
+func TestHumanizeDuration(t *testing.T) {
+	t.Run("english", func(t *testing.T) {
+		assert.Equal(t, "one hour", HumanizeDuration("en", time.Hour))
+		assert.Equal(t, "2 days and 2 hours", HumanizeDuration("en", 50*time.Hour))
+		assert.Equal(t, "one week, one day and 2 hours", HumanizeDuration("en", 194*time.Hour))
+	})
+	t.Run("german", func(t *testing.T) {
+		assert.Equal(t, "einer Stunde", HumanizeDuration("de", time.Hour))
+		assert.Equal(t, "2 Tagen und 2 Stunden", HumanizeDuration("de", 50*time.Hour))
+		assert.Equal(t, "einer Woche, einem Tag und 2 Stunden", HumanizeDuration("de", 194*time.Hour))
+	})
+}
@@ -29,6 +29,7 @@ import (
 	"code.vikunja.io/api/pkg/models"
 	"code.vikunja.io/api/pkg/modules/keyvalue"
 	migrator "code.vikunja.io/api/pkg/modules/migration"
+	"code.vikunja.io/api/pkg/modules/stream"
 	"code.vikunja.io/api/pkg/notifications"
 	"code.vikunja.io/api/pkg/red"
 	"code.vikunja.io/api/pkg/user"
@@ -94,15 +95,20 @@ func FullInit() {
 	cron.Init()
 	models.RegisterReminderCron()
 	models.RegisterOverdueReminderCron()
+	models.RegisterDigestCron()
 	user.RegisterTokenCleanupCron()
+	user.RegisterSessionCleanupCron()
 	user.RegisterDeletionNotificationCron()
 	models.RegisterUserDeletionCron()
 	models.RegisterOldExportCleanupCron()
+	models.RegisterTaskTemplateCron()
+	models.RegisterTrashPurgeCron()
 
 	// Start processing events
 	go func() {
 		models.RegisterListeners()
 		user.RegisterListeners()
+		stream.Init()
 		err := events.InitEvents()
 		if err != nil {
 			log.Fatal(err.Error())
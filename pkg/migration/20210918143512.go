@@ -0,0 +1,66 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package migration
+
+import (
+	"time"
+
+	"src.techknowlogick.com/xormigrate"
+	"xorm.io/xorm"
+)
+
+type customFields20210918143512 struct {
+	ID          int64     `xorm:"bigint autoincr not null unique pk" json:"id"`
+	Title       string    `xorm:"varchar(250) not null" json:"title"`
+	Type        string    `xorm:"varchar(20) not null" json:"type"`
+	Options     []string  `xorm:"JSON null" json:"options"`
+	ListID      int64     `xorm:"bigint null index" json:"list_id"`
+	NamespaceID int64     `xorm:"bigint null index" json:"namespace_id"`
+	CreatedByID int64     `xorm:"bigint not null" json:"-"`
+	Created     time.Time `xorm:"created not null" json:"created"`
+	Updated     time.Time `xorm:"updated not null" json:"updated"`
+}
+
+func (customFields20210918143512) TableName() string {
+	return "custom_fields"
+}
+
+type taskCustomFieldValues20210918143512 struct {
+	ID          int64     `xorm:"bigint autoincr not null unique pk" json:"-"`
+	TaskID      int64     `xorm:"bigint not null INDEX" json:"-"`
+	FieldID     int64     `xorm:"bigint not null INDEX" json:"-"`
+	Value       string    `xorm:"text null" json:"-"`
+	NumberValue float64   `xorm:"double null" json:"-"`
+	DateValue   time.Time `xorm:"DATETIME null 'date_value'" json:"-"`
+}
+
+func (taskCustomFieldValues20210918143512) TableName() string {
+	return "task_custom_field_values"
+}
+
+func init() {
+	migrations = append(migrations, &xormigrate.Migration{
+		ID:          "20210918143512",
+		Description: "Add custom fields for lists and namespaces",
+		Migrate: func(tx *xorm.Engine) error {
+			return tx.Sync2(customFields20210918143512{}, taskCustomFieldValues20210918143512{})
+		},
+		Rollback: func(tx *xorm.Engine) error {
+			return nil
+		},
+	})
+}
@@ -0,0 +1,65 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package migration
+
+import (
+	"time"
+
+	"src.techknowlogick.com/xormigrate"
+	"xorm.io/xorm"
+)
+
+type taskTemplateSubtask20210920091544 struct {
+	Title         string `json:"title"`
+	Description   string `json:"description"`
+	DueDateOffset int64  `json:"due_date_offset"`
+}
+
+type taskTemplates20210920091544 struct {
+	ID              int64                                `xorm:"bigint autoincr not null unique pk" json:"id"`
+	ListID          int64                                `xorm:"bigint not null INDEX" json:"list_id"`
+	Title           string                               `xorm:"varchar(250) not null" json:"title"`
+	TaskTitle       string                               `xorm:"varchar(250) not null" json:"task_title"`
+	Description     string                               `xorm:"longtext null" json:"description"`
+	LabelIDs        []int64                              `xorm:"JSON null 'label_ids'" json:"label_ids"`
+	AssigneeIDs     []int64                              `xorm:"JSON null 'assignee_ids'" json:"assignee_ids"`
+	DueDateOffset   int64                                `xorm:"bigint null" json:"due_date_offset"`
+	ReminderOffsets []int64                              `xorm:"JSON null" json:"reminder_offsets"`
+	Subtasks        []*taskTemplateSubtask20210920091544 `xorm:"JSON null" json:"subtasks"`
+	Schedule        string                               `xorm:"varchar(250) null" json:"schedule"`
+	NextRun         time.Time                            `xorm:"DATETIME null 'next_run'" json:"next_run"`
+	CreatedByID     int64                                `xorm:"bigint not null" json:"-"`
+	Created         time.Time                            `xorm:"created not null" json:"created"`
+	Updated         time.Time                            `xorm:"updated not null" json:"updated"`
+}
+
+func (taskTemplates20210920091544) TableName() string {
+	return "task_templates"
+}
+
+func init() {
+	migrations = append(migrations, &xormigrate.Migration{
+		ID:          "20210920091544",
+		Description: "Add task templates",
+		Migrate: func(tx *xorm.Engine) error {
+			return tx.Sync2(taskTemplates20210920091544{})
+		},
+		Rollback: func(tx *xorm.Engine) error {
+			return nil
+		},
+	})
+}
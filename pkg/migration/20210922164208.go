@@ -0,0 +1,51 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package migration
+
+import (
+	"time"
+
+	"src.techknowlogick.com/xormigrate"
+	"xorm.io/xorm"
+)
+
+type taskChanges20210922164208 struct {
+	ID       int64     `xorm:"bigint autoincr not null unique pk" json:"id"`
+	TaskID   int64     `xorm:"bigint not null INDEX" json:"task_id"`
+	Field    string    `xorm:"varchar(50) not null" json:"field"`
+	OldValue string    `xorm:"longtext null" json:"old_value"`
+	NewValue string    `xorm:"longtext null" json:"new_value"`
+	UserID   int64     `xorm:"bigint not null" json:"-"`
+	Created  time.Time `xorm:"created not null INDEX" json:"created"`
+}
+
+func (taskChanges20210922164208) TableName() string {
+	return "task_changes"
+}
+
+func init() {
+	migrations = append(migrations, &xormigrate.Migration{
+		ID:          "20210922164208",
+		Description: "Add a change history for tasks",
+		Migrate: func(tx *xorm.Engine) error {
+			return tx.Sync2(taskChanges20210922164208{})
+		},
+		Rollback: func(tx *xorm.Engine) error {
+			return nil
+		},
+	})
+}
@@ -0,0 +1,65 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package migration
+
+import (
+	"time"
+
+	"src.techknowlogick.com/xormigrate"
+	"xorm.io/xorm"
+)
+
+type tasks20210924103815 struct {
+	Deleted time.Time `xorm:"datetime null INDEX" json:"-"`
+}
+
+func (tasks20210924103815) TableName() string {
+	return "tasks"
+}
+
+type lists20210924103815 struct {
+	Deleted time.Time `xorm:"datetime null INDEX" json:"-"`
+}
+
+func (lists20210924103815) TableName() string {
+	return "lists"
+}
+
+type namespaces20210924103815 struct {
+	Deleted time.Time `xorm:"datetime null INDEX" json:"-"`
+}
+
+func (namespaces20210924103815) TableName() string {
+	return "namespaces"
+}
+
+func init() {
+	migrations = append(migrations, &xormigrate.Migration{
+		ID:          "20210924103815",
+		Description: "Add a deleted timestamp to tasks, lists and namespaces for the trash",
+		Migrate: func(tx *xorm.Engine) error {
+			return tx.Sync2(
+				tasks20210924103815{},
+				lists20210924103815{},
+				namespaces20210924103815{},
+			)
+		},
+		Rollback: func(tx *xorm.Engine) error {
+			return nil
+		},
+	})
+}
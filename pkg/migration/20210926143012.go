@@ -0,0 +1,51 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package migration
+
+import (
+	"time"
+
+	"src.techknowlogick.com/xormigrate"
+	"xorm.io/xorm"
+)
+
+type sessions20210926143012 struct {
+	ID               string    `xorm:"varchar(50) not null unique pk" json:"id"`
+	UserID           int64     `xorm:"bigint not null INDEX" json:"-"`
+	RefreshTokenHash string    `xorm:"varchar(45) not null unique" json:"-"`
+	DeviceInfo       string    `xorm:"text null" json:"device_info"`
+	IPAddress        string    `xorm:"varchar(50) null" json:"ip_address"`
+	LastActive       time.Time `xorm:"DATETIME not null" json:"last_active"`
+	Created          time.Time `xorm:"created not null" json:"created"`
+}
+
+func (sessions20210926143012) TableName() string {
+	return "sessions"
+}
+
+func init() {
+	migrations = append(migrations, &xormigrate.Migration{
+		ID:          "20210926143012",
+		Description: "Add user sessions with refresh tokens",
+		Migrate: func(tx *xorm.Engine) error {
+			return tx.Sync2(sessions20210926143012{})
+		},
+		Rollback: func(tx *xorm.Engine) error {
+			return nil
+		},
+	})
+}
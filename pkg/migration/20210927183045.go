@@ -0,0 +1,55 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package migration
+
+import (
+	"time"
+
+	"src.techknowlogick.com/xormigrate"
+	"xorm.io/xorm"
+)
+
+type webauthnCredentials20210927183045 struct {
+	ID              int64     `xorm:"bigint autoincr not null unique pk" json:"id"`
+	UserID          int64     `xorm:"bigint not null INDEX" json:"-"`
+	Name            string    `xorm:"varchar(250) not null" json:"name"`
+	CredentialID    string    `xorm:"varchar(250) not null unique" json:"-"`
+	PublicKey       []byte    `xorm:"blob not null" json:"-"`
+	AttestationType string    `xorm:"varchar(50) null" json:"-"`
+	Transports      []string  `xorm:"JSON null" json:"-"`
+	AAGUID          []byte    `xorm:"blob null" json:"-"`
+	SignCount       int64     `xorm:"bigint not null default 0" json:"-"`
+	LastUsed        time.Time `xorm:"DATETIME null" json:"last_used"`
+	Created         time.Time `xorm:"created not null" json:"created"`
+}
+
+func (webauthnCredentials20210927183045) TableName() string {
+	return "webauthn_credentials"
+}
+
+func init() {
+	migrations = append(migrations, &xormigrate.Migration{
+		ID:          "20210927183045",
+		Description: "Add webauthn credentials",
+		Migrate: func(tx *xorm.Engine) error {
+			return tx.Sync2(webauthnCredentials20210927183045{})
+		},
+		Rollback: func(tx *xorm.Engine) error {
+			return nil
+		},
+	})
+}
@@ -0,0 +1,51 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package migration
+
+import (
+	"time"
+
+	"src.techknowlogick.com/xormigrate"
+	"xorm.io/xorm"
+)
+
+type appPasswords20210928154310 struct {
+	ID           int64     `xorm:"bigint autoincr not null unique pk" json:"id"`
+	UserID       int64     `xorm:"bigint not null INDEX" json:"-"`
+	Name         string    `xorm:"varchar(250) not null" json:"name"`
+	PasswordHash string    `xorm:"varchar(64) not null unique" json:"-"`
+	CaldavOnly   bool      `xorm:"bool not null default true" json:"caldav_only"`
+	LastUsed     time.Time `xorm:"DATETIME null" json:"last_used"`
+	Created      time.Time `xorm:"created not null" json:"created"`
+}
+
+func (appPasswords20210928154310) TableName() string {
+	return "app_passwords"
+}
+
+func init() {
+	migrations = append(migrations, &xormigrate.Migration{
+		ID:          "20210928154310",
+		Description: "Add app passwords",
+		Migrate: func(tx *xorm.Engine) error {
+			return tx.Sync2(appPasswords20210928154310{})
+		},
+		Rollback: func(tx *xorm.Engine) error {
+			return nil
+		},
+	})
+}
@@ -0,0 +1,70 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package migration
+
+import (
+	"time"
+
+	"src.techknowlogick.com/xormigrate"
+	"xorm.io/xorm"
+)
+
+type oauth2Clients20210929103012 struct {
+	ID               int64     `xorm:"bigint autoincr not null unique pk" json:"id"`
+	ClientID         string    `xorm:"varchar(50) not null unique" json:"client_id"`
+	Name             string    `xorm:"varchar(250) not null" json:"name"`
+	RedirectURIs     []string  `xorm:"JSON not null 'redirect_uris'" json:"redirect_uris"`
+	Confidential     bool      `xorm:"bool not null default false" json:"confidential"`
+	ClientSecretHash string    `xorm:"varchar(45) null" json:"-"`
+	OwnerID          int64     `xorm:"bigint not null INDEX" json:"-"`
+	Created          time.Time `xorm:"created not null" json:"created"`
+}
+
+func (oauth2Clients20210929103012) TableName() string {
+	return "oauth2_clients"
+}
+
+type oauth2Grants20210929103012 struct {
+	ID               string    `xorm:"varchar(50) not null unique pk" json:"id"`
+	ClientID         string    `xorm:"varchar(50) not null INDEX" json:"-"`
+	UserID           int64     `xorm:"bigint not null INDEX" json:"-"`
+	Scopes           []string  `xorm:"JSON not null" json:"scopes"`
+	RefreshTokenHash string    `xorm:"varchar(45) not null unique" json:"-"`
+	LastUsed         time.Time `xorm:"DATETIME not null" json:"last_used"`
+	Created          time.Time `xorm:"created not null" json:"created"`
+}
+
+func (oauth2Grants20210929103012) TableName() string {
+	return "oauth2_grants"
+}
+
+func init() {
+	migrations = append(migrations, &xormigrate.Migration{
+		ID:          "20210929103012",
+		Description: "Add oauth2 clients and grants",
+		Migrate: func(tx *xorm.Engine) error {
+			err := tx.Sync2(oauth2Clients20210929103012{})
+			if err != nil {
+				return err
+			}
+			return tx.Sync2(oauth2Grants20210929103012{})
+		},
+		Rollback: func(tx *xorm.Engine) error {
+			return nil
+		},
+	})
+}
@@ -0,0 +1,49 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package migration
+
+import (
+	"time"
+
+	"src.techknowlogick.com/xormigrate"
+	"xorm.io/xorm"
+)
+
+type jwtKeys20210929161547 struct {
+	ID         string    `xorm:"varchar(50) not null unique pk" json:"id"`
+	Algorithm  string    `xorm:"varchar(10) not null" json:"algorithm"`
+	PrivateKey string    `xorm:"text not null" json:"-"`
+	RetiredAt  time.Time `xorm:"DATETIME null" json:"retired_at"`
+	Created    time.Time `xorm:"created not null" json:"created"`
+}
+
+func (jwtKeys20210929161547) TableName() string {
+	return "jwt_keys"
+}
+
+func init() {
+	migrations = append(migrations, &xormigrate.Migration{
+		ID:          "20210929161547",
+		Description: "Add jwt keys",
+		Migrate: func(tx *xorm.Engine) error {
+			return tx.Sync2(jwtKeys20210929161547{})
+		},
+		Rollback: func(tx *xorm.Engine) error {
+			return nil
+		},
+	})
+}
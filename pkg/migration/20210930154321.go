@@ -0,0 +1,54 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package migration
+
+import (
+	"time"
+
+	"src.techknowlogick.com/xormigrate"
+	"xorm.io/xorm"
+)
+
+type notificationChannels20210930154321 struct {
+	ID           int64     `xorm:"bigint autoincr not null unique pk" json:"id"`
+	NotifiableID int64     `xorm:"bigint not null index" json:"-"`
+	Type         string    `xorm:"varchar(50) not null" json:"type"`
+	Title        string    `xorm:"varchar(250) null" json:"title"`
+	URL          string    `xorm:"text not null" json:"url"`
+	Target       string    `xorm:"varchar(250) null" json:"target"`
+	Token        string    `xorm:"text null" json:"token"`
+	Enabled      bool      `xorm:"bool not null default true" json:"enabled"`
+	Created      time.Time `xorm:"created not null" json:"created"`
+	Updated      time.Time `xorm:"updated not null" json:"updated"`
+}
+
+func (notificationChannels20210930154321) TableName() string {
+	return "notification_channels"
+}
+
+func init() {
+	migrations = append(migrations, &xormigrate.Migration{
+		ID:          "20210930154321",
+		Description: "Add notification channels",
+		Migrate: func(tx *xorm.Engine) error {
+			return tx.Sync2(notificationChannels20210930154321{})
+		},
+		Rollback: func(tx *xorm.Engine) error {
+			return nil
+		},
+	})
+}
@@ -0,0 +1,66 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package migration
+
+import (
+	"time"
+
+	"src.techknowlogick.com/xormigrate"
+	"xorm.io/xorm"
+)
+
+type users20211001102143 struct {
+	DigestInterval string `xorm:"varchar(10) null" json:"-"`
+	DigestHour     int    `xorm:"null" json:"-"`
+}
+
+func (users20211001102143) TableName() string {
+	return "users"
+}
+
+type notificationDigestItems20211001102143 struct {
+	ID           int64     `xorm:"bigint autoincr not null unique pk" json:"-"`
+	NotifiableID int64     `xorm:"bigint not null index" json:"-"`
+	Notification string    `xorm:"varchar(250) not null" json:"-"`
+	ListID       int64     `xorm:"bigint null" json:"-"`
+	TaskID       int64     `xorm:"bigint null" json:"-"`
+	TaskTitle    string    `xorm:"text null" json:"-"`
+	Text         string    `xorm:"text not null" json:"-"`
+	Created      time.Time `xorm:"created not null" json:"-"`
+}
+
+func (notificationDigestItems20211001102143) TableName() string {
+	return "notification_digest_items"
+}
+
+func init() {
+	migrations = append(migrations, &xormigrate.Migration{
+		ID:          "20211001102143",
+		Description: "Add notification digests",
+		Migrate: func(tx *xorm.Engine) error {
+			err := tx.Sync2(users20211001102143{})
+			if err != nil {
+				return err
+			}
+
+			return tx.Sync2(notificationDigestItems20211001102143{})
+		},
+		Rollback: func(tx *xorm.Engine) error {
+			return nil
+		},
+	})
+}
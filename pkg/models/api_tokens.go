@@ -0,0 +1,293 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/utils"
+	"code.vikunja.io/web"
+	"xorm.io/builder"
+	"xorm.io/xorm"
+)
+
+// APITokenPrefix is the prefix of all api tokens. It is used to tell them apart from jwt tokens.
+const APITokenPrefix = "tk_"
+
+// These are all resources an api token can be scoped to
+const (
+	APITokenResourceUser          = "user"
+	APITokenResourceTeams         = "teams"
+	APITokenResourceNamespaces    = "namespaces"
+	APITokenResourceLists         = "lists"
+	APITokenResourceTasks         = "tasks"
+	APITokenResourceLabels        = "labels"
+	APITokenResourceFilters       = "filters"
+	APITokenResourceNotifications = "notifications"
+	APITokenResourceTrash         = "trash"
+)
+
+// APITokenPermission is the permission an api token has on a resource
+type APITokenPermission string
+
+// These are all permissions an api token can have on a resource. A higher permission includes all lower ones.
+const (
+	APITokenPermissionRead  APITokenPermission = "read"
+	APITokenPermissionWrite APITokenPermission = "write"
+	APITokenPermissionAdmin APITokenPermission = "admin"
+)
+
+func (p APITokenPermission) level() int {
+	switch p {
+	case APITokenPermissionRead:
+		return 1
+	case APITokenPermissionWrite:
+		return 2
+	case APITokenPermissionAdmin:
+		return 3
+	}
+	return 0
+}
+
+// APITokenScopes holds all scopes of an api token. Every scope has the form <resource>:<permission>, like
+// tasks:read or lists:admin.
+type APITokenScopes []string
+
+func parseAPITokenScope(scope string) (resource string, permission APITokenPermission, valid bool) {
+	parts := strings.Split(scope, ":")
+	if len(parts) != 2 {
+		return "", "", false
+	}
+
+	switch parts[0] {
+	case APITokenResourceUser,
+		APITokenResourceTeams,
+		APITokenResourceNamespaces,
+		APITokenResourceLists,
+		APITokenResourceTasks,
+		APITokenResourceLabels,
+		APITokenResourceFilters,
+		APITokenResourceNotifications,
+		APITokenResourceTrash:
+	default:
+		return "", "", false
+	}
+
+	permission = APITokenPermission(parts[1])
+	if permission.level() == 0 {
+		return "", "", false
+	}
+
+	return parts[0], permission, true
+}
+
+// GetAPITokenScopesForAllResources returns scopes with the admin permission on every resource.
+func GetAPITokenScopesForAllResources() APITokenScopes {
+	resources := []string{
+		APITokenResourceUser,
+		APITokenResourceTeams,
+		APITokenResourceNamespaces,
+		APITokenResourceLists,
+		APITokenResourceTasks,
+		APITokenResourceLabels,
+		APITokenResourceFilters,
+		APITokenResourceNotifications,
+		APITokenResourceTrash,
+	}
+	scopes := make(APITokenScopes, 0, len(resources))
+	for _, r := range resources {
+		scopes = append(scopes, r+":"+string(APITokenPermissionAdmin))
+	}
+	return scopes
+}
+
+// Allows checks if the scopes include a permission on a resource.
+func (scopes APITokenScopes) Allows(resource string, permission APITokenPermission) bool {
+	for _, scope := range scopes {
+		r, p, valid := parseAPITokenScope(scope)
+		if valid && r == resource && p.level() >= permission.level() {
+			return true
+		}
+	}
+	return false
+}
+
+// APITokenScopesClaim is the name of the jwt claim holding the scopes of an api token.
+// Tokens created from an api token carry the scopes with their go type, which means it is impossible to forge them
+// with a signed jwt token since its claims are always decoded to plain json types.
+const APITokenScopesClaim = "api_token_scopes"
+
+// APIToken is a long-lived personal token to access the api without logging in.
+type APIToken struct {
+	// The unique, numeric id of this api token.
+	ID int64 `xorm:"bigint autoincr not null unique pk" json:"id" param:"token"`
+	// A human-readable name for this token, to know what it is used for.
+	Title string `xorm:"varchar(250) not null" json:"title" valid:"required,runelength(1|250)" minLength:"1" maxLength:"250"`
+	// The actual token. It is only returned once after creating the token, Vikunja only stores a hash of it.
+	Token string `xorm:"-" json:"token,omitempty"`
+	// The scopes of this token, like `tasks:read`, `tasks:write` or `lists:admin`. Available resources are `user`, `teams`, `namespaces`, `lists`, `tasks`, `labels`, `filters`, `notifications` and `trash`, available permissions are `read`, `write` and `admin`. A higher permission includes all lower ones.
+	Scopes APITokenScopes `xorm:"JSON not null" json:"scopes" valid:"required"`
+	// The date when this token expires. It can't be used after this date.
+	ExpiresAt time.Time `xorm:"DATETIME not null" json:"expires_at" valid:"required"`
+	// The last time this token was used to access the api.
+	LastUsed time.Time `xorm:"DATETIME null" json:"last_used"`
+
+	TokenHash string `xorm:"varchar(45) not null unique" json:"-"`
+	OwnerID   int64  `xorm:"bigint not null INDEX" json:"-"`
+
+	// A timestamp when this token was created. You cannot change this value.
+	Created time.Time `xorm:"created not null" json:"created"`
+
+	web.CRUDable `xorm:"-" json:"-"`
+	web.Rights   `xorm:"-" json:"-"`
+}
+
+// TableName returns the table name for api tokens
+func (*APIToken) TableName() string {
+	return "api_tokens"
+}
+
+func getAPITokenByID(s *xorm.Session, id int64) (token *APIToken, err error) {
+	token = &APIToken{}
+	exists, err := s.Where("id = ?", id).Get(token)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrAPITokenDoesNotExist{ID: id}
+	}
+	return
+}
+
+// AuthenticateAPIToken returns the api token matching a raw token and marks it as used.
+// Expired tokens are treated as if they did not exist.
+func AuthenticateAPIToken(s *xorm.Session, rawToken string) (token *APIToken, err error) {
+	token = &APIToken{}
+	exists, err := s.Where("token_hash = ?", utils.Sha256(rawToken)).Get(token)
+	if err != nil {
+		return nil, err
+	}
+	if !exists || token.ExpiresAt.Before(time.Now()) {
+		return nil, ErrAPITokenDoesNotExist{}
+	}
+
+	token.LastUsed = time.Now()
+	_, err = s.
+		Where("id = ?", token.ID).
+		Cols("last_used").
+		NoAutoTime().
+		Update(token)
+	return
+}
+
+// Create creates a new api token
+// @Summary Create a new api token
+// @Description Creates a new api token for the current user. The token itself is only returned once in the response, store it somewhere safe.
+// @tags user
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param token body models.APIToken true "The new api token"
+// @Success 201 {object} models.APIToken "The created api token with the token."
+// @Failure 400 {object} web.HTTPError "Invalid api token object provided."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /user/tokens [put]
+func (t *APIToken) Create(s *xorm.Session, a web.Auth) (err error) {
+	for _, scope := range t.Scopes {
+		if _, _, valid := parseAPITokenScope(scope); !valid {
+			return ErrAPITokenInvalidScope{Scope: scope}
+		}
+	}
+
+	if t.ExpiresAt.Before(time.Now()) {
+		return ErrAPITokenExpiryInPast{ExpiresAt: t.ExpiresAt}
+	}
+
+	b := make([]byte, 20)
+	if _, err = rand.Read(b); err != nil {
+		return err
+	}
+
+	t.ID = 0
+	t.Token = APITokenPrefix + hex.EncodeToString(b)
+	t.TokenHash = utils.Sha256(t.Token)
+	t.OwnerID = a.GetID()
+	t.LastUsed = time.Time{}
+
+	_, err = s.Insert(t)
+	return
+}
+
+// ReadAll returns all api tokens of the current user
+// @Summary Get all api tokens of the current user
+// @Description Returns all api tokens the current user has created. The tokens themselves are never returned.
+// @tags user
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param page query int false "The page number. Used for pagination. If not provided, the first page of results is returned."
+// @Param per_page query int false "The maximum number of items per page. Note this parameter is limited by the configured maximum of items per page."
+// @Param s query string false "Search api tokens by their title."
+// @Success 200 {array} models.APIToken "The api tokens"
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /user/tokens [get]
+func (t *APIToken) ReadAll(s *xorm.Session, a web.Auth, search string, page int, perPage int) (result interface{}, resultCount int, totalItems int64, err error) {
+	if _, is := a.(*LinkSharing); is {
+		return nil, 0, 0, ErrGenericForbidden{}
+	}
+
+	cond := builder.And(
+		builder.Eq{"owner_id": a.GetID()},
+		db.ILIKE("title", search),
+	)
+
+	limit, start := getLimitFromPageIndex(page, perPage)
+
+	tokens := []*APIToken{}
+	query := s.Where(cond).OrderBy("id asc")
+	if limit > 0 {
+		query = query.Limit(limit, start)
+	}
+	err = query.Find(&tokens)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	totalItems, err = s.Where(cond).Count(&APIToken{})
+	return tokens, len(tokens), totalItems, err
+}
+
+// Delete revokes an api token
+// @Summary Revoke an api token
+// @Description Deletes an api token. It can't be used anymore afterwards.
+// @tags user
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param token path int true "Token ID"
+// @Success 200 {object} models.Message "The api token was successfully deleted."
+// @Failure 404 {object} web.HTTPError "The api token does not exist."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /user/tokens/{token} [delete]
+func (t *APIToken) Delete(s *xorm.Session, a web.Auth) (err error) {
+	_, err = s.Where("id = ? AND owner_id = ?", t.ID, a.GetID()).Delete(&APIToken{})
+	return
+}
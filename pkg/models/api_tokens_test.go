@@ -0,0 +1,185 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/user"
+	"code.vikunja.io/api/pkg/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIToken_Create(t *testing.T) {
+	u := &user.User{ID: 1}
+
+	t.Run("normal", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		token := &APIToken{
+			Title:     "ci",
+			Scopes:    APITokenScopes{"tasks:write", "lists:read"},
+			ExpiresAt: time.Now().Add(time.Hour),
+		}
+		err := token.Create(s, u)
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(token.Token, APITokenPrefix))
+		err = s.Commit()
+		assert.NoError(t, err)
+		db.AssertExists(t, "api_tokens", map[string]interface{}{
+			"id":         token.ID,
+			"title":      "ci",
+			"owner_id":   1,
+			"token_hash": utils.Sha256(token.Token),
+		}, false)
+	})
+	t.Run("invalid scope", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		token := &APIToken{
+			Title:     "ci",
+			Scopes:    APITokenScopes{"tasks:everything"},
+			ExpiresAt: time.Now().Add(time.Hour),
+		}
+		err := token.Create(s, u)
+		assert.Error(t, err)
+		assert.True(t, IsErrAPITokenInvalidScope(err))
+	})
+	t.Run("expiry in the past", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		token := &APIToken{
+			Title:     "ci",
+			Scopes:    APITokenScopes{"tasks:read"},
+			ExpiresAt: time.Now().Add(-time.Hour),
+		}
+		err := token.Create(s, u)
+		assert.Error(t, err)
+		assert.True(t, IsErrAPITokenExpiryInPast(err))
+	})
+	t.Run("link share", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		can, err := (&APIToken{}).CanCreate(s, &LinkSharing{ID: 1})
+		assert.NoError(t, err)
+		assert.False(t, can)
+	})
+}
+
+func TestAPIToken_ReadAll(t *testing.T) {
+	db.LoadAndAssertFixtures(t)
+	s := db.NewSession()
+	defer s.Close()
+
+	tokens, count, total, err := (&APIToken{}).ReadAll(s, &user.User{ID: 1}, "", 1, 50)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+	assert.Equal(t, int64(2), total)
+	assert.Equal(t, int64(1), tokens.([]*APIToken)[0].ID)
+	assert.Equal(t, "", tokens.([]*APIToken)[0].Token)
+}
+
+func TestAPIToken_Delete(t *testing.T) {
+	t.Run("normal", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		token := &APIToken{ID: 1}
+		can, err := token.CanDelete(s, &user.User{ID: 1})
+		assert.NoError(t, err)
+		assert.True(t, can)
+		err = token.Delete(s, &user.User{ID: 1})
+		assert.NoError(t, err)
+		err = s.Commit()
+		assert.NoError(t, err)
+		db.AssertMissing(t, "api_tokens", map[string]interface{}{"id": 1})
+	})
+	t.Run("other user", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		can, err := (&APIToken{ID: 3}).CanDelete(s, &user.User{ID: 1})
+		assert.NoError(t, err)
+		assert.False(t, can)
+	})
+	t.Run("nonexisting", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := (&APIToken{ID: 9999}).CanDelete(s, &user.User{ID: 1})
+		assert.Error(t, err)
+		assert.True(t, IsErrAPITokenDoesNotExist(err))
+	})
+}
+
+func TestAuthenticateAPIToken(t *testing.T) {
+	t.Run("normal", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		token, err := AuthenticateAPIToken(s, "tk_2eef46f40ebab3304919ab2e7e39993f75f29d2e")
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), token.ID)
+		assert.Equal(t, int64(1), token.OwnerID)
+		assert.False(t, token.LastUsed.IsZero())
+	})
+	t.Run("expired", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := AuthenticateAPIToken(s, "tk_a5e6f92ddbad68f49ee2c63e52174db0235008c8")
+		assert.Error(t, err)
+		assert.True(t, IsErrAPITokenDoesNotExist(err))
+	})
+	t.Run("wrong token", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := AuthenticateAPIToken(s, "tk_wrong")
+		assert.Error(t, err)
+		assert.True(t, IsErrAPITokenDoesNotExist(err))
+	})
+}
+
+func TestAPITokenScopes_Allows(t *testing.T) {
+	scopes := APITokenScopes{"tasks:read", "lists:admin", "invalid", "labels:nothing"}
+
+	assert.True(t, scopes.Allows(APITokenResourceTasks, APITokenPermissionRead))
+	assert.False(t, scopes.Allows(APITokenResourceTasks, APITokenPermissionWrite))
+	assert.True(t, scopes.Allows(APITokenResourceLists, APITokenPermissionRead))
+	assert.True(t, scopes.Allows(APITokenResourceLists, APITokenPermissionWrite))
+	assert.True(t, scopes.Allows(APITokenResourceLists, APITokenPermissionAdmin))
+	assert.False(t, scopes.Allows(APITokenResourceLabels, APITokenPermissionRead))
+	assert.False(t, scopes.Allows(APITokenResourceNamespaces, APITokenPermissionRead))
+}
@@ -0,0 +1,853 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/user"
+	"code.vikunja.io/web"
+	"xorm.io/builder"
+	"xorm.io/xorm"
+)
+
+// CustomFieldType defines what kind of values a custom field holds
+type CustomFieldType string
+
+// All available custom field types
+const (
+	CustomFieldTypeText        CustomFieldType = "text"
+	CustomFieldTypeNumber      CustomFieldType = "number"
+	CustomFieldTypeDate        CustomFieldType = "date"
+	CustomFieldTypeSelect      CustomFieldType = "select"
+	CustomFieldTypeMultiSelect CustomFieldType = "multiselect"
+	CustomFieldTypeUser        CustomFieldType = "user"
+	CustomFieldTypeURL         CustomFieldType = "url"
+)
+
+func (t CustomFieldType) isValid() bool {
+	switch t {
+	case
+		CustomFieldTypeText,
+		CustomFieldTypeNumber,
+		CustomFieldTypeDate,
+		CustomFieldTypeSelect,
+		CustomFieldTypeMultiSelect,
+		CustomFieldTypeUser,
+		CustomFieldTypeURL:
+		return true
+	}
+	return false
+}
+
+// CustomField is the definition of a typed field which tasks in a list or namespace can have a value for.
+type CustomField struct {
+	// The unique, numeric id of this custom field.
+	ID int64 `xorm:"bigint autoincr not null unique pk" json:"id" param:"customfield"`
+	// The title of this custom field, like "Customer" or "Estimate".
+	Title string `xorm:"varchar(250) not null" json:"title" valid:"runelength(1|250)" minLength:"1" maxLength:"250"`
+	// The type of the values of this field. Can be one of `text`, `number`, `date`, `select`, `multiselect`, `user` or `url`. The type cannot be changed after the field was created.
+	Type CustomFieldType `xorm:"varchar(20) not null" json:"type"`
+	// All options a user can choose from. Only used for the `select` and `multiselect` types.
+	Options []string `xorm:"JSON null" json:"options"`
+
+	// The list this custom field belongs to. Either this or the namespace id is set.
+	ListID int64 `xorm:"bigint null index" json:"list_id" param:"list"`
+	// The namespace this custom field belongs to. Fields of a namespace are available for the tasks of all its lists. Either this or the list id is set.
+	NamespaceID int64 `xorm:"bigint null index" json:"namespace_id" param:"namespace"`
+
+	// The user who created this custom field.
+	CreatedBy   *user.User `xorm:"-" json:"created_by"`
+	CreatedByID int64      `xorm:"bigint not null" json:"-"`
+
+	// A timestamp when this custom field was created. You cannot change this value.
+	Created time.Time `xorm:"created not null" json:"created"`
+	// A timestamp when this custom field was last updated. You cannot change this value.
+	Updated time.Time `xorm:"updated not null" json:"updated"`
+
+	web.CRUDable `xorm:"-" json:"-"`
+	web.Rights   `xorm:"-" json:"-"`
+}
+
+// TableName returns a better table name for custom fields
+func (f *CustomField) TableName() string {
+	return "custom_fields"
+}
+
+// TaskCustomFieldValue holds a value of a custom field for a task. Depending on the field type, only one of
+// the value columns is used. Multi select fields are saved with one row per selected option.
+type TaskCustomFieldValue struct {
+	ID          int64     `xorm:"bigint autoincr not null unique pk" json:"-"`
+	TaskID      int64     `xorm:"bigint not null INDEX" json:"-"`
+	FieldID     int64     `xorm:"bigint not null INDEX" json:"-"`
+	Value       string    `xorm:"text null" json:"-"`
+	NumberValue float64   `xorm:"double null" json:"-"`
+	DateValue   time.Time `xorm:"DATETIME null 'date_value'" json:"-"`
+}
+
+// TableName returns a better table name for custom field values
+func (TaskCustomFieldValue) TableName() string {
+	return "task_custom_field_values"
+}
+
+// Tasks can be filtered and sorted by custom fields using "custom_field_" and the id of the field as property.
+const taskPropertyCustomFieldPrefix = "custom_field_"
+
+func getCustomFieldIDFromTaskProperty(property string) (id int64, is bool) {
+	if !strings.HasPrefix(property, taskPropertyCustomFieldPrefix) {
+		return 0, false
+	}
+
+	id, err := strconv.ParseInt(strings.TrimPrefix(property, taskPropertyCustomFieldPrefix), 10, 64)
+	if err != nil || id < 1 {
+		return 0, false
+	}
+	return id, true
+}
+
+// valueColumn returns the column in which the values of this field are saved.
+func (f *CustomField) valueColumn() string {
+	switch f.Type {
+	case CustomFieldTypeNumber, CustomFieldTypeUser:
+		return "number_value"
+	case CustomFieldTypeDate:
+		return "date_value"
+	default:
+		return "value"
+	}
+}
+
+func (f *CustomField) hasOption(option string) bool {
+	for _, o := range f.Options {
+		if o == option {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *CustomField) validate() error {
+	if (f.ListID == 0) == (f.NamespaceID == 0) {
+		return ErrCustomFieldNeedsListOrNamespace{}
+	}
+
+	if !f.Type.isValid() {
+		return ErrInvalidCustomFieldType{Type: f.Type}
+	}
+
+	if f.Type != CustomFieldTypeSelect && f.Type != CustomFieldTypeMultiSelect {
+		f.Options = nil
+		return nil
+	}
+
+	options := make([]string, 0, len(f.Options))
+	seen := make(map[string]bool, len(f.Options))
+	for _, o := range f.Options {
+		o = strings.TrimSpace(o)
+		if o == "" || seen[o] {
+			continue
+		}
+		seen[o] = true
+		options = append(options, o)
+	}
+	if len(options) == 0 {
+		return ErrCustomFieldNeedsOptions{Type: f.Type}
+	}
+	f.Options = options
+
+	return nil
+}
+
+func getCustomFieldByID(s *xorm.Session, id int64) (f *CustomField, err error) {
+	f = &CustomField{}
+	exists, err := s.Where("id = ?", id).Get(f)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrCustomFieldDoesNotExist{ID: id}
+	}
+	return
+}
+
+// getCustomFieldsForList returns all custom fields of a list and its namespace
+func getCustomFieldsForList(s *xorm.Session, listID int64) (fields map[int64]*CustomField, err error) {
+	l, err := GetListSimpleByID(s, listID)
+	if err != nil {
+		return nil, err
+	}
+
+	all := []*CustomField{}
+	err = s.
+		Where(builder.Or(
+			builder.Eq{"list_id": l.ID},
+			builder.Eq{"namespace_id": l.NamespaceID},
+		)).
+		Find(&all)
+	if err != nil {
+		return
+	}
+
+	fields = make(map[int64]*CustomField, len(all))
+	for _, f := range all {
+		fields[f.ID] = f
+	}
+	return
+}
+
+// Create creates a new custom field
+// @Summary Create a custom field for a list or namespace
+// @Description Creates a new custom field. Fields of a namespace are available for all tasks in all lists of that namespace. The user needs write access to the list or namespace.
+// @tags customfields
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param list path int true "List ID"
+// @Param field body models.CustomField true "The new custom field"
+// @Success 201 {object} models.CustomField "The created custom field."
+// @Failure 400 {object} web.HTTPError "Invalid custom field object provided."
+// @Failure 403 {object} web.HTTPError "The user does not have write access to the list."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /lists/{list}/customfields [put]
+func (f *CustomField) Create(s *xorm.Session, a web.Auth) (err error) {
+	if err = f.validate(); err != nil {
+		return
+	}
+
+	f.ID = 0
+	f.CreatedByID = a.GetID()
+	_, err = s.Insert(f)
+	if err != nil {
+		return
+	}
+
+	f.CreatedBy, err = user.GetFromAuth(a)
+	return
+}
+
+// ReadOne returns a single custom field
+// @Summary Get one custom field
+// @Description Returns one custom field by its ID.
+// @tags customfields
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param list path int true "List ID"
+// @Param customfield path int true "Custom field ID"
+// @Success 200 {object} models.CustomField "The custom field"
+// @Failure 403 {object} web.HTTPError "The user does not have access to the list."
+// @Failure 404 {object} web.HTTPError "The custom field does not exist."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /lists/{list}/customfields/{customfield} [get]
+func (f *CustomField) ReadOne(s *xorm.Session, a web.Auth) (err error) {
+	field, err := getCustomFieldByID(s, f.ID)
+	if err != nil {
+		return
+	}
+	*f = *field
+
+	f.CreatedBy, err = user.GetUserByID(s, f.CreatedByID)
+	if user.IsErrUserDoesNotExist(err) {
+		return nil
+	}
+	return
+}
+
+// ReadAll returns all custom fields of a list or namespace
+// @Summary Get all custom fields of a list
+// @Description Returns all custom fields which are available for the tasks of a list. This includes the fields of the namespace the list belongs to.
+// @tags customfields
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param list path int true "List ID"
+// @Param page query int false "The page number. Used for pagination. If not provided, the first page of results is returned."
+// @Param per_page query int false "The maximum number of items per page. Note this parameter is limited by the configured maximum of items per page."
+// @Param s query string false "Search custom fields by their title."
+// @Success 200 {array} models.CustomField "The custom fields"
+// @Failure 403 {object} web.HTTPError "The user does not have access to the list."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /lists/{list}/customfields [get]
+func (f *CustomField) ReadAll(s *xorm.Session, a web.Auth, search string, page int, perPage int) (result interface{}, resultCount int, totalItems int64, err error) {
+	can, err := f.canReadCustomField(s, a)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	if !can {
+		return nil, 0, 0, ErrGenericForbidden{}
+	}
+
+	var parentCond builder.Cond = builder.Eq{"namespace_id": f.NamespaceID}
+	if f.ListID != 0 {
+		l, err := GetListSimpleByID(s, f.ListID)
+		if err != nil {
+			return nil, 0, 0, err
+		}
+		parentCond = builder.Or(
+			builder.Eq{"list_id": l.ID},
+			builder.Eq{"namespace_id": l.NamespaceID},
+		)
+	}
+
+	cond := builder.And(parentCond, db.ILIKE("title", search))
+
+	limit, start := getLimitFromPageIndex(page, perPage)
+
+	fields := []*CustomField{}
+	query := s.Where(cond).OrderBy("id asc")
+	if limit > 0 {
+		query = query.Limit(limit, start)
+	}
+	err = query.Find(&fields)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	userIDs := make([]int64, 0, len(fields))
+	for _, field := range fields {
+		userIDs = append(userIDs, field.CreatedByID)
+	}
+
+	users, err := user.GetUsersByIDs(s, userIDs)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	for _, field := range fields {
+		field.CreatedBy = users[field.CreatedByID]
+	}
+
+	totalItems, err = s.Where(cond).Count(&CustomField{})
+	return fields, len(fields), totalItems, err
+}
+
+// Update updates a custom field
+// @Summary Update a custom field
+// @Description Updates the title and options of a custom field. Values of tasks which use an option which was removed are removed as well.
+// @tags customfields
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param list path int true "List ID"
+// @Param customfield path int true "Custom field ID"
+// @Param field body models.CustomField true "The custom field with updated values"
+// @Success 200 {object} models.CustomField "The updated custom field."
+// @Failure 400 {object} web.HTTPError "Invalid custom field object provided."
+// @Failure 403 {object} web.HTTPError "The user does not have write access to the list."
+// @Failure 404 {object} web.HTTPError "The custom field does not exist."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /lists/{list}/customfields/{customfield} [post]
+func (f *CustomField) Update(s *xorm.Session, a web.Auth) (err error) {
+	existing, err := getCustomFieldByID(s, f.ID)
+	if err != nil {
+		return
+	}
+
+	// The type and parent of a field can't be changed
+	f.Type = existing.Type
+	f.ListID = existing.ListID
+	f.NamespaceID = existing.NamespaceID
+	if err = f.validate(); err != nil {
+		return
+	}
+
+	_, err = s.
+		Where("id = ?", f.ID).
+		Cols("title", "options").
+		Update(f)
+	if err != nil {
+		return
+	}
+
+	if len(f.Options) > 0 {
+		_, err = s.
+			Where(builder.And(
+				builder.Eq{"field_id": f.ID},
+				builder.NotIn("value", f.Options),
+			)).
+			Delete(&TaskCustomFieldValue{})
+		if err != nil {
+			return
+		}
+	}
+
+	return f.ReadOne(s, a)
+}
+
+// Delete removes a custom field
+// @Summary Delete a custom field
+// @Description Removes a custom field and all its values from all tasks.
+// @tags customfields
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param list path int true "List ID"
+// @Param customfield path int true "Custom field ID"
+// @Success 200 {object} models.Message "The custom field was successfully deleted."
+// @Failure 403 {object} web.HTTPError "The user does not have write access to the list."
+// @Failure 404 {object} web.HTTPError "The custom field does not exist."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /lists/{list}/customfields/{customfield} [delete]
+func (f *CustomField) Delete(s *xorm.Session, a web.Auth) (err error) {
+	_, err = s.Where("field_id = ?", f.ID).Delete(&TaskCustomFieldValue{})
+	if err != nil {
+		return
+	}
+
+	_, err = s.Where("id = ?", f.ID).Delete(&CustomField{})
+	return
+}
+
+func getUserIDFromCustomFieldValue(raw interface{}) (id int64, ok bool) {
+	switch v := raw.(type) {
+	case float64:
+		return int64(v), float64(int64(v)) == v
+	case int64:
+		return v, true
+	case int:
+		return int64(v), true
+	case string:
+		id, err := strconv.ParseInt(v, 10, 64)
+		return id, err == nil
+	case *user.User:
+		if v == nil {
+			return 0, false
+		}
+		return v.ID, true
+	case map[string]interface{}:
+		return getUserIDFromCustomFieldValue(v["id"])
+	}
+	return 0, false
+}
+
+// toValues converts a value of a custom field as it was provided by a user to the values which are saved in the db.
+func (f *CustomField) toValues(s *xorm.Session, taskID int64, raw interface{}) (values []*TaskCustomFieldValue, err error) {
+	invalid := ErrInvalidCustomFieldValue{FieldID: f.ID, Type: f.Type, Value: raw}
+	value := &TaskCustomFieldValue{
+		TaskID:  taskID,
+		FieldID: f.ID,
+	}
+
+	switch f.Type {
+	case CustomFieldTypeText:
+		text, is := raw.(string)
+		if !is {
+			return nil, invalid
+		}
+		value.Value = text
+	case CustomFieldTypeURL:
+		text, is := raw.(string)
+		if !is {
+			return nil, invalid
+		}
+		u, err := url.Parse(text)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return nil, invalid
+		}
+		value.Value = text
+	case CustomFieldTypeNumber:
+		switch n := raw.(type) {
+		case float64:
+			value.NumberValue = n
+		case int64:
+			value.NumberValue = float64(n)
+		case int:
+			value.NumberValue = float64(n)
+		case string:
+			value.NumberValue, err = strconv.ParseFloat(n, 64)
+			if err != nil {
+				return nil, invalid
+			}
+		default:
+			return nil, invalid
+		}
+	case CustomFieldTypeDate:
+		switch d := raw.(type) {
+		case time.Time:
+			value.DateValue = d
+		case string:
+			value.DateValue, err = time.Parse(time.RFC3339, d)
+			if err != nil {
+				return nil, invalid
+			}
+		default:
+			return nil, invalid
+		}
+	case CustomFieldTypeSelect:
+		option, is := raw.(string)
+		if !is || !f.hasOption(option) {
+			return nil, invalid
+		}
+		value.Value = option
+	case CustomFieldTypeMultiSelect:
+		var options []string
+		switch o := raw.(type) {
+		case []string:
+			options = o
+		case []interface{}:
+			for _, option := range o {
+				str, is := option.(string)
+				if !is {
+					return nil, invalid
+				}
+				options = append(options, str)
+			}
+		default:
+			return nil, invalid
+		}
+
+		seen := make(map[string]bool, len(options))
+		for _, option := range options {
+			if !f.hasOption(option) {
+				return nil, invalid
+			}
+			if seen[option] {
+				continue
+			}
+			seen[option] = true
+			values = append(values, &TaskCustomFieldValue{
+				TaskID:  taskID,
+				FieldID: f.ID,
+				Value:   option,
+			})
+		}
+		return values, nil
+	case CustomFieldTypeUser:
+		id, ok := getUserIDFromCustomFieldValue(raw)
+		if !ok {
+			return nil, invalid
+		}
+		_, err = user.GetUserByID(s, id)
+		if user.IsErrUserDoesNotExist(err) {
+			return nil, invalid
+		}
+		if err != nil {
+			return nil, err
+		}
+		value.NumberValue = float64(id)
+	}
+
+	return []*TaskCustomFieldValue{value}, nil
+}
+
+// getNativeFilterValue converts a filter value from a task collection to the type of the column the field's
+// values are saved in.
+func (f *CustomField) getNativeFilterValue(raw interface{}) (value interface{}, err error) {
+	if values, is := raw.([]interface{}); is {
+		native := make([]interface{}, 0, len(values))
+		for _, v := range values {
+			nv, err := f.getNativeFilterValue(v)
+			if err != nil {
+				return nil, err
+			}
+			native = append(native, nv)
+		}
+		return native, nil
+	}
+
+	str, is := raw.(string)
+	if !is {
+		return nil, ErrInvalidTaskFilterValue{Field: taskPropertyCustomFieldPrefix + strconv.FormatInt(f.ID, 10), Value: raw}
+	}
+
+	switch f.Type {
+	case CustomFieldTypeNumber:
+		value, err = strconv.ParseFloat(str, 64)
+	case CustomFieldTypeUser:
+		value, err = strconv.ParseInt(str, 10, 64)
+	case CustomFieldTypeDate:
+		var date time.Time
+		date, err = time.Parse(time.RFC3339, str)
+		value = date.In(config.GetTimeZone())
+	default:
+		value = str
+	}
+
+	if err != nil {
+		return nil, ErrInvalidTaskFilterValue{Field: taskPropertyCustomFieldPrefix + strconv.FormatInt(f.ID, 10), Value: raw}
+	}
+	return
+}
+
+func getCustomFieldFilterCond(s *xorm.Session, fieldID int64, f *taskFilter, includeNulls bool) (cond builder.Cond, err error) {
+	field, err := getCustomFieldByID(s, fieldID)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := field.getNativeFilterValue(f.value)
+	if err != nil {
+		return nil, err
+	}
+
+	valueCond, err := getFilterCond(&taskFilter{
+		field:      field.valueColumn(),
+		value:      value,
+		comparator: f.comparator,
+	}, false)
+	if err != nil {
+		return nil, err
+	}
+
+	cond = builder.In(
+		"id",
+		builder.
+			Select("task_id").
+			From("task_custom_field_values").
+			Where(builder.And(builder.Eq{"field_id": fieldID}, valueCond)),
+	)
+
+	if includeNulls {
+		cond = builder.Or(cond, builder.NotIn(
+			"id",
+			builder.
+				Select("task_id").
+				From("task_custom_field_values").
+				Where(builder.Eq{"field_id": fieldID}),
+		))
+	}
+
+	return
+}
+
+// Used to sort tasks by the value of a custom field. Multi select fields are sorted by their first option.
+func getCustomFieldSortSubquery(s *xorm.Session, fieldID int64) (string, error) {
+	field, err := getCustomFieldByID(s, fieldID)
+	if err != nil {
+		return "", err
+	}
+
+	return "(SELECT MIN(" + field.valueColumn() + ") FROM task_custom_field_values " +
+		"WHERE task_custom_field_values.task_id = tasks.id " +
+		"AND task_custom_field_values.field_id = " + strconv.FormatInt(field.ID, 10) + ")", nil
+}
+
+// getCustomFieldValuesForTasks returns the values of all custom fields of the given tasks, with the task id
+// and then the field id as keys.
+func getCustomFieldValuesForTasks(s *xorm.Session, taskIDs []int64) (values map[int64]map[int64]interface{}, err error) {
+	values = make(map[int64]map[int64]interface{})
+	if len(taskIDs) == 0 {
+		return
+	}
+
+	rows := []*TaskCustomFieldValue{}
+	err = s.In("task_id", taskIDs).OrderBy("id asc").Find(&rows)
+	if err != nil || len(rows) == 0 {
+		return
+	}
+
+	fieldIDs := make([]int64, 0, len(rows))
+	for _, r := range rows {
+		fieldIDs = append(fieldIDs, r.FieldID)
+	}
+
+	fields := make(map[int64]*CustomField)
+	err = s.In("id", fieldIDs).Find(&fields)
+	if err != nil {
+		return
+	}
+
+	userIDs := []int64{}
+	for _, r := range rows {
+		if f, exists := fields[r.FieldID]; exists && f.Type == CustomFieldTypeUser {
+			userIDs = append(userIDs, int64(r.NumberValue))
+		}
+	}
+
+	users, err := user.GetUsersByIDs(s, userIDs)
+	if err != nil {
+		return
+	}
+
+	for _, r := range rows {
+		f, exists := fields[r.FieldID]
+		if !exists {
+			continue
+		}
+
+		if _, has := values[r.TaskID]; !has {
+			values[r.TaskID] = make(map[int64]interface{})
+		}
+		taskValues := values[r.TaskID]
+
+		switch f.Type {
+		case CustomFieldTypeNumber:
+			taskValues[f.ID] = r.NumberValue
+		case CustomFieldTypeDate:
+			taskValues[f.ID] = r.DateValue.In(config.GetTimeZone())
+		case CustomFieldTypeUser:
+			u, exists := users[int64(r.NumberValue)]
+			if !exists {
+				continue
+			}
+			taskValues[f.ID] = u
+		case CustomFieldTypeMultiSelect:
+			options, _ := taskValues[f.ID].([]string)
+			taskValues[f.ID] = append(options, r.Value)
+		default:
+			taskValues[f.ID] = r.Value
+		}
+	}
+
+	return
+}
+
+// updateCustomFieldValues replaces all custom field values of a task with the ones in t.CustomFields.
+// If t.CustomFields is nil, the existing values are left untouched.
+func (t *Task) updateCustomFieldValues(s *xorm.Session) (err error) {
+	if t.CustomFields == nil {
+		return nil
+	}
+
+	fields, err := getCustomFieldsForList(s, t.ListID)
+	if err != nil {
+		return err
+	}
+
+	newValues := []*TaskCustomFieldValue{}
+	for fieldID, raw := range t.CustomFields {
+		field, exists := fields[fieldID]
+		if !exists {
+			return ErrCustomFieldNotAvailableForTask{FieldID: fieldID, TaskID: t.ID}
+		}
+
+		// Setting a field to null removes its value
+		if raw == nil {
+			continue
+		}
+
+		values, err := field.toValues(s, t.ID, raw)
+		if err != nil {
+			return err
+		}
+		newValues = append(newValues, values...)
+	}
+
+	_, err = s.Where("task_id = ?", t.ID).Delete(&TaskCustomFieldValue{})
+	if err != nil {
+		return
+	}
+
+	if len(newValues) > 0 {
+		_, err = s.Insert(&newValues)
+	}
+	return
+}
+
+// removeUnavailableCustomFieldValues removes all values of fields which don't belong to the list or namespace
+// of the task. This is used when moving a task to another list.
+func (t *Task) removeUnavailableCustomFieldValues(s *xorm.Session) (err error) {
+	fields, err := getCustomFieldsForList(s, t.ListID)
+	if err != nil {
+		return err
+	}
+
+	fieldIDs := make([]int64, 0, len(fields))
+	for id := range fields {
+		fieldIDs = append(fieldIDs, id)
+	}
+
+	var cond builder.Cond = builder.Eq{"task_id": t.ID}
+	if len(fieldIDs) > 0 {
+		cond = builder.And(cond, builder.NotIn("field_id", fieldIDs))
+	}
+	_, err = s.Where(cond).Delete(&TaskCustomFieldValue{})
+	if err != nil {
+		return
+	}
+
+	for id := range t.CustomFields {
+		if _, exists := fields[id]; !exists {
+			delete(t.CustomFields, id)
+		}
+	}
+	return
+}
+
+// reloadCustomFieldValues sets the custom field values of a task to the ones saved in the db.
+func (t *Task) reloadCustomFieldValues(s *xorm.Session) error {
+	values, err := getCustomFieldValuesForTasks(s, []int64{t.ID})
+	if err != nil {
+		return err
+	}
+	t.CustomFields = values[t.ID]
+	return nil
+}
+
+// deleteCustomFields removes all custom fields matching a condition together with their values.
+func deleteCustomFields(s *xorm.Session, cond builder.Cond) (err error) {
+	fieldIDs := []int64{}
+	err = s.Table("custom_fields").Where(cond).Cols("id").Find(&fieldIDs)
+	if err != nil || len(fieldIDs) == 0 {
+		return
+	}
+
+	_, err = s.In("field_id", fieldIDs).Delete(&TaskCustomFieldValue{})
+	if err != nil {
+		return
+	}
+
+	_, err = s.In("id", fieldIDs).Delete(&CustomField{})
+	return
+}
+
+// duplicateCustomFields copies all custom fields of a list to another list and returns a map with the old
+// field ids as keys and the new ones as values.
+func duplicateCustomFields(s *xorm.Session, fromListID, toListID int64, doer web.Auth) (fieldMap map[int64]int64, err error) {
+	fields := []*CustomField{}
+	err = s.Where("list_id = ?", fromListID).Find(&fields)
+	if err != nil {
+		return
+	}
+
+	fieldMap = make(map[int64]int64, len(fields))
+	for _, f := range fields {
+		oldID := f.ID
+		f.ListID = toListID
+		if err := f.Create(s, doer); err != nil {
+			return nil, err
+		}
+		fieldMap[oldID] = f.ID
+	}
+
+	return
+}
+
+// mapCustomFieldValues changes the keys of custom field values according to fieldMap and drops all values of
+// fields which are not available.
+func mapCustomFieldValues(values map[int64]interface{}, fieldMap map[int64]int64, available map[int64]*CustomField) map[int64]interface{} {
+	if values == nil {
+		return nil
+	}
+
+	mapped := make(map[int64]interface{}, len(values))
+	for id, value := range values {
+		if newID, has := fieldMap[id]; has {
+			id = newID
+		}
+		if _, has := available[id]; !has {
+			continue
+		}
+		mapped[id] = value
+	}
+	return mapped
+}
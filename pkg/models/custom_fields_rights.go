@@ -0,0 +1,109 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"code.vikunja.io/web"
+	"xorm.io/xorm"
+)
+
+// CanRead checks if the user can see a custom field
+func (f *CustomField) CanRead(s *xorm.Session, a web.Auth) (bool, int, error) {
+	if _, is := a.(*LinkSharing); is {
+		return false, 0, nil
+	}
+
+	field, err := getCustomFieldByID(s, f.ID)
+	if err != nil {
+		return false, 0, err
+	}
+
+	// Fields of a namespace are visible to everyone who can see a list in that namespace
+	if f.ListID != 0 && field.NamespaceID != 0 {
+		l, err := GetListSimpleByID(s, f.ListID)
+		if err != nil {
+			return false, 0, err
+		}
+		if l.NamespaceID == field.NamespaceID {
+			return l.CanRead(s, a)
+		}
+	}
+
+	return field.canReadParent(s, a)
+}
+
+// CanCreate checks if the user can create a custom field for a list or namespace
+func (f *CustomField) CanCreate(s *xorm.Session, a web.Auth) (bool, error) {
+	return f.canWriteParent(s, a)
+}
+
+// CanUpdate checks if the user can update a custom field
+func (f *CustomField) CanUpdate(s *xorm.Session, a web.Auth) (bool, error) {
+	return f.canWriteExistingCustomField(s, a)
+}
+
+// CanDelete checks if the user can delete a custom field
+func (f *CustomField) CanDelete(s *xorm.Session, a web.Auth) (bool, error) {
+	return f.canWriteExistingCustomField(s, a)
+}
+
+func (f *CustomField) canWriteExistingCustomField(s *xorm.Session, a web.Auth) (bool, error) {
+	field, err := getCustomFieldByID(s, f.ID)
+	if err != nil {
+		return false, err
+	}
+	return field.canWriteParent(s, a)
+}
+
+// canReadCustomField checks if the user can see the custom fields of the list or namespace of the field.
+func (f *CustomField) canReadCustomField(s *xorm.Session, a web.Auth) (bool, error) {
+	can, _, err := f.canReadParent(s, a)
+	return can, err
+}
+
+func (f *CustomField) canReadParent(s *xorm.Session, a web.Auth) (bool, int, error) {
+	if _, is := a.(*LinkSharing); is {
+		return false, 0, nil
+	}
+
+	if f.ListID > 0 {
+		return (&List{ID: f.ListID}).CanRead(s, a)
+	}
+
+	if f.NamespaceID > 0 {
+		return (&Namespace{ID: f.NamespaceID}).CanRead(s, a)
+	}
+
+	return false, 0, nil
+}
+
+// Only users with write access to the list or namespace a custom field belongs to may manage it.
+func (f *CustomField) canWriteParent(s *xorm.Session, a web.Auth) (bool, error) {
+	if _, is := a.(*LinkSharing); is {
+		return false, nil
+	}
+
+	if f.ListID > 0 {
+		return (&List{ID: f.ListID}).CanWrite(s, a)
+	}
+
+	if f.NamespaceID > 0 {
+		return (&Namespace{ID: f.NamespaceID}).CanWrite(s, a)
+	}
+
+	return false, nil
+}
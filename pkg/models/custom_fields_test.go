@@ -0,0 +1,378 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"testing"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/user"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCustomField_Create(t *testing.T) {
+	u := &user.User{ID: 1}
+
+	t.Run("list field", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		f := &CustomField{
+			Title:  "Invoice",
+			Type:   CustomFieldTypeURL,
+			ListID: 1,
+		}
+		err := f.Create(s, u)
+		assert.NoError(t, err)
+		assert.Equal(t, u.ID, f.CreatedBy.ID)
+		err = s.Commit()
+		assert.NoError(t, err)
+		db.AssertExists(t, "custom_fields", map[string]interface{}{
+			"id":            f.ID,
+			"title":         "Invoice",
+			"type":          "url",
+			"list_id":       1,
+			"created_by_id": 1,
+		}, false)
+	})
+	t.Run("namespace select field", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		f := &CustomField{
+			Title:       "Size",
+			Type:        CustomFieldTypeSelect,
+			Options:     []string{"S", " M ", "S", "", "L"},
+			NamespaceID: 1,
+		}
+		err := f.Create(s, u)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"S", "M", "L"}, f.Options)
+	})
+	t.Run("select without options", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		f := &CustomField{
+			Title:  "Size",
+			Type:   CustomFieldTypeMultiSelect,
+			ListID: 1,
+		}
+		err := f.Create(s, u)
+		assert.Error(t, err)
+		assert.True(t, IsErrCustomFieldNeedsOptions(err))
+	})
+	t.Run("invalid type", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		f := &CustomField{
+			Title:  "Size",
+			Type:   "color",
+			ListID: 1,
+		}
+		err := f.Create(s, u)
+		assert.Error(t, err)
+		assert.True(t, IsErrInvalidCustomFieldType(err))
+	})
+	t.Run("list and namespace", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		f := &CustomField{
+			Title:       "Size",
+			Type:        CustomFieldTypeText,
+			ListID:      1,
+			NamespaceID: 1,
+		}
+		err := f.Create(s, u)
+		assert.Error(t, err)
+		assert.True(t, IsErrCustomFieldNeedsListOrNamespace(err))
+	})
+	t.Run("no access to list", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		f := &CustomField{ListID: 5}
+		can, err := f.CanCreate(s, u)
+		assert.NoError(t, err)
+		assert.False(t, can)
+	})
+	t.Run("link share", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		f := &CustomField{ListID: 1}
+		can, err := f.CanCreate(s, &LinkSharing{ID: 1, ListID: 1, Right: RightAdmin})
+		assert.NoError(t, err)
+		assert.False(t, can)
+	})
+}
+
+func TestCustomField_ReadAll(t *testing.T) {
+	u := &user.User{ID: 1}
+
+	t.Run("list with namespace fields", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		f := &CustomField{ListID: 1}
+		fields, _, total, err := f.ReadAll(s, u, "", 0, 50)
+		assert.NoError(t, err)
+		assert.Len(t, fields, 3)
+		assert.Equal(t, int64(3), total)
+	})
+	t.Run("namespace", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		f := &CustomField{NamespaceID: 1}
+		fields, _, _, err := f.ReadAll(s, u, "", 0, 50)
+		assert.NoError(t, err)
+		assert.Len(t, fields, 1)
+		assert.Equal(t, int64(2), fields.([]*CustomField)[0].ID)
+	})
+	t.Run("no access to list", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		f := &CustomField{ListID: 5}
+		_, _, _, err := f.ReadAll(s, u, "", 0, 50)
+		assert.Error(t, err)
+		assert.True(t, IsErrGenericForbidden(err))
+	})
+}
+
+func TestCustomField_Update(t *testing.T) {
+	u := &user.User{ID: 1}
+
+	t.Run("removes values of removed options", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		f := &CustomField{
+			ID:      3,
+			Title:   "Status",
+			Type:    CustomFieldTypeText,
+			Options: []string{"backlog", "done"},
+		}
+		err := f.Update(s, u)
+		assert.NoError(t, err)
+		assert.Equal(t, CustomFieldTypeSelect, f.Type)
+		err = s.Commit()
+		assert.NoError(t, err)
+		db.AssertExists(t, "custom_fields", map[string]interface{}{
+			"id":    3,
+			"title": "Status",
+			"type":  "select",
+		}, false)
+		db.AssertMissing(t, "task_custom_field_values", map[string]interface{}{
+			"id": 3,
+		})
+	})
+	t.Run("no access", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		f := &CustomField{ID: 4}
+		can, err := f.CanUpdate(s, u)
+		assert.NoError(t, err)
+		assert.False(t, can)
+	})
+}
+
+func TestCustomField_Delete(t *testing.T) {
+	db.LoadAndAssertFixtures(t)
+	s := db.NewSession()
+	defer s.Close()
+
+	f := &CustomField{ID: 1}
+	err := f.Delete(s, &user.User{ID: 1})
+	assert.NoError(t, err)
+	err = s.Commit()
+	assert.NoError(t, err)
+	db.AssertMissing(t, "custom_fields", map[string]interface{}{
+		"id": 1,
+	})
+	db.AssertMissing(t, "task_custom_field_values", map[string]interface{}{
+		"field_id": 1,
+	})
+}
+
+func TestTask_UpdateCustomFields(t *testing.T) {
+	u := &user.User{ID: 1}
+
+	t.Run("normal", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		task := &Task{
+			ID:     1,
+			Title:  "test",
+			ListID: 1,
+			CustomFields: map[int64]interface{}{
+				1: "Globex",
+				2: nil,
+				3: "done",
+			},
+		}
+		err := task.Update(s, u)
+		assert.NoError(t, err)
+		assert.Equal(t, map[int64]interface{}{1: "Globex", 3: "done"}, task.CustomFields)
+		err = s.Commit()
+		assert.NoError(t, err)
+		db.AssertExists(t, "task_custom_field_values", map[string]interface{}{
+			"task_id":  1,
+			"field_id": 1,
+			"value":    "Globex",
+		}, false)
+		db.AssertMissing(t, "task_custom_field_values", map[string]interface{}{
+			"task_id":  1,
+			"field_id": 2,
+		})
+	})
+	t.Run("invalid option", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		task := &Task{
+			ID:           1,
+			Title:        "test",
+			ListID:       1,
+			CustomFields: map[int64]interface{}{3: "shipped"},
+		}
+		err := task.Update(s, u)
+		assert.Error(t, err)
+		assert.True(t, IsErrInvalidCustomFieldValue(err))
+	})
+	t.Run("invalid number", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		task := &Task{
+			ID:           1,
+			Title:        "test",
+			ListID:       1,
+			CustomFields: map[int64]interface{}{2: "a lot"},
+		}
+		err := task.Update(s, u)
+		assert.Error(t, err)
+		assert.True(t, IsErrInvalidCustomFieldValue(err))
+	})
+	t.Run("field of another list", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		task := &Task{
+			ID:           1,
+			Title:        "test",
+			ListID:       1,
+			CustomFields: map[int64]interface{}{4: "Something"},
+		}
+		err := task.Update(s, u)
+		assert.Error(t, err)
+		assert.True(t, IsErrCustomFieldNotAvailableForTask(err))
+	})
+	t.Run("move to another list", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		task := &Task{
+			ID:     1,
+			Title:  "test",
+			ListID: 2,
+		}
+		err := task.Update(s, u)
+		assert.NoError(t, err)
+		// Only the namespace field is still available
+		assert.Equal(t, map[int64]interface{}{2: 2.5}, task.CustomFields)
+		err = s.Commit()
+		assert.NoError(t, err)
+		db.AssertMissing(t, "task_custom_field_values", map[string]interface{}{
+			"task_id":  1,
+			"field_id": 1,
+		})
+	})
+}
+
+func TestTaskCollection_CustomFields(t *testing.T) {
+	u := &user.User{ID: 1}
+
+	t.Run("filter", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		tc := &TaskCollection{
+			ListID:      1,
+			FilterBy:    []string{"custom_field_1"},
+			FilterValue: []string{"ACME"},
+		}
+		tasks, _, _, err := tc.ReadAll(s, u, "", 0, 50)
+		assert.NoError(t, err)
+		assert.Len(t, tasks, 1)
+		assert.Equal(t, int64(1), tasks.([]*Task)[0].ID)
+	})
+	t.Run("filter number", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		tc := &TaskCollection{
+			ListID:           1,
+			FilterBy:         []string{"custom_field_2"},
+			FilterValue:      []string{"2"},
+			FilterComparator: []string{"greater"},
+		}
+		tasks, _, _, err := tc.ReadAll(s, u, "", 0, 50)
+		assert.NoError(t, err)
+		assert.Len(t, tasks, 1)
+	})
+	t.Run("sort", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		tc := &TaskCollection{
+			ListID:  1,
+			SortBy:  []string{"custom_field_1", "id"},
+			OrderBy: []string{"desc", "asc"},
+		}
+		tasks, _, _, err := tc.ReadAll(s, u, "", 0, 50)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, tasks)
+		// Task 1 is the only one with a value
+		assert.Equal(t, int64(1), tasks.([]*Task)[0].ID)
+	})
+}
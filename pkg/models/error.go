@@ -19,6 +19,7 @@ package models
 import (
 	"fmt"
 	"net/http"
+	"time"
 
 	"code.vikunja.io/api/pkg/config"
 	"code.vikunja.io/web"
@@ -763,6 +764,62 @@ func (err ErrInvalidTaskFilterValue) HTTPError() web.HTTPError {
 	}
 }
 
+// ErrInvalidTaskRepeatRule represents an error where the provided recurrence rule of a task is invalid
+type ErrInvalidTaskRepeatRule struct {
+	Rule   string
+	Reason string
+}
+
+// IsErrInvalidTaskRepeatRule checks if an error is ErrInvalidTaskRepeatRule.
+func IsErrInvalidTaskRepeatRule(err error) bool {
+	_, ok := err.(ErrInvalidTaskRepeatRule)
+	return ok
+}
+
+func (err ErrInvalidTaskRepeatRule) Error() string {
+	return fmt.Sprintf("Task repeat rule is invalid [Rule: %s, Reason: %s]", err.Rule, err.Reason)
+}
+
+// ErrCodeInvalidTaskRepeatRule holds the unique world-error code of this error
+const ErrCodeInvalidTaskRepeatRule = 4020
+
+// HTTPError holds the http error description
+func (err ErrInvalidTaskRepeatRule) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusBadRequest,
+		Code:     ErrCodeInvalidTaskRepeatRule,
+		Message:  fmt.Sprintf("The repeat rule '%s' is invalid: %s.", err.Rule, err.Reason),
+	}
+}
+
+// ErrInvalidTaskFilterExpression represents an error where the filter query of a task collection cannot be parsed
+type ErrInvalidTaskFilterExpression struct {
+	Expression string
+	Reason     string
+}
+
+// IsErrInvalidTaskFilterExpression checks if an error is ErrInvalidTaskFilterExpression.
+func IsErrInvalidTaskFilterExpression(err error) bool {
+	_, ok := err.(ErrInvalidTaskFilterExpression)
+	return ok
+}
+
+func (err ErrInvalidTaskFilterExpression) Error() string {
+	return fmt.Sprintf("Task filter expression is invalid [Expression: %s, Reason: %s]", err.Expression, err.Reason)
+}
+
+// ErrCodeInvalidTaskFilterExpression holds the unique world-error code of this error
+const ErrCodeInvalidTaskFilterExpression = 4021
+
+// HTTPError holds the http error description
+func (err ErrInvalidTaskFilterExpression) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusBadRequest,
+		Code:     ErrCodeInvalidTaskFilterExpression,
+		Message:  "The task filter is invalid: " + err.Reason,
+	}
+}
+
 // =================
 // Namespace errors
 // =================
@@ -1079,6 +1136,30 @@ func (err ErrTeamDoesNotHaveAccessToList) HTTPError() web.HTTPError {
 	return web.HTTPError{HTTPCode: http.StatusForbidden, Code: ErrCodeTeamDoesNotHaveAccessToList, Message: "This team does not have access to the list."}
 }
 
+// ErrTeamIsExternallyManaged represents an error where a user tries to change the members of a team which is managed
+// by an external auth provider.
+type ErrTeamIsExternallyManaged struct {
+	TeamID int64
+}
+
+// IsErrTeamIsExternallyManaged checks if an error is ErrTeamIsExternallyManaged.
+func IsErrTeamIsExternallyManaged(err error) bool {
+	_, ok := err.(ErrTeamIsExternallyManaged)
+	return ok
+}
+
+func (err ErrTeamIsExternallyManaged) Error() string {
+	return fmt.Sprintf("Team is externally managed [TeamID: %d]", err.TeamID)
+}
+
+// ErrCodeTeamIsExternallyManaged holds the unique world-error code of this error
+const ErrCodeTeamIsExternallyManaged = 6008
+
+// HTTPError holds the http error description
+func (err ErrTeamIsExternallyManaged) HTTPError() web.HTTPError {
+	return web.HTTPError{HTTPCode: http.StatusPreconditionFailed, Code: ErrCodeTeamIsExternallyManaged, Message: "The members of this team are managed by an external auth provider and cannot be changed."}
+}
+
 // ====================
 // User <-> List errors
 // ====================
@@ -1570,3 +1651,1012 @@ func (err ErrLinkSharePasswordInvalid) HTTPError() web.HTTPError {
 		Message:  "The provided link share password is invalid.",
 	}
 }
+
+// ==============
+// Webhook errors
+// ==============
+
+// ErrWebhookDoesNotExist represents an error where a webhook does not exist
+type ErrWebhookDoesNotExist struct {
+	WebhookID int64
+}
+
+// IsErrWebhookDoesNotExist checks if an error is ErrWebhookDoesNotExist.
+func IsErrWebhookDoesNotExist(err error) bool {
+	_, ok := err.(ErrWebhookDoesNotExist)
+	return ok
+}
+
+func (err ErrWebhookDoesNotExist) Error() string {
+	return fmt.Sprintf("Webhook does not exist [WebhookID: %d]", err.WebhookID)
+}
+
+// ErrCodeWebhookDoesNotExist holds the unique world-error code of this error
+const ErrCodeWebhookDoesNotExist = 14001
+
+// HTTPError holds the http error description
+func (err ErrWebhookDoesNotExist) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusNotFound,
+		Code:     ErrCodeWebhookDoesNotExist,
+		Message:  "This webhook does not exist.",
+	}
+}
+
+// ErrInvalidWebhookEvent represents an error where a webhook should listen to an event which does not exist
+type ErrInvalidWebhookEvent struct {
+	EventName string
+}
+
+// IsErrInvalidWebhookEvent checks if an error is ErrInvalidWebhookEvent.
+func IsErrInvalidWebhookEvent(err error) bool {
+	_, ok := err.(ErrInvalidWebhookEvent)
+	return ok
+}
+
+func (err ErrInvalidWebhookEvent) Error() string {
+	return fmt.Sprintf("Webhook event is invalid [EventName: %s]", err.EventName)
+}
+
+// ErrCodeInvalidWebhookEvent holds the unique world-error code of this error
+const ErrCodeInvalidWebhookEvent = 14002
+
+// HTTPError holds the http error description
+func (err ErrInvalidWebhookEvent) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusBadRequest,
+		Code:     ErrCodeInvalidWebhookEvent,
+		Message:  fmt.Sprintf("The webhook event '%s' does not exist.", err.EventName),
+	}
+}
+
+// ErrInvalidWebhookTargetURL represents an error where a webhook target url is not a valid http url
+type ErrInvalidWebhookTargetURL struct {
+	TargetURL string
+}
+
+// IsErrInvalidWebhookTargetURL checks if an error is ErrInvalidWebhookTargetURL.
+func IsErrInvalidWebhookTargetURL(err error) bool {
+	_, ok := err.(ErrInvalidWebhookTargetURL)
+	return ok
+}
+
+func (err ErrInvalidWebhookTargetURL) Error() string {
+	return fmt.Sprintf("Webhook target url is invalid [TargetURL: %s]", err.TargetURL)
+}
+
+// ErrCodeInvalidWebhookTargetURL holds the unique world-error code of this error
+const ErrCodeInvalidWebhookTargetURL = 14003
+
+// HTTPError holds the http error description
+func (err ErrInvalidWebhookTargetURL) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusBadRequest,
+		Code:     ErrCodeInvalidWebhookTargetURL,
+		Message:  "The webhook target url must be a valid http or https url.",
+	}
+}
+
+// ErrWebhookNeedsListOrNamespace represents an error where a webhook belongs to neither or both a list and a namespace
+type ErrWebhookNeedsListOrNamespace struct{}
+
+// IsErrWebhookNeedsListOrNamespace checks if an error is ErrWebhookNeedsListOrNamespace.
+func IsErrWebhookNeedsListOrNamespace(err error) bool {
+	_, ok := err.(ErrWebhookNeedsListOrNamespace)
+	return ok
+}
+
+func (err ErrWebhookNeedsListOrNamespace) Error() string {
+	return "Webhook needs either a list or a namespace"
+}
+
+// ErrCodeWebhookNeedsListOrNamespace holds the unique world-error code of this error
+const ErrCodeWebhookNeedsListOrNamespace = 14004
+
+// HTTPError holds the http error description
+func (err ErrWebhookNeedsListOrNamespace) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusBadRequest,
+		Code:     ErrCodeWebhookNeedsListOrNamespace,
+		Message:  "A webhook needs to belong to either a list or a namespace.",
+	}
+}
+
+// ==================
+// Time entry errors
+// ==================
+
+// ErrTaskTimeEntryDoesNotExist represents an error where a task time entry does not exist
+type ErrTaskTimeEntryDoesNotExist struct {
+	ID     int64
+	TaskID int64
+}
+
+// IsErrTaskTimeEntryDoesNotExist checks if an error is ErrTaskTimeEntryDoesNotExist.
+func IsErrTaskTimeEntryDoesNotExist(err error) bool {
+	_, ok := err.(ErrTaskTimeEntryDoesNotExist)
+	return ok
+}
+
+func (err ErrTaskTimeEntryDoesNotExist) Error() string {
+	return fmt.Sprintf("Task time entry does not exist [ID: %d, TaskID: %d]", err.ID, err.TaskID)
+}
+
+// ErrCodeTaskTimeEntryDoesNotExist holds the unique world-error code of this error
+const ErrCodeTaskTimeEntryDoesNotExist = 15001
+
+// HTTPError holds the http error description
+func (err ErrTaskTimeEntryDoesNotExist) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusNotFound,
+		Code:     ErrCodeTaskTimeEntryDoesNotExist,
+		Message:  "This time entry does not exist.",
+	}
+}
+
+// ErrInvalidTaskTimeEntryRange represents an error where a time entry ends before it starts
+type ErrInvalidTaskTimeEntryRange struct {
+	Start time.Time
+	End   time.Time
+}
+
+// IsErrInvalidTaskTimeEntryRange checks if an error is ErrInvalidTaskTimeEntryRange.
+func IsErrInvalidTaskTimeEntryRange(err error) bool {
+	_, ok := err.(ErrInvalidTaskTimeEntryRange)
+	return ok
+}
+
+func (err ErrInvalidTaskTimeEntryRange) Error() string {
+	return fmt.Sprintf("Task time entry range is invalid [Start: %s, End: %s]", err.Start, err.End)
+}
+
+// ErrCodeInvalidTaskTimeEntryRange holds the unique world-error code of this error
+const ErrCodeInvalidTaskTimeEntryRange = 15002
+
+// HTTPError holds the http error description
+func (err ErrInvalidTaskTimeEntryRange) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusBadRequest,
+		Code:     ErrCodeInvalidTaskTimeEntryRange,
+		Message:  "A time entry needs a start and an end date and must not end before it starts.",
+	}
+}
+
+// ErrNoRunningTaskTimer represents an error where a user wants to stop a timer which is not running
+type ErrNoRunningTaskTimer struct {
+	TaskID int64
+	UserID int64
+}
+
+// IsErrNoRunningTaskTimer checks if an error is ErrNoRunningTaskTimer.
+func IsErrNoRunningTaskTimer(err error) bool {
+	_, ok := err.(ErrNoRunningTaskTimer)
+	return ok
+}
+
+func (err ErrNoRunningTaskTimer) Error() string {
+	return fmt.Sprintf("No timer is running for this task [TaskID: %d, UserID: %d]", err.TaskID, err.UserID)
+}
+
+// ErrCodeNoRunningTaskTimer holds the unique world-error code of this error
+const ErrCodeNoRunningTaskTimer = 15003
+
+// HTTPError holds the http error description
+func (err ErrNoRunningTaskTimer) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusPreconditionFailed,
+		Code:     ErrCodeNoRunningTaskTimer,
+		Message:  "You don't have a running timer on this task.",
+	}
+}
+
+// =============
+// Custom fields
+// =============
+
+// ErrCustomFieldDoesNotExist represents an error where a custom field does not exist
+type ErrCustomFieldDoesNotExist struct {
+	ID int64
+}
+
+// IsErrCustomFieldDoesNotExist checks if an error is ErrCustomFieldDoesNotExist.
+func IsErrCustomFieldDoesNotExist(err error) bool {
+	_, ok := err.(ErrCustomFieldDoesNotExist)
+	return ok
+}
+
+func (err ErrCustomFieldDoesNotExist) Error() string {
+	return fmt.Sprintf("Custom field does not exist [ID: %d]", err.ID)
+}
+
+// ErrCodeCustomFieldDoesNotExist holds the unique world-error code of this error
+const ErrCodeCustomFieldDoesNotExist = 16001
+
+// HTTPError holds the http error description
+func (err ErrCustomFieldDoesNotExist) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusNotFound,
+		Code:     ErrCodeCustomFieldDoesNotExist,
+		Message:  "This custom field does not exist.",
+	}
+}
+
+// ErrInvalidCustomFieldType represents an error where the type of a custom field is invalid
+type ErrInvalidCustomFieldType struct {
+	Type CustomFieldType
+}
+
+// IsErrInvalidCustomFieldType checks if an error is ErrInvalidCustomFieldType.
+func IsErrInvalidCustomFieldType(err error) bool {
+	_, ok := err.(ErrInvalidCustomFieldType)
+	return ok
+}
+
+func (err ErrInvalidCustomFieldType) Error() string {
+	return fmt.Sprintf("Custom field type is invalid [Type: %s]", err.Type)
+}
+
+// ErrCodeInvalidCustomFieldType holds the unique world-error code of this error
+const ErrCodeInvalidCustomFieldType = 16002
+
+// HTTPError holds the http error description
+func (err ErrInvalidCustomFieldType) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusBadRequest,
+		Code:     ErrCodeInvalidCustomFieldType,
+		Message:  fmt.Sprintf("The custom field type '%s' is invalid.", err.Type),
+	}
+}
+
+// ErrCustomFieldNeedsListOrNamespace represents an error where a custom field belongs to neither or both a list and a namespace
+type ErrCustomFieldNeedsListOrNamespace struct{}
+
+// IsErrCustomFieldNeedsListOrNamespace checks if an error is ErrCustomFieldNeedsListOrNamespace.
+func IsErrCustomFieldNeedsListOrNamespace(err error) bool {
+	_, ok := err.(ErrCustomFieldNeedsListOrNamespace)
+	return ok
+}
+
+func (err ErrCustomFieldNeedsListOrNamespace) Error() string {
+	return "Custom field needs either a list or a namespace"
+}
+
+// ErrCodeCustomFieldNeedsListOrNamespace holds the unique world-error code of this error
+const ErrCodeCustomFieldNeedsListOrNamespace = 16003
+
+// HTTPError holds the http error description
+func (err ErrCustomFieldNeedsListOrNamespace) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusBadRequest,
+		Code:     ErrCodeCustomFieldNeedsListOrNamespace,
+		Message:  "A custom field needs to belong to either a list or a namespace.",
+	}
+}
+
+// ErrCustomFieldNeedsOptions represents an error where a select custom field has no options
+type ErrCustomFieldNeedsOptions struct {
+	Type CustomFieldType
+}
+
+// IsErrCustomFieldNeedsOptions checks if an error is ErrCustomFieldNeedsOptions.
+func IsErrCustomFieldNeedsOptions(err error) bool {
+	_, ok := err.(ErrCustomFieldNeedsOptions)
+	return ok
+}
+
+func (err ErrCustomFieldNeedsOptions) Error() string {
+	return fmt.Sprintf("Custom field needs options [Type: %s]", err.Type)
+}
+
+// ErrCodeCustomFieldNeedsOptions holds the unique world-error code of this error
+const ErrCodeCustomFieldNeedsOptions = 16004
+
+// HTTPError holds the http error description
+func (err ErrCustomFieldNeedsOptions) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusBadRequest,
+		Code:     ErrCodeCustomFieldNeedsOptions,
+		Message:  fmt.Sprintf("A custom field of type '%s' needs at least one option.", err.Type),
+	}
+}
+
+// ErrCustomFieldNotAvailableForTask represents an error where a value is set for a custom field which does not belong to the list or namespace of the task
+type ErrCustomFieldNotAvailableForTask struct {
+	FieldID int64
+	TaskID  int64
+}
+
+// IsErrCustomFieldNotAvailableForTask checks if an error is ErrCustomFieldNotAvailableForTask.
+func IsErrCustomFieldNotAvailableForTask(err error) bool {
+	_, ok := err.(ErrCustomFieldNotAvailableForTask)
+	return ok
+}
+
+func (err ErrCustomFieldNotAvailableForTask) Error() string {
+	return fmt.Sprintf("Custom field is not available for this task [FieldID: %d, TaskID: %d]", err.FieldID, err.TaskID)
+}
+
+// ErrCodeCustomFieldNotAvailableForTask holds the unique world-error code of this error
+const ErrCodeCustomFieldNotAvailableForTask = 16005
+
+// HTTPError holds the http error description
+func (err ErrCustomFieldNotAvailableForTask) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusBadRequest,
+		Code:     ErrCodeCustomFieldNotAvailableForTask,
+		Message:  "This custom field does not belong to the list or namespace of the task.",
+	}
+}
+
+// ErrInvalidCustomFieldValue represents an error where the value of a custom field does not match its type
+type ErrInvalidCustomFieldValue struct {
+	FieldID int64
+	Type    CustomFieldType
+	Value   interface{}
+}
+
+// IsErrInvalidCustomFieldValue checks if an error is ErrInvalidCustomFieldValue.
+func IsErrInvalidCustomFieldValue(err error) bool {
+	_, ok := err.(ErrInvalidCustomFieldValue)
+	return ok
+}
+
+func (err ErrInvalidCustomFieldValue) Error() string {
+	return fmt.Sprintf("Custom field value is invalid [FieldID: %d, Type: %s, Value: %v]", err.FieldID, err.Type, err.Value)
+}
+
+// ErrCodeInvalidCustomFieldValue holds the unique world-error code of this error
+const ErrCodeInvalidCustomFieldValue = 16006
+
+// HTTPError holds the http error description
+func (err ErrInvalidCustomFieldValue) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusBadRequest,
+		Code:     ErrCodeInvalidCustomFieldValue,
+		Message:  fmt.Sprintf("The value '%v' is not valid for a custom field of type '%s'.", err.Value, err.Type),
+	}
+}
+
+// ==============
+// Task templates
+// ==============
+
+// ErrTaskTemplateDoesNotExist represents an error where a task template does not exist
+type ErrTaskTemplateDoesNotExist struct {
+	ID int64
+}
+
+// IsErrTaskTemplateDoesNotExist checks if an error is ErrTaskTemplateDoesNotExist.
+func IsErrTaskTemplateDoesNotExist(err error) bool {
+	_, ok := err.(ErrTaskTemplateDoesNotExist)
+	return ok
+}
+
+func (err ErrTaskTemplateDoesNotExist) Error() string {
+	return fmt.Sprintf("Task template does not exist [ID: %d]", err.ID)
+}
+
+// ErrCodeTaskTemplateDoesNotExist holds the unique world-error code of this error
+const ErrCodeTaskTemplateDoesNotExist = 17001
+
+// HTTPError holds the http error description
+func (err ErrTaskTemplateDoesNotExist) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusNotFound,
+		Code:     ErrCodeTaskTemplateDoesNotExist,
+		Message:  "This task template does not exist.",
+	}
+}
+
+// ErrInvalidTaskTemplateSchedule represents an error where the schedule of a task template is not a valid cron expression
+type ErrInvalidTaskTemplateSchedule struct {
+	Schedule string
+	Reason   string
+}
+
+// IsErrInvalidTaskTemplateSchedule checks if an error is ErrInvalidTaskTemplateSchedule.
+func IsErrInvalidTaskTemplateSchedule(err error) bool {
+	_, ok := err.(ErrInvalidTaskTemplateSchedule)
+	return ok
+}
+
+func (err ErrInvalidTaskTemplateSchedule) Error() string {
+	return fmt.Sprintf("Task template schedule is invalid [Schedule: %s, Reason: %s]", err.Schedule, err.Reason)
+}
+
+// ErrCodeInvalidTaskTemplateSchedule holds the unique world-error code of this error
+const ErrCodeInvalidTaskTemplateSchedule = 17002
+
+// HTTPError holds the http error description
+func (err ErrInvalidTaskTemplateSchedule) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusBadRequest,
+		Code:     ErrCodeInvalidTaskTemplateSchedule,
+		Message:  "The schedule is not a valid cron expression: " + err.Reason,
+	}
+}
+
+// =====
+// Trash
+// =====
+
+// ErrTrashItemDoesNotExist represents an error where a task, list or namespace is not in the trash
+type ErrTrashItemDoesNotExist struct {
+	Kind string
+	ID   int64
+}
+
+// IsErrTrashItemDoesNotExist checks if an error is ErrTrashItemDoesNotExist.
+func IsErrTrashItemDoesNotExist(err error) bool {
+	_, ok := err.(ErrTrashItemDoesNotExist)
+	return ok
+}
+
+func (err ErrTrashItemDoesNotExist) Error() string {
+	return fmt.Sprintf("Trash item does not exist [Kind: %s, ID: %d]", err.Kind, err.ID)
+}
+
+// ErrCodeTrashItemDoesNotExist holds the unique world-error code of this error
+const ErrCodeTrashItemDoesNotExist = 18001
+
+// HTTPError holds the http error description
+func (err ErrTrashItemDoesNotExist) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusNotFound,
+		Code:     ErrCodeTrashItemDoesNotExist,
+		Message:  fmt.Sprintf("This %s is not in the trash.", err.Kind),
+	}
+}
+
+// ErrTrashItemParentIsDeleted represents an error where a task or list cannot be restored because the list or
+// namespace it belongs to is still in the trash
+type ErrTrashItemParentIsDeleted struct {
+	Kind     string
+	ID       int64
+	ParentID int64
+}
+
+// IsErrTrashItemParentIsDeleted checks if an error is ErrTrashItemParentIsDeleted.
+func IsErrTrashItemParentIsDeleted(err error) bool {
+	_, ok := err.(ErrTrashItemParentIsDeleted)
+	return ok
+}
+
+func (err ErrTrashItemParentIsDeleted) Error() string {
+	return fmt.Sprintf("Trash item parent is deleted [Kind: %s, ID: %d, ParentID: %d]", err.Kind, err.ID, err.ParentID)
+}
+
+// ErrCodeTrashItemParentIsDeleted holds the unique world-error code of this error
+const ErrCodeTrashItemParentIsDeleted = 18002
+
+// HTTPError holds the http error description
+func (err ErrTrashItemParentIsDeleted) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusPreconditionFailed,
+		Code:     ErrCodeTrashItemParentIsDeleted,
+		Message:  fmt.Sprintf("This %s cannot be restored because its parent is still in the trash. Restore the parent first.", err.Kind),
+	}
+}
+
+// ==========
+// API Tokens
+// ==========
+
+// ErrAPITokenDoesNotExist represents an error where an api token does not exist
+type ErrAPITokenDoesNotExist struct {
+	ID int64
+}
+
+// IsErrAPITokenDoesNotExist checks if an error is ErrAPITokenDoesNotExist.
+func IsErrAPITokenDoesNotExist(err error) bool {
+	_, ok := err.(ErrAPITokenDoesNotExist)
+	return ok
+}
+
+func (err ErrAPITokenDoesNotExist) Error() string {
+	return fmt.Sprintf("API token does not exist [ID: %d]", err.ID)
+}
+
+// ErrCodeAPITokenDoesNotExist holds the unique world-error code of this error
+const ErrCodeAPITokenDoesNotExist = 19001
+
+// HTTPError holds the http error description
+func (err ErrAPITokenDoesNotExist) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusNotFound,
+		Code:     ErrCodeAPITokenDoesNotExist,
+		Message:  "This api token does not exist.",
+	}
+}
+
+// ErrAPITokenInvalidScope represents an error where an api token should be created with a scope which does not exist
+type ErrAPITokenInvalidScope struct {
+	Scope string
+}
+
+// IsErrAPITokenInvalidScope checks if an error is ErrAPITokenInvalidScope.
+func IsErrAPITokenInvalidScope(err error) bool {
+	_, ok := err.(ErrAPITokenInvalidScope)
+	return ok
+}
+
+func (err ErrAPITokenInvalidScope) Error() string {
+	return fmt.Sprintf("API token scope is invalid [Scope: %s]", err.Scope)
+}
+
+// ErrCodeAPITokenInvalidScope holds the unique world-error code of this error
+const ErrCodeAPITokenInvalidScope = 19002
+
+// HTTPError holds the http error description
+func (err ErrAPITokenInvalidScope) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusBadRequest,
+		Code:     ErrCodeAPITokenInvalidScope,
+		Message:  fmt.Sprintf("The scope '%s' is invalid.", err.Scope),
+	}
+}
+
+// ErrAPITokenMissingScope represents an error where an api token is used for a route it has no scope for
+type ErrAPITokenMissingScope struct {
+	Resource   string
+	Permission APITokenPermission
+}
+
+// IsErrAPITokenMissingScope checks if an error is ErrAPITokenMissingScope.
+func IsErrAPITokenMissingScope(err error) bool {
+	_, ok := err.(ErrAPITokenMissingScope)
+	return ok
+}
+
+func (err ErrAPITokenMissingScope) Error() string {
+	return fmt.Sprintf("API token is missing a scope [Resource: %s, Permission: %s]", err.Resource, err.Permission)
+}
+
+// ErrCodeAPITokenMissingScope holds the unique world-error code of this error
+const ErrCodeAPITokenMissingScope = 19003
+
+// HTTPError holds the http error description
+func (err ErrAPITokenMissingScope) HTTPError() web.HTTPError {
+	if err.Resource == "" {
+		return web.HTTPError{
+			HTTPCode: http.StatusForbidden,
+			Code:     ErrCodeAPITokenMissingScope,
+			Message:  "This route cannot be used with an api token.",
+		}
+	}
+	return web.HTTPError{
+		HTTPCode: http.StatusForbidden,
+		Code:     ErrCodeAPITokenMissingScope,
+		Message:  fmt.Sprintf("This api token needs the scope '%s:%s' for this route.", err.Resource, err.Permission),
+	}
+}
+
+// ErrAPITokenExpiryInPast represents an error where an api token should be created with an expiry date in the past
+type ErrAPITokenExpiryInPast struct {
+	ExpiresAt time.Time
+}
+
+// IsErrAPITokenExpiryInPast checks if an error is ErrAPITokenExpiryInPast.
+func IsErrAPITokenExpiryInPast(err error) bool {
+	_, ok := err.(ErrAPITokenExpiryInPast)
+	return ok
+}
+
+func (err ErrAPITokenExpiryInPast) Error() string {
+	return fmt.Sprintf("API token expiry date is in the past [ExpiresAt: %s]", err.ExpiresAt)
+}
+
+// ErrCodeAPITokenExpiryInPast holds the unique world-error code of this error
+const ErrCodeAPITokenExpiryInPast = 19004
+
+// HTTPError holds the http error description
+func (err ErrAPITokenExpiryInPast) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusBadRequest,
+		Code:     ErrCodeAPITokenExpiryInPast,
+		Message:  "The expiry date of an api token must be in the future.",
+	}
+}
+
+// ======
+// OAuth2
+// ======
+
+// ErrOAuth2ClientDoesNotExist represents an error where an oauth2 client does not exist
+type ErrOAuth2ClientDoesNotExist struct {
+	ID       int64
+	ClientID string
+}
+
+// IsErrOAuth2ClientDoesNotExist checks if an error is ErrOAuth2ClientDoesNotExist.
+func IsErrOAuth2ClientDoesNotExist(err error) bool {
+	_, ok := err.(ErrOAuth2ClientDoesNotExist)
+	return ok
+}
+
+func (err ErrOAuth2ClientDoesNotExist) Error() string {
+	return fmt.Sprintf("OAuth2 client does not exist [ID: %d, ClientID: %s]", err.ID, err.ClientID)
+}
+
+// ErrCodeOAuth2ClientDoesNotExist holds the unique world-error code of this error
+const ErrCodeOAuth2ClientDoesNotExist = 20001
+
+// HTTPError holds the http error description
+func (err ErrOAuth2ClientDoesNotExist) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusNotFound,
+		Code:     ErrCodeOAuth2ClientDoesNotExist,
+		Message:  "This oauth2 client does not exist.",
+	}
+}
+
+// OAuth2Error returns the error code defined in the oauth2 spec
+func (err ErrOAuth2ClientDoesNotExist) OAuth2Error() string {
+	return "invalid_client"
+}
+
+// ErrOAuth2InvalidRedirectURI represents an error where a redirect uri is not valid or not registered for a client
+type ErrOAuth2InvalidRedirectURI struct {
+	RedirectURI string
+}
+
+// IsErrOAuth2InvalidRedirectURI checks if an error is ErrOAuth2InvalidRedirectURI.
+func IsErrOAuth2InvalidRedirectURI(err error) bool {
+	_, ok := err.(ErrOAuth2InvalidRedirectURI)
+	return ok
+}
+
+func (err ErrOAuth2InvalidRedirectURI) Error() string {
+	return fmt.Sprintf("OAuth2 redirect uri is invalid [RedirectURI: %s]", err.RedirectURI)
+}
+
+// ErrCodeOAuth2InvalidRedirectURI holds the unique world-error code of this error
+const ErrCodeOAuth2InvalidRedirectURI = 20002
+
+// HTTPError holds the http error description
+func (err ErrOAuth2InvalidRedirectURI) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusBadRequest,
+		Code:     ErrCodeOAuth2InvalidRedirectURI,
+		Message:  fmt.Sprintf("The redirect uri '%s' is invalid or not registered for this client. Redirect uris must be absolute, use https, http on localhost or a private-use scheme like 'com.example.app:' and must not contain a fragment.", err.RedirectURI),
+	}
+}
+
+// OAuth2Error returns the error code defined in the oauth2 spec
+func (err ErrOAuth2InvalidRedirectURI) OAuth2Error() string {
+	return "invalid_request"
+}
+
+// ErrOAuth2InvalidScope represents an error where an oauth2 client requests a scope which does not exist
+type ErrOAuth2InvalidScope struct {
+	Scope string
+}
+
+// IsErrOAuth2InvalidScope checks if an error is ErrOAuth2InvalidScope.
+func IsErrOAuth2InvalidScope(err error) bool {
+	_, ok := err.(ErrOAuth2InvalidScope)
+	return ok
+}
+
+func (err ErrOAuth2InvalidScope) Error() string {
+	return fmt.Sprintf("OAuth2 scope is invalid [Scope: %s]", err.Scope)
+}
+
+// ErrCodeOAuth2InvalidScope holds the unique world-error code of this error
+const ErrCodeOAuth2InvalidScope = 20003
+
+// HTTPError holds the http error description
+func (err ErrOAuth2InvalidScope) HTTPError() web.HTTPError {
+	if err.Scope == "" {
+		return web.HTTPError{
+			HTTPCode: http.StatusBadRequest,
+			Code:     ErrCodeOAuth2InvalidScope,
+			Message:  "At least one scope is required.",
+		}
+	}
+	return web.HTTPError{
+		HTTPCode: http.StatusBadRequest,
+		Code:     ErrCodeOAuth2InvalidScope,
+		Message:  fmt.Sprintf("The scope '%s' is invalid.", err.Scope),
+	}
+}
+
+// OAuth2Error returns the error code defined in the oauth2 spec
+func (err ErrOAuth2InvalidScope) OAuth2Error() string {
+	return "invalid_scope"
+}
+
+// ErrOAuth2InvalidRequest represents an error where an oauth2 request is missing a parameter or has an invalid one
+type ErrOAuth2InvalidRequest struct {
+	Reason string
+}
+
+// IsErrOAuth2InvalidRequest checks if an error is ErrOAuth2InvalidRequest.
+func IsErrOAuth2InvalidRequest(err error) bool {
+	_, ok := err.(ErrOAuth2InvalidRequest)
+	return ok
+}
+
+func (err ErrOAuth2InvalidRequest) Error() string {
+	return fmt.Sprintf("OAuth2 request is invalid [Reason: %s]", err.Reason)
+}
+
+// ErrCodeOAuth2InvalidRequest holds the unique world-error code of this error
+const ErrCodeOAuth2InvalidRequest = 20004
+
+// HTTPError holds the http error description
+func (err ErrOAuth2InvalidRequest) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusBadRequest,
+		Code:     ErrCodeOAuth2InvalidRequest,
+		Message:  err.Reason,
+	}
+}
+
+// OAuth2Error returns the error code defined in the oauth2 spec
+func (err ErrOAuth2InvalidRequest) OAuth2Error() string {
+	return "invalid_request"
+}
+
+// ErrOAuth2InvalidClientCredentials represents an error where an oauth2 client could not be authenticated
+type ErrOAuth2InvalidClientCredentials struct {
+	ClientID string
+}
+
+// IsErrOAuth2InvalidClientCredentials checks if an error is ErrOAuth2InvalidClientCredentials.
+func IsErrOAuth2InvalidClientCredentials(err error) bool {
+	_, ok := err.(ErrOAuth2InvalidClientCredentials)
+	return ok
+}
+
+func (err ErrOAuth2InvalidClientCredentials) Error() string {
+	return fmt.Sprintf("OAuth2 client credentials are invalid [ClientID: %s]", err.ClientID)
+}
+
+// ErrCodeOAuth2InvalidClientCredentials holds the unique world-error code of this error
+const ErrCodeOAuth2InvalidClientCredentials = 20005
+
+// HTTPError holds the http error description
+func (err ErrOAuth2InvalidClientCredentials) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusUnauthorized,
+		Code:     ErrCodeOAuth2InvalidClientCredentials,
+		Message:  "The client id or client secret is wrong.",
+	}
+}
+
+// OAuth2Error returns the error code defined in the oauth2 spec
+func (err ErrOAuth2InvalidClientCredentials) OAuth2Error() string {
+	return "invalid_client"
+}
+
+// ErrOAuth2InvalidGrant represents an error where an authorization code or refresh token is invalid, expired or was
+// issued to another client.
+type ErrOAuth2InvalidGrant struct{}
+
+// IsErrOAuth2InvalidGrant checks if an error is ErrOAuth2InvalidGrant.
+func IsErrOAuth2InvalidGrant(err error) bool {
+	_, ok := err.(ErrOAuth2InvalidGrant)
+	return ok
+}
+
+func (err ErrOAuth2InvalidGrant) Error() string {
+	return "OAuth2 grant is invalid"
+}
+
+// ErrCodeOAuth2InvalidGrant holds the unique world-error code of this error
+const ErrCodeOAuth2InvalidGrant = 20006
+
+// HTTPError holds the http error description
+func (err ErrOAuth2InvalidGrant) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusBadRequest,
+		Code:     ErrCodeOAuth2InvalidGrant,
+		Message:  "The authorization code or refresh token is invalid, expired or was issued to another client.",
+	}
+}
+
+// OAuth2Error returns the error code defined in the oauth2 spec
+func (err ErrOAuth2InvalidGrant) OAuth2Error() string {
+	return "invalid_grant"
+}
+
+// ErrOAuth2UnsupportedGrantType represents an error where an oauth2 client uses a grant type Vikunja does not support
+type ErrOAuth2UnsupportedGrantType struct {
+	GrantType string
+}
+
+// IsErrOAuth2UnsupportedGrantType checks if an error is ErrOAuth2UnsupportedGrantType.
+func IsErrOAuth2UnsupportedGrantType(err error) bool {
+	_, ok := err.(ErrOAuth2UnsupportedGrantType)
+	return ok
+}
+
+func (err ErrOAuth2UnsupportedGrantType) Error() string {
+	return fmt.Sprintf("OAuth2 grant type is not supported [GrantType: %s]", err.GrantType)
+}
+
+// ErrCodeOAuth2UnsupportedGrantType holds the unique world-error code of this error
+const ErrCodeOAuth2UnsupportedGrantType = 20007
+
+// HTTPError holds the http error description
+func (err ErrOAuth2UnsupportedGrantType) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusBadRequest,
+		Code:     ErrCodeOAuth2UnsupportedGrantType,
+		Message:  fmt.Sprintf("The grant type '%s' is not supported. Supported grant types are 'authorization_code' and 'refresh_token'.", err.GrantType),
+	}
+}
+
+// OAuth2Error returns the error code defined in the oauth2 spec
+func (err ErrOAuth2UnsupportedGrantType) OAuth2Error() string {
+	return "unsupported_grant_type"
+}
+
+// ErrOAuth2GrantDoesNotExist represents an error where an oauth2 grant does not exist
+type ErrOAuth2GrantDoesNotExist struct {
+	ID string
+}
+
+// IsErrOAuth2GrantDoesNotExist checks if an error is ErrOAuth2GrantDoesNotExist.
+func IsErrOAuth2GrantDoesNotExist(err error) bool {
+	_, ok := err.(ErrOAuth2GrantDoesNotExist)
+	return ok
+}
+
+func (err ErrOAuth2GrantDoesNotExist) Error() string {
+	return fmt.Sprintf("OAuth2 grant does not exist [ID: %s]", err.ID)
+}
+
+// ErrCodeOAuth2GrantDoesNotExist holds the unique world-error code of this error
+const ErrCodeOAuth2GrantDoesNotExist = 20008
+
+// HTTPError holds the http error description
+func (err ErrOAuth2GrantDoesNotExist) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusNotFound,
+		Code:     ErrCodeOAuth2GrantDoesNotExist,
+		Message:  "This authorized app does not exist.",
+	}
+}
+
+// =============
+// Notifications
+// =============
+
+// ErrInvalidNotificationPreference represents an error where a user tries to change a notification which can't be turned off
+type ErrInvalidNotificationPreference struct {
+	Notification string
+	Channel      string
+}
+
+// IsErrInvalidNotificationPreference checks if an error is ErrInvalidNotificationPreference.
+func IsErrInvalidNotificationPreference(err error) bool {
+	_, ok := err.(ErrInvalidNotificationPreference)
+	return ok
+}
+
+func (err ErrInvalidNotificationPreference) Error() string {
+	return fmt.Sprintf("Notification preference is invalid [Notification: %s, Channel: %s]", err.Notification, err.Channel)
+}
+
+// ErrCodeInvalidNotificationPreference holds the unique world-error code of this error
+const ErrCodeInvalidNotificationPreference = 21001
+
+// HTTPError holds the http error description
+func (err ErrInvalidNotificationPreference) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusBadRequest,
+		Code:     ErrCodeInvalidNotificationPreference,
+		Message:  fmt.Sprintf("The notification '%s' can't be turned on or off for the channel '%s'.", err.Notification, err.Channel),
+	}
+}
+
+// ErrNotificationChannelDoesNotExist represents an error where a notification channel does not exist
+type ErrNotificationChannelDoesNotExist struct {
+	ID int64
+}
+
+// IsErrNotificationChannelDoesNotExist checks if an error is ErrNotificationChannelDoesNotExist.
+func IsErrNotificationChannelDoesNotExist(err error) bool {
+	_, ok := err.(ErrNotificationChannelDoesNotExist)
+	return ok
+}
+
+func (err ErrNotificationChannelDoesNotExist) Error() string {
+	return fmt.Sprintf("Notification channel does not exist [ID: %d]", err.ID)
+}
+
+// ErrCodeNotificationChannelDoesNotExist holds the unique world-error code of this error
+const ErrCodeNotificationChannelDoesNotExist = 21002
+
+// HTTPError holds the http error description
+func (err ErrNotificationChannelDoesNotExist) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusNotFound,
+		Code:     ErrCodeNotificationChannelDoesNotExist,
+		Message:  "This notification channel does not exist.",
+	}
+}
+
+// ErrInvalidNotificationChannelType represents an error where a notification channel has a type which does not exist
+type ErrInvalidNotificationChannelType struct {
+	Type string
+}
+
+// IsErrInvalidNotificationChannelType checks if an error is ErrInvalidNotificationChannelType.
+func IsErrInvalidNotificationChannelType(err error) bool {
+	_, ok := err.(ErrInvalidNotificationChannelType)
+	return ok
+}
+
+func (err ErrInvalidNotificationChannelType) Error() string {
+	return fmt.Sprintf("Notification channel type is invalid [Type: %s]", err.Type)
+}
+
+// ErrCodeInvalidNotificationChannelType holds the unique world-error code of this error
+const ErrCodeInvalidNotificationChannelType = 21003
+
+// HTTPError holds the http error description
+func (err ErrInvalidNotificationChannelType) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusBadRequest,
+		Code:     ErrCodeInvalidNotificationChannelType,
+		Message:  fmt.Sprintf("The notification channel type '%s' does not exist.", err.Type),
+	}
+}
+
+// ErrInvalidNotificationChannelURL represents an error where the url of a notification channel is not a valid http url
+type ErrInvalidNotificationChannelURL struct {
+	URL string
+}
+
+// IsErrInvalidNotificationChannelURL checks if an error is ErrInvalidNotificationChannelURL.
+func IsErrInvalidNotificationChannelURL(err error) bool {
+	_, ok := err.(ErrInvalidNotificationChannelURL)
+	return ok
+}
+
+func (err ErrInvalidNotificationChannelURL) Error() string {
+	return fmt.Sprintf("Notification channel url is invalid [URL: %s]", err.URL)
+}
+
+// ErrCodeInvalidNotificationChannelURL holds the unique world-error code of this error
+const ErrCodeInvalidNotificationChannelURL = 21004
+
+// HTTPError holds the http error description
+func (err ErrInvalidNotificationChannelURL) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusBadRequest,
+		Code:     ErrCodeInvalidNotificationChannelURL,
+		Message:  "The notification channel url must be a valid http or https url.",
+	}
+}
+
+// ErrNotificationChannelMissingField represents an error where a field needed for a notification channel type is empty
+type ErrNotificationChannelMissingField struct {
+	Type  string
+	Field string
+}
+
+// IsErrNotificationChannelMissingField checks if an error is ErrNotificationChannelMissingField.
+func IsErrNotificationChannelMissingField(err error) bool {
+	_, ok := err.(ErrNotificationChannelMissingField)
+	return ok
+}
+
+func (err ErrNotificationChannelMissingField) Error() string {
+	return fmt.Sprintf("Notification channel is missing a field [Type: %s, Field: %s]", err.Type, err.Field)
+}
+
+// ErrCodeNotificationChannelMissingField holds the unique world-error code of this error
+const ErrCodeNotificationChannelMissingField = 21005
+
+// HTTPError holds the http error description
+func (err ErrNotificationChannelMissingField) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusBadRequest,
+		Code:     ErrCodeNotificationChannelMissingField,
+		Message:  fmt.Sprintf("A %s notification channel needs a %s.", err.Type, err.Field),
+	}
+}
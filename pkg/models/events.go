@@ -17,10 +17,86 @@
 package models
 
 import (
+	"bytes"
+	"encoding/json"
+	"time"
+
 	"code.vikunja.io/api/pkg/user"
 	"code.vikunja.io/web"
+	"xorm.io/xorm"
 )
 
+// eventEntities holds everything we need from an event payload to find out where the event happened.
+type eventEntities struct {
+	Task      *Task
+	Bucket    *Bucket
+	List      *List
+	Namespace *Namespace
+}
+
+// GetListAndNamespaceIDFromEvent takes the json payload of an event and returns the ids of the list and namespace
+// the event happened in. Both are 0 if the event is not related to a list or namespace.
+func GetListAndNamespaceIDFromEvent(s *xorm.Session, payload []byte) (listID, namespaceID int64, err error) {
+	entities := &eventEntities{}
+	err = json.Unmarshal(payload, entities)
+	if err != nil {
+		return
+	}
+
+	switch {
+	case entities.List != nil:
+		return entities.List.ID, entities.List.NamespaceID, nil
+	case entities.Namespace != nil:
+		return 0, entities.Namespace.ID, nil
+	case entities.Task != nil:
+		listID = entities.Task.ListID
+	case entities.Bucket != nil:
+		listID = entities.Bucket.ListID
+	default:
+		return
+	}
+
+	list, err := GetListSimpleByID(s, listID)
+	if err != nil {
+		if IsErrListDoesNotExist(err) {
+			return listID, 0, nil
+		}
+		return
+	}
+
+	return listID, list.NamespaceID, nil
+}
+
+// SanitizeEventPayload removes the email addresses of all users in the json payload of an event, like the doer or
+// the creator of a task. The payload is sent to everyone with access to the list, so it must not contain more than
+// they could see through the api.
+func SanitizeEventPayload(payload []byte) (json.RawMessage, error) {
+	var data interface{}
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	// Keeps ids as they are instead of converting them to float64
+	decoder.UseNumber()
+	if err := decoder.Decode(&data); err != nil {
+		return nil, err
+	}
+
+	removeEmailsFromEventData(data)
+	return json.Marshal(data)
+}
+
+func removeEmailsFromEventData(data interface{}) {
+	switch v := data.(type) {
+	case map[string]interface{}:
+		delete(v, "email")
+		for _, value := range v {
+			removeEmailsFromEventData(value)
+		}
+	case []interface{}:
+		for _, value := range v {
+			removeEmailsFromEventData(value)
+		}
+	}
+}
+
 // DataExportRequestEvent represents a DataExportRequestEvent event
 type DataExportRequestEvent struct {
 	User *user.User
@@ -68,6 +144,17 @@ func (t *TaskDeletedEvent) Name() string {
 	return "task.deleted"
 }
 
+// TaskRestoredEvent represents an event where a task has been restored from the trash
+type TaskRestoredEvent struct {
+	Task *Task
+	Doer *user.User
+}
+
+// Name defines the name for TaskRestoredEvent
+func (t *TaskRestoredEvent) Name() string {
+	return "task.restored"
+}
+
 // TaskAssigneeCreatedEvent represents an event where a task has been assigned to a user
 type TaskAssigneeCreatedEvent struct {
 	Task     *Task
@@ -141,6 +228,17 @@ func (t *NamespaceDeletedEvent) Name() string {
 	return "namespace.deleted"
 }
 
+// NamespaceRestoredEvent represents an event where a namespace has been restored from the trash
+type NamespaceRestoredEvent struct {
+	Namespace *Namespace
+	Doer      web.Auth
+}
+
+// Name defines the name for NamespaceRestoredEvent
+func (t *NamespaceRestoredEvent) Name() string {
+	return "namespace.restored"
+}
+
 /////////////////
 // List Events //
 /////////////////
@@ -178,6 +276,54 @@ func (t *ListDeletedEvent) Name() string {
 	return "list.deleted"
 }
 
+// ListRestoredEvent represents an event where a list has been restored from the trash
+type ListRestoredEvent struct {
+	List *List
+	Doer web.Auth
+}
+
+// Name defines the name for ListRestoredEvent
+func (t *ListRestoredEvent) Name() string {
+	return "list.restored"
+}
+
+///////////////////
+// Bucket Events //
+///////////////////
+
+// BucketCreatedEvent represents an event where a kanban bucket has been created
+type BucketCreatedEvent struct {
+	Bucket *Bucket
+	Doer   web.Auth
+}
+
+// Name defines the name for BucketCreatedEvent
+func (b *BucketCreatedEvent) Name() string {
+	return "bucket.created"
+}
+
+// BucketUpdatedEvent represents an event where a kanban bucket has been updated
+type BucketUpdatedEvent struct {
+	Bucket *Bucket
+	Doer   web.Auth
+}
+
+// Name defines the name for BucketUpdatedEvent
+func (b *BucketUpdatedEvent) Name() string {
+	return "bucket.updated"
+}
+
+// BucketDeletedEvent represents an event where a kanban bucket has been deleted
+type BucketDeletedEvent struct {
+	Bucket *Bucket
+	Doer   web.Auth
+}
+
+// Name defines the name for BucketDeletedEvent
+func (b *BucketDeletedEvent) Name() string {
+	return "bucket.deleted"
+}
+
 ////////////////////
 // Sharing Events //
 ////////////////////
@@ -277,3 +423,21 @@ type UserDataExportRequestedEvent struct {
 func (t *UserDataExportRequestedEvent) Name() string {
 	return "user.export.requested"
 }
+
+////////////////////
+// Webhook Events //
+////////////////////
+
+// WebhookDeliveryEvent represents an event where the payload of another event should be sent to a webhook.
+// Each webhook gets its own delivery event so that failed deliveries can be retried independently.
+type WebhookDeliveryEvent struct {
+	WebhookID int64
+	EventName string
+	Time      time.Time
+	Data      json.RawMessage
+}
+
+// Name defines the name for WebhookDeliveryEvent
+func (w *WebhookDeliveryEvent) Name() string {
+	return "webhook.delivery"
+}
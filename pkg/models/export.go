@@ -34,6 +34,7 @@ import (
 	"code.vikunja.io/api/pkg/user"
 	"code.vikunja.io/api/pkg/utils"
 	"code.vikunja.io/api/pkg/version"
+	"xorm.io/builder"
 	"xorm.io/xorm"
 )
 
@@ -130,6 +131,7 @@ func exportListsAndTasks(s *xorm.Session, u *user.User, wr *zip.Writer) (err err
 
 	namespaceIDs := []int64{}
 	namespaces := []*NamespaceWithListsAndTasks{}
+	namespaceMap := make(map[int64]*NamespaceWithListsAndTasks)
 	listMap := make(map[int64]*ListWithTasksAndBuckets)
 	listIDs := []int64{}
 	for _, n := range namspaces.([]*NamespaceWithLists) {
@@ -139,8 +141,9 @@ func exportListsAndTasks(s *xorm.Session, u *user.User, wr *zip.Writer) (err err
 		}
 
 		nn := &NamespaceWithListsAndTasks{
-			Namespace: n.Namespace,
-			Lists:     []*ListWithTasksAndBuckets{},
+			Namespace:    n.Namespace,
+			Lists:        []*ListWithTasksAndBuckets{},
+			CustomFields: []*CustomField{},
 		}
 
 		for _, l := range n.Lists {
@@ -148,6 +151,7 @@ func exportListsAndTasks(s *xorm.Session, u *user.User, wr *zip.Writer) (err err
 				List:             *l,
 				BackgroundFileID: l.BackgroundFileID,
 				Tasks:            []*TaskWithComments{},
+				CustomFields:     []*CustomField{},
 			}
 			nn.Lists = append(nn.Lists, ll)
 			listMap[l.ID] = ll
@@ -156,6 +160,7 @@ func exportListsAndTasks(s *xorm.Session, u *user.User, wr *zip.Writer) (err err
 
 		namespaceIDs = append(namespaceIDs, n.ID)
 		namespaces = append(namespaces, nn)
+		namespaceMap[n.ID] = nn
 	}
 
 	if len(namespaceIDs) == 0 {
@@ -205,6 +210,28 @@ func exportListsAndTasks(s *xorm.Session, u *user.User, wr *zip.Writer) (err err
 		taskMap[c.TaskID].Comments = append(taskMap[c.TaskID].Comments, c)
 	}
 
+	taskIDs := make([]int64, 0, len(taskMap))
+	for id := range taskMap {
+		taskIDs = append(taskIDs, id)
+	}
+	timeEntries, err := getTaskTimeEntriesForTasks(s, taskIDs)
+	if err != nil {
+		return
+	}
+
+	for _, te := range timeEntries {
+		taskMap[te.TaskID].TimeEntries = append(taskMap[te.TaskID].TimeEntries, te)
+	}
+
+	history, err := getTaskChangesForTasks(s, taskIDs)
+	if err != nil {
+		return
+	}
+
+	for _, c := range history {
+		taskMap[c.TaskID].History = append(taskMap[c.TaskID].History, c)
+	}
+
 	buckets := []*Bucket{}
 	err = s.In("list_id", listIDs).Find(&buckets)
 	if err != nil {
@@ -219,6 +246,27 @@ func exportListsAndTasks(s *xorm.Session, u *user.User, wr *zip.Writer) (err err
 		listMap[b.ListID].Buckets = append(listMap[b.ListID].Buckets, b)
 	}
 
+	customFields := []*CustomField{}
+	err = s.
+		Where(builder.Or(
+			builder.In("list_id", listIDs),
+			builder.In("namespace_id", namespaceIDs),
+		)).
+		Find(&customFields)
+	if err != nil {
+		return
+	}
+
+	for _, f := range customFields {
+		if l, exists := listMap[f.ListID]; exists {
+			l.CustomFields = append(l.CustomFields, f)
+			continue
+		}
+		if n, exists := namespaceMap[f.NamespaceID]; exists {
+			n.CustomFields = append(n.CustomFields, f)
+		}
+	}
+
 	data, err := json.Marshal(namespaces)
 	if err != nil {
 		return err
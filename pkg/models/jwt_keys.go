@@ -0,0 +1,82 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"time"
+
+	"xorm.io/builder"
+	"xorm.io/xorm"
+)
+
+// JWTKey is a private key used to sign jwt tokens. Only the newest key which is not retired is used to sign new
+// tokens, retired keys are kept around to verify tokens which were signed before they were rotated.
+type JWTKey struct {
+	// The key id, it is sent as the `kid` header of every token signed with this key.
+	ID string `xorm:"varchar(50) not null unique pk" json:"id"`
+	// The jwt algorithm of this key, either RS256 or EdDSA.
+	Algorithm string `xorm:"varchar(10) not null" json:"algorithm"`
+	// The PKCS #8 encoded private key in PEM format.
+	PrivateKey string `xorm:"text not null" json:"-"`
+	// When this key was rotated. Retired keys are not used to sign new tokens anymore.
+	RetiredAt time.Time `xorm:"DATETIME null" json:"retired_at"`
+
+	// A timestamp when this key was created.
+	Created time.Time `xorm:"created not null" json:"created"`
+}
+
+// TableName returns the table name for jwt keys
+func (*JWTKey) TableName() string {
+	return "jwt_keys"
+}
+
+// GetJWTKeys returns all keys which are not retired or were retired after a date, the newest first.
+func GetJWTKeys(s *xorm.Session, retiredAfter time.Time) (keys []*JWTKey, err error) {
+	keys = []*JWTKey{}
+	err = s.
+		Where(builder.Or(
+			builder.IsNull{"retired_at"},
+			builder.Gt{"retired_at": retiredAfter},
+		)).
+		OrderBy("created desc, id desc").
+		Find(&keys)
+	return
+}
+
+// RotateJWTKeys retires all current keys and saves a new one which is used to sign all new tokens from now on.
+// Keys which were retired before retiredBefore can't verify any valid token anymore and are removed.
+func RotateJWTKeys(s *xorm.Session, key *JWTKey, retiredBefore time.Time) (err error) {
+	_, err = s.
+		Where("retired_at IS NOT NULL AND retired_at < ?", retiredBefore).
+		Delete(&JWTKey{})
+	if err != nil {
+		return err
+	}
+
+	_, err = s.
+		Where("retired_at IS NULL").
+		Cols("retired_at").
+		NoAutoTime().
+		Update(&JWTKey{RetiredAt: time.Now()})
+	if err != nil {
+		return err
+	}
+
+	key.RetiredAt = time.Time{}
+	_, err = s.Insert(key)
+	return
+}
@@ -19,6 +19,7 @@ package models
 import (
 	"time"
 
+	"code.vikunja.io/api/pkg/events"
 	"code.vikunja.io/api/pkg/log"
 	"code.vikunja.io/api/pkg/user"
 	"code.vikunja.io/web"
@@ -263,7 +264,14 @@ func (b *Bucket) Create(s *xorm.Session, a web.Auth) (err error) {
 
 	b.Position = calculateDefaultPosition(b.ID, b.Position)
 	_, err = s.Where("id = ?", b.ID).Update(b)
-	return
+	if err != nil {
+		return
+	}
+
+	return events.Dispatch(&BucketCreatedEvent{
+		Bucket: b,
+		Doer:   a,
+	})
 }
 
 // Update Updates an existing bucket
@@ -304,7 +312,14 @@ func (b *Bucket) Update(s *xorm.Session, a web.Auth) (err error) {
 			"position",
 		).
 		Update(b)
-	return
+	if err != nil {
+		return
+	}
+
+	return events.Dispatch(&BucketUpdatedEvent{
+		Bucket: b,
+		Doer:   a,
+	})
 }
 
 // Delete removes a bucket, but no tasks
@@ -351,5 +366,12 @@ func (b *Bucket) Delete(s *xorm.Session, a web.Auth) (err error) {
 		Where("bucket_id = ?", b.ID).
 		Cols("bucket_id").
 		Update(&Task{BucketID: defaultBucket.ID})
-	return
+	if err != nil {
+		return
+	}
+
+	return events.Dispatch(&BucketDeletedEvent{
+		Bucket: b,
+		Doer:   a,
+	})
 }
@@ -50,6 +50,9 @@ func (LabelTask) TableName() string {
 	return "label_tasks"
 }
 
+// Used to sort tasks by the alphabetically first title of all their labels
+const taskLabelsSortSubquery = "(SELECT MIN(labels.title) FROM label_tasks INNER JOIN labels ON labels.id = label_tasks.label_id WHERE label_tasks.task_id = tasks.id)"
+
 // Delete deletes a label on a task
 // @Summary Remove a label from a task
 // @Description Remove a label from a task. The user needs to have write-access to the list to be able do this.
@@ -66,7 +69,11 @@ func (LabelTask) TableName() string {
 // @Router /tasks/{task}/labels/{label} [delete]
 func (lt *LabelTask) Delete(s *xorm.Session, a web.Auth) (err error) {
 	_, err = s.Delete(&LabelTask{LabelID: lt.LabelID, TaskID: lt.TaskID})
-	return err
+	if err != nil {
+		return err
+	}
+
+	return recordTaskChange(s, a, lt.TaskID, taskChangeFieldLabels, lt.LabelID, nil)
 }
 
 // Create adds a label to a task
@@ -100,6 +107,11 @@ func (lt *LabelTask) Create(s *xorm.Session, a web.Auth) (err error) {
 		return err
 	}
 
+	err = recordTaskChange(s, a, lt.TaskID, taskChangeFieldLabels, nil, lt.LabelID)
+	if err != nil {
+		return err
+	}
+
 	err = updateListByTaskID(s, lt.TaskID)
 	return
 }
@@ -270,7 +282,15 @@ func (t *Task) updateTaskLabels(s *xorm.Session, creator web.Auth, labels []*Lab
 	if len(labels) == 0 && len(t.Labels) > 0 {
 		_, err = s.Where("task_id = ?", t.ID).
 			Delete(LabelTask{})
-		return err
+		if err != nil {
+			return err
+		}
+		for _, l := range t.Labels {
+			if err := recordTaskChange(s, creator, t.ID, taskChangeFieldLabels, l.ID, nil); err != nil {
+				return err
+			}
+		}
+		return nil
 	}
 
 	// If we didn't change anything (from 0 to zero) don't do anything.
@@ -315,6 +335,11 @@ func (t *Task) updateTaskLabels(s *xorm.Session, creator web.Auth, labels []*Lab
 		if err != nil {
 			return err
 		}
+		for _, id := range labelsToDelete {
+			if err := recordTaskChange(s, creator, t.ID, taskChangeFieldLabels, id, nil); err != nil {
+				return err
+			}
+		}
 	}
 
 	// Loop through our labels and add them
@@ -346,6 +371,9 @@ func (t *Task) updateTaskLabels(s *xorm.Session, creator web.Auth, labels []*Lab
 		if err != nil {
 			return err
 		}
+		if err := recordTaskChange(s, creator, t.ID, taskChangeFieldLabels, nil, l.ID); err != nil {
+			return err
+		}
 		t.Labels = append(t.Labels, label)
 	}
 
@@ -28,6 +28,7 @@ import (
 	"code.vikunja.io/api/pkg/files"
 	"code.vikunja.io/api/pkg/log"
 	"code.vikunja.io/api/pkg/user"
+	"code.vikunja.io/api/pkg/utils"
 	"code.vikunja.io/web"
 	"xorm.io/builder"
 	"xorm.io/xorm"
@@ -74,6 +75,8 @@ type List struct {
 	Created time.Time `xorm:"created not null" json:"created"`
 	// A timestamp when this list was last updated. You cannot change this value.
 	Updated time.Time `xorm:"updated not null" json:"updated"`
+	// A timestamp when this list was moved to the trash. Deleted lists are hidden everywhere until they are restored or purged.
+	Deleted time.Time `xorm:"deleted null" json:"-"`
 
 	web.CRUDable `xorm:"-" json:"-"`
 	web.Rights   `xorm:"-" json:"-"`
@@ -86,6 +89,8 @@ type ListWithTasksAndBuckets struct {
 	// Only used for migration.
 	Buckets          []*Bucket `xorm:"-" json:"buckets"`
 	BackgroundFileID int64     `xorm:"null" json:"background_file_id"`
+	// The custom fields which belong to this list. Only used for migration.
+	CustomFields []*CustomField `xorm:"-" json:"custom_fields"`
 }
 
 // TableName returns a better name for the lists table
@@ -316,14 +321,15 @@ func GetListSimplByTaskID(s *xorm.Session, taskID int64) (l *List, err error) {
 		Select("lists.*").
 		Table(List{}).
 		Join("INNER", "tasks", "lists.id = tasks.list_id").
-		Where("tasks.id = ?", taskID).
+		Where("tasks.id = ? AND tasks.deleted IS NULL", taskID).
 		Get(&list)
 	if err != nil {
 		return
 	}
 
+	// Tasks in the trash don't have a list anyone can work with
 	if !exists {
-		return &List{}, ErrListDoesNotExist{ID: l.ID}
+		return &List{}, ErrTaskDoesNotExist{ID: taskID}
 	}
 
 	return &list, nil
@@ -365,12 +371,15 @@ func getUserListsStatement(userID int64) *builder.Builder {
 		Join("LEFT", "team_members tm2", "tm2.team_id = tl.team_id").
 		Join("LEFT", "users_lists ul", "ul.list_id = l.id").
 		Join("LEFT", "users_namespaces un", "un.namespace_id = l.namespace_id").
-		Where(builder.Or(
-			builder.Eq{"tm.user_id": userID},
-			builder.Eq{"tm2.user_id": userID},
-			builder.Eq{"ul.user_id": userID},
-			builder.Eq{"un.user_id": userID},
-			builder.Eq{"l.owner_id": userID},
+		Where(builder.And(
+			builder.Or(
+				builder.Eq{"tm.user_id": userID},
+				builder.Eq{"tm2.user_id": userID},
+				builder.Eq{"ul.user_id": userID},
+				builder.Eq{"un.user_id": userID},
+				builder.Eq{"l.owner_id": userID},
+			),
+			builder.IsNull{"l.deleted"},
 		)).
 		OrderBy("position").
 		GroupBy("l.id")
@@ -770,22 +779,28 @@ func (l *List) Create(s *xorm.Session, a web.Auth) (err error) {
 // @Failure 500 {object} models.Message "Internal error"
 // @Router /lists/{id} [delete]
 func (l *List) Delete(s *xorm.Session, a web.Auth) (err error) {
+	return l.moveToTrash(s, a, utils.GetTimeWithoutNanoSeconds(time.Now()))
+}
 
-	// Delete the list
-	_, err = s.ID(l.ID).Delete(&List{})
+// moveToTrash marks a list and all its tasks as deleted. All tasks get the same deletion time as the list which is
+// used to restore them together with the list later.
+func (l *List) moveToTrash(s *xorm.Session, a web.Auth, now time.Time) (err error) {
+
+	err = markAsDeleted(s, "lists", builder.Eq{"id": l.ID}, now)
 	if err != nil {
 		return
 	}
 
-	// Delete all tasks on that list
-	// Using the loop to make sure all related entities to all tasks are properly deleted as well.
-	tasks, _, _, err := getRawTasksForLists(s, []*List{l}, a, &taskOptions{})
+	// Move all tasks on that list to the trash
+	// Using the loop to make sure the events for all tasks are dispatched properly.
+	tasks := []*Task{}
+	err = s.Where("list_id = ?", l.ID).Find(&tasks)
 	if err != nil {
 		return
 	}
 
 	for _, task := range tasks {
-		err = task.Delete(s, a)
+		err = task.moveToTrash(s, a, now)
 		if err != nil {
 			return err
 		}
@@ -797,6 +812,41 @@ func (l *List) Delete(s *xorm.Session, a web.Auth) (err error) {
 	})
 }
 
+// purge permanently removes a list with all its tasks, custom fields and task templates.
+func (l *List) purge(s *xorm.Session) (err error) {
+
+	// Delete the list
+	_, err = s.Unscoped().ID(l.ID).Delete(&List{})
+	if err != nil {
+		return
+	}
+
+	// Delete all tasks on that list, including the ones which were moved to the trash on their own
+	// Using the loop to make sure all related entities to all tasks are properly deleted as well.
+	tasks := []*Task{}
+	err = s.Unscoped().Where("list_id = ?", l.ID).Find(&tasks)
+	if err != nil {
+		return
+	}
+
+	for _, task := range tasks {
+		err = task.purge(s)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Delete all custom fields of that list
+	err = deleteCustomFields(s, builder.Eq{"list_id": l.ID})
+	if err != nil {
+		return
+	}
+
+	// Delete all task templates of that list
+	_, err = s.Where("list_id = ?", l.ID).Delete(&TaskTemplate{})
+	return
+}
+
 // SetListBackground sets a background file as list background in the db
 func SetListBackground(s *xorm.Session, listID int64, background *files.File) (err error) {
 	l := &List{
@@ -107,7 +107,16 @@ func (ld *ListDuplicate) Create(s *xorm.Session, doer web.Auth) (err error) {
 
 	log.Debugf("Duplicated all buckets from list %d into %d", ld.ListID, ld.List.ID)
 
-	err = duplicateTasks(s, doer, ld, bucketMap)
+	// Duplicate custom fields
+	// Old field ID as key, new id as value
+	fieldMap, err := duplicateCustomFields(s, ld.ListID, ld.List.ID, doer)
+	if err != nil {
+		return
+	}
+
+	log.Debugf("Duplicated all custom fields from list %d into %d", ld.ListID, ld.List.ID)
+
+	err = duplicateTasks(s, doer, ld, bucketMap, fieldMap)
 	if err != nil {
 		return
 	}
@@ -201,7 +210,7 @@ func (ld *ListDuplicate) Create(s *xorm.Session, doer web.Auth) (err error) {
 	return
 }
 
-func duplicateTasks(s *xorm.Session, doer web.Auth, ld *ListDuplicate, bucketMap map[int64]int64) (err error) {
+func duplicateTasks(s *xorm.Session, doer web.Auth, ld *ListDuplicate, bucketMap map[int64]int64, fieldMap map[int64]int64) (err error) {
 	// Get all tasks + all task details
 	tasks, _, _, err := getTasksForLists(s, []*List{{ID: ld.ListID}}, doer, &taskOptions{})
 	if err != nil {
@@ -212,6 +221,12 @@ func duplicateTasks(s *xorm.Session, doer web.Auth, ld *ListDuplicate, bucketMap
 		return nil
 	}
 
+	// Fields of the old namespace are only kept if the new list is in the same namespace
+	availableFields, err := getCustomFieldsForList(s, ld.List.ID)
+	if err != nil {
+		return err
+	}
+
 	// This map contains the old task id as key and the new duplicated task id as value.
 	// It is used to map old task items to new ones.
 	taskMap := make(map[int64]int64)
@@ -223,6 +238,7 @@ func duplicateTasks(s *xorm.Session, doer web.Auth, ld *ListDuplicate, bucketMap
 		t.ListID = ld.List.ID
 		t.BucketID = bucketMap[t.BucketID]
 		t.UID = ""
+		t.CustomFields = mapCustomFieldValues(t.CustomFields, fieldMap, availableFields)
 		err := createTask(s, t, doer, false)
 		if err != nil {
 			return err
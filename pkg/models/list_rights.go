@@ -158,19 +158,25 @@ func (l *List) IsAdmin(s *xorm.Session, a web.Auth) (bool, error) {
 		return false, err
 	}
 
+	return originalList.isAdmin(s, a)
+}
+
+// isAdmin checks if the user is admin on an already loaded list.
+// This is also used for lists in the trash which cannot be loaded with GetListSimpleByID.
+func (l *List) isAdmin(s *xorm.Session, a web.Auth) (bool, error) {
 	// Check if we're dealing with a share auth
 	shareAuth, ok := a.(*LinkSharing)
 	if ok {
-		return originalList.ID == shareAuth.ListID && shareAuth.Right == RightAdmin, nil
+		return l.ID == shareAuth.ListID && shareAuth.Right == RightAdmin, nil
 	}
 
 	// Check all the things
 	// Check if the user is either owner or can write to the list
 	// Owners are always admins
-	if originalList.isOwner(&user.User{ID: a.GetID()}) {
+	if l.isOwner(&user.User{ID: a.GetID()}) {
 		return true, nil
 	}
-	is, _, err := originalList.checkRight(s, a, RightAdmin)
+	is, _, err := l.checkRight(s, a, RightAdmin)
 	return is, err
 }
 
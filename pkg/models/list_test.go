@@ -174,11 +174,19 @@ func TestList_Delete(t *testing.T) {
 	}
 	err := list.Delete(s, &user.User{ID: 1})
 	assert.NoError(t, err)
-	err = s.Commit()
+
+	_, err = GetListSimpleByID(s, 1)
+	assert.Error(t, err)
+	assert.True(t, IsErrListDoesNotExist(err))
+
+	// The list and its tasks are only moved to the trash
+	trashed, err := getListIncludingTrashed(s, 1)
 	assert.NoError(t, err)
-	db.AssertMissing(t, "lists", map[string]interface{}{
-		"id": 1,
-	})
+	assert.False(t, trashed.Deleted.IsZero())
+	task, err := getTaskIncludingTrashed(s, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, trashed.Deleted.Unix(), task.Deleted.Unix())
+	_ = s.Close()
 }
 
 func TestList_ReadAll(t *testing.T) {
@@ -18,7 +18,9 @@ package models
 
 import (
 	"encoding/json"
+	"time"
 
+	"code.vikunja.io/api/pkg/config"
 	"code.vikunja.io/api/pkg/db"
 	"code.vikunja.io/api/pkg/events"
 	"code.vikunja.io/api/pkg/log"
@@ -35,10 +37,13 @@ import (
 func RegisterListeners() {
 	events.RegisterListener((&ListCreatedEvent{}).Name(), &IncreaseListCounter{})
 	events.RegisterListener((&ListDeletedEvent{}).Name(), &DecreaseListCounter{})
+	events.RegisterListener((&ListRestoredEvent{}).Name(), &IncreaseListCounter{})
 	events.RegisterListener((&NamespaceCreatedEvent{}).Name(), &IncreaseNamespaceCounter{})
 	events.RegisterListener((&NamespaceDeletedEvent{}).Name(), &DecreaseNamespaceCounter{})
+	events.RegisterListener((&NamespaceRestoredEvent{}).Name(), &IncreaseNamespaceCounter{})
 	events.RegisterListener((&TaskCreatedEvent{}).Name(), &IncreaseTaskCounter{})
 	events.RegisterListener((&TaskDeletedEvent{}).Name(), &DecreaseTaskCounter{})
+	events.RegisterListener((&TaskRestoredEvent{}).Name(), &IncreaseTaskCounter{})
 	events.RegisterListener((&TeamDeletedEvent{}).Name(), &DecreaseTeamCounter{})
 	events.RegisterListener((&TeamCreatedEvent{}).Name(), &IncreaseTeamCounter{})
 	events.RegisterListener((&TaskCommentCreatedEvent{}).Name(), &SendTaskCommentNotification{})
@@ -51,6 +56,12 @@ func RegisterListeners() {
 	events.RegisterListener((&TaskCreatedEvent{}).Name(), &HandleTaskCreateMentions{})
 	events.RegisterListener((&TaskUpdatedEvent{}).Name(), &HandleTaskUpdatedMentions{})
 	events.RegisterListener((&UserDataExportRequestedEvent{}).Name(), &HandleUserDataExport{})
+	if config.WebhooksEnabled.GetBool() {
+		for _, eventName := range GetAvailableWebhookEvents() {
+			events.RegisterListener(eventName, &DispatchWebhookDeliveries{EventName: eventName})
+		}
+		events.RegisterListener((&WebhookDeliveryEvent{}).Name(), &SendWebhookPayload{})
+	}
 }
 
 //////
@@ -606,3 +617,86 @@ func (s *HandleUserDataExport) Handle(msg *message.Message) (err error) {
 	err = sess.Commit()
 	return err
 }
+
+///////
+// Webhook Events
+
+// DispatchWebhookDeliveries represents a listener
+type DispatchWebhookDeliveries struct {
+	EventName string
+}
+
+// Name defines the name for the DispatchWebhookDeliveries listener
+func (s *DispatchWebhookDeliveries) Name() string {
+	return "webhook.dispatch.deliveries"
+}
+
+// Handle is executed when the event DispatchWebhookDeliveries listens on is fired
+func (s *DispatchWebhookDeliveries) Handle(msg *message.Message) (err error) {
+	sess := db.NewSession()
+	defer sess.Close()
+
+	listID, namespaceID, err := GetListAndNamespaceIDFromEvent(sess, msg.Payload)
+	if err != nil {
+		return err
+	}
+
+	webhooks, err := getWebhooksForEvent(sess, s.EventName, listID, namespaceID)
+	if err != nil || len(webhooks) == 0 {
+		return err
+	}
+
+	data, err := SanitizeEventPayload(msg.Payload)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, w := range webhooks {
+		err = events.Dispatch(&WebhookDeliveryEvent{
+			WebhookID: w.ID,
+			EventName: s.EventName,
+			Time:      now,
+			Data:      data,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SendWebhookPayload represents a listener
+type SendWebhookPayload struct {
+}
+
+// Name defines the name for the SendWebhookPayload listener
+func (s *SendWebhookPayload) Name() string {
+	return "webhook.send.payload"
+}
+
+// Handle is executed when the event SendWebhookPayload listens on is fired
+// If the target does not respond successfully, the returned error makes the event router retry the delivery
+// before it ends up in the poison queue.
+func (s *SendWebhookPayload) Handle(msg *message.Message) (err error) {
+	event := &WebhookDeliveryEvent{}
+	err = json.Unmarshal(msg.Payload, event)
+	if err != nil {
+		return err
+	}
+
+	sess := db.NewSession()
+	defer sess.Close()
+
+	w, err := getWebhookByID(sess, event.WebhookID)
+	if err != nil {
+		if IsErrWebhookDoesNotExist(err) {
+			// The webhook was deleted after the event was dispatched
+			return nil
+		}
+		return err
+	}
+
+	return w.sendPayload(event.EventName, event.Time, event.Data)
+}
@@ -61,6 +61,16 @@ func GetTables() []interface{} {
 		&SavedFilter{},
 		&Subscription{},
 		&Favorite{},
+		&Webhook{},
+		&TaskTimeEntry{},
+		&CustomField{},
+		&TaskCustomFieldValue{},
+		&TaskTemplate{},
+		&TaskChange{},
+		&APIToken{},
+		&OAuth2Client{},
+		&OAuth2Grant{},
+		&JWTKey{},
 	}
 }
 
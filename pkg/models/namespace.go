@@ -27,6 +27,7 @@ import (
 	"code.vikunja.io/api/pkg/events"
 	"code.vikunja.io/api/pkg/log"
 	"code.vikunja.io/api/pkg/user"
+	"code.vikunja.io/api/pkg/utils"
 
 	"code.vikunja.io/web"
 	"xorm.io/builder"
@@ -60,6 +61,8 @@ type Namespace struct {
 	Created time.Time `xorm:"created not null" json:"created"`
 	// A timestamp when this namespace was last updated. You cannot change this value.
 	Updated time.Time `xorm:"updated not null" json:"updated"`
+	// A timestamp when this namespace was moved to the trash. Deleted namespaces are hidden everywhere until they are restored or purged.
+	Deleted time.Time `xorm:"deleted null" json:"-"`
 
 	// If set to true, will only return the namespaces, not their lists.
 	NamespacesOnly bool `xorm:"-" json:"-" query:"namespaces_only"`
@@ -190,6 +193,8 @@ type NamespaceWithLists struct {
 type NamespaceWithListsAndTasks struct {
 	Namespace
 	Lists []*ListWithTasksAndBuckets `xorm:"-" json:"lists"`
+	// The custom fields which belong to this namespace. Only used for migration.
+	CustomFields []*CustomField `xorm:"-" json:"custom_fields"`
 }
 
 func makeNamespaceSlice(namespaces map[int64]*NamespaceWithLists, userMap map[int64]*user.User, subscriptions map[int64]*Subscription) []*NamespaceWithLists {
@@ -263,7 +268,8 @@ func getNamespacesWithLists(s *xorm.Session, namespaces *map[int64]*NamespaceWit
 		Or("users_namespaces.user_id = ?", userID).
 		GroupBy("namespaces.id").
 		Where(filterCond).
-		Where(isArchivedCond)
+		Where(isArchivedCond).
+		And("namespaces.deleted IS NULL")
 	if limit > 0 {
 		query = query.Limit(limit, start)
 	}
@@ -284,6 +290,7 @@ func getNamespacesWithLists(s *xorm.Session, namespaces *map[int64]*NamespaceWit
 		GroupBy("namespaces.id").
 		Where(filterCond).
 		Where(isArchivedCond).
+		And("namespaces.deleted IS NULL").
 		Count(&NamespaceWithLists{})
 	return numberOfTotalItems, err
 }
@@ -655,50 +662,48 @@ func CreateNewNamespaceForUser(s *xorm.Session, user *user.User) (err error) {
 // @Failure 500 {object} models.Message "Internal error"
 // @Router /namespaces/{id} [delete]
 func (n *Namespace) Delete(s *xorm.Session, a web.Auth) (err error) {
-	return deleteNamespace(s, n, a, true)
-}
-
-func deleteNamespace(s *xorm.Session, n *Namespace, a web.Auth, withLists bool) (err error) {
 	// Check if the namespace exists
 	_, err = GetNamespaceByID(s, n.ID)
 	if err != nil {
 		return
 	}
 
-	// Delete the namespace
-	_, err = s.ID(n.ID).Delete(&Namespace{})
+	now := utils.GetTimeWithoutNanoSeconds(time.Now())
+	err = markAsDeleted(s, "namespaces", builder.Eq{"id": n.ID}, now)
 	if err != nil {
 		return
 	}
 
-	namespaceDeleted := &NamespaceDeletedEvent{
-		Namespace: n,
-		Doer:      a,
-	}
-
-	if !withLists {
-		return events.Dispatch(namespaceDeleted)
-	}
-
-	// Delete all lists with their tasks
-	lists, err := GetListsByNamespaceID(s, n.ID, &user.User{})
+	// Move all lists with their tasks to the trash as well
+	lists := []*List{}
+	err = s.Where("namespace_id = ?", n.ID).Find(&lists)
 	if err != nil {
 		return
 	}
 
-	if len(lists) == 0 {
-		return events.Dispatch(namespaceDeleted)
-	}
-
-	// Looping over all lists to let the list handle properly cleaning up the tasks and everything else associated with it.
+	// Looping over all lists to let the list handle moving its tasks to the trash.
 	for _, list := range lists {
-		err = list.Delete(s, a)
+		err = list.moveToTrash(s, a, now)
 		if err != nil {
 			return err
 		}
 	}
 
-	return events.Dispatch(namespaceDeleted)
+	return events.Dispatch(&NamespaceDeletedEvent{
+		Namespace: n,
+		Doer:      a,
+	})
+}
+
+// purge permanently removes a namespace with its custom fields. Its lists are not touched.
+func (n *Namespace) purge(s *xorm.Session) (err error) {
+	_, err = s.Unscoped().ID(n.ID).Delete(&Namespace{})
+	if err != nil {
+		return
+	}
+
+	// Delete all custom fields of the namespace
+	return deleteCustomFields(s, builder.Eq{"namespace_id": n.ID})
 }
 
 // Update implements the update method via the interface
@@ -72,10 +72,16 @@ func (n *Namespace) checkRight(s *xorm.Session, a web.Auth, rights ...Right) (bo
 		return false, 0, err
 	}
 
-	if a.GetID() == nn.OwnerID ||
-		nn.ID == SharedListsPseudoNamespace.ID ||
-		nn.ID == FavoritesPseudoNamespace.ID ||
-		nn.ID == SavedFiltersPseudoNamespace.ID {
+	return nn.checkRightOnLoaded(s, a, rights...)
+}
+
+// checkRightOnLoaded checks the rights of a user on an already loaded namespace.
+// This is also used for namespaces in the trash which cannot be loaded with getNamespaceSimpleByID.
+func (n *Namespace) checkRightOnLoaded(s *xorm.Session, a web.Auth, rights ...Right) (bool, int, error) {
+	if a.GetID() == n.OwnerID ||
+		n.ID == SharedListsPseudoNamespace.ID ||
+		n.ID == FavoritesPseudoNamespace.ID ||
+		n.ID == SavedFiltersPseudoNamespace.ID {
 		return true, int(RightAdmin), nil
 	}
 
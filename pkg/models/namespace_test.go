@@ -172,12 +172,19 @@ func TestNamespace_Delete(t *testing.T) {
 		}
 		err := n.Delete(s, u)
 		assert.NoError(t, err)
-		err = s.Commit()
-		assert.NoError(t, err)
 
-		db.AssertMissing(t, "namespaces", map[string]interface{}{
-			"id": 1,
-		})
+		_, err = GetNamespaceByID(s, 1)
+		assert.Error(t, err)
+		assert.True(t, IsErrNamespaceDoesNotExist(err))
+
+		// The namespace and its lists are only moved to the trash
+		trashed, err := getNamespaceIncludingTrashed(s, 1)
+		assert.NoError(t, err)
+		assert.False(t, trashed.Deleted.IsZero())
+		list, err := getListIncludingTrashed(s, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, trashed.Deleted.Unix(), list.Deleted.Unix())
+		_ = s.Close()
 	})
 	t.Run("nonexisting", func(t *testing.T) {
 		db.LoadAndAssertFixtures(t)
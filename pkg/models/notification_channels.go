@@ -0,0 +1,201 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"net/url"
+
+	"code.vikunja.io/api/pkg/notifications"
+	"code.vikunja.io/web"
+	"xorm.io/xorm"
+)
+
+// NotificationChannel is a wrapper around the crud operations of a notification channel.
+type NotificationChannel struct {
+	notifications.NotificationChannel `xorm:"extends"`
+
+	web.CRUDable `xorm:"-" json:"-"`
+	web.Rights   `xorm:"-" json:"-"`
+}
+
+func getNotificationChannelByID(s *xorm.Session, id int64) (channel *NotificationChannel, err error) {
+	channel = &NotificationChannel{}
+	exists, err := s.Where("id = ?", id).Get(channel)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrNotificationChannelDoesNotExist{ID: id}
+	}
+	return
+}
+
+func (c *NotificationChannel) validate(isNew bool) error {
+	if !notifications.IsValidChannelType(c.Type) {
+		return ErrInvalidNotificationChannelType{Type: c.Type}
+	}
+
+	u, err := url.Parse(c.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ErrInvalidNotificationChannelURL{URL: c.URL}
+	}
+
+	if (c.Type == notifications.ChannelTypeMatrix || c.Type == notifications.ChannelTypeNtfy) && c.Target == "" {
+		return ErrNotificationChannelMissingField{Type: c.Type, Field: "target"}
+	}
+
+	// When updating a channel, an empty token means the existing one is kept
+	if isNew && (c.Type == notifications.ChannelTypeMatrix || c.Type == notifications.ChannelTypeGotify) && c.Token == "" {
+		return ErrNotificationChannelMissingField{Type: c.Type, Field: "token"}
+	}
+
+	return nil
+}
+
+// Create adds a new notification channel for the current user
+// @Summary Add a notification channel
+// @Description Adds a chat or push channel the current user gets their notifications through, in addition to mails and in-app notifications. The token can only be set, it is never returned.
+// @tags user
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param channel body models.NotificationChannel true "The new notification channel"
+// @Success 201 {object} models.NotificationChannel "The created notification channel."
+// @Failure 400 {object} web.HTTPError "Invalid notification channel object provided."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /user/settings/notifications/channels [put]
+func (c *NotificationChannel) Create(s *xorm.Session, a web.Auth) (err error) {
+	if err = c.validate(true); err != nil {
+		return
+	}
+
+	c.ID = 0
+	c.NotifiableID = a.GetID()
+	_, err = s.Insert(c)
+	if err != nil {
+		return
+	}
+
+	c.Token = ""
+	return
+}
+
+// ReadOne returns a single notification channel
+// @Summary Get one notification channel
+// @Description Returns one notification channel of the current user by its ID.
+// @tags user
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param channel path int true "Notification channel ID"
+// @Success 200 {object} models.NotificationChannel "The notification channel"
+// @Failure 403 {object} web.HTTPError "The channel belongs to another user."
+// @Failure 404 {object} web.HTTPError "The notification channel does not exist."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /user/settings/notifications/channels/{channel} [get]
+func (c *NotificationChannel) ReadOne(s *xorm.Session, a web.Auth) (err error) {
+	channel, err := getNotificationChannelByID(s, c.ID)
+	if err != nil {
+		return
+	}
+	*c = *channel
+
+	c.Token = ""
+	return
+}
+
+// ReadAll returns all notification channels of the current user
+// @Summary Get all notification channels
+// @Description Returns all chat and push channels of the current user.
+// @tags user
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Success 200 {array} models.NotificationChannel "The notification channels"
+// @Failure 403 {object} web.HTTPError "Link shares cannot have notification channels."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /user/settings/notifications/channels [get]
+func (c *NotificationChannel) ReadAll(s *xorm.Session, a web.Auth, search string, page int, perPage int) (result interface{}, resultCount int, totalItems int64, err error) {
+	if _, is := a.(*LinkSharing); is {
+		return nil, 0, 0, ErrGenericForbidden{}
+	}
+
+	channels, err := notifications.GetNotificationChannels(s, a.GetID())
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	for _, channel := range channels {
+		channel.Token = ""
+	}
+
+	return channels, len(channels), int64(len(channels)), nil
+}
+
+// Update updates a notification channel
+// @Summary Update a notification channel
+// @Description Updates a notification channel of the current user. If no token is provided, the existing one is kept.
+// @tags user
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param channel path int true "Notification channel ID"
+// @Param notificationChannel body models.NotificationChannel true "The notification channel with updated values"
+// @Success 200 {object} models.NotificationChannel "The updated notification channel."
+// @Failure 400 {object} web.HTTPError "Invalid notification channel object provided."
+// @Failure 403 {object} web.HTTPError "The channel belongs to another user."
+// @Failure 404 {object} web.HTTPError "The notification channel does not exist."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /user/settings/notifications/channels/{channel} [post]
+func (c *NotificationChannel) Update(s *xorm.Session, a web.Auth) (err error) {
+	if err = c.validate(false); err != nil {
+		return
+	}
+
+	cols := []string{"type", "title", "url", "target", "enabled"}
+	if c.Token != "" {
+		cols = append(cols, "token")
+	}
+
+	_, err = s.
+		Where("id = ?", c.ID).
+		Cols(cols...).
+		Update(c)
+	if err != nil {
+		return
+	}
+
+	return c.ReadOne(s, a)
+}
+
+// Delete removes a notification channel
+// @Summary Remove a notification channel
+// @Description Removes a notification channel of the current user. No further notifications are sent to it.
+// @tags user
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param channel path int true "Notification channel ID"
+// @Success 200 {object} models.Message "The notification channel was successfully deleted."
+// @Failure 403 {object} web.HTTPError "The channel belongs to another user."
+// @Failure 404 {object} web.HTTPError "The notification channel does not exist."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /user/settings/notifications/channels/{channel} [delete]
+func (c *NotificationChannel) Delete(s *xorm.Session, a web.Auth) (err error) {
+	_, err = s.Where("id = ?", c.ID).Delete(&notifications.NotificationChannel{})
+	return
+}
@@ -0,0 +1,58 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"code.vikunja.io/web"
+	"xorm.io/xorm"
+)
+
+// CanRead checks if the user can see a notification channel
+func (c *NotificationChannel) CanRead(s *xorm.Session, a web.Auth) (bool, int, error) {
+	can, err := c.isOwner(s, a)
+	return can, int(RightAdmin), err
+}
+
+// CanCreate checks if the user can add a notification channel. Only users can have notification channels.
+func (c *NotificationChannel) CanCreate(s *xorm.Session, a web.Auth) (bool, error) {
+	if _, is := a.(*LinkSharing); is {
+		return false, nil
+	}
+	return true, nil
+}
+
+// CanUpdate checks if the user can update a notification channel
+func (c *NotificationChannel) CanUpdate(s *xorm.Session, a web.Auth) (bool, error) {
+	return c.isOwner(s, a)
+}
+
+// CanDelete checks if the user can delete a notification channel
+func (c *NotificationChannel) CanDelete(s *xorm.Session, a web.Auth) (bool, error) {
+	return c.isOwner(s, a)
+}
+
+func (c *NotificationChannel) isOwner(s *xorm.Session, a web.Auth) (bool, error) {
+	if _, is := a.(*LinkSharing); is {
+		return false, nil
+	}
+
+	channel, err := getNotificationChannelByID(s, c.ID)
+	if err != nil {
+		return false, err
+	}
+	return channel.NotifiableID == a.GetID(), nil
+}
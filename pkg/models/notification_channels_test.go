@@ -0,0 +1,147 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"testing"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/notifications"
+	"code.vikunja.io/api/pkg/user"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNotificationChannel_Create(t *testing.T) {
+	u := &user.User{ID: 1}
+
+	t.Run("normal", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		c := &NotificationChannel{}
+		c.Type = notifications.ChannelTypeGotify
+		c.URL = "https://gotify.example.com"
+		c.Token = "apptoken"
+		c.Enabled = true
+		err := c.Create(s, u)
+		assert.NoError(t, err)
+		assert.Empty(t, c.Token)
+		db.AssertExists(t, "notification_channels", map[string]interface{}{
+			"id":            c.ID,
+			"notifiable_id": u.ID,
+			"type":          notifications.ChannelTypeGotify,
+			"token":         "apptoken",
+		}, false)
+	})
+	t.Run("invalid type", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		c := &NotificationChannel{}
+		c.Type = "carrier-pigeon"
+		c.URL = "https://example.com"
+		err := c.Create(s, u)
+		assert.Error(t, err)
+		assert.True(t, IsErrInvalidNotificationChannelType(err))
+	})
+	t.Run("invalid url", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		c := &NotificationChannel{}
+		c.Type = notifications.ChannelTypeSlack
+		c.URL = "ftp://example.com"
+		err := c.Create(s, u)
+		assert.Error(t, err)
+		assert.True(t, IsErrInvalidNotificationChannelURL(err))
+	})
+	t.Run("matrix without room", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		c := &NotificationChannel{}
+		c.Type = notifications.ChannelTypeMatrix
+		c.URL = "https://matrix.example.com"
+		c.Token = "token"
+		err := c.Create(s, u)
+		assert.Error(t, err)
+		assert.True(t, IsErrNotificationChannelMissingField(err))
+	})
+}
+
+func TestNotificationChannel_ReadAll(t *testing.T) {
+	db.LoadAndAssertFixtures(t)
+	s := db.NewSession()
+	defer s.Close()
+
+	c := &NotificationChannel{}
+	channels, _, total, err := c.ReadAll(s, &user.User{ID: 1}, "", 1, 50)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	all := channels.([]*notifications.NotificationChannel)
+	assert.Equal(t, int64(1), all[0].ID)
+	assert.Empty(t, all[0].Token)
+}
+
+func TestNotificationChannel_Update(t *testing.T) {
+	t.Run("keeps the token", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		c := &NotificationChannel{}
+		c.ID = 1
+		c.Type = notifications.ChannelTypeMatrix
+		c.Title = "Other room"
+		c.URL = "https://matrix.example.com"
+		c.Target = "!other:example.com"
+		err := c.Update(s, &user.User{ID: 1})
+		assert.NoError(t, err)
+		db.AssertExists(t, "notification_channels", map[string]interface{}{
+			"id":     1,
+			"title":  "Other room",
+			"target": "!other:example.com",
+			"token":  "matrix-access-token",
+		}, false)
+	})
+}
+
+func TestNotificationChannel_Rights(t *testing.T) {
+	db.LoadAndAssertFixtures(t)
+	s := db.NewSession()
+	defer s.Close()
+
+	c := &NotificationChannel{}
+	c.ID = 1
+	can, err := c.CanDelete(s, &user.User{ID: 1})
+	assert.NoError(t, err)
+	assert.True(t, can)
+
+	can, err = c.CanDelete(s, &user.User{ID: 2})
+	assert.NoError(t, err)
+	assert.False(t, can)
+
+	c.ID = 9999
+	_, err = c.CanDelete(s, &user.User{ID: 1})
+	assert.Error(t, err)
+	assert.True(t, IsErrNotificationChannelDoesNotExist(err))
+}
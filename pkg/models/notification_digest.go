@@ -0,0 +1,270 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"strconv"
+	"time"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/cron"
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/i18n"
+	"code.vikunja.io/api/pkg/log"
+	"code.vikunja.io/api/pkg/notifications"
+	"code.vikunja.io/api/pkg/user"
+
+	"xorm.io/xorm"
+)
+
+// DigestNotification is the summary of all notifications a user got since their last digest
+type DigestNotification struct {
+	User         *user.User
+	Items        []*notifications.DigestItem
+	Lists        map[int64]*List
+	OverdueTasks []*Task
+}
+
+type digestTaskGroup struct {
+	id      int64
+	title   string
+	deleted bool
+	lines   []string
+}
+
+type digestListGroup struct {
+	id    int64
+	lines []string
+	tasks []*digestTaskGroup
+}
+
+// groupDigestItems groups the items of a digest by list and task, in the order they happened.
+func groupDigestItems(items []*notifications.DigestItem) (groups []*digestListGroup) {
+	lists := make(map[int64]*digestListGroup)
+	tasks := make(map[int64]*digestTaskGroup)
+
+	for _, item := range items {
+		lg, exists := lists[item.ListID]
+		if !exists {
+			lg = &digestListGroup{id: item.ListID}
+			lists[item.ListID] = lg
+			groups = append(groups, lg)
+		}
+
+		if item.TaskID == 0 {
+			lg.lines = append(lg.lines, item.Text)
+			continue
+		}
+
+		tg, exists := tasks[item.TaskID]
+		if !exists {
+			tg = &digestTaskGroup{id: item.TaskID, title: item.TaskTitle}
+			tasks[item.TaskID] = tg
+			lg.tasks = append(lg.tasks, tg)
+		}
+		tg.lines = append(tg.lines, item.Text)
+		if item.Notification == (&TaskDeletedNotification{}).Name() {
+			tg.deleted = true
+		}
+	}
+
+	return
+}
+
+// ToMail returns the mail notification for DigestNotification
+func (n *DigestNotification) ToMail(lang string) *notifications.Mail {
+	mail := notifications.NewMail().
+		Subject(i18n.T(lang, "notifications.digest.subject")).
+		Greeting(i18n.T(lang, "mail.greeting", i18n.Params{"name": n.User.GetName()}))
+
+	if len(n.Items) > 0 {
+		mail.Line(i18n.T(lang, "notifications.digest.message"))
+	}
+
+	for _, lg := range groupDigestItems(n.Items) {
+		if l, exists := n.Lists[lg.id]; exists {
+			mail.Line("**" + l.Title + "**")
+		}
+
+		for _, line := range lg.lines {
+			mail.Line(line)
+		}
+
+		for _, tg := range lg.tasks {
+			task := "[" + tg.title + "](" + config.ServiceFrontendurl.GetString() + "tasks/" + strconv.FormatInt(tg.id, 10) + ")"
+			if tg.deleted {
+				task = tg.title
+			}
+			for _, line := range tg.lines {
+				task += "\n* " + line
+			}
+			mail.Line(task)
+		}
+	}
+
+	if len(n.OverdueTasks) > 0 {
+		mail.
+			Line(i18n.T(lang, "notifications.digest.overdue_tasks")).
+			Line(getOverdueTasksLine(lang, n.User, n.OverdueTasks))
+	}
+
+	return mail.
+		Action(i18n.T(lang, "notifications.open_vikunja"), config.ServiceFrontendurl.GetString()).
+		Line(i18n.T(lang, "mail.have_a_nice_day"))
+}
+
+// ToDB returns the DigestNotification notification in a format which can be saved in the db
+func (n *DigestNotification) ToDB() interface{} {
+	return nil
+}
+
+// ToText returns nothing because the notifications in the digest were already sent to all chat channels.
+func (n *DigestNotification) ToText(lang string) *notifications.Text {
+	return nil
+}
+
+// Name returns the name of the notification
+func (n *DigestNotification) Name() string {
+	return "digest"
+}
+
+// isDigestDue checks if the digest of a user should be sent now and whether it should contain their overdue tasks.
+// Overdue tasks are part of the digest sent at the hour the user chose, in their timezone.
+func isDigestDue(u *user.User, now time.Time) (due bool, withOverdueTasks bool) {
+	now = now.In(u.GetTimeZone())
+	atDigestHour := now.Hour() == u.DigestHour
+
+	switch u.DigestInterval {
+	case user.DigestIntervalHourly:
+		return true, atDigestHour
+	case user.DigestIntervalDaily:
+		return atDigestHour, atDigestHour
+	case user.DigestIntervalWeekly:
+		due = atDigestHour && int(now.Weekday()) == u.WeekStart
+		return due, due
+	}
+
+	return false, false
+}
+
+func sendDigests(s *xorm.Session, now time.Time) (err error) {
+	users := []*user.User{}
+	err = s.
+		Where("digest_interval IS NOT NULL AND digest_interval != ''").
+		Find(&users)
+	if err != nil {
+		return
+	}
+
+	var overdue map[int64]*userWithTasks
+
+	for _, u := range users {
+		due, withOverdueTasks := isDigestDue(u, now)
+		if !due {
+			continue
+		}
+
+		items, err := notifications.GetDigestItems(s, u.ID)
+		if err != nil {
+			return err
+		}
+
+		var overdueTasks []*Task
+		if withOverdueTasks && u.OverdueTasksRemindersEnabled && config.ServiceEnableEmailReminders.GetBool() {
+			if overdue == nil {
+				overdue, err = getUndoneOverdueTasksByUser(s, now)
+				if err != nil {
+					return err
+				}
+			}
+			if ut, has := overdue[u.ID]; has {
+				overdueTasks = ut.tasks
+			}
+		}
+
+		if len(items) == 0 && len(overdueTasks) == 0 {
+			continue
+		}
+
+		err = sendDigest(s, u, items, overdueTasks)
+		if err != nil {
+			log.Errorf("[Notification Digest] Could not send digest to user %d: %s", u.ID, err)
+			continue
+		}
+
+		log.Debugf("[Notification Digest] Sent digest with %d items and %d overdue tasks to user %d", len(items), len(overdueTasks), u.ID)
+	}
+
+	return nil
+}
+
+func sendDigest(s *xorm.Session, u *user.User, items []*notifications.DigestItem, overdueTasks []*Task) (err error) {
+	listIDs := make([]int64, 0, len(items))
+	for _, item := range items {
+		if item.ListID != 0 {
+			listIDs = append(listIDs, item.ListID)
+		}
+	}
+	lists, err := GetListsByIDs(s, listIDs)
+	if err != nil {
+		return err
+	}
+
+	err = notifications.Notify(u, &DigestNotification{
+		User:         u,
+		Items:        items,
+		Lists:        lists,
+		OverdueTasks: overdueTasks,
+	})
+	if err != nil {
+		return err
+	}
+
+	return notifications.DeleteDigestItems(s, items)
+}
+
+// SendPendingDigest sends all items which are still waiting for the next digest of a user right away.
+// Once a user turns their digest off, nothing else would ever send them.
+func SendPendingDigest(s *xorm.Session, u *user.User) (err error) {
+	items, err := notifications.GetDigestItems(s, u.ID)
+	if err != nil || len(items) == 0 {
+		return err
+	}
+
+	return sendDigest(s, u, items, nil)
+}
+
+// RegisterDigestCron registers a function which sends the notification digests of all users who want one every hour.
+func RegisterDigestCron() {
+	if !config.MailerEnabled.GetBool() {
+		log.Info("Mailer is disabled, not sending notification digests")
+		return
+	}
+
+	err := cron.Schedule("0 * * * *", func() {
+		s := db.NewSession()
+		defer s.Close()
+
+		err := sendDigests(s, time.Now())
+		if err != nil {
+			log.Errorf("[Notification Digest] Could not send digests: %s", err)
+		}
+	})
+	if err != nil {
+		log.Fatalf("Could not register notification digest cron: %s", err)
+	}
+}
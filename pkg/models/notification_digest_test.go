@@ -0,0 +1,158 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"testing"
+	"time"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/notifications"
+	"code.vikunja.io/api/pkg/user"
+
+	"github.com/stretchr/testify/assert"
+	"xorm.io/xorm"
+)
+
+func TestIsDigestDue(t *testing.T) {
+	// 2021-10-04 is a monday
+	at := func(hour int) time.Time {
+		return time.Date(2021, 10, 4, hour, 0, 0, 0, config.GetTimeZone())
+	}
+
+	t.Run("no digest", func(t *testing.T) {
+		due, _ := isDigestDue(&user.User{}, at(8))
+		assert.False(t, due)
+	})
+	t.Run("hourly", func(t *testing.T) {
+		u := &user.User{DigestInterval: user.DigestIntervalHourly, DigestHour: 8}
+		due, withOverdueTasks := isDigestDue(u, at(7))
+		assert.True(t, due)
+		assert.False(t, withOverdueTasks)
+		due, withOverdueTasks = isDigestDue(u, at(8))
+		assert.True(t, due)
+		assert.True(t, withOverdueTasks)
+	})
+	t.Run("daily", func(t *testing.T) {
+		u := &user.User{DigestInterval: user.DigestIntervalDaily, DigestHour: 18}
+		due, _ := isDigestDue(u, at(8))
+		assert.False(t, due)
+		due, withOverdueTasks := isDigestDue(u, at(18))
+		assert.True(t, due)
+		assert.True(t, withOverdueTasks)
+	})
+	t.Run("weekly", func(t *testing.T) {
+		u := &user.User{DigestInterval: user.DigestIntervalWeekly, DigestHour: 8, WeekStart: 1}
+		due, _ := isDigestDue(u, at(8))
+		assert.True(t, due)
+		due, _ = isDigestDue(u, at(8).Add(24*time.Hour))
+		assert.False(t, due)
+	})
+}
+
+func TestGroupDigestItems(t *testing.T) {
+	items := []*notifications.DigestItem{
+		{ListID: 1, TaskID: 1, TaskTitle: "one", Text: "first"},
+		{ListID: 2, Text: "list"},
+		{ListID: 1, TaskID: 2, TaskTitle: "two", Text: "second", Notification: "task.deleted"},
+		{ListID: 1, TaskID: 1, TaskTitle: "one", Text: "third"},
+	}
+
+	groups := groupDigestItems(items)
+	assert.Len(t, groups, 2)
+	assert.Equal(t, int64(1), groups[0].id)
+	assert.Len(t, groups[0].tasks, 2)
+	assert.Equal(t, []string{"first", "third"}, groups[0].tasks[0].lines)
+	assert.False(t, groups[0].tasks[0].deleted)
+	assert.True(t, groups[0].tasks[1].deleted)
+	assert.Equal(t, []string{"list"}, groups[1].lines)
+}
+
+func TestSendDigests(t *testing.T) {
+	// After the due date of task #6 which is overdue and created by user 1
+	now := time.Date(2018, 12, 1, 8, 0, 0, 0, config.GetTimeZone())
+
+	setDigest := func(t *testing.T, s *xorm.Session, id int64, interval string, hour int) {
+		_, err := s.
+			Where("id = ?", id).
+			Cols("digest_interval", "digest_hour").
+			Update(&user.User{DigestInterval: interval, DigestHour: hour})
+		assert.NoError(t, err)
+	}
+
+	t.Run("due", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+		notifications.Fake()
+		defer notifications.Unfake()
+
+		setDigest(t, s, 1, user.DigestIntervalDaily, 8)
+
+		err := sendDigests(s, now)
+		assert.NoError(t, err)
+		notifications.AssertSent(t, &DigestNotification{})
+		db.AssertMissing(t, "notification_digest_items", map[string]interface{}{"notifiable_id": 1})
+		// User 2 does not want a digest
+		db.AssertExists(t, "notification_digest_items", map[string]interface{}{"id": 4}, false)
+	})
+	t.Run("not due", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+		notifications.Fake()
+		defer notifications.Unfake()
+
+		setDigest(t, s, 1, user.DigestIntervalDaily, 18)
+
+		err := sendDigests(s, now)
+		assert.NoError(t, err)
+		db.AssertExists(t, "notification_digest_items", map[string]interface{}{"id": 1}, false)
+	})
+}
+
+func TestSendPendingDigest(t *testing.T) {
+	t.Run("pending items", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+		notifications.Fake()
+		defer notifications.Unfake()
+
+		u, err := user.GetUserWithEmail(s, &user.User{ID: 2})
+		assert.NoError(t, err)
+
+		err = SendPendingDigest(s, u)
+		assert.NoError(t, err)
+		notifications.AssertSent(t, &DigestNotification{})
+		db.AssertMissing(t, "notification_digest_items", map[string]interface{}{"notifiable_id": 2})
+		// Items of other users are kept
+		db.AssertExists(t, "notification_digest_items", map[string]interface{}{"id": 1}, false)
+	})
+	t.Run("nothing pending", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+		notifications.Fake()
+		defer notifications.Unfake()
+
+		err := SendPendingDigest(s, &user.User{ID: 3})
+		assert.NoError(t, err)
+		notifications.AssertNothingSent(t)
+	})
+}
@@ -0,0 +1,68 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"code.vikunja.io/api/pkg/notifications"
+	"code.vikunja.io/api/pkg/user"
+
+	"xorm.io/xorm"
+)
+
+func init() {
+	for _, n := range []notifications.Notification{
+		&TaskCommentNotification{},
+		&TaskAssignedNotification{},
+		&TaskDeletedNotification{},
+		&ListCreatedNotification{},
+		&TeamMemberAddedNotification{},
+		&UserMentionedInTaskNotification{},
+	} {
+		notifications.RegisterConfigurableNotification(n.Name(), notifications.ChannelMail, notifications.ChannelInApp, notifications.ChannelChat)
+	}
+
+	// Overdue reminders are not saved in the database
+	notifications.RegisterConfigurableNotification((&UndoneTasksOverdueNotification{}).Name(), notifications.ChannelMail, notifications.ChannelChat)
+}
+
+// GetNotificationPreferences returns the notification settings of a user for all notifications they can turn on or off.
+func GetNotificationPreferences(s *xorm.Session, u *user.User) (preferences []*notifications.NotificationPreference, err error) {
+	return notifications.GetNotificationPreferences(s, u.ID)
+}
+
+// UpdateNotificationPreferences turns notifications on or off for a user.
+// Only preferences passed in are changed, all others stay as they are.
+func UpdateNotificationPreferences(s *xorm.Session, u *user.User, preferences []*notifications.NotificationPreference) (err error) {
+	for _, p := range preferences {
+		if !notifications.IsConfigurableNotification(p.Notification, p.Channel) {
+			return ErrInvalidNotificationPreference{
+				Notification: p.Notification,
+				Channel:      p.Channel,
+			}
+		}
+	}
+
+	for _, p := range preferences {
+		p.NotifiableID = u.ID
+		err = notifications.SetNotificationPreference(s, p)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
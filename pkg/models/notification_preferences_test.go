@@ -0,0 +1,92 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"testing"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/notifications"
+	"code.vikunja.io/api/pkg/user"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateNotificationPreferences(t *testing.T) {
+	u := &user.User{ID: 1}
+
+	t.Run("normal", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		err := UpdateNotificationPreferences(s, u, []*notifications.NotificationPreference{
+			{
+				Notification: "task.comment",
+				Channel:      notifications.ChannelMail,
+				Enabled:      false,
+			},
+		})
+		assert.NoError(t, err)
+		db.AssertExists(t, "notification_preferences", map[string]interface{}{
+			"notifiable_id": 1,
+			"notification":  "task.comment",
+			"channel":       notifications.ChannelMail,
+			"enabled":       false,
+		}, false)
+
+		preferences, err := GetNotificationPreferences(s, u)
+		assert.NoError(t, err)
+		for _, p := range preferences {
+			if p.Notification == "task.comment" && p.Channel == notifications.ChannelMail {
+				assert.False(t, p.Enabled)
+				continue
+			}
+			assert.True(t, p.Enabled)
+		}
+	})
+	t.Run("notification which can't be turned off", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		err := UpdateNotificationPreferences(s, u, []*notifications.NotificationPreference{
+			{
+				Notification: "user.deletion.confirm",
+				Channel:      notifications.ChannelMail,
+				Enabled:      false,
+			},
+		})
+		assert.Error(t, err)
+		assert.True(t, IsErrInvalidNotificationPreference(err))
+	})
+	t.Run("channel the notification is not sent through", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		err := UpdateNotificationPreferences(s, u, []*notifications.NotificationPreference{
+			{
+				Notification: "task.undone.overdue",
+				Channel:      notifications.ChannelInApp,
+				Enabled:      false,
+			},
+		})
+		assert.Error(t, err)
+		assert.True(t, IsErrInvalidNotificationPreference(err))
+	})
+}
@@ -22,13 +22,17 @@ import (
 	"strings"
 	"time"
 
-	"code.vikunja.io/api/pkg/utils"
-
 	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/i18n"
 	"code.vikunja.io/api/pkg/notifications"
 	"code.vikunja.io/api/pkg/user"
 )
 
+// formatDateForUser returns a date in the timezone and language of the user, to show it in a notification.
+func formatDateForUser(lang string, u *user.User, date time.Time) string {
+	return i18n.FormatDate(lang, date.In(u.GetTimeZone()))
+}
+
 // ReminderDueNotification represents a ReminderDueNotification notification
 type ReminderDueNotification struct {
 	User *user.User `json:"user"`
@@ -36,14 +40,20 @@ type ReminderDueNotification struct {
 }
 
 // ToMail returns the mail notification for ReminderDueNotification
-func (n *ReminderDueNotification) ToMail() *notifications.Mail {
-	return notifications.NewMail().
+func (n *ReminderDueNotification) ToMail(lang string) *notifications.Mail {
+	mail := notifications.NewMail().
 		To(n.User.Email).
-		Subject(`Reminder for "`+n.Task.Title+`"`).
-		Greeting("Hi "+n.User.GetName()+",").
-		Line(`This is a friendly reminder of the task "`+n.Task.Title+`".`).
-		Action("Open Task", config.ServiceFrontendurl.GetString()+"tasks/"+strconv.FormatInt(n.Task.ID, 10)).
-		Line("Have a nice day!")
+		Subject(i18n.T(lang, "notifications.task.reminder.subject", i18n.Params{"task": n.Task.Title})).
+		Greeting(i18n.T(lang, "mail.greeting", i18n.Params{"name": n.User.GetName()})).
+		Line(i18n.T(lang, "notifications.task.reminder.message", i18n.Params{"task": n.Task.Title}))
+
+	if !n.Task.DueDate.IsZero() {
+		mail.Line(i18n.T(lang, "notifications.task.reminder.due", i18n.Params{"date": formatDateForUser(lang, n.User, n.Task.DueDate)}))
+	}
+
+	return mail.
+		Action(i18n.T(lang, "notifications.task.open"), config.ServiceFrontendurl.GetString()+"tasks/"+strconv.FormatInt(n.Task.ID, 10)).
+		Line(i18n.T(lang, "mail.have_a_nice_day"))
 }
 
 // ToDB returns the ReminderDueNotification notification in a format which can be saved in the db
@@ -69,15 +79,15 @@ func (n *TaskCommentNotification) SubjectID() int64 {
 }
 
 // ToMail returns the mail notification for TaskCommentNotification
-func (n *TaskCommentNotification) ToMail() *notifications.Mail {
+func (n *TaskCommentNotification) ToMail(lang string) *notifications.Mail {
 
 	mail := notifications.NewMail().
 		From(n.Doer.GetNameAndFromEmail())
 
-	subject := "Re: " + n.Task.Title
+	subject := i18n.T(lang, "notifications.task.comment.subject", i18n.Params{"task": n.Task.Title})
 	if n.Mentioned {
-		subject = n.Doer.GetName() + ` mentioned you in a comment in "` + n.Task.Title + `"`
-		mail.Line("**" + n.Doer.GetName() + "** mentioned you in a comment:")
+		subject = i18n.T(lang, "notifications.task.comment.mentioned_subject", i18n.Params{"doer": n.Doer.GetName(), "task": n.Task.Title})
+		mail.Line(i18n.T(lang, "notifications.task.comment.mentioned_message", i18n.Params{"doer": n.Doer.GetName()}))
 	}
 
 	mail.Subject(subject)
@@ -88,7 +98,7 @@ func (n *TaskCommentNotification) ToMail() *notifications.Mail {
 	}
 
 	return mail.
-		Action("View Task", n.Task.GetFrontendURL())
+		Action(i18n.T(lang, "notifications.task.view"), n.Task.GetFrontendURL())
 }
 
 // ToDB returns the TaskCommentNotification notification in a format which can be saved in the db
@@ -101,6 +111,21 @@ func (n *TaskCommentNotification) Name() string {
 	return "task.comment"
 }
 
+// ToDigest returns the summary of TaskCommentNotification for the notification digest
+func (n *TaskCommentNotification) ToDigest(lang string) *notifications.DigestItem {
+	comment := []rune(strings.Join(strings.Fields(n.Comment.Comment), " "))
+	if len(comment) > 200 {
+		comment = append(comment[:200], '…')
+	}
+
+	return &notifications.DigestItem{
+		ListID:    n.Task.ListID,
+		TaskID:    n.Task.ID,
+		TaskTitle: n.Task.Title,
+		Text:      i18n.T(lang, "notifications.task.comment.digest", i18n.Params{"doer": n.Doer.GetName(), "comment": string(comment)}),
+	}
+}
+
 // TaskAssignedNotification represents a TaskAssignedNotification notification
 type TaskAssignedNotification struct {
 	Doer     *user.User `json:"doer"`
@@ -109,11 +134,11 @@ type TaskAssignedNotification struct {
 }
 
 // ToMail returns the mail notification for TaskAssignedNotification
-func (n *TaskAssignedNotification) ToMail() *notifications.Mail {
+func (n *TaskAssignedNotification) ToMail(lang string) *notifications.Mail {
 	return notifications.NewMail().
-		Subject(n.Task.Title+"("+n.Task.GetFullIdentifier()+")"+" has been assigned to "+n.Assignee.GetName()).
-		Line(n.Doer.GetName()+" has assigned this task to "+n.Assignee.GetName()+".").
-		Action("View Task", n.Task.GetFrontendURL())
+		Subject(i18n.T(lang, "notifications.task.assigned.subject", i18n.Params{"task": n.Task.Title, "identifier": n.Task.GetFullIdentifier(), "assignee": n.Assignee.GetName()})).
+		Line(i18n.T(lang, "notifications.task.assigned.message", i18n.Params{"doer": n.Doer.GetName(), "assignee": n.Assignee.GetName()})).
+		Action(i18n.T(lang, "notifications.task.view"), n.Task.GetFrontendURL())
 }
 
 // ToDB returns the TaskAssignedNotification notification in a format which can be saved in the db
@@ -126,6 +151,16 @@ func (n *TaskAssignedNotification) Name() string {
 	return "task.assigned"
 }
 
+// ToDigest returns the summary of TaskAssignedNotification for the notification digest
+func (n *TaskAssignedNotification) ToDigest(lang string) *notifications.DigestItem {
+	return &notifications.DigestItem{
+		ListID:    n.Task.ListID,
+		TaskID:    n.Task.ID,
+		TaskTitle: n.Task.Title,
+		Text:      i18n.T(lang, "notifications.task.assigned.message", i18n.Params{"doer": n.Doer.GetName(), "assignee": n.Assignee.GetName()}),
+	}
+}
+
 // TaskDeletedNotification represents a TaskDeletedNotification notification
 type TaskDeletedNotification struct {
 	Doer *user.User `json:"doer"`
@@ -133,10 +168,10 @@ type TaskDeletedNotification struct {
 }
 
 // ToMail returns the mail notification for TaskDeletedNotification
-func (n *TaskDeletedNotification) ToMail() *notifications.Mail {
+func (n *TaskDeletedNotification) ToMail(lang string) *notifications.Mail {
 	return notifications.NewMail().
-		Subject(n.Task.Title + "(" + n.Task.GetFullIdentifier() + ")" + " has been delete").
-		Line(n.Doer.GetName() + " has deleted the task " + n.Task.Title + "(" + n.Task.GetFullIdentifier() + ")")
+		Subject(i18n.T(lang, "notifications.task.deleted.subject", i18n.Params{"task": n.Task.Title, "identifier": n.Task.GetFullIdentifier()})).
+		Line(i18n.T(lang, "notifications.task.deleted.message", i18n.Params{"doer": n.Doer.GetName(), "task": n.Task.Title, "identifier": n.Task.GetFullIdentifier()}))
 }
 
 // ToDB returns the TaskDeletedNotification notification in a format which can be saved in the db
@@ -149,6 +184,16 @@ func (n *TaskDeletedNotification) Name() string {
 	return "task.deleted"
 }
 
+// ToDigest returns the summary of TaskDeletedNotification for the notification digest
+func (n *TaskDeletedNotification) ToDigest(lang string) *notifications.DigestItem {
+	return &notifications.DigestItem{
+		ListID:    n.Task.ListID,
+		TaskID:    n.Task.ID,
+		TaskTitle: n.Task.Title,
+		Text:      i18n.T(lang, "notifications.task.deleted.digest", i18n.Params{"doer": n.Doer.GetName()}),
+	}
+}
+
 // ListCreatedNotification represents a ListCreatedNotification notification
 type ListCreatedNotification struct {
 	Doer *user.User `json:"doer"`
@@ -156,11 +201,11 @@ type ListCreatedNotification struct {
 }
 
 // ToMail returns the mail notification for ListCreatedNotification
-func (n *ListCreatedNotification) ToMail() *notifications.Mail {
+func (n *ListCreatedNotification) ToMail(lang string) *notifications.Mail {
 	return notifications.NewMail().
-		Subject(n.Doer.GetName()+` created the list "`+n.List.Title+`"`).
-		Line(n.Doer.GetName()+` created the list "`+n.List.Title+`"`).
-		Action("View List", config.ServiceFrontendurl.GetString()+"lists/")
+		Subject(i18n.T(lang, "notifications.list.created.subject", i18n.Params{"doer": n.Doer.GetName(), "list": n.List.Title})).
+		Line(i18n.T(lang, "notifications.list.created.subject", i18n.Params{"doer": n.Doer.GetName(), "list": n.List.Title})).
+		Action(i18n.T(lang, "notifications.list.view"), config.ServiceFrontendurl.GetString()+"lists/")
 }
 
 // ToDB returns the ListCreatedNotification notification in a format which can be saved in the db
@@ -173,6 +218,14 @@ func (n *ListCreatedNotification) Name() string {
 	return "list.created"
 }
 
+// ToDigest returns the summary of ListCreatedNotification for the notification digest
+func (n *ListCreatedNotification) ToDigest(lang string) *notifications.DigestItem {
+	return &notifications.DigestItem{
+		ListID: n.List.ID,
+		Text:   i18n.T(lang, "notifications.list.created.digest", i18n.Params{"doer": n.Doer.GetName()}),
+	}
+}
+
 // TeamMemberAddedNotification represents a TeamMemberAddedNotification notification
 type TeamMemberAddedNotification struct {
 	Member *user.User `json:"member"`
@@ -181,13 +234,13 @@ type TeamMemberAddedNotification struct {
 }
 
 // ToMail returns the mail notification for TeamMemberAddedNotification
-func (n *TeamMemberAddedNotification) ToMail() *notifications.Mail {
+func (n *TeamMemberAddedNotification) ToMail(lang string) *notifications.Mail {
 	return notifications.NewMail().
-		Subject(n.Doer.GetName()+" added you to the "+n.Team.Name+" team in Vikunja").
+		Subject(i18n.T(lang, "notifications.team.member_added.subject", i18n.Params{"doer": n.Doer.GetName(), "team": n.Team.Name})).
 		From(n.Doer.GetNameAndFromEmail()).
-		Greeting("Hi "+n.Member.GetName()+",").
-		Line(n.Doer.GetName()+" has just added you to the "+n.Team.Name+" team in Vikunja.").
-		Action("View Team", config.ServiceFrontendurl.GetString()+"teams/"+strconv.FormatInt(n.Team.ID, 10)+"/edit")
+		Greeting(i18n.T(lang, "mail.greeting", i18n.Params{"name": n.Member.GetName()})).
+		Line(i18n.T(lang, "notifications.team.member_added.message", i18n.Params{"doer": n.Doer.GetName(), "team": n.Team.Name})).
+		Action(i18n.T(lang, "notifications.team.view"), config.ServiceFrontendurl.GetString()+"teams/"+strconv.FormatInt(n.Team.ID, 10)+"/edit")
 }
 
 // ToDB returns the TeamMemberAddedNotification notification in a format which can be saved in the db
@@ -207,14 +260,15 @@ type UndoneTaskOverdueNotification struct {
 }
 
 // ToMail returns the mail notification for UndoneTaskOverdueNotification
-func (n *UndoneTaskOverdueNotification) ToMail() *notifications.Mail {
+func (n *UndoneTaskOverdueNotification) ToMail(lang string) *notifications.Mail {
 	until := time.Until(n.Task.DueDate).Round(1*time.Hour) * -1
 	return notifications.NewMail().
-		Subject(`Task "`+n.Task.Title+`" is overdue`).
-		Greeting("Hi "+n.User.GetName()+",").
-		Line(`This is a friendly reminder of the task "`+n.Task.Title+`" which is overdue since `+utils.HumanizeDuration(until)+` and not yet done.`).
-		Action("Open Task", config.ServiceFrontendurl.GetString()+"tasks/"+strconv.FormatInt(n.Task.ID, 10)).
-		Line("Have a nice day!")
+		Subject(i18n.T(lang, "notifications.task.overdue.subject", i18n.Params{"task": n.Task.Title})).
+		Greeting(i18n.T(lang, "mail.greeting", i18n.Params{"name": n.User.GetName()})).
+		Line(i18n.T(lang, "notifications.task.overdue.message", i18n.Params{"task": n.Task.Title, "duration": i18n.HumanizeDuration(lang, until)})).
+		Line(i18n.T(lang, "notifications.task.overdue.due", i18n.Params{"date": formatDateForUser(lang, n.User, n.Task.DueDate)})).
+		Action(i18n.T(lang, "notifications.task.open"), config.ServiceFrontendurl.GetString()+"tasks/"+strconv.FormatInt(n.Task.ID, 10)).
+		Line(i18n.T(lang, "mail.have_a_nice_day"))
 }
 
 // ToDB returns the UndoneTaskOverdueNotification notification in a format which can be saved in the db
@@ -227,6 +281,19 @@ func (n *UndoneTaskOverdueNotification) Name() string {
 	return "task.undone.overdue"
 }
 
+func getOverdueTasksLine(lang string, u *user.User, tasks []*Task) (overdueLine string) {
+	for _, task := range tasks {
+		until := time.Until(task.DueDate).Round(1*time.Hour) * -1
+		overdueLine += i18n.T(lang, "notifications.task.overdue.line", i18n.Params{
+			"task":     task.Title,
+			"url":      config.ServiceFrontendurl.GetString() + "tasks/" + strconv.FormatInt(task.ID, 10),
+			"duration": i18n.HumanizeDuration(lang, until),
+			"date":     formatDateForUser(lang, u, task.DueDate),
+		}) + "\n"
+	}
+	return
+}
+
 // UndoneTasksOverdueNotification represents a UndoneTasksOverdueNotification notification
 type UndoneTasksOverdueNotification struct {
 	User  *user.User
@@ -234,21 +301,15 @@ type UndoneTasksOverdueNotification struct {
 }
 
 // ToMail returns the mail notification for UndoneTasksOverdueNotification
-func (n *UndoneTasksOverdueNotification) ToMail() *notifications.Mail {
-
-	overdueLine := ""
-	for _, task := range n.Tasks {
-		until := time.Until(task.DueDate).Round(1*time.Hour) * -1
-		overdueLine += `* [` + task.Title + `](` + config.ServiceFrontendurl.GetString() + "tasks/" + strconv.FormatInt(task.ID, 10) + `), overdue since ` + utils.HumanizeDuration(until) + "\n"
-	}
+func (n *UndoneTasksOverdueNotification) ToMail(lang string) *notifications.Mail {
 
 	return notifications.NewMail().
-		Subject(`Your overdue tasks`).
-		Greeting("Hi "+n.User.GetName()+",").
-		Line("You have the following overdue tasks:").
-		Line(overdueLine).
-		Action("Open Vikunja", config.ServiceFrontendurl.GetString()).
-		Line("Have a nice day!")
+		Subject(i18n.T(lang, "notifications.tasks.overdue.subject")).
+		Greeting(i18n.T(lang, "mail.greeting", i18n.Params{"name": n.User.GetName()})).
+		Line(i18n.T(lang, "notifications.tasks.overdue.message")).
+		Line(getOverdueTasksLine(lang, n.User, n.Tasks)).
+		Action(i18n.T(lang, "notifications.open_vikunja"), config.ServiceFrontendurl.GetString()).
+		Line(i18n.T(lang, "mail.have_a_nice_day"))
 }
 
 // ToDB returns the UndoneTasksOverdueNotification notification in a format which can be saved in the db
@@ -273,16 +334,16 @@ func (n *UserMentionedInTaskNotification) SubjectID() int64 {
 }
 
 // ToMail returns the mail notification for UserMentionedInTaskNotification
-func (n *UserMentionedInTaskNotification) ToMail() *notifications.Mail {
-	subject := n.Doer.GetName() + ` mentioned you in a new task "` + n.Task.Title + `"`
+func (n *UserMentionedInTaskNotification) ToMail(lang string) *notifications.Mail {
+	subject := i18n.T(lang, "notifications.task.mentioned.subject", i18n.Params{"doer": n.Doer.GetName(), "task": n.Task.Title})
 	if n.IsNew {
-		subject = n.Doer.GetName() + ` mentioned you in a task "` + n.Task.Title + `"`
+		subject = i18n.T(lang, "notifications.task.mentioned.subject_new", i18n.Params{"doer": n.Doer.GetName(), "task": n.Task.Title})
 	}
 
 	mail := notifications.NewMail().
 		From(n.Doer.GetNameAndFromEmail()).
 		Subject(subject).
-		Line("**" + n.Doer.GetName() + "** mentioned you in a task:")
+		Line(i18n.T(lang, "notifications.task.mentioned.message", i18n.Params{"doer": n.Doer.GetName()}))
 
 	lines := bufio.NewScanner(strings.NewReader(n.Task.Description))
 	for lines.Scan() {
@@ -290,7 +351,7 @@ func (n *UserMentionedInTaskNotification) ToMail() *notifications.Mail {
 	}
 
 	return mail.
-		Action("View Task", n.Task.GetFrontendURL())
+		Action(i18n.T(lang, "notifications.task.view"), n.Task.GetFrontendURL())
 }
 
 // ToDB returns the UserMentionedInTaskNotification notification in a format which can be saved in the db
@@ -303,20 +364,30 @@ func (n *UserMentionedInTaskNotification) Name() string {
 	return "task.mentioned"
 }
 
+// ToDigest returns the summary of UserMentionedInTaskNotification for the notification digest
+func (n *UserMentionedInTaskNotification) ToDigest(lang string) *notifications.DigestItem {
+	return &notifications.DigestItem{
+		ListID:    n.Task.ListID,
+		TaskID:    n.Task.ID,
+		TaskTitle: n.Task.Title,
+		Text:      i18n.T(lang, "notifications.task.mentioned.digest", i18n.Params{"doer": n.Doer.GetName()}),
+	}
+}
+
 // DataExportReadyNotification represents a DataExportReadyNotification notification
 type DataExportReadyNotification struct {
 	User *user.User `json:"user"`
 }
 
 // ToMail returns the mail notification for DataExportReadyNotification
-func (n *DataExportReadyNotification) ToMail() *notifications.Mail {
+func (n *DataExportReadyNotification) ToMail(lang string) *notifications.Mail {
 	return notifications.NewMail().
-		Subject("Your Vikunja Data Export is ready").
-		Greeting("Hi "+n.User.GetName()+",").
-		Line("Your Vikunja Data Export is ready for you to download. Click the button below to download it:").
-		Action("Download", config.ServiceFrontendurl.GetString()+"user/export/download").
-		Line("The download will be available for the next 7 days.").
-		Line("Have a nice day!")
+		Subject(i18n.T(lang, "notifications.data_export.subject")).
+		Greeting(i18n.T(lang, "mail.greeting", i18n.Params{"name": n.User.GetName()})).
+		Line(i18n.T(lang, "notifications.data_export.message")).
+		Action(i18n.T(lang, "notifications.data_export.action"), config.ServiceFrontendurl.GetString()+"user/export/download").
+		Line(i18n.T(lang, "notifications.data_export.valid")).
+		Line(i18n.T(lang, "mail.have_a_nice_day"))
 }
 
 // ToDB returns the DataExportReadyNotification notification in a format which can be saved in the db
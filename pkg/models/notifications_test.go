@@ -0,0 +1,55 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"testing"
+	"time"
+
+	"code.vikunja.io/api/pkg/notifications"
+	"code.vikunja.io/api/pkg/user"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReminderDueNotification_ToMail(t *testing.T) {
+	n := &ReminderDueNotification{
+		User: &user.User{Username: "user1", Timezone: "Europe/Berlin"},
+		Task: &Task{
+			ID:      1,
+			Title:   "Task #1",
+			DueDate: time.Date(2021, time.March, 3, 13, 30, 0, 0, time.UTC),
+		},
+	}
+
+	t.Run("german", func(t *testing.T) {
+		mail, err := notifications.RenderMail(n.ToMail("de").Language("de"))
+		assert.NoError(t, err)
+		assert.Equal(t, "Erinnerung an „Task #1“", mail.Subject)
+		assert.Contains(t, mail.Message, "Hallo user1,")
+		assert.Contains(t, mail.Message, "Sie ist am 3. März 2021, 14:30 CET fällig.")
+		assert.Contains(t, mail.Message, "Aufgabe öffnen:")
+		assert.Contains(t, mail.HTMLMessage, "Falls der Button oben nicht funktioniert")
+	})
+	t.Run("english fallback", func(t *testing.T) {
+		mail, err := notifications.RenderMail(n.ToMail("xx").Language("xx"))
+		assert.NoError(t, err)
+		assert.Equal(t, `Reminder for "Task #1"`, mail.Subject)
+		assert.Contains(t, mail.Message, "It is due on March 3, 2021 14:30 CET.")
+		assert.Contains(t, mail.HTMLMessage, "If the button above doesn't work")
+	})
+}
@@ -0,0 +1,264 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/utils"
+	"code.vikunja.io/web"
+	"xorm.io/builder"
+	"xorm.io/xorm"
+)
+
+// OAuth2Client is a third-party application which can act on behalf of users after they authorized it.
+type OAuth2Client struct {
+	// The unique, numeric id of this client.
+	ID int64 `xorm:"bigint autoincr not null unique pk" json:"id" param:"client"`
+	// The public identifier of this client which is used in the oauth2 flow.
+	ClientID string `xorm:"varchar(50) not null unique" json:"client_id"`
+	// The name of the application. It is shown to users when they are asked to authorize it.
+	Name string `xorm:"varchar(250) not null" json:"name" valid:"required,runelength(1|250)" minLength:"1" maxLength:"250"`
+	// All urls users may be redirected to after they authorized the application. They must match exactly.
+	RedirectURIs []string `xorm:"JSON not null 'redirect_uris'" json:"redirect_uris" valid:"required"`
+	// Confidential clients get a client secret which they need to use when exchanging codes and refresh tokens.
+	// Applications which can't keep a secret, like single page or mobile apps, should not be confidential.
+	Confidential bool `xorm:"bool not null default false" json:"confidential"`
+	// The client secret. It is only returned once after creating a confidential client, Vikunja only stores a hash of it.
+	ClientSecret string `xorm:"-" json:"client_secret,omitempty"`
+
+	ClientSecretHash string `xorm:"varchar(45) null" json:"-"`
+	OwnerID          int64  `xorm:"bigint not null INDEX" json:"-"`
+
+	// A timestamp when this client was created. You cannot change this value.
+	Created time.Time `xorm:"created not null" json:"created"`
+
+	web.CRUDable `xorm:"-" json:"-"`
+	web.Rights   `xorm:"-" json:"-"`
+}
+
+// TableName returns the table name for oauth2 clients
+func (*OAuth2Client) TableName() string {
+	return "oauth2_clients"
+}
+
+func getOAuth2ClientByID(s *xorm.Session, id int64) (client *OAuth2Client, err error) {
+	client = &OAuth2Client{}
+	exists, err := s.Where("id = ?", id).Get(client)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrOAuth2ClientDoesNotExist{ID: id}
+	}
+	return
+}
+
+// GetOAuth2ClientByClientID returns an oauth2 client by its public client id.
+func GetOAuth2ClientByClientID(s *xorm.Session, clientID string) (client *OAuth2Client, err error) {
+	client = &OAuth2Client{}
+	exists, err := s.Where("client_id = ?", clientID).Get(client)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrOAuth2ClientDoesNotExist{ClientID: clientID}
+	}
+	return
+}
+
+// AuthenticateOAuth2Client checks the credentials of a client. Public clients only need their client id,
+// confidential clients also need their secret.
+func AuthenticateOAuth2Client(s *xorm.Session, clientID, clientSecret string) (client *OAuth2Client, err error) {
+	client, err = GetOAuth2ClientByClientID(s, clientID)
+	if IsErrOAuth2ClientDoesNotExist(err) {
+		return nil, ErrOAuth2InvalidClientCredentials{ClientID: clientID}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if client.Confidential && (clientSecret == "" || utils.Sha256(clientSecret) != client.ClientSecretHash) {
+		return nil, ErrOAuth2InvalidClientCredentials{ClientID: clientID}
+	}
+
+	return client, nil
+}
+
+// Schemes which execute or embed content in the browser instead of handing the redirect to an app.
+var deniedOAuth2RedirectSchemes = map[string]bool{
+	"javascript": true,
+	"data":       true,
+	"vbscript":   true,
+	"file":       true,
+	"blob":       true,
+	"about":      true,
+	"filesystem": true,
+}
+
+// Redirect uris need to be absolute and must not have a fragment. Allowed are https, plain http for loopback
+// addresses and private-use schemes in reverse domain notation like com.example.app:/callback, which is what
+// native apps use.
+func isValidOAuth2RedirectURI(redirectURI string) bool {
+	u, err := url.Parse(redirectURI)
+	if err != nil || !u.IsAbs() || u.Fragment != "" {
+		return false
+	}
+
+	scheme := strings.ToLower(u.Scheme)
+	switch {
+	case deniedOAuth2RedirectSchemes[scheme]:
+		return false
+	case scheme == "https":
+		return u.Host != ""
+	case scheme == "http":
+		if u.Hostname() == "localhost" {
+			return true
+		}
+		ip := net.ParseIP(u.Hostname())
+		return ip != nil && ip.IsLoopback()
+	default:
+		return strings.Contains(scheme, ".")
+	}
+}
+
+func (c *OAuth2Client) getRedirectURI(redirectURI string) (string, error) {
+	if redirectURI == "" && len(c.RedirectURIs) == 1 {
+		return c.RedirectURIs[0], nil
+	}
+
+	for _, uri := range c.RedirectURIs {
+		if uri == redirectURI {
+			return uri, nil
+		}
+	}
+
+	return "", ErrOAuth2InvalidRedirectURI{RedirectURI: redirectURI}
+}
+
+// Create registers a new oauth2 client
+// @Summary Register a new oauth2 client
+// @Description Registers a new third-party application which can then ask users to act on their behalf. The client secret of confidential clients is only returned once in the response, store it somewhere safe.
+// @tags oauth2
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param client body models.OAuth2Client true "The new oauth2 client"
+// @Success 201 {object} models.OAuth2Client "The created oauth2 client with the client secret."
+// @Failure 400 {object} web.HTTPError "Invalid oauth2 client object provided."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /oauth2/clients [put]
+func (c *OAuth2Client) Create(s *xorm.Session, a web.Auth) (err error) {
+	if len(c.RedirectURIs) == 0 {
+		return ErrOAuth2InvalidRedirectURI{}
+	}
+	for _, uri := range c.RedirectURIs {
+		if !isValidOAuth2RedirectURI(uri) {
+			return ErrOAuth2InvalidRedirectURI{RedirectURI: uri}
+		}
+	}
+
+	c.ID = 0
+	c.ClientID = utils.MakeRandomString(32)
+	c.ClientSecret = ""
+	c.ClientSecretHash = ""
+	c.OwnerID = a.GetID()
+
+	if c.Confidential {
+		b := make([]byte, 32)
+		if _, err = rand.Read(b); err != nil {
+			return err
+		}
+		c.ClientSecret = hex.EncodeToString(b)
+		c.ClientSecretHash = utils.Sha256(c.ClientSecret)
+	}
+
+	_, err = s.Insert(c)
+	return
+}
+
+// ReadAll returns all oauth2 clients of the current user
+// @Summary Get all oauth2 clients of the current user
+// @Description Returns all oauth2 clients the current user has registered. The client secrets are never returned.
+// @tags oauth2
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param page query int false "The page number. Used for pagination. If not provided, the first page of results is returned."
+// @Param per_page query int false "The maximum number of items per page. Note this parameter is limited by the configured maximum of items per page."
+// @Param s query string false "Search oauth2 clients by their name."
+// @Success 200 {array} models.OAuth2Client "The oauth2 clients"
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /oauth2/clients [get]
+func (c *OAuth2Client) ReadAll(s *xorm.Session, a web.Auth, search string, page int, perPage int) (result interface{}, resultCount int, totalItems int64, err error) {
+	if _, is := a.(*LinkSharing); is {
+		return nil, 0, 0, ErrGenericForbidden{}
+	}
+
+	cond := builder.And(
+		builder.Eq{"owner_id": a.GetID()},
+		db.ILIKE("name", search),
+	)
+
+	limit, start := getLimitFromPageIndex(page, perPage)
+
+	clients := []*OAuth2Client{}
+	query := s.Where(cond).OrderBy("id asc")
+	if limit > 0 {
+		query = query.Limit(limit, start)
+	}
+	err = query.Find(&clients)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	totalItems, err = s.Where(cond).Count(&OAuth2Client{})
+	return clients, len(clients), totalItems, err
+}
+
+// Delete removes an oauth2 client
+// @Summary Delete an oauth2 client
+// @Description Deletes an oauth2 client and revokes all access users have given it.
+// @tags oauth2
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param client path int true "Client ID"
+// @Success 200 {object} models.Message "The oauth2 client was successfully deleted."
+// @Failure 404 {object} web.HTTPError "The oauth2 client does not exist."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /oauth2/clients/{client} [delete]
+func (c *OAuth2Client) Delete(s *xorm.Session, a web.Auth) (err error) {
+	client, err := getOAuth2ClientByID(s, c.ID)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.Where("client_id = ?", client.ClientID).Delete(&OAuth2Grant{})
+	if err != nil {
+		return err
+	}
+
+	_, err = s.Where("id = ?", client.ID).Delete(&OAuth2Client{})
+	return
+}
@@ -0,0 +1,196 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"testing"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/user"
+	"code.vikunja.io/api/pkg/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOAuth2Client_Create(t *testing.T) {
+	u := &user.User{ID: 1}
+
+	t.Run("public", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		client := &OAuth2Client{
+			Name:         "Mobile app",
+			RedirectURIs: []string{"https://app.example.com/callback", "http://127.0.0.1:1234/callback", "com.example.app:/callback"},
+		}
+		err := client.Create(s, u)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, client.ClientID)
+		assert.Empty(t, client.ClientSecret)
+		err = s.Commit()
+		assert.NoError(t, err)
+		db.AssertExists(t, "oauth2_clients", map[string]interface{}{
+			"id":        client.ID,
+			"client_id": client.ClientID,
+			"owner_id":  1,
+		}, false)
+	})
+	t.Run("confidential", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		client := &OAuth2Client{
+			Name:         "Server",
+			RedirectURIs: []string{"https://server.example.com/callback"},
+			Confidential: true,
+		}
+		err := client.Create(s, u)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, client.ClientSecret)
+		assert.Equal(t, utils.Sha256(client.ClientSecret), client.ClientSecretHash)
+	})
+	t.Run("without redirect uri", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		err := (&OAuth2Client{Name: "Server"}).Create(s, u)
+		assert.Error(t, err)
+		assert.True(t, IsErrOAuth2InvalidRedirectURI(err))
+	})
+	t.Run("invalid redirect uris", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		for _, uri := range []string{
+			"/callback",
+			"http://app.example.com/callback",
+			"https://app.example.com/callback#fragment",
+			"javascript:alert(document.cookie)",
+			"JavaScript://example.com/%0Aalert(1)",
+			"data:text/html,<script>alert(1)</script>",
+			"vbscript:msgbox(1)",
+			"file:///etc/passwd",
+			"myapp:/callback",
+			"https:/callback",
+		} {
+			err := (&OAuth2Client{Name: "Server", RedirectURIs: []string{uri}}).Create(s, u)
+			assert.Error(t, err, uri)
+			assert.True(t, IsErrOAuth2InvalidRedirectURI(err), uri)
+		}
+	})
+}
+
+func TestOAuth2Client_ReadAll(t *testing.T) {
+	db.LoadAndAssertFixtures(t)
+	s := db.NewSession()
+	defer s.Close()
+
+	clients, count, total, err := (&OAuth2Client{}).ReadAll(s, &user.User{ID: 1}, "", 1, 50)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+	assert.Equal(t, int64(2), total)
+	assert.Equal(t, "public-client", clients.([]*OAuth2Client)[0].ClientID)
+	assert.Equal(t, "", clients.([]*OAuth2Client)[1].ClientSecret)
+}
+
+func TestOAuth2Client_Delete(t *testing.T) {
+	t.Run("normal", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		client := &OAuth2Client{ID: 1}
+		can, err := client.CanDelete(s, &user.User{ID: 1})
+		assert.NoError(t, err)
+		assert.True(t, can)
+		err = client.Delete(s, &user.User{ID: 1})
+		assert.NoError(t, err)
+		err = s.Commit()
+		assert.NoError(t, err)
+		db.AssertMissing(t, "oauth2_clients", map[string]interface{}{"id": 1})
+		db.AssertMissing(t, "oauth2_grants", map[string]interface{}{"client_id": "public-client"})
+	})
+	t.Run("other user", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		can, err := (&OAuth2Client{ID: 3}).CanDelete(s, &user.User{ID: 1})
+		assert.NoError(t, err)
+		assert.False(t, can)
+	})
+	t.Run("nonexisting", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := (&OAuth2Client{ID: 9999}).CanDelete(s, &user.User{ID: 1})
+		assert.Error(t, err)
+		assert.True(t, IsErrOAuth2ClientDoesNotExist(err))
+	})
+}
+
+func TestAuthenticateOAuth2Client(t *testing.T) {
+	t.Run("public", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		client, err := AuthenticateOAuth2Client(s, "public-client", "")
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), client.ID)
+	})
+	t.Run("confidential", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		client, err := AuthenticateOAuth2Client(s, "confidential-client", "confidential-secret")
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), client.ID)
+	})
+	t.Run("confidential without secret", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := AuthenticateOAuth2Client(s, "confidential-client", "")
+		assert.Error(t, err)
+		assert.True(t, IsErrOAuth2InvalidClientCredentials(err))
+	})
+	t.Run("wrong secret", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := AuthenticateOAuth2Client(s, "confidential-client", "wrong")
+		assert.Error(t, err)
+		assert.True(t, IsErrOAuth2InvalidClientCredentials(err))
+	})
+	t.Run("nonexisting", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := AuthenticateOAuth2Client(s, "nonexisting", "")
+		assert.Error(t, err)
+		assert.True(t, IsErrOAuth2InvalidClientCredentials(err))
+	})
+}
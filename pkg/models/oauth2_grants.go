@@ -0,0 +1,423 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"net/url"
+	"strings"
+	"time"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/modules/keyvalue"
+	"code.vikunja.io/api/pkg/user"
+	"code.vikunja.io/api/pkg/utils"
+	"code.vikunja.io/web"
+	"xorm.io/xorm"
+)
+
+// OAuth2Grant is the access a user has given an oauth2 client. Every grant has a refresh token which the client
+// uses to get new short-lived access tokens. Deleting a grant revokes all tokens issued for it.
+type OAuth2Grant struct {
+	// The unique id of this grant.
+	ID       string `xorm:"varchar(50) not null unique pk" json:"id" param:"grant"`
+	ClientID string `xorm:"varchar(50) not null INDEX" json:"-"`
+	UserID   int64  `xorm:"bigint not null INDEX" json:"-"`
+	// The application this grant was given to.
+	Client *OAuth2Client `xorm:"-" json:"client"`
+	// The scopes the user agreed to.
+	Scopes APITokenScopes `xorm:"JSON not null" json:"scopes"`
+	// Only a hash of the refresh token is stored.
+	RefreshTokenHash string `xorm:"varchar(45) not null unique" json:"-"`
+	// The last time the application refreshed its access token.
+	LastUsed time.Time `xorm:"DATETIME not null" json:"last_used"`
+
+	// A timestamp when this grant was created.
+	Created time.Time `xorm:"created not null" json:"created"`
+
+	web.CRUDable `xorm:"-" json:"-"`
+	web.Rights   `xorm:"-" json:"-"`
+}
+
+// TableName returns the table name for oauth2 grants
+func (*OAuth2Grant) TableName() string {
+	return "oauth2_grants"
+}
+
+func init() {
+	// Grants are revoked in all cases where a user is logged out everywhere, like after a password change.
+	user.RegisterSessionRevocationHook(deleteAllOAuth2GrantsForUser)
+}
+
+func deleteAllOAuth2GrantsForUser(s *xorm.Session, userID int64) (err error) {
+	_, err = s.
+		Where("user_id = ?", userID).
+		Delete(&OAuth2Grant{})
+	return
+}
+
+// Authorization codes can only be exchanged for tokens for this long.
+const oauth2AuthorizationCodeTimeout = 5 * time.Minute
+
+// OAuth2AuthorizationRequest holds the parameters a client sends when it asks a user for authorization.
+// Only the authorization code flow with a S256 pkce challenge is supported.
+type OAuth2AuthorizationRequest struct {
+	// Must be `code`.
+	ResponseType string `query:"response_type" json:"response_type"`
+	// The public identifier of the client.
+	ClientID string `query:"client_id" json:"client_id"`
+	// One of the redirect uris registered for the client. Can be omitted if the client only has one.
+	RedirectURI string `query:"redirect_uri" json:"redirect_uri"`
+	// All requested scopes, separated by spaces.
+	Scope string `query:"scope" json:"scope"`
+	// An opaque value which is passed back to the client.
+	State string `query:"state" json:"state"`
+	// The pkce code challenge.
+	CodeChallenge string `query:"code_challenge" json:"code_challenge"`
+	// Must be `S256`.
+	CodeChallengeMethod string `query:"code_challenge_method" json:"code_challenge_method"`
+}
+
+// OAuth2Authorization holds everything a user needs to know to decide whether to authorize a client.
+type OAuth2Authorization struct {
+	// The application asking for access.
+	Client *OAuth2Client `json:"client"`
+	// The scopes the application asks for.
+	Scopes APITokenScopes `json:"scopes"`
+	// Where the user will be redirected to after authorizing the application.
+	RedirectURI string `json:"redirect_uri"`
+}
+
+// OAuth2AuthorizationResponse holds the url the user needs to be redirected to after they authorized a client.
+type OAuth2AuthorizationResponse struct {
+	// The redirect uri of the client with the authorization code and state.
+	RedirectURI string `json:"redirect_uri"`
+}
+
+// oauth2AuthorizationCode is what we remember between authorizing a client and exchanging the code.
+type oauth2AuthorizationCode struct {
+	ClientID      string
+	UserID        int64
+	RedirectURI   string
+	Scopes        APITokenScopes
+	CodeChallenge string
+	Expires       time.Time
+}
+
+func getOAuth2AuthorizationCodeKey(code string) string {
+	return "oauth2_code_" + utils.Sha256(code)
+}
+
+func newOAuth2Secret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func getOAuth2GrantTTL() time.Duration {
+	return time.Duration(config.ServiceJWTTTL.GetInt64()) * time.Second
+}
+
+func parseOAuth2Scopes(scope string) (scopes APITokenScopes, err error) {
+	scopes = strings.Fields(scope)
+	if len(scopes) == 0 {
+		return nil, ErrOAuth2InvalidScope{}
+	}
+	for _, s := range scopes {
+		if _, _, valid := parseAPITokenScope(s); !valid {
+			return nil, ErrOAuth2InvalidScope{Scope: s}
+		}
+	}
+	return
+}
+
+func (r *OAuth2AuthorizationRequest) validate(s *xorm.Session) (client *OAuth2Client, redirectURI string, scopes APITokenScopes, err error) {
+	client, err = GetOAuth2ClientByClientID(s, r.ClientID)
+	if err != nil {
+		return
+	}
+
+	redirectURI, err = client.getRedirectURI(r.RedirectURI)
+	if err != nil {
+		return
+	}
+
+	if r.ResponseType != "code" {
+		err = ErrOAuth2InvalidRequest{Reason: "The response type must be 'code'."}
+		return
+	}
+
+	if r.CodeChallengeMethod != "S256" || len(r.CodeChallenge) < 43 || len(r.CodeChallenge) > 128 {
+		err = ErrOAuth2InvalidRequest{Reason: "A pkce code challenge with the method 'S256' is required."}
+		return
+	}
+
+	scopes, err = parseOAuth2Scopes(r.Scope)
+	return
+}
+
+// GetOAuth2Authorization validates an authorization request and returns what a user needs to know to authorize it.
+func GetOAuth2Authorization(s *xorm.Session, r *OAuth2AuthorizationRequest) (authorization *OAuth2Authorization, err error) {
+	client, redirectURI, scopes, err := r.validate(s)
+	if err != nil {
+		return nil, err
+	}
+
+	return &OAuth2Authorization{
+		Client:      client,
+		Scopes:      scopes,
+		RedirectURI: redirectURI,
+	}, nil
+}
+
+// AuthorizeOAuth2Client records the consent of a user and returns the redirect uri with an authorization code
+// the client can exchange for tokens.
+func AuthorizeOAuth2Client(s *xorm.Session, a web.Auth, r *OAuth2AuthorizationRequest) (response *OAuth2AuthorizationResponse, err error) {
+	if _, is := a.(*LinkSharing); is {
+		return nil, ErrGenericForbidden{}
+	}
+
+	client, redirectURI, scopes, err := r.validate(s)
+	if err != nil {
+		return nil, err
+	}
+
+	code, err := newOAuth2Secret()
+	if err != nil {
+		return nil, err
+	}
+
+	err = keyvalue.Put(getOAuth2AuthorizationCodeKey(code), &oauth2AuthorizationCode{
+		ClientID:      client.ClientID,
+		UserID:        a.GetID(),
+		RedirectURI:   r.RedirectURI,
+		Scopes:        scopes,
+		CodeChallenge: r.CodeChallenge,
+		Expires:       time.Now().Add(oauth2AuthorizationCodeTimeout),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	u, err := url.Parse(redirectURI)
+	if err != nil {
+		return nil, err
+	}
+	query := u.Query()
+	query.Set("code", code)
+	if r.State != "" {
+		query.Set("state", r.State)
+	}
+	u.RawQuery = query.Encode()
+
+	return &OAuth2AuthorizationResponse{RedirectURI: u.String()}, nil
+}
+
+func checkPKCECodeVerifier(challenge, verifier string) bool {
+	if len(verifier) < 43 || len(verifier) > 128 {
+		return false
+	}
+	hash := sha256.Sum256([]byte(verifier))
+	expected := base64.RawURLEncoding.EncodeToString(hash[:])
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(challenge)) == 1
+}
+
+// ExchangeOAuth2AuthorizationCode creates a new grant from an authorization code and returns it together with
+// its refresh token. Every code can only be used once.
+func ExchangeOAuth2AuthorizationCode(s *xorm.Session, client *OAuth2Client, code, redirectURI, codeVerifier string) (grant *OAuth2Grant, refreshToken string, err error) {
+	if code == "" {
+		return nil, "", ErrOAuth2InvalidRequest{Reason: "The authorization code is missing."}
+	}
+
+	key := getOAuth2AuthorizationCodeKey(code)
+	authorizationCode := &oauth2AuthorizationCode{}
+	exists, err := keyvalue.GetWithValue(key, authorizationCode)
+	if err != nil {
+		return nil, "", err
+	}
+	if !exists {
+		return nil, "", ErrOAuth2InvalidGrant{}
+	}
+
+	// Codes can only be used once, even if the exchange fails
+	err = keyvalue.Del(key)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if authorizationCode.ClientID != client.ClientID ||
+		authorizationCode.RedirectURI != redirectURI ||
+		authorizationCode.Expires.Before(time.Now()) ||
+		!checkPKCECodeVerifier(authorizationCode.CodeChallenge, codeVerifier) {
+		return nil, "", ErrOAuth2InvalidGrant{}
+	}
+
+	refreshToken, err = newOAuth2Secret()
+	if err != nil {
+		return nil, "", err
+	}
+
+	grant = &OAuth2Grant{
+		ID:               utils.MakeRandomString(40),
+		ClientID:         client.ClientID,
+		UserID:           authorizationCode.UserID,
+		Scopes:           authorizationCode.Scopes,
+		RefreshTokenHash: utils.Sha256(refreshToken),
+		LastUsed:         time.Now(),
+	}
+	_, err = s.Insert(grant)
+	return
+}
+
+// GetOAuth2GrantByRefreshToken returns the grant of a refresh token if it was issued to the client and did not expire.
+func GetOAuth2GrantByRefreshToken(s *xorm.Session, client *OAuth2Client, refreshToken string) (grant *OAuth2Grant, err error) {
+	grant = &OAuth2Grant{}
+	exists, err := s.
+		Where("refresh_token_hash = ? AND client_id = ?", utils.Sha256(refreshToken), client.ClientID).
+		Get(grant)
+	if err != nil {
+		return nil, err
+	}
+	if !exists || grant.LastUsed.Add(getOAuth2GrantTTL()).Before(time.Now()) {
+		return nil, ErrOAuth2InvalidGrant{}
+	}
+	return
+}
+
+// RefreshOAuth2Grant exchanges the refresh token of a grant for a new one. Every refresh token can only be used once.
+// Grants which were not used for longer than the session lifetime can't be refreshed anymore.
+func RefreshOAuth2Grant(s *xorm.Session, client *OAuth2Client, refreshToken string) (grant *OAuth2Grant, newToken string, err error) {
+	if refreshToken == "" {
+		return nil, "", ErrOAuth2InvalidRequest{Reason: "The refresh token is missing."}
+	}
+
+	grant, err = GetOAuth2GrantByRefreshToken(s, client, refreshToken)
+	if err != nil {
+		return nil, "", err
+	}
+
+	newToken, err = newOAuth2Secret()
+	if err != nil {
+		return nil, "", err
+	}
+
+	grant.RefreshTokenHash = utils.Sha256(newToken)
+	grant.LastUsed = time.Now()
+	_, err = s.
+		Where("id = ?", grant.ID).
+		Cols("refresh_token_hash", "last_used").
+		Update(grant)
+	return
+}
+
+func getOAuth2GrantByID(s *xorm.Session, id string) (grant *OAuth2Grant, err error) {
+	grant = &OAuth2Grant{}
+	exists, err := s.Where("id = ?", id).Get(grant)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrOAuth2GrantDoesNotExist{ID: id}
+	}
+	return
+}
+
+// CheckOAuth2Grant makes sure a grant an access token was issued for still exists and returns it.
+// It does not check the user, callers need to make sure the user is still active.
+func CheckOAuth2Grant(s *xorm.Session, grantID string, userID int64) (grant *OAuth2Grant, err error) {
+	grant = &OAuth2Grant{}
+	exists, err := s.Where("id = ? AND user_id = ?", grantID, userID).Get(grant)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrOAuth2GrantDoesNotExist{ID: grantID}
+	}
+	return
+}
+
+// ReadAll returns all applications the current user has authorized
+// @Summary Get all authorized apps
+// @Description Returns all oauth2 clients the current user has given access to their account.
+// @tags oauth2
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Success 200 {array} models.OAuth2Grant "The grants"
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /oauth2/grants [get]
+func (g *OAuth2Grant) ReadAll(s *xorm.Session, a web.Auth, search string, page int, perPage int) (result interface{}, resultCount int, totalItems int64, err error) {
+	if _, is := a.(*LinkSharing); is {
+		return nil, 0, 0, ErrGenericForbidden{}
+	}
+
+	grants := []*OAuth2Grant{}
+	err = s.
+		Where("user_id = ?", a.GetID()).
+		OrderBy("last_used desc").
+		Find(&grants)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	clientIDs := make([]string, 0, len(grants))
+	for _, grant := range grants {
+		clientIDs = append(clientIDs, grant.ClientID)
+	}
+
+	clients := []*OAuth2Client{}
+	if len(clientIDs) > 0 {
+		err = s.In("client_id", clientIDs).Find(&clients)
+		if err != nil {
+			return nil, 0, 0, err
+		}
+	}
+
+	for _, grant := range grants {
+		for _, client := range clients {
+			if client.ClientID == grant.ClientID {
+				client.RedirectURIs = nil
+				grant.Client = client
+			}
+		}
+	}
+
+	return grants, len(grants), int64(len(grants)), nil
+}
+
+// Delete revokes the access a user has given an application
+// @Summary Revoke an authorized app
+// @Description Revokes the access of an oauth2 client. All access and refresh tokens it has for this grant stop working.
+// @tags oauth2
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param grant path string true "Grant ID"
+// @Success 200 {object} models.Message "The grant was successfully revoked."
+// @Failure 404 {object} web.HTTPError "The grant does not exist."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /oauth2/grants/{grant} [delete]
+func (g *OAuth2Grant) Delete(s *xorm.Session, a web.Auth) (err error) {
+	_, err = s.Where("id = ? AND user_id = ?", g.ID, a.GetID()).Delete(&OAuth2Grant{})
+	return
+}
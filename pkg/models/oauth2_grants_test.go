@@ -0,0 +1,283 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"net/url"
+	"testing"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/user"
+	"github.com/stretchr/testify/assert"
+)
+
+// The example from RFC 7636
+const (
+	testPKCECodeVerifier  = "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	testPKCECodeChallenge = "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"
+)
+
+func newTestOAuth2AuthorizationRequest() *OAuth2AuthorizationRequest {
+	return &OAuth2AuthorizationRequest{
+		ResponseType:        "code",
+		ClientID:            "public-client",
+		RedirectURI:         "https://bot.example.com/callback",
+		Scope:               "tasks:read lists:write",
+		State:               "xyz",
+		CodeChallenge:       testPKCECodeChallenge,
+		CodeChallengeMethod: "S256",
+	}
+}
+
+func TestGetOAuth2Authorization(t *testing.T) {
+	t.Run("normal", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		authorization, err := GetOAuth2Authorization(s, newTestOAuth2AuthorizationRequest())
+		assert.NoError(t, err)
+		assert.Equal(t, "Slack bot", authorization.Client.Name)
+		assert.Equal(t, APITokenScopes{"tasks:read", "lists:write"}, authorization.Scopes)
+		assert.Equal(t, "https://bot.example.com/callback", authorization.RedirectURI)
+	})
+	t.Run("default redirect uri", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		r := newTestOAuth2AuthorizationRequest()
+		r.ClientID = "confidential-client"
+		r.RedirectURI = ""
+		authorization, err := GetOAuth2Authorization(s, r)
+		assert.NoError(t, err)
+		assert.Equal(t, "https://reports.example.com/callback", authorization.RedirectURI)
+	})
+	t.Run("unregistered redirect uri", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		r := newTestOAuth2AuthorizationRequest()
+		r.RedirectURI = "https://evil.example.com/callback"
+		_, err := GetOAuth2Authorization(s, r)
+		assert.Error(t, err)
+		assert.True(t, IsErrOAuth2InvalidRedirectURI(err))
+	})
+	t.Run("nonexisting client", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		r := newTestOAuth2AuthorizationRequest()
+		r.ClientID = "nonexisting"
+		_, err := GetOAuth2Authorization(s, r)
+		assert.Error(t, err)
+		assert.True(t, IsErrOAuth2ClientDoesNotExist(err))
+	})
+	t.Run("without pkce", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		r := newTestOAuth2AuthorizationRequest()
+		r.CodeChallengeMethod = "plain"
+		_, err := GetOAuth2Authorization(s, r)
+		assert.Error(t, err)
+		assert.True(t, IsErrOAuth2InvalidRequest(err))
+	})
+	t.Run("invalid scope", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		r := newTestOAuth2AuthorizationRequest()
+		r.Scope = "tasks:read everything"
+		_, err := GetOAuth2Authorization(s, r)
+		assert.Error(t, err)
+		assert.True(t, IsErrOAuth2InvalidScope(err))
+	})
+}
+
+func TestExchangeOAuth2AuthorizationCode(t *testing.T) {
+	client := &OAuth2Client{ClientID: "public-client"}
+
+	authorize := func(t *testing.T) string {
+		s := db.NewSession()
+		defer s.Close()
+
+		response, err := AuthorizeOAuth2Client(s, &user.User{ID: 1}, newTestOAuth2AuthorizationRequest())
+		assert.NoError(t, err)
+		u, err := url.Parse(response.RedirectURI)
+		assert.NoError(t, err)
+		assert.Equal(t, "xyz", u.Query().Get("state"))
+		return u.Query().Get("code")
+	}
+
+	t.Run("normal", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		code := authorize(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		grant, refreshToken, err := ExchangeOAuth2AuthorizationCode(s, client, code, "https://bot.example.com/callback", testPKCECodeVerifier)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, refreshToken)
+		assert.Equal(t, int64(1), grant.UserID)
+		assert.Equal(t, APITokenScopes{"tasks:read", "lists:write"}, grant.Scopes)
+		err = s.Commit()
+		assert.NoError(t, err)
+		db.AssertExists(t, "oauth2_grants", map[string]interface{}{
+			"id":        grant.ID,
+			"client_id": "public-client",
+			"user_id":   1,
+		}, false)
+
+		// Codes can only be used once
+		_, _, err = ExchangeOAuth2AuthorizationCode(s, client, code, "https://bot.example.com/callback", testPKCECodeVerifier)
+		assert.Error(t, err)
+		assert.True(t, IsErrOAuth2InvalidGrant(err))
+	})
+	t.Run("wrong code verifier", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		code := authorize(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, _, err := ExchangeOAuth2AuthorizationCode(s, client, code, "https://bot.example.com/callback", "wrongwrongwrongwrongwrongwrongwrongwrongwrong")
+		assert.Error(t, err)
+		assert.True(t, IsErrOAuth2InvalidGrant(err))
+	})
+	t.Run("other redirect uri", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		code := authorize(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, _, err := ExchangeOAuth2AuthorizationCode(s, client, code, "http://localhost:8080/callback", testPKCECodeVerifier)
+		assert.Error(t, err)
+		assert.True(t, IsErrOAuth2InvalidGrant(err))
+	})
+	t.Run("other client", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		code := authorize(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, _, err := ExchangeOAuth2AuthorizationCode(s, &OAuth2Client{ClientID: "other-client"}, code, "https://bot.example.com/callback", testPKCECodeVerifier)
+		assert.Error(t, err)
+		assert.True(t, IsErrOAuth2InvalidGrant(err))
+	})
+	t.Run("nonexisting code", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, _, err := ExchangeOAuth2AuthorizationCode(s, client, "nonexisting", "https://bot.example.com/callback", testPKCECodeVerifier)
+		assert.Error(t, err)
+		assert.True(t, IsErrOAuth2InvalidGrant(err))
+	})
+}
+
+func TestRefreshOAuth2Grant(t *testing.T) {
+	t.Run("normal", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		grant, newToken, err := RefreshOAuth2Grant(s, &OAuth2Client{ClientID: "public-client"}, "refresh-token-1")
+		assert.NoError(t, err)
+		assert.Equal(t, "f1e2d3c4b5a6f7e8d9c0b1a2f3e4d5c6b7a8f9e0", grant.ID)
+		assert.NotEqual(t, "refresh-token-1", newToken)
+
+		// Refresh tokens can only be used once
+		_, _, err = RefreshOAuth2Grant(s, &OAuth2Client{ClientID: "public-client"}, "refresh-token-1")
+		assert.Error(t, err)
+		assert.True(t, IsErrOAuth2InvalidGrant(err))
+	})
+	t.Run("expired", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, _, err := RefreshOAuth2Grant(s, &OAuth2Client{ClientID: "confidential-client"}, "refresh-token-2")
+		assert.Error(t, err)
+		assert.True(t, IsErrOAuth2InvalidGrant(err))
+	})
+	t.Run("other client", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, _, err := RefreshOAuth2Grant(s, &OAuth2Client{ClientID: "confidential-client"}, "refresh-token-1")
+		assert.Error(t, err)
+		assert.True(t, IsErrOAuth2InvalidGrant(err))
+	})
+}
+
+func TestOAuth2Grant_ReadAll(t *testing.T) {
+	db.LoadAndAssertFixtures(t)
+	s := db.NewSession()
+	defer s.Close()
+
+	grants, count, _, err := (&OAuth2Grant{}).ReadAll(s, &user.User{ID: 1}, "", 1, 50)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+	assert.Equal(t, "Slack bot", grants.([]*OAuth2Grant)[0].Client.Name)
+	assert.Equal(t, "Reporting dashboard", grants.([]*OAuth2Grant)[1].Client.Name)
+}
+
+func TestOAuth2Grant_Delete(t *testing.T) {
+	t.Run("normal", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		grant := &OAuth2Grant{ID: "f1e2d3c4b5a6f7e8d9c0b1a2f3e4d5c6b7a8f9e0"}
+		can, err := grant.CanDelete(s, &user.User{ID: 1})
+		assert.NoError(t, err)
+		assert.True(t, can)
+		err = grant.Delete(s, &user.User{ID: 1})
+		assert.NoError(t, err)
+		err = s.Commit()
+		assert.NoError(t, err)
+		db.AssertMissing(t, "oauth2_grants", map[string]interface{}{"id": grant.ID})
+	})
+	t.Run("other user", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		can, err := (&OAuth2Grant{ID: "d9c8b7a6f5e4d3c2b1a0f9e8d7c6b5a4f3e2d1c0"}).CanDelete(s, &user.User{ID: 1})
+		assert.NoError(t, err)
+		assert.False(t, can)
+	})
+}
+
+func TestOAuth2Grant_RevokedWithSessions(t *testing.T) {
+	db.LoadAndAssertFixtures(t)
+	s := db.NewSession()
+	defer s.Close()
+
+	// Called after password changes and resets, totp changes and when a user is disabled
+	err := user.DeleteAllSessionsForUser(s, 1)
+	assert.NoError(t, err)
+	err = s.Commit()
+	assert.NoError(t, err)
+	db.AssertMissing(t, "oauth2_grants", map[string]interface{}{"user_id": 1})
+	db.AssertExists(t, "oauth2_grants", map[string]interface{}{"id": "d9c8b7a6f5e4d3c2b1a0f9e8d7c6b5a4f3e2d1c0"}, false)
+}
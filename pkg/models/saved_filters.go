@@ -118,11 +118,24 @@ func (sf *SavedFilter) toList() *List {
 // @Failure 500 {object} models.Message "Internal error"
 // @Router /filters [put]
 func (sf *SavedFilter) Create(s *xorm.Session, auth web.Auth) error {
+	if err := sf.validateFilterQuery(); err != nil {
+		return err
+	}
+
 	sf.OwnerID = auth.GetID()
 	_, err := s.Insert(sf)
 	return err
 }
 
+// validateFilterQuery makes sure a saved filter never contains a filter query which can't be parsed.
+func (sf *SavedFilter) validateFilterQuery() error {
+	if sf.Filters == nil || sf.Filters.Filter == "" {
+		return nil
+	}
+	_, err := parseTaskFilterQuery(sf.Filters.Filter)
+	return err
+}
+
 func getSavedFilterSimpleByID(s *xorm.Session, id int64) (sf *SavedFilter, err error) {
 	sf = &SavedFilter{}
 	exists, err := s.
@@ -179,6 +192,10 @@ func (sf *SavedFilter) Update(s *xorm.Session, a web.Auth) error {
 		sf.Filters = origFilter.Filters
 	}
 
+	if err := sf.validateFilterQuery(); err != nil {
+		return err
+	}
+
 	_, err = s.
 		Where("id = ?", sf.ID).
 		Cols(
@@ -46,6 +46,9 @@ func (TaskAssginee) TableName() string {
 	return "task_assignees"
 }
 
+// Used to sort tasks by the alphabetically first username of all their assignees
+const taskAssigneesSortSubquery = "(SELECT MIN(users.username) FROM task_assignees INNER JOIN users ON users.id = task_assignees.user_id WHERE task_assignees.task_id = tasks.id)"
+
 // TaskAssigneeWithUser is a helper type to deal with user joins
 type TaskAssigneeWithUser struct {
 	TaskID    int64
@@ -80,8 +83,16 @@ func (t *Task) updateTaskAssignees(s *xorm.Session, assignees []*user.User, doer
 	if len(assignees) == 0 && len(t.Assignees) > 0 {
 		_, err = s.Where("task_id = ?", t.ID).
 			Delete(TaskAssginee{})
+		if err != nil {
+			return err
+		}
+		for _, a := range t.Assignees {
+			if err := recordTaskChange(s, doer, t.ID, taskChangeFieldAssignees, a.ID, nil); err != nil {
+				return err
+			}
+		}
 		t.setTaskAssignees(assignees)
-		return err
+		return nil
 	}
 
 	// If we didn't change anything (from 0 to zero) don't do anything.
@@ -121,6 +132,11 @@ func (t *Task) updateTaskAssignees(s *xorm.Session, assignees []*user.User, doer
 		if err != nil {
 			return err
 		}
+		for _, id := range assigneesToDelete {
+			if err := recordTaskChange(s, doer, t.ID, taskChangeFieldAssignees, id, nil); err != nil {
+				return err
+			}
+		}
 	}
 
 	// Get the list to perform later checks
@@ -178,6 +194,11 @@ func (la *TaskAssginee) Delete(s *xorm.Session, a web.Auth) (err error) {
 		return err
 	}
 
+	err = recordTaskChange(s, a, la.TaskID, taskChangeFieldAssignees, la.UserID, nil)
+	if err != nil {
+		return err
+	}
+
 	err = updateListByTaskID(s, la.TaskID)
 	return
 }
@@ -229,6 +250,11 @@ func (t *Task) addNewAssigneeByID(s *xorm.Session, newAssigneeID int64, list *Li
 		return err
 	}
 
+	err = recordTaskChange(s, auth, t.ID, taskChangeFieldAssignees, nil, newAssigneeID)
+	if err != nil {
+		return err
+	}
+
 	doer, _ := user.GetFromAuth(auth)
 	err = events.Dispatch(&TaskAssigneeCreatedEvent{
 		Task:     t,
@@ -47,6 +47,8 @@ type TaskCollection struct {
 	FilterConcat string `query:"filter_concat" json:"filter_concat"`
 	// If set to true, the result will also include null values
 	FilterIncludeNulls bool `query:"filter_include_nulls" json:"filter_include_nulls"`
+	// A filter query like `(priority >= 3 && assignees in me) || labels in urgent`. It is combined with all other filters using "and".
+	Filter string `query:"filter" json:"filter"`
 
 	web.CRUDable `xorm:"-" json:"-"`
 	web.Rights   `xorm:"-" json:"-"`
@@ -74,7 +76,14 @@ func validateTaskField(fieldName string) error {
 		taskPropertyUpdated,
 		taskPropertyPosition,
 		taskPropertyKanbanPosition,
-		taskPropertyBucketID:
+		taskPropertyBucketID,
+		taskPropertyTimeTracked,
+		taskPropertyLastCommented,
+		taskPropertyAssignees,
+		taskPropertyLabels:
+		return nil
+	}
+	if _, is := getCustomFieldIDFromTaskProperty(fieldName); is {
 		return nil
 	}
 	return ErrInvalidTaskField{TaskField: fieldName}
@@ -114,6 +123,13 @@ func getTaskFilterOptsFromCollection(tf *TaskCollection) (opts *taskOptions, err
 		filterIncludeNulls: tf.FilterIncludeNulls,
 	}
 
+	if tf.Filter != "" {
+		opts.filterExpression, err = parseTaskFilterQuery(tf.Filter)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	opts.filters, err = getTaskFiltersByCollections(tf)
 	return opts, err
 }
@@ -128,13 +144,14 @@ func getTaskFilterOptsFromCollection(tf *TaskCollection) (opts *taskOptions, err
 // @Param page query int false "The page number. Used for pagination. If not provided, the first page of results is returned."
 // @Param per_page query int false "The maximum number of items per page. Note this parameter is limited by the configured maximum of items per page."
 // @Param s query string false "Search tasks by task text."
-// @Param sort_by query string false "The sorting parameter. You can pass this multiple times to get the tasks ordered by multiple different parametes, along with `order_by`. Possible values to sort by are `id`, `title`, `description`, `done`, `done_at`, `due_date`, `created_by_id`, `list_id`, `repeat_after`, `priority`, `start_date`, `end_date`, `hex_color`, `percent_done`, `uid`, `created`, `updated`. Default is `id`."
+// @Param sort_by query string false "The sorting parameter. You can pass this multiple times to get the tasks ordered by multiple different parametes, along with `order_by`. Possible values to sort by are `id`, `title`, `description`, `done`, `done_at`, `due_date`, `created_by_id`, `list_id`, `repeat_after`, `priority`, `start_date`, `end_date`, `hex_color`, `percent_done`, `uid`, `created`, `updated`, `time_tracked`, `last_commented`, `assignees` (the first username), `labels` (the first label title) and `custom_field_<id>` to sort by the value of a custom field. Default is `id`."
 // @Param order_by query string false "The ordering parameter. Possible values to order by are `asc` or `desc`. Default is `asc`."
-// @Param filter_by query string false "The name of the field to filter by. Allowed values are all task properties. Task properties which are their own object require passing in the id of that entity. Accepts an array for multiple filters which will be chanied together, all supplied filter must match."
+// @Param filter_by query string false "The name of the field to filter by. Allowed values are all task properties. Task properties which are their own object require passing in the id of that entity. Additionally, `blocked` (blocked by an undone task), `has_subtasks` and `has_attachments` accept `true` or `false` and `last_commented` accepts a date. Accepts an array for multiple filters which will be chanied together, all supplied filter must match."
 // @Param filter_value query string false "The value to filter for."
 // @Param filter_comparator query string false "The comparator to use for a filter. Available values are `equals`, `greater`, `greater_equals`, `less`, `less_equals`, `like` and `in`. `in` expects comma-separated values in `filter_value`. Defaults to `equals`"
 // @Param filter_concat query string false "The concatinator to use for filters. Available values are `and` or `or`. Defaults to `or`."
 // @Param filter_include_nulls query string false "If set to true the result will include filtered fields whose value is set to `null`. Available values are `true` or `false`. Defaults to `false`."
+// @Param filter query string false "A filter query like `(priority >= 3 && assignees in me) || labels in urgent`. See the filter documentation for the full syntax. Combined with all other filter parameters using `and`."
 // @Security JWTKeyAuth
 // @Success 200 {array} models.Task "The tasks"
 // @Failure 500 {object} models.Message "Internal error"
@@ -25,6 +25,7 @@ import (
 
 	"code.vikunja.io/api/pkg/config"
 	"github.com/iancoleman/strcase"
+	"xorm.io/builder"
 	"xorm.io/xorm/schemas"
 )
 
@@ -159,8 +160,7 @@ func getValueForField(field reflect.StructField, rawValue string) (value interfa
 		value, err = strconv.ParseBool(rawValue)
 	case reflect.Struct:
 		if field.Type == schemas.TimeType {
-			value, err = time.Parse(time.RFC3339, rawValue)
-			value = value.(time.Time).In(config.GetTimeZone())
+			value, err = parseFilterDate(rawValue)
 		}
 	case reflect.Slice:
 		// If this is a slice of pointers we're dealing with some property which is a relation
@@ -173,8 +173,7 @@ func getValueForField(field reflect.StructField, rawValue string) (value interfa
 
 		// There are probably better ways to do this - please let me know if you have one.
 		if field.Type.Elem().String() == "time.Time" {
-			value, err = time.Parse(time.RFC3339, rawValue)
-			value = value.(time.Time).In(config.GetTimeZone())
+			value, err = parseFilterDate(rawValue)
 			return
 		}
 		fallthrough
@@ -185,8 +184,83 @@ func getValueForField(field reflect.StructField, rawValue string) (value interfa
 	return
 }
 
+// parseFilterDate parses a date filter value. Apart from RFC3339 dates it accepts plain dates like 2021-09-26
+// and dates relative to now like "now", "now+3d" or "now-1w".
+func parseFilterDate(rawValue string) (time.Time, error) {
+	if strings.HasPrefix(rawValue, "now") {
+		return parseRelativeFilterDate(rawValue, time.Now())
+	}
+
+	t, err := time.Parse(time.RFC3339, rawValue)
+	if err == nil {
+		return t.In(config.GetTimeZone()), nil
+	}
+
+	return time.ParseInLocation("2006-01-02", rawValue, config.GetTimeZone())
+}
+
+// parseRelativeFilterDate parses a date like "now+3d" relative to now. Available units are s (seconds),
+// m (minutes), h (hours), d (days), w (weeks), M (months) and y (years).
+func parseRelativeFilterDate(rawValue string, now time.Time) (t time.Time, err error) {
+	t = now.In(config.GetTimeZone())
+	offset := strings.TrimPrefix(rawValue, "now")
+	if offset == "" {
+		return t, nil
+	}
+
+	if len(offset) < 3 || (offset[0] != '+' && offset[0] != '-') {
+		return t, fmt.Errorf("invalid relative date %s", rawValue)
+	}
+
+	amount, err := strconv.Atoi(offset[1 : len(offset)-1])
+	if err != nil {
+		return t, fmt.Errorf("invalid relative date %s", rawValue)
+	}
+	if offset[0] == '-' {
+		amount = -amount
+	}
+
+	switch offset[len(offset)-1] {
+	case 's':
+		return t.Add(time.Duration(amount) * time.Second), nil
+	case 'm':
+		return t.Add(time.Duration(amount) * time.Minute), nil
+	case 'h':
+		return t.Add(time.Duration(amount) * time.Hour), nil
+	case 'd':
+		return t.AddDate(0, 0, amount), nil
+	case 'w':
+		return t.AddDate(0, 0, amount*7), nil
+	case 'M':
+		return t.AddDate(0, amount, 0), nil
+	case 'y':
+		return t.AddDate(amount, 0, 0), nil
+	}
+
+	return t, fmt.Errorf("invalid relative date unit in %s", rawValue)
+}
+
 func getNativeValueForTaskField(fieldName string, comparator taskFilterComparator, value string) (nativeValue interface{}, err error) {
 
+	// Custom field values are converted once the type of the field is known
+	if _, is := getCustomFieldIDFromTaskProperty(fieldName); is {
+		if comparator == taskFilterComparatorIn {
+			valueSlice := []interface{}{}
+			for _, val := range strings.Split(value, ",") {
+				valueSlice = append(valueSlice, val)
+			}
+			return valueSlice, nil
+		}
+		return value, nil
+	}
+
+	switch fieldName {
+	case taskPropertyBlocked, taskPropertyHasSubtasks, taskPropertyHasAttachments:
+		return strconv.ParseBool(value)
+	case taskPropertyLastCommented:
+		return parseFilterDate(value)
+	}
+
 	realFieldName := strings.ReplaceAll(strcase.ToCamel(fieldName), "Id", "ID")
 
 	if realFieldName == "Namespace" {
@@ -227,3 +301,62 @@ func getNativeValueForTaskField(fieldName string, comparator taskFilterComparato
 
 	return getValueForField(field, value)
 }
+
+// getTaskBoolRelationSubquery returns a subquery with the ids of all tasks for which a boolean filter field derived
+// from a relation of the task is true.
+func getTaskBoolRelationSubquery(field string) (subquery *builder.Builder, is bool) {
+	switch field {
+	case taskPropertyBlocked:
+		// A task is only blocked as long as the task blocking it is neither done nor in the trash
+		return builder.
+			Select("task_id").
+			From("task_relations").
+			Where(builder.And(
+				builder.Eq{"relation_kind": RelationKindBlocked},
+				builder.In("other_task_id", builder.
+					Select("id").
+					From("tasks").
+					Where(builder.And(
+						builder.IsNull{"deleted"},
+						builder.Eq{"done": false},
+					))),
+			)), true
+	case taskPropertyHasSubtasks:
+		return builder.
+			Select("task_id").
+			From("task_relations").
+			Where(builder.And(
+				builder.Eq{"relation_kind": RelationKindSubtask},
+				// Subtasks in the trash don't count
+				builder.In("other_task_id", builder.
+					Select("id").
+					From("tasks").
+					Where(builder.IsNull{"deleted"})),
+			)), true
+	case taskPropertyHasAttachments:
+		return builder.
+			Select("task_id").
+			From("task_attachments"), true
+	}
+
+	return nil, false
+}
+
+func getTaskBoolRelationFilterCond(f *taskFilter, subquery *builder.Builder) (builder.Cond, error) {
+	if f.comparator != taskFilterComparatorEquals && f.comparator != taskFilterComparatorNotEquals {
+		return nil, ErrInvalidTaskFilterComparator{Comparator: f.comparator}
+	}
+
+	value, is := f.value.(bool)
+	if !is {
+		return nil, ErrInvalidTaskFilterValue{Field: f.field, Value: f.value}
+	}
+	if f.comparator == taskFilterComparatorNotEquals {
+		value = !value
+	}
+
+	if value {
+		return builder.In("id", subquery), nil
+	}
+	return builder.NotIn("id", subquery), nil
+}
@@ -46,6 +46,15 @@ const (
 	taskPropertyPosition       string = "position"
 	taskPropertyKanbanPosition string = "kanban_position"
 	taskPropertyBucketID       string = "bucket_id"
+	taskPropertyTimeTracked    string = "time_tracked"
+	taskPropertyLastCommented  string = "last_commented"
+	taskPropertyAssignees      string = "assignees"
+	taskPropertyLabels         string = "labels"
+
+	// These can only be used to filter tasks, not to sort them
+	taskPropertyBlocked        string = "blocked"
+	taskPropertyHasSubtasks    string = "has_subtasks"
+	taskPropertyHasAttachments string = "has_attachments"
 )
 
 const (
@@ -61,6 +61,9 @@ func TestSortParamValidation(t *testing.T) {
 			taskPropertyCreated,
 			taskPropertyUpdated,
 			taskPropertyPosition,
+			taskPropertyLastCommented,
+			taskPropertyAssignees,
+			taskPropertyLabels,
 		} {
 			t.Run(test, func(t *testing.T) {
 				s := &sortParam{
@@ -90,4 +93,13 @@ func TestSortParamValidation(t *testing.T) {
 		assert.Error(t, err)
 		assert.True(t, IsErrInvalidTaskField(err))
 	})
+	t.Run("Test filter only field", func(t *testing.T) {
+		s := &sortParam{
+			orderBy: orderAscending,
+			sortBy:  taskPropertyBlocked,
+		}
+		err := s.validate()
+		assert.Error(t, err)
+		assert.True(t, IsErrInvalidTaskField(err))
+	})
 }
@@ -91,6 +91,12 @@ func TestTaskCollection_ReadAll(t *testing.T) {
 		BucketID:    1,
 		IsFavorite:  true,
 		Position:    2,
+		TimeTracked: 5400,
+		CustomFields: map[int64]interface{}{
+			1: "ACME",
+			2: 2.5,
+			3: "review",
+		},
 		Labels: []*Label{
 			label4,
 		},
@@ -596,6 +602,7 @@ func TestTaskCollection_ReadAll(t *testing.T) {
 		FilterValue        []string
 		FilterComparator   []string
 		FilterIncludeNulls bool
+		Filter             string
 
 		CRUDable web.CRUDable
 		Rights   web.Rights
@@ -1023,6 +1030,84 @@ func TestTaskCollection_ReadAll(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "filter query",
+			fields: fields{
+				Filter: "(priority >= 3 && done = false) || assignees in me",
+			},
+			args: defaultArgs,
+			want: []*Task{
+				task3,
+				task30,
+			},
+			wantErr: false,
+		},
+		{
+			name: "filter query by label title",
+			fields: fields{
+				Filter: `labels = "label #4 - visible via other task"`,
+			},
+			args: defaultArgs,
+			want: []*Task{
+				task1,
+				task2,
+			},
+			wantErr: false,
+		},
+		{
+			name: "filter has subtasks",
+			fields: fields{
+				FilterBy:         []string{"has_subtasks"},
+				FilterValue:      []string{"true"},
+				FilterComparator: []string{"equals"},
+			},
+			args: defaultArgs,
+			want: []*Task{
+				task1,
+			},
+			wantErr: false,
+		},
+		{
+			name: "filter query has attachments and last commented",
+			fields: fields{
+				Filter: "has_attachments = true && last_commented > 2020-01-01",
+			},
+			args: defaultArgs,
+			want: []*Task{
+				task1,
+			},
+			wantErr: false,
+		},
+		{
+			name: "filter query not blocked in list",
+			fields: fields{
+				Filter: "blocked = false && list = 1 && priority > 0",
+			},
+			args: defaultArgs,
+			want: []*Task{
+				task3,
+				task4,
+			},
+			wantErr: false,
+		},
+		{
+			name: "filter bool relation with invalid comparator",
+			fields: fields{
+				FilterBy:         []string{"has_attachments"},
+				FilterValue:      []string{"true"},
+				FilterComparator: []string{"greater"},
+			},
+			args:    defaultArgs,
+			wantErr: true,
+		},
+		{
+			name: "filter query invalid",
+			fields: fields{
+				Filter: "priority >=",
+			},
+			args:    defaultArgs,
+			wantErr: true,
+		},
 		{
 			name:   "search for task index",
 			fields: fields{},
@@ -1116,6 +1201,7 @@ func TestTaskCollection_ReadAll(t *testing.T) {
 				FilterValue:        tt.fields.FilterValue,
 				FilterComparator:   tt.fields.FilterComparator,
 				FilterIncludeNulls: tt.fields.FilterIncludeNulls,
+				Filter:             tt.fields.Filter,
 
 				CRUDable: tt.fields.CRUDable,
 				Rights:   tt.fields.Rights,
@@ -1125,6 +1211,9 @@ func TestTaskCollection_ReadAll(t *testing.T) {
 				t.Errorf("Test %s, Task.ReadAll() error = %v, wantErr %v", tt.name, err, tt.wantErr)
 				return
 			}
+			if tt.wantErr {
+				return
+			}
 			if diff, equal := messagediff.PrettyDiff(got, tt.want); !equal {
 				if len(got.([]*Task)) == 0 && len(tt.want.([]*Task)) == 0 {
 					return
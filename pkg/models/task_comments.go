@@ -49,6 +49,9 @@ func (tc *TaskComment) TableName() string {
 	return "task_comments"
 }
 
+// Used to sort and filter tasks by the time of their last comment
+const taskLastCommentedSubquery = "(SELECT MAX(created) FROM task_comments WHERE task_comments.task_id = tasks.id)"
+
 // Create creates a new task comment
 // @Summary Create a new task comment
 // @Description Create a new task comment. The user doing this need to have at least write access to the task this comment should belong to.
@@ -0,0 +1,546 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"code.vikunja.io/api/pkg/user"
+	"code.vikunja.io/web"
+	"xorm.io/builder"
+	"xorm.io/xorm"
+)
+
+// A filter query is a small expression language to filter tasks, for example:
+//
+//   (priority >= 3 && assignees in me) || labels in urgent
+//
+// Each comparison consists of a field, a comparator and one or more comma separated values. Comparisons can be
+// combined with && (or "and") and || (or "or") and grouped with parentheses. && binds stronger than ||.
+// Values containing spaces or special characters need to be quoted with " or '.
+
+// These fields are task relations which can be filtered by the id or the name of the related entity.
+const (
+	taskFilterQueryFieldLabels    = "labels"
+	taskFilterQueryFieldAssignees = "assignees"
+	taskFilterQueryFieldList      = "list"
+	taskFilterQueryFieldNamespace = "namespace"
+	taskFilterQueryFieldReminders = "reminders"
+)
+
+// These limits keep the recursive parser from running out of stack space
+const (
+	maxTaskFilterQueryLength = 2000
+	maxTaskFilterQueryDepth  = 32
+)
+
+// taskFilterQueryValueMe can be used as a value for the assignees field to filter for the current user.
+const taskFilterQueryValueMe = "me"
+
+type taskFilterQueryTokenKind int
+
+const (
+	taskFilterQueryTokenValue taskFilterQueryTokenKind = iota
+	taskFilterQueryTokenComparator
+	taskFilterQueryTokenAnd
+	taskFilterQueryTokenOr
+	taskFilterQueryTokenOpenParen
+	taskFilterQueryTokenCloseParen
+	taskFilterQueryTokenComma
+)
+
+type taskFilterQueryToken struct {
+	kind  taskFilterQueryTokenKind
+	value string
+	// Quoted values are never treated as keywords like "in" or "like"
+	quoted bool
+}
+
+// taskFilterExpression is a parsed filter query. Every expression is either a combination of other expressions
+// or a single comparison.
+type taskFilterExpression struct {
+	concat      taskFilterConcatinator
+	expressions []*taskFilterExpression
+
+	field      string
+	comparator taskFilterComparator
+	values     []string
+}
+
+func isTaskFilterQuerySpecialRune(r rune) bool {
+	return unicode.IsSpace(r) || strings.ContainsRune(`(),&|=!<>"'`, r)
+}
+
+func tokenizeTaskFilterQuery(query string) (tokens []*taskFilterQueryToken, err error) {
+	runes := []rune(query)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, &taskFilterQueryToken{kind: taskFilterQueryTokenOpenParen, value: "("})
+			i++
+		case r == ')':
+			tokens = append(tokens, &taskFilterQueryToken{kind: taskFilterQueryTokenCloseParen, value: ")"})
+			i++
+		case r == ',':
+			tokens = append(tokens, &taskFilterQueryToken{kind: taskFilterQueryTokenComma, value: ","})
+			i++
+		case r == '&' || r == '|':
+			if i+1 >= len(runes) || runes[i+1] != r {
+				return nil, fmt.Errorf("unexpected '%c', did you mean '%c%c'", r, r, r)
+			}
+			kind := taskFilterQueryTokenAnd
+			if r == '|' {
+				kind = taskFilterQueryTokenOr
+			}
+			tokens = append(tokens, &taskFilterQueryToken{kind: kind, value: string(runes[i : i+2])})
+			i += 2
+		case r == '=' || r == '!' || r == '<' || r == '>':
+			op := string(r)
+			i++
+			if i < len(runes) && runes[i] == '=' {
+				op += "="
+				i++
+			}
+			var comparator taskFilterComparator
+			switch op {
+			case "=", "==":
+				comparator = taskFilterComparatorEquals
+			case "!=":
+				comparator = taskFilterComparatorNotEquals
+			case ">":
+				comparator = taskFilterComparatorGreater
+			case ">=":
+				comparator = taskFilterComparatorGreateEquals
+			case "<":
+				comparator = taskFilterComparatorLess
+			case "<=":
+				comparator = taskFilterComparatorLessEquals
+			default:
+				return nil, fmt.Errorf("unexpected '%s'", op)
+			}
+			tokens = append(tokens, &taskFilterQueryToken{kind: taskFilterQueryTokenComparator, value: string(comparator)})
+		case r == '"' || r == '\'':
+			end := i + 1
+			for end < len(runes) && runes[end] != r {
+				end++
+			}
+			if end >= len(runes) {
+				return nil, fmt.Errorf("missing closing %c", r)
+			}
+			tokens = append(tokens, &taskFilterQueryToken{kind: taskFilterQueryTokenValue, value: string(runes[i+1 : end]), quoted: true})
+			i = end + 1
+		default:
+			start := i
+			for i < len(runes) && !isTaskFilterQuerySpecialRune(runes[i]) {
+				i++
+			}
+			word := string(runes[start:i])
+			switch strings.ToLower(word) {
+			case "and":
+				tokens = append(tokens, &taskFilterQueryToken{kind: taskFilterQueryTokenAnd, value: word})
+			case "or":
+				tokens = append(tokens, &taskFilterQueryToken{kind: taskFilterQueryTokenOr, value: word})
+			default:
+				tokens = append(tokens, &taskFilterQueryToken{kind: taskFilterQueryTokenValue, value: word})
+			}
+		}
+	}
+
+	return
+}
+
+type taskFilterQueryParser struct {
+	tokens []*taskFilterQueryToken
+	pos    int
+	// How many parentheses are currently open
+	depth int
+}
+
+func (p *taskFilterQueryParser) peek() *taskFilterQueryToken {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return nil
+}
+
+func (p *taskFilterQueryParser) next() *taskFilterQueryToken {
+	t := p.peek()
+	if t != nil {
+		p.pos++
+	}
+	return t
+}
+
+func (p *taskFilterQueryParser) parseOr() (*taskFilterExpression, error) {
+	expr, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	expressions := []*taskFilterExpression{expr}
+	for t := p.peek(); t != nil && t.kind == taskFilterQueryTokenOr; t = p.peek() {
+		p.pos++
+		expr, err = p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		expressions = append(expressions, expr)
+	}
+
+	if len(expressions) == 1 {
+		return expressions[0], nil
+	}
+	return &taskFilterExpression{concat: filterConcatOr, expressions: expressions}, nil
+}
+
+func (p *taskFilterQueryParser) parseAnd() (*taskFilterExpression, error) {
+	expr, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	expressions := []*taskFilterExpression{expr}
+	for t := p.peek(); t != nil && t.kind == taskFilterQueryTokenAnd; t = p.peek() {
+		p.pos++
+		expr, err = p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		expressions = append(expressions, expr)
+	}
+
+	if len(expressions) == 1 {
+		return expressions[0], nil
+	}
+	return &taskFilterExpression{concat: filterConcatAnd, expressions: expressions}, nil
+}
+
+func (p *taskFilterQueryParser) parseOperand() (*taskFilterExpression, error) {
+	t := p.next()
+	if t == nil {
+		return nil, errors.New("unexpected end of the filter")
+	}
+
+	if t.kind == taskFilterQueryTokenOpenParen {
+		p.depth++
+		if p.depth > maxTaskFilterQueryDepth {
+			return nil, fmt.Errorf("parentheses can't be nested deeper than %d levels", maxTaskFilterQueryDepth)
+		}
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing == nil || closing.kind != taskFilterQueryTokenCloseParen {
+			return nil, errors.New("missing closing parenthesis")
+		}
+		p.depth--
+		return expr, nil
+	}
+
+	if t.kind != taskFilterQueryTokenValue {
+		return nil, fmt.Errorf("expected a field but got '%s'", t.value)
+	}
+
+	return p.parseComparison(t.value)
+}
+
+func (p *taskFilterQueryParser) parseComparison(fieldName string) (*taskFilterExpression, error) {
+	field, err := getTaskFilterQueryField(fieldName)
+	if err != nil {
+		return nil, err
+	}
+
+	expr := &taskFilterExpression{field: field}
+
+	c := p.next()
+	switch {
+	case c == nil:
+		return nil, fmt.Errorf("missing comparator after '%s'", fieldName)
+	case c.kind == taskFilterQueryTokenComparator:
+		expr.comparator = taskFilterComparator(c.value)
+	case c.kind == taskFilterQueryTokenValue && !c.quoted && strings.EqualFold(c.value, "like"):
+		expr.comparator = taskFilterComparatorLike
+	case c.kind == taskFilterQueryTokenValue && !c.quoted && strings.EqualFold(c.value, "in"):
+		expr.comparator = taskFilterComparatorIn
+	default:
+		return nil, fmt.Errorf("expected a comparator after '%s' but got '%s'", fieldName, c.value)
+	}
+
+	if expr.isRelation() &&
+		expr.comparator != taskFilterComparatorEquals &&
+		expr.comparator != taskFilterComparatorNotEquals &&
+		expr.comparator != taskFilterComparatorIn {
+		return nil, fmt.Errorf("'%s' can only be compared with =, != or in", fieldName)
+	}
+
+	for {
+		v := p.next()
+		if v == nil || v.kind != taskFilterQueryTokenValue {
+			return nil, fmt.Errorf("missing value for '%s'", fieldName)
+		}
+		expr.values = append(expr.values, v.value)
+
+		// Only "in" accepts multiple comma separated values
+		if expr.comparator != taskFilterComparatorIn {
+			break
+		}
+		if n := p.peek(); n == nil || n.kind != taskFilterQueryTokenComma {
+			break
+		}
+		p.pos++
+	}
+
+	return expr, nil
+}
+
+func getTaskFilterQueryField(field string) (string, error) {
+	switch strings.ToLower(field) {
+	case "label", "labels", "label_id":
+		return taskFilterQueryFieldLabels, nil
+	case "assignee", "assignees", "user_id":
+		return taskFilterQueryFieldAssignees, nil
+	case "list", "list_id":
+		return taskFilterQueryFieldList, nil
+	case "namespace", "namespace_id":
+		return taskFilterQueryFieldNamespace, nil
+	case "reminder", "reminders":
+		return taskFilterQueryFieldReminders, nil
+	}
+
+	if _, is := getTaskBoolRelationSubquery(field); is {
+		return field, nil
+	}
+
+	if err := validateTaskField(field); err != nil {
+		return "", fmt.Errorf("unknown field '%s'", field)
+	}
+	return field, nil
+}
+
+// parseTaskFilterQuery parses a filter query into an expression which can be converted to a db condition.
+func parseTaskFilterQuery(query string) (*taskFilterExpression, error) {
+	if len(query) > maxTaskFilterQueryLength {
+		return nil, ErrInvalidTaskFilterExpression{
+			Expression: query[:50] + "...",
+			Reason:     fmt.Sprintf("the filter is longer than %d characters", maxTaskFilterQueryLength),
+		}
+	}
+
+	tokens, err := tokenizeTaskFilterQuery(query)
+	if err != nil {
+		return nil, ErrInvalidTaskFilterExpression{Expression: query, Reason: err.Error()}
+	}
+	if len(tokens) == 0 {
+		return nil, ErrInvalidTaskFilterExpression{Expression: query, Reason: "the filter is empty"}
+	}
+
+	p := &taskFilterQueryParser{tokens: tokens}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, ErrInvalidTaskFilterExpression{Expression: query, Reason: err.Error()}
+	}
+	if t := p.peek(); t != nil {
+		return nil, ErrInvalidTaskFilterExpression{Expression: query, Reason: fmt.Sprintf("unexpected '%s'", t.value)}
+	}
+
+	return expr, nil
+}
+
+func (e *taskFilterExpression) isRelation() bool {
+	switch e.field {
+	case taskFilterQueryFieldLabels,
+		taskFilterQueryFieldAssignees,
+		taskFilterQueryFieldList,
+		taskFilterQueryFieldNamespace:
+		return true
+	}
+	return false
+}
+
+// splitIDsAndNames splits the values of a comparison with a relation into numeric ids and (lower case) names.
+func (e *taskFilterExpression) splitIDsAndNames() (ids []int64, names []string) {
+	for _, v := range e.values {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err == nil {
+			ids = append(ids, id)
+			continue
+		}
+		names = append(names, strings.ToLower(v))
+	}
+	return
+}
+
+// getEntityCond returns a condition matching all entities of a relation with either one of the ids or one of the names.
+func getTaskFilterQueryEntityCond(ids []int64, names []string, nameColumn string) builder.Cond {
+	conds := []builder.Cond{}
+	if len(ids) > 0 {
+		conds = append(conds, builder.In("id", ids))
+	}
+	if len(names) > 0 {
+		conds = append(conds, builder.In("LOWER("+nameColumn+")", names))
+	}
+	return builder.Or(conds...)
+}
+
+func (e *taskFilterExpression) getRelationCond(column string, subquery *builder.Builder) builder.Cond {
+	if e.comparator == taskFilterComparatorNotEquals {
+		return builder.NotIn(column, subquery)
+	}
+	return builder.In(column, subquery)
+}
+
+// getTaskFilter converts the values of a comparison to their native type.
+func (e *taskFilterExpression) getTaskFilter() (f *taskFilter, err error) {
+	f = &taskFilter{
+		field:      e.field,
+		comparator: e.comparator,
+	}
+
+	if e.comparator != taskFilterComparatorIn {
+		f.value, err = getNativeValueForTaskField(e.field, e.comparator, e.values[0])
+		if err != nil {
+			return nil, ErrInvalidTaskFilterValue{Value: e.values[0], Field: e.field}
+		}
+		return
+	}
+
+	values := make([]interface{}, 0, len(e.values))
+	for _, v := range e.values {
+		value, err := getNativeValueForTaskField(e.field, taskFilterComparatorEquals, v)
+		if err != nil {
+			return nil, ErrInvalidTaskFilterValue{Value: v, Field: e.field}
+		}
+		values = append(values, value)
+	}
+	f.value = values
+	return
+}
+
+// toCond converts an expression into a db condition for the tasks table.
+func (e *taskFilterExpression) toCond(s *xorm.Session, a web.Auth, includeNulls bool) (builder.Cond, error) {
+	if e.concat != "" {
+		conds := make([]builder.Cond, 0, len(e.expressions))
+		for _, expr := range e.expressions {
+			cond, err := expr.toCond(s, a, includeNulls)
+			if err != nil {
+				return nil, err
+			}
+			conds = append(conds, cond)
+		}
+		if e.concat == filterConcatAnd {
+			return builder.And(conds...), nil
+		}
+		return builder.Or(conds...), nil
+	}
+
+	switch e.field {
+	case taskFilterQueryFieldLabels:
+		ids, names := e.splitIDsAndNames()
+		return e.getRelationCond("id", builder.
+			Select("task_id").
+			From("label_tasks").
+			Where(builder.In("label_id", builder.
+				Select("id").
+				From("labels").
+				Where(getTaskFilterQueryEntityCond(ids, names, "title")))),
+		), nil
+
+	case taskFilterQueryFieldAssignees:
+		ids, names := e.splitIDsAndNames()
+		usernames := make([]string, 0, len(names))
+		for _, name := range names {
+			if name != taskFilterQueryValueMe {
+				usernames = append(usernames, name)
+				continue
+			}
+			doer, err := user.GetFromAuth(a)
+			if err != nil {
+				return nil, ErrInvalidTaskFilterValue{Value: name, Field: e.field}
+			}
+			ids = append(ids, doer.ID)
+		}
+		return e.getRelationCond("id", builder.
+			Select("task_id").
+			From("task_assignees").
+			Where(builder.In("user_id", builder.
+				Select("id").
+				From("users").
+				Where(getTaskFilterQueryEntityCond(ids, usernames, "username")))),
+		), nil
+
+	case taskFilterQueryFieldList:
+		ids, names := e.splitIDsAndNames()
+		return e.getRelationCond("list_id", builder.
+			Select("id").
+			From("lists").
+			Where(getTaskFilterQueryEntityCond(ids, names, "title")),
+		), nil
+
+	case taskFilterQueryFieldNamespace:
+		ids, names := e.splitIDsAndNames()
+		return e.getRelationCond("list_id", builder.
+			Select("id").
+			From("lists").
+			Where(builder.In("namespace_id", builder.
+				Select("id").
+				From("namespaces").
+				Where(getTaskFilterQueryEntityCond(ids, names, "title")))),
+		), nil
+
+	case taskFilterQueryFieldReminders:
+		f, err := e.getTaskFilter()
+		if err != nil {
+			return nil, err
+		}
+		f.field = "reminder" // This is the name in the db
+		// A task without any reminder at that time should be matched by !=, not a task with any other reminder
+		if e.comparator == taskFilterComparatorNotEquals {
+			f.comparator = taskFilterComparatorEquals
+		}
+		cond, err := getFilterCond(f, false)
+		if err != nil {
+			return nil, err
+		}
+		return e.getRelationCond("id", builder.
+			Select("task_id").
+			From("task_reminders").
+			Where(cond),
+		), nil
+	}
+
+	f, err := e.getTaskFilter()
+	if err != nil {
+		return nil, err
+	}
+
+	if subquery, is := getTaskBoolRelationSubquery(e.field); is {
+		return getTaskBoolRelationFilterCond(f, subquery)
+	}
+
+	if fieldID, is := getCustomFieldIDFromTaskProperty(e.field); is {
+		return getCustomFieldFilterCond(s, fieldID, f, includeNulls)
+	}
+
+	return getFilterCond(f, includeNulls)
+}
@@ -0,0 +1,129 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTaskFilterQuery(t *testing.T) {
+	t.Run("single comparison", func(t *testing.T) {
+		expr, err := parseTaskFilterQuery("priority >= 3")
+		assert.NoError(t, err)
+		assert.Equal(t, "priority", expr.field)
+		assert.Equal(t, taskFilterComparatorGreateEquals, expr.comparator)
+		assert.Equal(t, []string{"3"}, expr.values)
+	})
+	t.Run("and binds stronger than or", func(t *testing.T) {
+		expr, err := parseTaskFilterQuery("done = false || priority > 1 and priority < 5")
+		assert.NoError(t, err)
+		assert.Equal(t, taskFilterConcatinator(filterConcatOr), expr.concat)
+		assert.Len(t, expr.expressions, 2)
+		assert.Equal(t, "done", expr.expressions[0].field)
+		assert.Equal(t, taskFilterConcatinator(filterConcatAnd), expr.expressions[1].concat)
+		assert.Len(t, expr.expressions[1].expressions, 2)
+	})
+	t.Run("parentheses", func(t *testing.T) {
+		expr, err := parseTaskFilterQuery("(done = false || priority > 1) && labels in 1, 'with space'")
+		assert.NoError(t, err)
+		assert.Equal(t, taskFilterConcatinator(filterConcatAnd), expr.concat)
+		assert.Equal(t, taskFilterConcatinator(filterConcatOr), expr.expressions[0].concat)
+		assert.Equal(t, taskFilterQueryFieldLabels, expr.expressions[1].field)
+		assert.Equal(t, taskFilterComparatorIn, expr.expressions[1].comparator)
+		assert.Equal(t, []string{"1", "with space"}, expr.expressions[1].values)
+	})
+	t.Run("like", func(t *testing.T) {
+		expr, err := parseTaskFilterQuery(`title like "in progress"`)
+		assert.NoError(t, err)
+		assert.Equal(t, taskFilterComparatorLike, expr.comparator)
+		assert.Equal(t, []string{"in progress"}, expr.values)
+	})
+	t.Run("invalid", func(t *testing.T) {
+		for _, query := range []string{
+			"",
+			"priority",
+			"priority >=",
+			"priority >= 3 &&",
+			"(priority >= 3",
+			"priority >= 3)",
+			"priority & 3",
+			"title = 'unterminated",
+			"unknown = 1",
+			"labels > 1",
+		} {
+			t.Run(query, func(t *testing.T) {
+				_, err := parseTaskFilterQuery(query)
+				assert.Error(t, err)
+				assert.True(t, IsErrInvalidTaskFilterExpression(err))
+			})
+		}
+	})
+	t.Run("nesting limit", func(t *testing.T) {
+		query := strings.Repeat("(", maxTaskFilterQueryDepth) + "priority >= 3" + strings.Repeat(")", maxTaskFilterQueryDepth)
+		_, err := parseTaskFilterQuery(query)
+		assert.NoError(t, err)
+
+		query = "(" + query + ")"
+		_, err = parseTaskFilterQuery(query)
+		assert.Error(t, err)
+		assert.True(t, IsErrInvalidTaskFilterExpression(err))
+	})
+	t.Run("deeply nested", func(t *testing.T) {
+		_, err := parseTaskFilterQuery(strings.Repeat("(", maxTaskFilterQueryLength))
+		assert.Error(t, err)
+		assert.True(t, IsErrInvalidTaskFilterExpression(err))
+	})
+	t.Run("too long", func(t *testing.T) {
+		query := "priority >= 3" + strings.Repeat(" || priority >= 3", maxTaskFilterQueryLength/16)
+		_, err := parseTaskFilterQuery(query)
+		assert.Error(t, err)
+		assert.True(t, IsErrInvalidTaskFilterExpression(err))
+	})
+}
+
+func TestParseRelativeFilterDate(t *testing.T) {
+	now := time.Date(2021, 9, 26, 12, 0, 0, 0, time.UTC)
+
+	for value, expected := range map[string]time.Time{
+		"now":      now,
+		"now+30m":  now.Add(30 * time.Minute),
+		"now-2h":   now.Add(-2 * time.Hour),
+		"now+3d":   now.AddDate(0, 0, 3),
+		"now-1w":   now.AddDate(0, 0, -7),
+		"now+1M":   now.AddDate(0, 1, 0),
+		"now-1y":   now.AddDate(-1, 0, 0),
+		"now+10s":  now.Add(10 * time.Second),
+		"now+100d": now.AddDate(0, 0, 100),
+	} {
+		t.Run(value, func(t *testing.T) {
+			date, err := parseRelativeFilterDate(value, now)
+			assert.NoError(t, err)
+			assert.True(t, expected.Equal(date))
+		})
+	}
+
+	for _, value := range []string{"now+", "now+d", "now3d", "now+3x"} {
+		t.Run(value, func(t *testing.T) {
+			_, err := parseRelativeFilterDate(value, now)
+			assert.Error(t, err)
+		})
+	}
+}
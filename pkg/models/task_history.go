@@ -0,0 +1,237 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/user"
+	"code.vikunja.io/web"
+	"xorm.io/xorm"
+)
+
+// TaskChange is a single change of one property of a task
+type TaskChange struct {
+	// The unique, numeric id of this change.
+	ID int64 `xorm:"bigint autoincr not null unique pk" json:"id"`
+	// The task this change belongs to.
+	TaskID int64 `xorm:"bigint not null INDEX" json:"task_id" param:"task"`
+	// The property of the task which was changed, for example `due_date`, `bucket_id`, `labels` or `assignees`.
+	Field string `xorm:"varchar(50) not null" json:"field"`
+	// The value before the change. Empty if a label, assignee or relation was added.
+	OldValue string `xorm:"longtext null" json:"old_value"`
+	// The value after the change. Empty if a label, assignee or relation was removed.
+	NewValue string `xorm:"longtext null" json:"new_value"`
+
+	// The user who made this change.
+	User   *user.User `xorm:"-" json:"user"`
+	UserID int64      `xorm:"bigint not null" json:"-"`
+
+	// A timestamp when this change was made.
+	Created time.Time `xorm:"created not null INDEX" json:"created"`
+
+	web.CRUDable `xorm:"-" json:"-"`
+	web.Rights   `xorm:"-" json:"-"`
+}
+
+// TableName returns a better table name for task changes
+func (*TaskChange) TableName() string {
+	return "task_changes"
+}
+
+// All fields of a task which are tracked in the change history besides the ones from Task.Update.
+const (
+	taskChangeFieldLabels       = "labels"
+	taskChangeFieldAssignees    = "assignees"
+	taskChangeFieldRelatedTasks = "related_tasks"
+	taskChangeFieldReminders    = "reminders"
+)
+
+// Link shares are saved with a negative id, the same way as comments do it.
+func getTaskChangeDoerID(a web.Auth) int64 {
+	if share, is := a.(*LinkSharing); is {
+		return share.ID * -1
+	}
+	return a.GetID()
+}
+
+func formatTaskChangeValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case TaskRepeatMode:
+		return strconv.Itoa(int(v))
+	case time.Time:
+		if v.IsZero() {
+			return ""
+		}
+		return v.In(config.GetTimeZone()).Format(time.RFC3339)
+	case []time.Time:
+		formatted := make([]string, 0, len(v))
+		for _, t := range v {
+			formatted = append(formatted, formatTaskChangeValue(t))
+		}
+		sort.Strings(formatted)
+		return strings.Join(formatted, ",")
+	}
+	return ""
+}
+
+func formatTaskRelationChangeValue(kind RelationKind, otherTaskID int64) string {
+	return string(kind) + ":" + strconv.FormatInt(otherTaskID, 10)
+}
+
+// recordTaskChange saves a change of a task property in its history. Nothing is saved if the value did not change.
+func recordTaskChange(s *xorm.Session, a web.Auth, taskID int64, field string, oldValue, newValue interface{}) error {
+	change := &TaskChange{
+		TaskID:   taskID,
+		Field:    field,
+		OldValue: formatTaskChangeValue(oldValue),
+		NewValue: formatTaskChangeValue(newValue),
+		UserID:   getTaskChangeDoerID(a),
+	}
+	if change.OldValue == change.NewValue {
+		return nil
+	}
+
+	_, err := s.Insert(change)
+	return err
+}
+
+// recordTaskUpdateChanges saves all changes of the properties which can be changed with Task.Update.
+func recordTaskUpdateChanges(s *xorm.Session, a web.Auth, oldTask, newTask *Task) error {
+	fields := []struct {
+		name     string
+		oldValue interface{}
+		newValue interface{}
+	}{
+		{taskPropertyTitle, oldTask.Title, newTask.Title},
+		{taskPropertyDescription, oldTask.Description, newTask.Description},
+		{taskPropertyDone, oldTask.Done, newTask.Done},
+		{taskPropertyDueDate, oldTask.DueDate, newTask.DueDate},
+		{taskPropertyRepeatAfter, oldTask.RepeatAfter, newTask.RepeatAfter},
+		{"repeat_mode", oldTask.RepeatMode, newTask.RepeatMode},
+		{"repeat_rule", oldTask.RepeatRule, newTask.RepeatRule},
+		{taskPropertyPriority, oldTask.Priority, newTask.Priority},
+		{taskPropertyStartDate, oldTask.StartDate, newTask.StartDate},
+		{taskPropertyEndDate, oldTask.EndDate, newTask.EndDate},
+		{taskPropertyHexColor, oldTask.HexColor, newTask.HexColor},
+		{taskPropertyPercentDone, oldTask.PercentDone, newTask.PercentDone},
+		{taskPropertyListID, oldTask.ListID, newTask.ListID},
+		{taskPropertyBucketID, oldTask.BucketID, newTask.BucketID},
+		{taskChangeFieldReminders, oldTask.Reminders, newTask.Reminders},
+	}
+
+	for _, f := range fields {
+		if err := recordTaskChange(s, a, oldTask.ID, f.name, f.oldValue, f.newValue); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadAll returns the change history of a task
+// @Summary Get the change history of a task
+// @Description Returns all changes of a task, newest first. Labels, assignees and related tasks are saved with their id, all dates are formatted as RFC 3339.
+// @tags task
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param task path int true "Task ID"
+// @Param page query int false "The page number. Used for pagination. If not provided, the first page of results is returned."
+// @Param per_page query int false "The maximum number of items per page. Note this parameter is limited by the configured maximum of items per page."
+// @Success 200 {array} models.TaskChange "The changes"
+// @Failure 403 {object} web.HTTPError "The user does not have access to the task."
+// @Failure 404 {object} web.HTTPError "The task does not exist."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /tasks/{task}/history [get]
+func (tc *TaskChange) ReadAll(s *xorm.Session, a web.Auth, search string, page int, perPage int) (result interface{}, resultCount int, totalItems int64, err error) {
+	task := &Task{ID: tc.TaskID}
+	can, _, err := task.CanRead(s, a)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	if !can {
+		return nil, 0, 0, ErrGenericForbidden{}
+	}
+
+	limit, start := getLimitFromPageIndex(page, perPage)
+
+	changes := []*TaskChange{}
+	query := s.
+		Where("task_id = ?", tc.TaskID).
+		OrderBy("created desc, id desc")
+	if limit > 0 {
+		query = query.Limit(limit, start)
+	}
+	err = query.Find(&changes)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	err = addUsersToTaskChanges(s, changes)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	totalItems, err = s.Where("task_id = ?", tc.TaskID).Count(&TaskChange{})
+	return changes, len(changes), totalItems, err
+}
+
+func addUsersToTaskChanges(s *xorm.Session, changes []*TaskChange) error {
+	userIDs := make([]int64, 0, len(changes))
+	for _, c := range changes {
+		userIDs = append(userIDs, c.UserID)
+	}
+
+	users, err := getUsersOrLinkSharesFromIDs(s, userIDs)
+	if err != nil {
+		return err
+	}
+
+	for _, c := range changes {
+		c.User = users[c.UserID]
+	}
+	return nil
+}
+
+func getTaskChangesForTasks(s *xorm.Session, taskIDs []int64) (changes []*TaskChange, err error) {
+	changes = []*TaskChange{}
+	if len(taskIDs) == 0 {
+		return
+	}
+
+	err = s.In("task_id", taskIDs).OrderBy("created asc, id asc").Find(&changes)
+	if err != nil {
+		return
+	}
+
+	err = addUsersToTaskChanges(s, changes)
+	return
+}
@@ -0,0 +1,239 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"testing"
+	"time"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/user"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTaskChange_ReadAll(t *testing.T) {
+	u := &user.User{ID: 1}
+
+	t.Run("normal", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		tc := &TaskChange{TaskID: 1}
+		result, count, total, err := tc.ReadAll(s, u, "", 0, 50)
+		assert.NoError(t, err)
+		assert.Equal(t, 3, count)
+		assert.Equal(t, int64(3), total)
+		changes := result.([]*TaskChange)
+		// Newest first
+		assert.Equal(t, int64(3), changes[0].ID)
+		assert.Equal(t, int64(2), changes[0].User.ID)
+		// Changes made by link shares
+		assert.Equal(t, int64(-1), changes[1].User.ID)
+	})
+	t.Run("paging", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		tc := &TaskChange{TaskID: 1}
+		result, count, total, err := tc.ReadAll(s, u, "", 2, 2)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, count)
+		assert.Equal(t, int64(3), total)
+		assert.Equal(t, int64(1), result.([]*TaskChange)[0].ID)
+	})
+	t.Run("no access", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		tc := &TaskChange{TaskID: 14}
+		_, _, _, err := tc.ReadAll(s, u, "", 0, 50)
+		assert.Error(t, err)
+		assert.True(t, IsErrGenericForbidden(err))
+	})
+}
+
+func TestTaskChange_Record(t *testing.T) {
+	u := &user.User{ID: 1}
+
+	t.Run("task update", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		dueDate := time.Date(2021, 9, 30, 12, 0, 0, 0, time.UTC)
+		task := &Task{
+			ID:          1,
+			Title:       "task #1",
+			Description: "Lorem Ipsum",
+			DueDate:     dueDate,
+			ListID:      1,
+			BucketID:    1,
+		}
+		err := task.Update(s, u)
+		assert.NoError(t, err)
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		db.AssertExists(t, "task_changes", map[string]interface{}{
+			"task_id":   1,
+			"field":     "due_date",
+			"old_value": "",
+			"new_value": formatTaskChangeValue(dueDate),
+			"user_id":   1,
+		}, false)
+		// Unchanged fields are not saved
+		db.AssertMissing(t, "task_changes", map[string]interface{}{
+			"task_id": 1,
+			"field":   "description",
+		})
+	})
+	t.Run("bucket", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		task := &Task{
+			ID:          1,
+			Title:       "task #1",
+			Description: "Lorem Ipsum",
+			ListID:      1,
+			BucketID:    3,
+		}
+		err := task.Update(s, u)
+		assert.NoError(t, err)
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		db.AssertExists(t, "task_changes", map[string]interface{}{
+			"task_id":   1,
+			"field":     "bucket_id",
+			"old_value": "1",
+			"new_value": "3",
+			"user_id":   1,
+		}, false)
+	})
+	t.Run("labels", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		lt := &LabelTask{TaskID: 1, LabelID: 1}
+		err := lt.Create(s, u)
+		assert.NoError(t, err)
+		err = (&LabelTask{TaskID: 1, LabelID: 4}).Delete(s, u)
+		assert.NoError(t, err)
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		db.AssertExists(t, "task_changes", map[string]interface{}{
+			"task_id":   1,
+			"field":     "labels",
+			"old_value": "",
+			"new_value": "1",
+		}, false)
+		db.AssertExists(t, "task_changes", map[string]interface{}{
+			"task_id":   1,
+			"field":     "labels",
+			"old_value": "4",
+			"new_value": "",
+		}, false)
+	})
+	t.Run("assignees", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		ta := &TaskAssginee{TaskID: 1, UserID: 1}
+		err := ta.Create(s, u)
+		assert.NoError(t, err)
+		err = ta.Delete(s, u)
+		assert.NoError(t, err)
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		db.AssertExists(t, "task_changes", map[string]interface{}{
+			"task_id":   1,
+			"field":     "assignees",
+			"old_value": "",
+			"new_value": "1",
+		}, false)
+		db.AssertExists(t, "task_changes", map[string]interface{}{
+			"task_id":   1,
+			"field":     "assignees",
+			"old_value": "1",
+			"new_value": "",
+		}, false)
+	})
+	t.Run("reminders of a repeating task", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		reminders, err := getTaskReminderMap(s, []int64{27})
+		assert.NoError(t, err)
+		oldReminders := reminders[27]
+		assert.Len(t, oldReminders, 2)
+		_, err = s.Where("id = ?", 27).Cols("repeat_after").Update(&Task{RepeatAfter: 3600})
+		assert.NoError(t, err)
+
+		task := &Task{
+			ID:          27,
+			Title:       "task #27 with reminders",
+			Done:        true,
+			RepeatAfter: 3600,
+			Reminders:   append([]time.Time(nil), oldReminders...),
+			ListID:      1,
+			BucketID:    1,
+		}
+		err = task.Update(s, u)
+		assert.NoError(t, err)
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		db.AssertExists(t, "task_changes", map[string]interface{}{
+			"task_id":   27,
+			"field":     "reminders",
+			"old_value": formatTaskChangeValue(oldReminders),
+			"new_value": formatTaskChangeValue(task.Reminders),
+		}, false)
+	})
+	t.Run("relations", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		rel := &TaskRelation{
+			TaskID:       1,
+			OtherTaskID:  2,
+			RelationKind: RelationKindBlocking,
+		}
+		err := rel.Create(s, u)
+		assert.NoError(t, err)
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		db.AssertExists(t, "task_changes", map[string]interface{}{
+			"task_id":   1,
+			"field":     "related_tasks",
+			"old_value": "",
+			"new_value": "blocking:2",
+		}, false)
+	})
+}
@@ -55,7 +55,38 @@ type userWithTasks struct {
 	tasks []*Task
 }
 
-// RegisterOverdueReminderCron registers a function which checks once a day for tasks that are overdue and not done.
+// getUndoneOverdueTasksByUser returns all undone overdue tasks grouped by the users who want a reminder for them.
+func getUndoneOverdueTasksByUser(s *xorm.Session, now time.Time) (uts map[int64]*userWithTasks, err error) {
+	taskIDs, err := getUndoneOverdueTasks(s, now)
+	if err != nil {
+		return
+	}
+
+	users, err := getTaskUsersForTasks(s, taskIDs, builder.Eq{"users.overdue_tasks_reminders_enabled": true})
+	if err != nil {
+		return
+	}
+
+	uts = make(map[int64]*userWithTasks)
+	for _, t := range users {
+		_, exists := uts[t.User.ID]
+		if !exists {
+			uts[t.User.ID] = &userWithTasks{
+				user:  t.User,
+				tasks: []*Task{},
+			}
+		}
+		uts[t.User.ID].tasks = append(uts[t.User.ID].tasks, t.Task)
+	}
+
+	return
+}
+
+// overdueReminderHour is the hour of the day users get their overdue tasks reminder at, in their timezone.
+const overdueReminderHour = 8
+
+// RegisterOverdueReminderCron registers a function which checks every hour for tasks that are overdue and not done
+// and reminds all users for whom it is 08:00.
 func RegisterOverdueReminderCron() {
 	if !config.ServiceEnableEmailReminders.GetBool() {
 		return
@@ -66,38 +97,27 @@ func RegisterOverdueReminderCron() {
 		return
 	}
 
-	err := cron.Schedule("0 8 * * *", func() {
+	err := cron.Schedule("0 * * * *", func() {
 		s := db.NewSession()
 		defer s.Close()
 
 		now := time.Now()
-		taskIDs, err := getUndoneOverdueTasks(s, now)
-		if err != nil {
-			log.Errorf("[Undone Overdue Tasks Reminder] Could not get tasks with reminders in the next minute: %s", err)
-			return
-		}
-
-		users, err := getTaskUsersForTasks(s, taskIDs, builder.Eq{"users.overdue_tasks_reminders_enabled": true})
+		uts, err := getUndoneOverdueTasksByUser(s, now)
 		if err != nil {
-			log.Errorf("[Undone Overdue Tasks Reminder] Could not get task users to send them reminders: %s", err)
+			log.Errorf("[Undone Overdue Tasks Reminder] Could not get overdue tasks to send reminders for: %s", err)
 			return
 		}
 
-		uts := make(map[int64]*userWithTasks)
-		for _, t := range users {
-			_, exists := uts[t.User.ID]
-			if !exists {
-				uts[t.User.ID] = &userWithTasks{
-					user:  t.User,
-					tasks: []*Task{},
-				}
+		for _, ut := range uts {
+			if now.In(ut.user.GetTimeZone()).Hour() != overdueReminderHour {
+				continue
 			}
-			uts[t.User.ID].tasks = append(uts[t.User.ID].tasks, t.Task)
-		}
 
-		log.Debugf("[Undone Overdue Tasks Reminder] Sending reminders to %d users", len(users))
+			// Users with a digest get their overdue tasks as part of it
+			if ut.user.WantsDigest() {
+				continue
+			}
 
-		for _, ut := range uts {
 			var n notifications.Notification = &UndoneTasksOverdueNotification{
 				User:  ut.user,
 				Tasks: ut.tasks,
@@ -190,7 +190,11 @@ func (rel *TaskRelation) Create(s *xorm.Session, a web.Auth) error {
 		rel,
 		otherRelation,
 	})
-	return err
+	if err != nil {
+		return err
+	}
+
+	return recordTaskChange(s, a, rel.TaskID, taskChangeFieldRelatedTasks, nil, formatTaskRelationChangeValue(rel.RelationKind, rel.OtherTaskID))
 }
 
 // Delete removes a task relation
@@ -241,5 +245,9 @@ func (rel *TaskRelation) Delete(s *xorm.Session, a web.Auth) error {
 	_, err = s.
 		Where(cond).
 		Delete(&TaskRelation{})
-	return err
+	if err != nil {
+		return err
+	}
+
+	return recordTaskChange(s, a, rel.TaskID, taskChangeFieldRelatedTasks, formatTaskRelationChangeValue(rel.RelationKind, rel.OtherTaskID), nil)
 }
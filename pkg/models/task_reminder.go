@@ -53,6 +53,9 @@ type taskUser struct {
 
 const dbTimeFormat = `2006-01-02 15:04:05`
 
+// taskUserColumns are all columns of a user needed to send them notifications about a task.
+const taskUserColumns = "users.id, users.username, users.email, users.name, users.digest_interval, users.timezone, users.quiet_hours_start, users.quiet_hours_end, users.language"
+
 func getTaskUsersForTasks(s *xorm.Session, taskIDs []int64, cond builder.Cond) (taskUsers []*taskUser, err error) {
 	if len(taskIDs) == 0 {
 		return
@@ -61,11 +64,11 @@ func getTaskUsersForTasks(s *xorm.Session, taskIDs []int64, cond builder.Cond) (
 	// Get all creators of tasks
 	creators := make(map[int64]*user.User, len(taskIDs))
 	err = s.
-		Select("users.id, users.username, users.email, users.name").
+		Select(taskUserColumns).
 		Join("LEFT", "tasks", "tasks.created_by_id = users.id").
 		In("tasks.id", taskIDs).
 		Where(cond).
-		GroupBy("tasks.id, users.id, users.username, users.email, users.name").
+		GroupBy("tasks.id, " + taskUserColumns).
 		Find(&creators)
 	if err != nil {
 		return
@@ -112,16 +115,18 @@ func getTaskUsersForTasks(s *xorm.Session, taskIDs []int64, cond builder.Cond) (
 
 func getTasksWithRemindersInTheNextMinute(s *xorm.Session, now time.Time) (taskIDs []int64, err error) {
 	now = utils.GetTimeWithoutNanoSeconds(now)
+	return getTasksWithRemindersBetween(s, now, now.Add(1*time.Minute))
+}
 
-	nextMinute := now.Add(1 * time.Minute)
-
-	log.Debugf("[Task Reminder Cron] Looking for reminders between %s and %s to send...", now, nextMinute)
+func getTasksWithRemindersBetween(s *xorm.Session, from, to time.Time) (taskIDs []int64, err error) {
+	log.Debugf("[Task Reminder Cron] Looking for reminders between %s and %s to send...", from, to)
 
 	reminders := []*TaskReminder{}
 	err = s.
 		Join("INNER", "tasks", "tasks.id = task_reminders.task_id").
-		Where("reminder >= ? and reminder < ?", now.Format(dbTimeFormat), nextMinute.Format(dbTimeFormat)).
+		Where("reminder >= ? and reminder < ?", from.Format(dbTimeFormat), to.Format(dbTimeFormat)).
 		And("tasks.done = false").
+		And("tasks.deleted IS NULL").
 		Find(&reminders)
 	if err != nil {
 		return
@@ -141,6 +146,55 @@ func getTasksWithRemindersInTheNextMinute(s *xorm.Session, now time.Time) (taskI
 	return
 }
 
+// sendRemindersAfterQuietHours sends all reminders which were held back because they were due during the quiet
+// hours of a user to every user whose quiet hours end in the current minute.
+func sendRemindersAfterQuietHours(s *xorm.Session, now time.Time) (err error) {
+	now = now.Truncate(time.Minute)
+
+	users := []*user.User{}
+	err = s.
+		Where("quiet_hours_start IS NOT NULL AND quiet_hours_start != '' AND quiet_hours_end IS NOT NULL AND quiet_hours_end != ''").
+		And("email_reminders_enabled = ?", true).
+		Find(&users)
+	if err != nil {
+		return
+	}
+
+	for _, u := range users {
+		start, end, ends := u.GetQuietHoursEndingIn(now, time.Minute)
+		if !ends {
+			continue
+		}
+
+		taskIDs, err := getTasksWithRemindersBetween(s, start.In(now.Location()), end.In(now.Location()))
+		if err != nil {
+			return err
+		}
+
+		taskUsers, err := getTaskUsersForTasks(s, taskIDs, builder.And(
+			builder.Eq{"users.email_reminders_enabled": true},
+			builder.Eq{"users.id": u.ID},
+		))
+		if err != nil {
+			return err
+		}
+
+		for _, tu := range taskUsers {
+			err = notifications.Notify(tu.User, &ReminderDueNotification{
+				User: tu.User,
+				Task: tu.Task,
+			})
+			if err != nil {
+				return err
+			}
+
+			log.Debugf("[Task Reminder Cron] Sent reminder email for task %d to user %d after their quiet hours", tu.Task.ID, tu.User.ID)
+		}
+	}
+
+	return nil
+}
+
 // RegisterReminderCron registers a cron function which runs every minute to check if any reminders are due the
 // next minute to send emails.
 func RegisterReminderCron() {
@@ -162,6 +216,12 @@ func RegisterReminderCron() {
 		defer s.Close()
 
 		now := time.Now()
+
+		err := sendRemindersAfterQuietHours(s, now)
+		if err != nil {
+			log.Errorf("[Task Reminder Cron] Could not send reminders held back during quiet hours: %s", err)
+		}
+
 		taskIDs, err := getTasksWithRemindersInTheNextMinute(s, now)
 		if err != nil {
 			log.Errorf("[Task Reminder Cron] Could not get tasks with reminders in the next minute: %s", err)
@@ -181,6 +241,12 @@ func RegisterReminderCron() {
 		log.Debugf("[Task Reminder Cron] Sending reminders to %d users", len(users))
 
 		for _, u := range users {
+			// Reminders during the quiet hours of a user are sent once they are over
+			if u.User.IsInQuietHours(now) {
+				log.Debugf("[Task Reminder Cron] Holding back reminder for task %d until the quiet hours of user %d are over", u.Task.ID, u.User.ID)
+				continue
+			}
+
 			n := &ReminderDueNotification{
 				User: u.User,
 				Task: u.Task,
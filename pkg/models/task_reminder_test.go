@@ -21,7 +21,10 @@ import (
 	"time"
 
 	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/notifications"
+	"code.vikunja.io/api/pkg/user"
 	"github.com/stretchr/testify/assert"
+	"xorm.io/xorm"
 )
 
 func TestReminderGetTasksInTheNextMinute(t *testing.T) {
@@ -49,3 +52,36 @@ func TestReminderGetTasksInTheNextMinute(t *testing.T) {
 		assert.Len(t, taskIDs, 0)
 	})
 }
+
+func TestSendRemindersAfterQuietHours(t *testing.T) {
+	setQuietHours := func(t *testing.T, s *xorm.Session) {
+		_, err := s.
+			Where("id = ?", 1).
+			Cols("timezone", "quiet_hours_start", "quiet_hours_end").
+			Update(&user.User{Timezone: "UTC", QuietHoursStart: "01:00", QuietHoursEnd: "02:00"})
+		assert.NoError(t, err)
+	}
+
+	t.Run("quiet hours end now", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+		notifications.Fake()
+		setQuietHours(t, s)
+
+		err := sendRemindersAfterQuietHours(s, time.Date(2018, 12, 1, 2, 0, 12, 0, time.UTC))
+		assert.NoError(t, err)
+		notifications.AssertSent(t, &ReminderDueNotification{})
+	})
+	t.Run("quiet hours are not over", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+		notifications.Fake()
+		setQuietHours(t, s)
+
+		err := sendRemindersAfterQuietHours(s, time.Date(2018, 12, 1, 1, 30, 0, 0, time.UTC))
+		assert.NoError(t, err)
+		notifications.AssertNothingSent(t)
+	})
+}
@@ -0,0 +1,586 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/cron"
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/log"
+	"code.vikunja.io/api/pkg/user"
+	"code.vikunja.io/web"
+	"xorm.io/builder"
+	"xorm.io/xorm"
+)
+
+// TaskTemplate is a blueprint for tasks which are regularly created in the same way, like onboarding or release checklists.
+type TaskTemplate struct {
+	// The unique, numeric id of this template.
+	ID int64 `xorm:"bigint autoincr not null unique pk" json:"id" param:"tasktemplate"`
+	// The list this template belongs to. Tasks created from this template are created in this list by default.
+	ListID int64 `xorm:"bigint not null INDEX" json:"list_id" param:"list"`
+	// The name of this template.
+	Title string `xorm:"varchar(250) not null" json:"title" valid:"runelength(1|250)" minLength:"1" maxLength:"250"`
+
+	// The title of tasks created from this template. It can contain the placeholders `{{date}}`, `{{day}}`, `{{weekday}}`, `{{week}}`, `{{month}}`, `{{month_name}}` and `{{year}}` which are replaced with the date the task is created.
+	TaskTitle string `xorm:"varchar(250) not null" json:"task_title" valid:"runelength(1|250)" minLength:"1" maxLength:"250"`
+	// The description of tasks created from this template. It can contain the same placeholders as the title.
+	Description string `xorm:"longtext null" json:"description"`
+	// The ids of all labels which are added to tasks created from this template.
+	LabelIDs []int64 `xorm:"JSON null 'label_ids'" json:"label_ids"`
+	// The ids of all users who are assigned to tasks created from this template. Users who don't have access to the list are skipped.
+	AssigneeIDs []int64 `xorm:"JSON null 'assignee_ids'" json:"assignee_ids"`
+	// The due date of tasks created from this template, in seconds after the task was created. 0 means no due date.
+	DueDateOffset int64 `xorm:"bigint null" json:"due_date_offset"`
+	// The reminders of tasks created from this template, in seconds relative to the due date. If the template has no due date, they are relative to the time the task was created. Negative values are before the due date.
+	ReminderOffsets []int64 `xorm:"JSON null" json:"reminder_offsets"`
+	// Tasks which are created as subtasks of every task created from this template.
+	Subtasks []*TaskTemplateSubtask `xorm:"JSON null" json:"subtasks"`
+
+	// A cron expression like `0 9 1 * *` to automatically create a task from this template on a schedule, in the timezone of this Vikunja instance. Leave empty to only create tasks manually.
+	Schedule string `xorm:"varchar(250) null" json:"schedule"`
+	// The next time a task will be created from this template if it has a schedule. You cannot change this value.
+	NextRun time.Time `xorm:"DATETIME null 'next_run'" json:"next_run"`
+
+	// The user who created this template. Scheduled tasks are created in their name.
+	CreatedBy   *user.User `xorm:"-" json:"created_by"`
+	CreatedByID int64      `xorm:"bigint not null" json:"-"`
+
+	// A timestamp when this template was created. You cannot change this value.
+	Created time.Time `xorm:"created not null" json:"created"`
+	// A timestamp when this template was last updated. You cannot change this value.
+	Updated time.Time `xorm:"updated not null" json:"updated"`
+
+	web.CRUDable `xorm:"-" json:"-"`
+	web.Rights   `xorm:"-" json:"-"`
+}
+
+// TaskTemplateSubtask is a subtask which is created with every task created from a template.
+type TaskTemplateSubtask struct {
+	// The title of the subtask. It can contain the same placeholders as the title of the template.
+	Title string `json:"title"`
+	// The description of the subtask.
+	Description string `json:"description"`
+	// The due date of the subtask, in seconds after the task was created. 0 means no due date.
+	DueDateOffset int64 `json:"due_date_offset"`
+}
+
+// TableName returns a better table name for task templates
+func (*TaskTemplate) TableName() string {
+	return "task_templates"
+}
+
+func getTaskTemplateByID(s *xorm.Session, id int64) (tpl *TaskTemplate, err error) {
+	tpl = &TaskTemplate{}
+	exists, err := s.Where("id = ?", id).Get(tpl)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrTaskTemplateDoesNotExist{ID: id}
+	}
+	return
+}
+
+// setNextRun calculates when a task should be created next from a template's schedule.
+func (tpl *TaskTemplate) setNextRun(now time.Time) error {
+	tpl.Schedule = strings.TrimSpace(tpl.Schedule)
+	if tpl.Schedule == "" {
+		tpl.NextRun = time.Time{}
+		return nil
+	}
+
+	next, err := cron.Next(tpl.Schedule, now.In(config.GetTimeZone()))
+	if err != nil {
+		return ErrInvalidTaskTemplateSchedule{Schedule: tpl.Schedule, Reason: err.Error()}
+	}
+	tpl.NextRun = next
+	return nil
+}
+
+// Create creates a new task template
+// @Summary Create a task template
+// @Description Creates a new task template in a list. The user needs write access to the list.
+// @tags tasktemplates
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param list path int true "List ID"
+// @Param template body models.TaskTemplate true "The new task template"
+// @Success 201 {object} models.TaskTemplate "The created task template."
+// @Failure 400 {object} web.HTTPError "Invalid task template object provided."
+// @Failure 403 {object} web.HTTPError "The user does not have write access to the list."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /lists/{list}/templates [put]
+func (tpl *TaskTemplate) Create(s *xorm.Session, a web.Auth) (err error) {
+	if err = tpl.setNextRun(time.Now()); err != nil {
+		return
+	}
+
+	tpl.ID = 0
+	tpl.CreatedByID = a.GetID()
+	_, err = s.Insert(tpl)
+	if err != nil {
+		return
+	}
+
+	tpl.CreatedBy, err = user.GetFromAuth(a)
+	return
+}
+
+// ReadOne returns a single task template
+// @Summary Get one task template
+// @Description Returns one task template by its ID.
+// @tags tasktemplates
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param list path int true "List ID"
+// @Param tasktemplate path int true "Task template ID"
+// @Success 200 {object} models.TaskTemplate "The task template"
+// @Failure 403 {object} web.HTTPError "The user does not have access to the list."
+// @Failure 404 {object} web.HTTPError "The task template does not exist."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /lists/{list}/templates/{tasktemplate} [get]
+func (tpl *TaskTemplate) ReadOne(s *xorm.Session, a web.Auth) (err error) {
+	template, err := getTaskTemplateByID(s, tpl.ID)
+	if err != nil {
+		return
+	}
+	*tpl = *template
+
+	tpl.CreatedBy, err = user.GetUserByID(s, tpl.CreatedByID)
+	if user.IsErrUserDoesNotExist(err) {
+		return nil
+	}
+	return
+}
+
+// ReadAll returns all task templates of a list
+// @Summary Get all task templates of a list
+// @Description Returns all task templates of a list.
+// @tags tasktemplates
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param list path int true "List ID"
+// @Param page query int false "The page number. Used for pagination. If not provided, the first page of results is returned."
+// @Param per_page query int false "The maximum number of items per page. Note this parameter is limited by the configured maximum of items per page."
+// @Param s query string false "Search task templates by their title."
+// @Success 200 {array} models.TaskTemplate "The task templates"
+// @Failure 403 {object} web.HTTPError "The user does not have access to the list."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /lists/{list}/templates [get]
+func (tpl *TaskTemplate) ReadAll(s *xorm.Session, a web.Auth, search string, page int, perPage int) (result interface{}, resultCount int, totalItems int64, err error) {
+	if _, is := a.(*LinkSharing); is {
+		return nil, 0, 0, ErrGenericForbidden{}
+	}
+
+	can, _, err := (&List{ID: tpl.ListID}).CanRead(s, a)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	if !can {
+		return nil, 0, 0, ErrGenericForbidden{}
+	}
+
+	cond := builder.And(
+		builder.Eq{"list_id": tpl.ListID},
+		db.ILIKE("title", search),
+	)
+
+	limit, start := getLimitFromPageIndex(page, perPage)
+
+	templates := []*TaskTemplate{}
+	query := s.Where(cond).OrderBy("id asc")
+	if limit > 0 {
+		query = query.Limit(limit, start)
+	}
+	err = query.Find(&templates)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	userIDs := make([]int64, 0, len(templates))
+	for _, t := range templates {
+		userIDs = append(userIDs, t.CreatedByID)
+	}
+
+	users, err := user.GetUsersByIDs(s, userIDs)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	for _, t := range templates {
+		t.CreatedBy = users[t.CreatedByID]
+	}
+
+	totalItems, err = s.Where(cond).Count(&TaskTemplate{})
+	return templates, len(templates), totalItems, err
+}
+
+// Update updates a task template
+// @Summary Update a task template
+// @Description Updates a task template. Changing the schedule resets the time the next task will be created. The user updating the template becomes its creator, scheduled tasks are created in their name.
+// @tags tasktemplates
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param list path int true "List ID"
+// @Param tasktemplate path int true "Task template ID"
+// @Param template body models.TaskTemplate true "The task template with updated values"
+// @Success 200 {object} models.TaskTemplate "The updated task template."
+// @Failure 400 {object} web.HTTPError "Invalid task template object provided."
+// @Failure 403 {object} web.HTTPError "The user does not have write access to the list."
+// @Failure 404 {object} web.HTTPError "The task template does not exist."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /lists/{list}/templates/{tasktemplate} [post]
+func (tpl *TaskTemplate) Update(s *xorm.Session, a web.Auth) (err error) {
+	existing, err := getTaskTemplateByID(s, tpl.ID)
+	if err != nil {
+		return
+	}
+
+	// Scheduled tasks are created as the template's creator, otherwise anyone with write access to the list could
+	// add labels and assignees in the name of someone else.
+	tpl.CreatedByID = a.GetID()
+	tpl.NextRun = existing.NextRun
+	if strings.TrimSpace(tpl.Schedule) != existing.Schedule {
+		if err = tpl.setNextRun(time.Now()); err != nil {
+			return
+		}
+	}
+
+	_, err = s.
+		Where("id = ?", tpl.ID).
+		Cols(
+			"title",
+			"task_title",
+			"description",
+			"label_ids",
+			"assignee_ids",
+			"due_date_offset",
+			"reminder_offsets",
+			"subtasks",
+			"schedule",
+			"next_run",
+			"created_by_id",
+		).
+		Update(tpl)
+	if err != nil {
+		return
+	}
+
+	return tpl.ReadOne(s, a)
+}
+
+// Delete removes a task template
+// @Summary Delete a task template
+// @Description Removes a task template. Tasks which were already created from it are not touched.
+// @tags tasktemplates
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param list path int true "List ID"
+// @Param tasktemplate path int true "Task template ID"
+// @Success 200 {object} models.Message "The task template was successfully deleted."
+// @Failure 403 {object} web.HTTPError "The user does not have write access to the list."
+// @Failure 404 {object} web.HTTPError "The task template does not exist."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /lists/{list}/templates/{tasktemplate} [delete]
+func (tpl *TaskTemplate) Delete(s *xorm.Session, a web.Auth) (err error) {
+	_, err = s.Where("id = ?", tpl.ID).Delete(&TaskTemplate{})
+	return
+}
+
+// replaceTaskTemplatePlaceholders replaces all date placeholders in a text with the values for the given date.
+func replaceTaskTemplatePlaceholders(text string, date time.Time) string {
+	date = date.In(config.GetTimeZone())
+	_, week := date.ISOWeek()
+
+	return strings.NewReplacer(
+		"{{date}}", date.Format("2006-01-02"),
+		"{{day}}", date.Format("02"),
+		"{{weekday}}", date.Weekday().String(),
+		"{{week}}", strconv.Itoa(week),
+		"{{month}}", date.Format("01"),
+		"{{month_name}}", date.Month().String(),
+		"{{year}}", strconv.Itoa(date.Year()),
+	).Replace(text)
+}
+
+// createTask creates a new task with all its labels, assignees and subtasks from a template.
+func (tpl *TaskTemplate) createTask(s *xorm.Session, list *List, doer web.Auth, now time.Time) (task *Task, err error) {
+	task = &Task{
+		Title:       replaceTaskTemplatePlaceholders(tpl.TaskTitle, now),
+		Description: replaceTaskTemplatePlaceholders(tpl.Description, now),
+		ListID:      list.ID,
+	}
+
+	reminderBase := now
+	if tpl.DueDateOffset != 0 {
+		task.DueDate = now.Add(time.Duration(tpl.DueDateOffset) * time.Second)
+		reminderBase = task.DueDate
+	}
+	for _, offset := range tpl.ReminderOffsets {
+		task.Reminders = append(task.Reminders, reminderBase.Add(time.Duration(offset)*time.Second))
+	}
+
+	err = createTask(s, task, doer, false)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, labelID := range tpl.LabelIDs {
+		label, err := getLabelByIDSimple(s, labelID)
+		if IsErrLabelDoesNotExist(err) {
+			log.Debugf("Not adding label %d from template %d because it does not exist", labelID, tpl.ID)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		has, _, err := label.hasAccessToLabel(s, doer)
+		if err != nil {
+			return nil, err
+		}
+		if !has {
+			log.Debugf("Not adding label %d from template %d because user %d does not have access to it", labelID, tpl.ID, doer.GetID())
+			continue
+		}
+
+		lt := &LabelTask{
+			TaskID:  task.ID,
+			LabelID: labelID,
+		}
+		if err := lt.Create(s, doer); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, assigneeID := range tpl.AssigneeIDs {
+		err = task.addNewAssigneeByID(s, assigneeID, list, doer)
+		if IsErrUserDoesNotHaveAccessToList(err) || user.IsErrUserDoesNotExist(err) {
+			log.Debugf("Not assigning user %d from template %d: %s", assigneeID, tpl.ID, err)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, st := range tpl.Subtasks {
+		subtask := &Task{
+			Title:       replaceTaskTemplatePlaceholders(st.Title, now),
+			Description: replaceTaskTemplatePlaceholders(st.Description, now),
+			ListID:      list.ID,
+		}
+		if st.DueDateOffset != 0 {
+			subtask.DueDate = now.Add(time.Duration(st.DueDateOffset) * time.Second)
+		}
+		err = createTask(s, subtask, doer, false)
+		if err != nil {
+			return nil, err
+		}
+
+		rel := &TaskRelation{
+			TaskID:       task.ID,
+			OtherTaskID:  subtask.ID,
+			RelationKind: RelationKindSubtask,
+		}
+		if err := rel.Create(s, doer); err != nil {
+			return nil, err
+		}
+	}
+
+	err = task.ReadOne(s, doer)
+	return
+}
+
+// TaskTemplateInstance is used to create a task from a template.
+type TaskTemplateInstance struct {
+	// The id of the template to create a task from.
+	TemplateID int64 `json:"-" param:"tasktemplate"`
+	// The list the task should be created in. Defaults to the list of the template.
+	ListID int64 `json:"list_id"`
+
+	// The created task.
+	Task *Task `json:"task,omitempty"`
+
+	template *TaskTemplate
+
+	web.CRUDable `json:"-"`
+	web.Rights   `json:"-"`
+}
+
+// CanCreate checks if a user can create a task from a template in a list
+func (ti *TaskTemplateInstance) CanCreate(s *xorm.Session, a web.Auth) (bool, error) {
+	if _, is := a.(*LinkSharing); is {
+		return false, nil
+	}
+
+	tpl, err := getTaskTemplateByID(s, ti.TemplateID)
+	if err != nil {
+		return false, err
+	}
+	ti.template = tpl
+
+	canRead, _, err := (&List{ID: tpl.ListID}).CanRead(s, a)
+	if err != nil || !canRead {
+		return false, err
+	}
+
+	if ti.ListID == 0 {
+		ti.ListID = tpl.ListID
+	}
+
+	return (&List{ID: ti.ListID}).CanWrite(s, a)
+}
+
+// Create creates a task from a template
+// @Summary Create a task from a template
+// @Description Creates a new task with all labels, assignees, reminders and subtasks of a template. The user needs read access to the list of the template and write access to the list the task is created in.
+// @tags tasktemplates
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param tasktemplate path int true "Task template ID"
+// @Param instance body models.TaskTemplateInstance true "The list to create the task in. Leave empty to use the list of the template."
+// @Success 201 {object} models.TaskTemplateInstance "The created task."
+// @Failure 403 {object} web.HTTPError "The user does not have access to the template or list."
+// @Failure 404 {object} web.HTTPError "The task template does not exist."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /tasktemplates/{tasktemplate}/instantiate [put]
+func (ti *TaskTemplateInstance) Create(s *xorm.Session, a web.Auth) (err error) {
+	list, err := GetListSimpleByID(s, ti.ListID)
+	if err != nil {
+		return err
+	}
+
+	ti.Task, err = ti.template.createTask(s, list, a, time.Now())
+	return
+}
+
+// RegisterTaskTemplateCron registers a cron function which creates tasks from all templates with a schedule.
+func RegisterTaskTemplateCron() {
+	err := cron.Schedule("* * * * *", func() {
+		s := db.NewSession()
+		defer s.Close()
+
+		err := createTasksFromScheduledTemplates(s, time.Now())
+		if err != nil {
+			log.Errorf("[Task Template Cron] Could not create tasks from templates: %s", err)
+		}
+	})
+	if err != nil {
+		log.Fatalf("Could not register task template cron: %s", err)
+	}
+}
+
+// createTasksFromScheduledTemplates creates a task from every template which is due. If a template was due multiple
+// times (because Vikunja was not running for a while), only one task is created. Every template gets its own
+// transaction so a broken template does not leave half-created tasks behind or affect other templates.
+func createTasksFromScheduledTemplates(s *xorm.Session, now time.Time) (err error) {
+	templates := []*TaskTemplate{}
+	err = s.
+		Where(builder.And(
+			builder.Neq{"schedule": ""},
+			builder.Lte{"next_run": now},
+		)).
+		Find(&templates)
+	if err != nil || len(templates) == 0 {
+		return
+	}
+
+	log.Debugf("[Task Template Cron] Creating tasks from %d templates", len(templates))
+
+	for _, tpl := range templates {
+		err = s.Begin()
+		if err != nil {
+			return err
+		}
+
+		err = createTaskFromScheduledTemplate(s, tpl, now)
+		if err != nil {
+			_ = s.Rollback()
+			log.Errorf("[Task Template Cron] Could not create task from template %d: %s", tpl.ID, err)
+
+			// The next run of a broken template is still updated, otherwise it would be retried every minute.
+			if err = updateNextRunOfTemplate(s, tpl, now); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err = updateNextRunOfTemplate(s, tpl, now); err != nil {
+			_ = s.Rollback()
+			return err
+		}
+
+		if err = s.Commit(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func updateNextRunOfTemplate(s *xorm.Session, tpl *TaskTemplate, now time.Time) (err error) {
+	if err := tpl.setNextRun(now); err != nil {
+		log.Errorf("[Task Template Cron] Could not calculate next run of template %d: %s", tpl.ID, err)
+		tpl.NextRun = time.Time{}
+	}
+	_, err = s.
+		Where("id = ?", tpl.ID).
+		Cols("next_run").
+		NoAutoTime().
+		Update(tpl)
+	return
+}
+
+func createTaskFromScheduledTemplate(s *xorm.Session, tpl *TaskTemplate, now time.Time) (err error) {
+	u, err := user.GetUserByID(s, tpl.CreatedByID)
+	if err != nil {
+		return err
+	}
+
+	list, err := GetListSimpleByID(s, tpl.ListID)
+	if err != nil {
+		return err
+	}
+
+	can, err := list.CanWrite(s, u)
+	if err != nil {
+		return err
+	}
+	if !can {
+		return ErrGenericForbidden{}
+	}
+
+	task, err := tpl.createTask(s, list, u, now)
+	if err != nil {
+		return err
+	}
+
+	log.Debugf("[Task Template Cron] Created task %d from template %d", task.ID, tpl.ID)
+	return nil
+}
@@ -0,0 +1,67 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"code.vikunja.io/web"
+	"xorm.io/xorm"
+)
+
+// CanRead checks if the user can see a task template
+func (tpl *TaskTemplate) CanRead(s *xorm.Session, a web.Auth) (bool, int, error) {
+	if _, is := a.(*LinkSharing); is {
+		return false, 0, nil
+	}
+
+	template, err := getTaskTemplateByID(s, tpl.ID)
+	if err != nil {
+		return false, 0, err
+	}
+	return (&List{ID: template.ListID}).CanRead(s, a)
+}
+
+// CanCreate checks if the user can create a task template in a list
+func (tpl *TaskTemplate) CanCreate(s *xorm.Session, a web.Auth) (bool, error) {
+	return tpl.canWriteList(s, a, tpl.ListID)
+}
+
+// CanUpdate checks if the user can update a task template
+func (tpl *TaskTemplate) CanUpdate(s *xorm.Session, a web.Auth) (bool, error) {
+	return tpl.canWriteExistingTaskTemplate(s, a)
+}
+
+// CanDelete checks if the user can delete a task template
+func (tpl *TaskTemplate) CanDelete(s *xorm.Session, a web.Auth) (bool, error) {
+	return tpl.canWriteExistingTaskTemplate(s, a)
+}
+
+func (tpl *TaskTemplate) canWriteExistingTaskTemplate(s *xorm.Session, a web.Auth) (bool, error) {
+	template, err := getTaskTemplateByID(s, tpl.ID)
+	if err != nil {
+		return false, err
+	}
+	return tpl.canWriteList(s, a, template.ListID)
+}
+
+// Templates can't be managed by link shares because tasks from scheduled templates are created in the name of the user
+// who created the template.
+func (tpl *TaskTemplate) canWriteList(s *xorm.Session, a web.Auth, listID int64) (bool, error) {
+	if _, is := a.(*LinkSharing); is {
+		return false, nil
+	}
+	return (&List{ID: listID}).CanWrite(s, a)
+}
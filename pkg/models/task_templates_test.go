@@ -0,0 +1,277 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"testing"
+	"time"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/user"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTaskTemplate_Create(t *testing.T) {
+	u := &user.User{ID: 1}
+
+	t.Run("normal", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		tpl := &TaskTemplate{
+			ListID:    1,
+			Title:     "Release",
+			TaskTitle: "Release {{year}}",
+			Schedule:  "0 9 * * 1",
+		}
+		err := tpl.Create(s, u)
+		assert.NoError(t, err)
+		assert.Equal(t, u.ID, tpl.CreatedBy.ID)
+		assert.True(t, tpl.NextRun.After(time.Now()))
+		assert.Equal(t, time.Monday, tpl.NextRun.In(config.GetTimeZone()).Weekday())
+		err = s.Commit()
+		assert.NoError(t, err)
+		db.AssertExists(t, "task_templates", map[string]interface{}{
+			"id":            tpl.ID,
+			"list_id":       1,
+			"title":         "Release",
+			"schedule":      "0 9 * * 1",
+			"created_by_id": 1,
+		}, false)
+	})
+	t.Run("invalid schedule", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		tpl := &TaskTemplate{
+			ListID:    1,
+			Title:     "Release",
+			TaskTitle: "Release",
+			Schedule:  "every monday",
+		}
+		err := tpl.Create(s, u)
+		assert.Error(t, err)
+		assert.True(t, IsErrInvalidTaskTemplateSchedule(err))
+	})
+	t.Run("no access to list", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		tpl := &TaskTemplate{ListID: 5}
+		can, err := tpl.CanCreate(s, u)
+		assert.NoError(t, err)
+		assert.False(t, can)
+	})
+}
+
+func TestTaskTemplate_ReadAll(t *testing.T) {
+	db.LoadAndAssertFixtures(t)
+	s := db.NewSession()
+	defer s.Close()
+
+	tpl := &TaskTemplate{ListID: 1}
+	templates, _, total, err := tpl.ReadAll(s, &user.User{ID: 1}, "", 0, 50)
+	assert.NoError(t, err)
+	assert.Len(t, templates, 2)
+	assert.Equal(t, int64(2), total)
+}
+
+func TestTaskTemplate_Update(t *testing.T) {
+	db.LoadAndAssertFixtures(t)
+	s := db.NewSession()
+	defer s.Close()
+
+	tpl := &TaskTemplate{
+		ID:        2,
+		Title:     "Invoicing",
+		TaskTitle: "Invoicing {{month_name}}",
+	}
+	err := tpl.Update(s, &user.User{ID: 1})
+	assert.NoError(t, err)
+	assert.True(t, tpl.NextRun.IsZero())
+	err = s.Commit()
+	assert.NoError(t, err)
+	db.AssertExists(t, "task_templates", map[string]interface{}{
+		"id":       2,
+		"title":    "Invoicing",
+		"schedule": "",
+	}, false)
+}
+
+func TestTaskTemplate_UpdateChangesCreator(t *testing.T) {
+	db.LoadAndAssertFixtures(t)
+	s := db.NewSession()
+	defer s.Close()
+
+	tpl := &TaskTemplate{
+		ID:        3,
+		Title:     "Other list",
+		TaskTitle: "Other",
+		LabelIDs:  []int64{1},
+	}
+	err := tpl.Update(s, &user.User{ID: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), tpl.CreatedByID)
+	err = s.Commit()
+	assert.NoError(t, err)
+	db.AssertExists(t, "task_templates", map[string]interface{}{
+		"id":            3,
+		"created_by_id": 1,
+	}, false)
+}
+
+func TestTaskTemplateInstance_Create(t *testing.T) {
+	u := &user.User{ID: 1}
+
+	t.Run("normal", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		ti := &TaskTemplateInstance{TemplateID: 1}
+		can, err := ti.CanCreate(s, u)
+		assert.NoError(t, err)
+		assert.True(t, can)
+		err = ti.Create(s, u)
+		assert.NoError(t, err)
+
+		now := time.Now().In(config.GetTimeZone())
+		task := ti.Task
+		assert.Equal(t, "Onboarding "+now.Format("2006-01-02"), task.Title)
+		assert.Equal(t, "Welcome to the team in "+now.Month().String()+"!", task.Description)
+		assert.Equal(t, int64(1), task.ListID)
+		assert.False(t, task.DueDate.IsZero())
+		assert.Len(t, task.Reminders, 1)
+		assert.Equal(t, task.DueDate.Add(-time.Hour).Unix(), task.Reminders[0].Unix())
+		// Label 3 and user 13 are skipped because the user does not have access to them
+		assert.Len(t, task.Labels, 1)
+		assert.Equal(t, int64(1), task.Labels[0].ID)
+		assert.Len(t, task.Assignees, 1)
+		assert.Equal(t, int64(1), task.Assignees[0].ID)
+		assert.Len(t, task.RelatedTasks[RelationKindSubtask], 2)
+
+		err = s.Commit()
+		assert.NoError(t, err)
+		db.AssertExists(t, "task_relations", map[string]interface{}{
+			"task_id":       task.ID,
+			"relation_kind": RelationKindSubtask,
+		}, false)
+	})
+	t.Run("into another list", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		ti := &TaskTemplateInstance{TemplateID: 2, ListID: 2}
+		can, err := ti.CanCreate(s, u)
+		assert.NoError(t, err)
+		assert.True(t, can)
+		err = ti.Create(s, u)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), ti.Task.ListID)
+	})
+	t.Run("no access to template", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		ti := &TaskTemplateInstance{TemplateID: 3, ListID: 1}
+		can, err := ti.CanCreate(s, u)
+		assert.NoError(t, err)
+		assert.False(t, can)
+	})
+	t.Run("nonexisting template", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		ti := &TaskTemplateInstance{TemplateID: 9999}
+		_, err := ti.CanCreate(s, u)
+		assert.Error(t, err)
+		assert.True(t, IsErrTaskTemplateDoesNotExist(err))
+	})
+}
+
+func TestCreateTasksFromScheduledTemplates(t *testing.T) {
+	db.LoadAndAssertFixtures(t)
+	s := db.NewSession()
+	defer s.Close()
+
+	now := time.Date(2021, 10, 1, 0, 0, 30, 0, config.GetTimeZone())
+	err := createTasksFromScheduledTemplates(s, now)
+	assert.NoError(t, err)
+	err = s.Commit()
+	assert.NoError(t, err)
+
+	db.AssertExists(t, "tasks", map[string]interface{}{
+		"title":   "Invoicing 10/2021",
+		"list_id": 1,
+	}, false)
+
+	s = db.NewSession()
+	defer s.Close()
+	tpl, err := getTaskTemplateByID(s, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2021, 11, 1, 0, 0, 0, 0, config.GetTimeZone()).Unix(), tpl.NextRun.Unix())
+}
+
+func TestCreateTasksFromScheduledTemplatesWithBrokenTemplate(t *testing.T) {
+	db.LoadAndAssertFixtures(t)
+	s := db.NewSession()
+	defer s.Close()
+
+	// User 2 can't write to list 1
+	broken := &TaskTemplate{
+		ListID:      1,
+		Title:       "Broken",
+		TaskTitle:   "Broken task",
+		Schedule:    "0 0 1 * *",
+		NextRun:     time.Date(2021, 10, 1, 0, 0, 0, 0, config.GetTimeZone()),
+		CreatedByID: 2,
+	}
+	_, err := s.Insert(broken)
+	assert.NoError(t, err)
+
+	now := time.Date(2021, 10, 1, 0, 0, 30, 0, config.GetTimeZone())
+	err = createTasksFromScheduledTemplates(s, now)
+	assert.NoError(t, err)
+
+	db.AssertExists(t, "tasks", map[string]interface{}{
+		"title":   "Invoicing 10/2021",
+		"list_id": 1,
+	}, false)
+	db.AssertMissing(t, "tasks", map[string]interface{}{
+		"title": "Broken task",
+	})
+
+	tpl, err := getTaskTemplateByID(s, broken.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2021, 11, 1, 0, 0, 0, 0, config.GetTimeZone()).Unix(), tpl.NextRun.Unix())
+}
+
+func TestReplaceTaskTemplatePlaceholders(t *testing.T) {
+	date := time.Date(2021, 9, 6, 12, 0, 0, 0, config.GetTimeZone())
+	assert.Equal(
+		t,
+		"Week 36: Monday, 06.09.2021 (September)",
+		replaceTaskTemplatePlaceholders("Week {{week}}: {{weekday}}, {{day}}.{{month}}.{{year}} ({{month_name}})", date),
+	)
+}
@@ -0,0 +1,385 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"time"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/user"
+	"code.vikunja.io/web"
+	"xorm.io/builder"
+	"xorm.io/xorm"
+)
+
+// TaskTimeEntry represents a span of time a user spent working on a task
+type TaskTimeEntry struct {
+	// The unique, numeric id of this time entry.
+	ID int64 `xorm:"bigint autoincr not null unique pk" json:"id" param:"timeentry"`
+	// The task this time entry belongs to.
+	TaskID int64 `xorm:"bigint not null index" json:"task_id" param:"task"`
+	// The user who tracked this time.
+	User   *user.User `xorm:"-" json:"user"`
+	UserID int64      `xorm:"bigint not null index" json:"-"`
+	// When the user started working on the task.
+	Start time.Time `xorm:"DATETIME not null 'start_time'" json:"start"`
+	// When the user stopped working on the task. If this is not set, the timer is still running.
+	End time.Time `xorm:"DATETIME null 'end_time'" json:"end"`
+	// The tracked time in seconds. You cannot set this value, it is calculated from start and end.
+	Duration int64 `xorm:"bigint not null default 0" json:"duration"`
+	// An optional note about what was done in this time.
+	Note string `xorm:"text null" json:"note"`
+
+	// A timestamp when this time entry was created. You cannot change this value.
+	Created time.Time `xorm:"created not null" json:"created"`
+	// A timestamp when this time entry was last updated. You cannot change this value.
+	Updated time.Time `xorm:"updated not null" json:"updated"`
+
+	web.CRUDable `xorm:"-" json:"-"`
+	web.Rights   `xorm:"-" json:"-"`
+}
+
+// TableName holds the table name for task time entries
+func (te *TaskTimeEntry) TableName() string {
+	return "task_time_entries"
+}
+
+// Used to sort and filter tasks by the sum of all their time entries
+const taskTimeTrackedSubquery = "(SELECT COALESCE(SUM(duration), 0) FROM task_time_entries WHERE task_time_entries.task_id = tasks.id)"
+
+func (te *TaskTimeEntry) setDuration() error {
+	if te.Start.IsZero() || te.End.IsZero() || te.End.Before(te.Start) {
+		return ErrInvalidTaskTimeEntryRange{Start: te.Start, End: te.End}
+	}
+	te.Duration = int64(te.End.Sub(te.Start).Seconds())
+	return nil
+}
+
+func getTaskTimeEntryByID(s *xorm.Session, id int64) (te *TaskTimeEntry, err error) {
+	te = &TaskTimeEntry{}
+	exists, err := s.Where("id = ?", id).Get(te)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrTaskTimeEntryDoesNotExist{ID: id}
+	}
+	return
+}
+
+func getRunningTaskTimeEntryForUser(s *xorm.Session, userID int64) (te *TaskTimeEntry, exists bool, err error) {
+	te = &TaskTimeEntry{}
+	exists, err = s.
+		Where("user_id = ? AND end_time IS NULL", userID).
+		Get(te)
+	return
+}
+
+// getTimeTrackedForTasks returns the sum of all finished time entries for each task.
+func getTimeTrackedForTasks(s *xorm.Session, taskIDs []int64) (timeTracked map[int64]int64, err error) {
+	timeTracked = make(map[int64]int64)
+
+	type taskTimeSum struct {
+		TaskID int64
+		Total  int64
+	}
+	sums := []*taskTimeSum{}
+	err = s.
+		Table("task_time_entries").
+		Select("task_id, SUM(duration) AS total").
+		In("task_id", taskIDs).
+		GroupBy("task_id").
+		Find(&sums)
+	if err != nil {
+		return
+	}
+
+	for _, sum := range sums {
+		timeTracked[sum.TaskID] = sum.Total
+	}
+	return
+}
+
+func getTaskTimeEntriesForTasks(s *xorm.Session, taskIDs []int64) (entries []*TaskTimeEntry, err error) {
+	entries = []*TaskTimeEntry{}
+	if len(taskIDs) == 0 {
+		return
+	}
+
+	err = s.In("task_id", taskIDs).OrderBy("start_time asc").Find(&entries)
+	return
+}
+
+// Create adds a finished time entry to a task
+// @Summary Add a time entry to a task
+// @Description Adds a time entry with a start and end date to a task for the current user. To track time while working, use the timer endpoints instead. The user needs write access to the task.
+// @tags task
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param task path int true "Task ID"
+// @Param entry body models.TaskTimeEntry true "The time entry"
+// @Success 201 {object} models.TaskTimeEntry "The created time entry."
+// @Failure 400 {object} web.HTTPError "Invalid time entry object provided."
+// @Failure 403 {object} web.HTTPError "The user does not have access to the task."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /tasks/{task}/time [put]
+func (te *TaskTimeEntry) Create(s *xorm.Session, a web.Auth) (err error) {
+	if err = te.setDuration(); err != nil {
+		return
+	}
+
+	te.ID = 0
+	te.UserID = a.GetID()
+	_, err = s.Insert(te)
+	if err != nil {
+		return
+	}
+
+	te.User, err = user.GetUserByID(s, te.UserID)
+	return
+}
+
+// ReadOne returns a single time entry
+// @Summary Get one time entry
+// @Description Returns a single time entry of a task.
+// @tags task
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param task path int true "Task ID"
+// @Param timeentry path int true "Time entry ID"
+// @Success 200 {object} models.TaskTimeEntry "The time entry"
+// @Failure 403 {object} web.HTTPError "The user does not have access to the task."
+// @Failure 404 {object} web.HTTPError "The time entry does not exist."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /tasks/{task}/time/{timeentry} [get]
+func (te *TaskTimeEntry) ReadOne(s *xorm.Session, a web.Auth) (err error) {
+	entry, err := getTaskTimeEntryByID(s, te.ID)
+	if err != nil {
+		return
+	}
+	*te = *entry
+
+	users, err := user.GetUsersByIDs(s, []int64{te.UserID})
+	if err != nil {
+		return
+	}
+	te.User = users[te.UserID]
+	return
+}
+
+// ReadAll returns all time entries of a task
+// @Summary Get all time entries of a task
+// @Description Returns all time entries of all users for a task. The user needs read access to the task.
+// @tags task
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param task path int true "Task ID"
+// @Param page query int false "The page number. Used for pagination. If not provided, the first page of results is returned."
+// @Param per_page query int false "The maximum number of items per page. Note this parameter is limited by the configured maximum of items per page."
+// @Param s query string false "Search time entries by their note."
+// @Success 200 {array} models.TaskTimeEntry "The time entries"
+// @Failure 403 {object} web.HTTPError "The user does not have access to the task."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /tasks/{task}/time [get]
+func (te *TaskTimeEntry) ReadAll(s *xorm.Session, a web.Auth, search string, page int, perPage int) (result interface{}, resultCount int, totalItems int64, err error) {
+	canRead, _, err := (&Task{ID: te.TaskID}).CanRead(s, a)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	if !canRead {
+		return nil, 0, 0, ErrGenericForbidden{}
+	}
+
+	var cond builder.Cond = builder.Eq{"task_id": te.TaskID}
+	// Entries without a note would not match an empty search
+	if search != "" {
+		cond = builder.And(cond, db.ILIKE("note", search))
+	}
+
+	limit, start := getLimitFromPageIndex(page, perPage)
+
+	entries := []*TaskTimeEntry{}
+	query := s.Where(cond).OrderBy("start_time asc")
+	if limit > 0 {
+		query = query.Limit(limit, start)
+	}
+	err = query.Find(&entries)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	userIDs := make([]int64, 0, len(entries))
+	for _, e := range entries {
+		userIDs = append(userIDs, e.UserID)
+	}
+
+	users, err := user.GetUsersByIDs(s, userIDs)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	for _, e := range entries {
+		e.User = users[e.UserID]
+	}
+
+	totalItems, err = s.Where(cond).Count(&TaskTimeEntry{})
+	return entries, len(entries), totalItems, err
+}
+
+// Update updates a time entry
+// @Summary Update a time entry
+// @Description Updates the start, end and note of a time entry. Users can only update their own time entries.
+// @tags task
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param task path int true "Task ID"
+// @Param timeentry path int true "Time entry ID"
+// @Param entry body models.TaskTimeEntry true "The time entry with updated values"
+// @Success 200 {object} models.TaskTimeEntry "The updated time entry."
+// @Failure 400 {object} web.HTTPError "Invalid time entry object provided."
+// @Failure 403 {object} web.HTTPError "The user does not have access to the time entry."
+// @Failure 404 {object} web.HTTPError "The time entry does not exist."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /tasks/{task}/time/{timeentry} [post]
+func (te *TaskTimeEntry) Update(s *xorm.Session, a web.Auth) (err error) {
+	if err = te.setDuration(); err != nil {
+		return
+	}
+
+	_, err = s.
+		Where("id = ?", te.ID).
+		Cols("start_time", "end_time", "duration", "note").
+		Update(te)
+	if err != nil {
+		return
+	}
+
+	return te.ReadOne(s, a)
+}
+
+// Delete removes a time entry
+// @Summary Delete a time entry
+// @Description Removes a time entry. Users can only delete their own time entries.
+// @tags task
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param task path int true "Task ID"
+// @Param timeentry path int true "Time entry ID"
+// @Success 200 {object} models.Message "The time entry was successfully deleted."
+// @Failure 403 {object} web.HTTPError "The user does not have access to the time entry."
+// @Failure 404 {object} web.HTTPError "The time entry does not exist."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /tasks/{task}/time/{timeentry} [delete]
+func (te *TaskTimeEntry) Delete(s *xorm.Session, a web.Auth) (err error) {
+	_, err = s.Where("id = ?", te.ID).Delete(&TaskTimeEntry{})
+	return
+}
+
+// TaskTimerStart is used to start the time tracking timer of the current user on a task.
+type TaskTimerStart TaskTimeEntry
+
+// Create starts a new timer for the current user on a task
+// @Summary Start a timer
+// @Description Starts tracking time on a task for the current user. If the user already has a running timer on any task, it is stopped first.
+// @tags task
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param task path int true "Task ID"
+// @Param entry body models.TaskTimeEntry true "The time entry, only the note is used."
+// @Success 201 {object} models.TaskTimeEntry "The running time entry."
+// @Failure 403 {object} web.HTTPError "The user does not have access to the task."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /tasks/{task}/time/start [post]
+func (t *TaskTimerStart) Create(s *xorm.Session, a web.Auth) (err error) {
+	running, exists, err := getRunningTaskTimeEntryForUser(s, a.GetID())
+	if err != nil {
+		return
+	}
+	if exists {
+		err = running.stop(s)
+		if err != nil {
+			return
+		}
+	}
+
+	t.ID = 0
+	t.UserID = a.GetID()
+	t.Start = time.Now()
+	t.End = time.Time{}
+	t.Duration = 0
+	_, err = s.Insert((*TaskTimeEntry)(t))
+	if err != nil {
+		return
+	}
+
+	t.User, err = user.GetUserByID(s, t.UserID)
+	return
+}
+
+// TaskTimerStop is used to stop the running time tracking timer of the current user on a task.
+type TaskTimerStop TaskTimeEntry
+
+// Create stops the running timer of the current user on a task
+// @Summary Stop a timer
+// @Description Stops the running timer of the current user on a task and saves the tracked time.
+// @tags task
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param task path int true "Task ID"
+// @Success 201 {object} models.TaskTimeEntry "The finished time entry."
+// @Failure 403 {object} web.HTTPError "The user does not have access to the task."
+// @Failure 412 {object} web.HTTPError "The user has no running timer on this task."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /tasks/{task}/time/stop [post]
+func (t *TaskTimerStop) Create(s *xorm.Session, a web.Auth) (err error) {
+	running, exists, err := getRunningTaskTimeEntryForUser(s, a.GetID())
+	if err != nil {
+		return
+	}
+	if !exists || running.TaskID != t.TaskID {
+		return ErrNoRunningTaskTimer{TaskID: t.TaskID, UserID: a.GetID()}
+	}
+
+	err = running.stop(s)
+	if err != nil {
+		return
+	}
+
+	*t = TaskTimerStop(*running)
+	t.User, err = user.GetUserByID(s, t.UserID)
+	return
+}
+
+func (te *TaskTimeEntry) stop(s *xorm.Session) (err error) {
+	te.End = time.Now()
+	if err = te.setDuration(); err != nil {
+		return
+	}
+
+	_, err = s.
+		Where("id = ?", te.ID).
+		Cols("end_time", "duration").
+		Update(te)
+	return
+}
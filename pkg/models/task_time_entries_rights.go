@@ -0,0 +1,77 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"code.vikunja.io/web"
+	"xorm.io/xorm"
+)
+
+// CanRead checks if a user can see a time entry
+func (te *TaskTimeEntry) CanRead(s *xorm.Session, a web.Auth) (bool, int, error) {
+	entry, err := getTaskTimeEntryByID(s, te.ID)
+	if err != nil {
+		return false, 0, err
+	}
+	return (&Task{ID: entry.TaskID}).CanRead(s, a)
+}
+
+// CanCreate checks if a user can add a time entry to a task
+func (te *TaskTimeEntry) CanCreate(s *xorm.Session, a web.Auth) (bool, error) {
+	return canTrackTimeOnTask(s, a, te.TaskID)
+}
+
+// CanUpdate checks if a user can update a time entry
+func (te *TaskTimeEntry) CanUpdate(s *xorm.Session, a web.Auth) (bool, error) {
+	return te.canDoOwnTimeEntry(s, a)
+}
+
+// CanDelete checks if a user can delete a time entry
+func (te *TaskTimeEntry) CanDelete(s *xorm.Session, a web.Auth) (bool, error) {
+	return te.canDoOwnTimeEntry(s, a)
+}
+
+// Users can only change their own time entries, and only as long as they can still write to the task.
+func (te *TaskTimeEntry) canDoOwnTimeEntry(s *xorm.Session, a web.Auth) (bool, error) {
+	entry, err := getTaskTimeEntryByID(s, te.ID)
+	if err != nil {
+		return false, err
+	}
+	if entry.UserID != a.GetID() {
+		return false, nil
+	}
+	return canTrackTimeOnTask(s, a, entry.TaskID)
+}
+
+// CanCreate checks if a user can start a timer on a task
+func (t *TaskTimerStart) CanCreate(s *xorm.Session, a web.Auth) (bool, error) {
+	return canTrackTimeOnTask(s, a, t.TaskID)
+}
+
+// CanCreate checks if a user can stop a timer on a task
+func (t *TaskTimerStop) CanCreate(s *xorm.Session, a web.Auth) (bool, error) {
+	return canTrackTimeOnTask(s, a, t.TaskID)
+}
+
+func canTrackTimeOnTask(s *xorm.Session, a web.Auth, taskID int64) (bool, error) {
+	// Time entries always belong to a user
+	if _, is := a.(*LinkSharing); is {
+		return false, nil
+	}
+
+	return (&Task{ID: taskID}).CanWrite(s, a)
+}
@@ -0,0 +1,260 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"testing"
+	"time"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/user"
+	"github.com/stretchr/testify/assert"
+	"xorm.io/builder"
+)
+
+func TestTaskTimeEntry_Create(t *testing.T) {
+	u := &user.User{ID: 1}
+
+	t.Run("normal", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		start := time.Date(2021, 9, 13, 8, 0, 0, 0, time.Local)
+		te := &TaskTimeEntry{
+			TaskID: 1,
+			Start:  start,
+			End:    start.Add(90 * time.Minute),
+			Note:   "Review",
+		}
+		err := te.Create(s, u)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(5400), te.Duration)
+		assert.Equal(t, u.ID, te.UserID)
+		err = s.Commit()
+		assert.NoError(t, err)
+		db.AssertExists(t, "task_time_entries", map[string]interface{}{
+			"id":       te.ID,
+			"task_id":  1,
+			"user_id":  1,
+			"duration": 5400,
+			"note":     "Review",
+		}, false)
+	})
+	t.Run("end before start", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		start := time.Date(2021, 9, 13, 8, 0, 0, 0, time.Local)
+		te := &TaskTimeEntry{
+			TaskID: 1,
+			Start:  start,
+			End:    start.Add(-time.Hour),
+		}
+		err := te.Create(s, u)
+		assert.Error(t, err)
+		assert.True(t, IsErrInvalidTaskTimeEntryRange(err))
+	})
+	t.Run("no access to task", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		te := &TaskTimeEntry{TaskID: 14}
+		can, err := te.CanCreate(s, u)
+		assert.NoError(t, err)
+		assert.False(t, can)
+	})
+	t.Run("link share", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		te := &TaskTimeEntry{TaskID: 1}
+		can, err := te.CanCreate(s, &LinkSharing{ID: 1, ListID: 1, Right: RightWrite})
+		assert.NoError(t, err)
+		assert.False(t, can)
+	})
+}
+
+func TestTaskTimeEntry_ReadAll(t *testing.T) {
+	t.Run("normal", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		te := &TaskTimeEntry{TaskID: 1}
+		entries, _, total, err := te.ReadAll(s, &user.User{ID: 1}, "", 0, 50)
+		assert.NoError(t, err)
+		assert.Len(t, entries, 3)
+		assert.Equal(t, int64(3), total)
+	})
+	t.Run("no access to task", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		te := &TaskTimeEntry{TaskID: 14}
+		_, _, _, err := te.ReadAll(s, &user.User{ID: 1}, "", 0, 50)
+		assert.Error(t, err)
+		assert.True(t, IsErrGenericForbidden(err))
+	})
+}
+
+func TestTaskTimeEntry_Update(t *testing.T) {
+	t.Run("own entry", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		u := &user.User{ID: 1}
+		start := time.Date(2021, 9, 12, 10, 0, 0, 0, time.Local)
+		te := &TaskTimeEntry{
+			ID:     1,
+			TaskID: 1,
+			Start:  start,
+			End:    start.Add(2 * time.Hour),
+			Note:   "Updated",
+		}
+		can, err := te.CanUpdate(s, u)
+		assert.NoError(t, err)
+		assert.True(t, can)
+		err = te.Update(s, u)
+		assert.NoError(t, err)
+		err = s.Commit()
+		assert.NoError(t, err)
+		db.AssertExists(t, "task_time_entries", map[string]interface{}{
+			"id":       1,
+			"duration": 7200,
+			"note":     "Updated",
+		}, false)
+	})
+	t.Run("entry of another user", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		te := &TaskTimeEntry{ID: 2, TaskID: 1}
+		can, err := te.CanUpdate(s, &user.User{ID: 1})
+		assert.NoError(t, err)
+		assert.False(t, can)
+	})
+	t.Run("nonexisting", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		te := &TaskTimeEntry{ID: 9999, TaskID: 1}
+		_, err := te.CanUpdate(s, &user.User{ID: 1})
+		assert.Error(t, err)
+		assert.True(t, IsErrTaskTimeEntryDoesNotExist(err))
+	})
+}
+
+func TestTaskTimeEntry_Delete(t *testing.T) {
+	t.Run("own entry", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		u := &user.User{ID: 1}
+		te := &TaskTimeEntry{ID: 1, TaskID: 1}
+		can, err := te.CanDelete(s, u)
+		assert.NoError(t, err)
+		assert.True(t, can)
+		err = te.Delete(s, u)
+		assert.NoError(t, err)
+		err = s.Commit()
+		assert.NoError(t, err)
+		db.AssertMissing(t, "task_time_entries", map[string]interface{}{
+			"id": 1,
+		})
+	})
+	t.Run("entry of another user", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		te := &TaskTimeEntry{ID: 2, TaskID: 1}
+		can, err := te.CanDelete(s, &user.User{ID: 1})
+		assert.NoError(t, err)
+		assert.False(t, can)
+	})
+}
+
+// assertTimerRunning checks whether the time entry has no end time, a nil value in AssertExists would not
+// match NULL columns.
+func assertTimerRunning(t *testing.T, id int64, running bool) {
+	s := db.NewSession()
+	defer s.Close()
+
+	exists, err := s.
+		Where(builder.And(builder.Eq{"id": id}, builder.IsNull{"end_time"})).
+		Exist(&TaskTimeEntry{})
+	assert.NoError(t, err)
+	assert.Equal(t, running, exists)
+}
+
+func TestTaskTimer(t *testing.T) {
+	u := &user.User{ID: 1}
+
+	t.Run("start stops the running timer", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		timer := &TaskTimerStart{TaskID: 2}
+		err := timer.Create(s, u)
+		assert.NoError(t, err)
+		assert.True(t, timer.End.IsZero())
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		db.AssertExists(t, "task_time_entries", map[string]interface{}{
+			"id":      timer.ID,
+			"task_id": 2,
+			"user_id": 1,
+		}, false)
+		assertTimerRunning(t, timer.ID, true)
+		assertTimerRunning(t, 3, false)
+	})
+	t.Run("stop", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		timer := &TaskTimerStop{TaskID: 1}
+		err := timer.Create(s, u)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), timer.ID)
+		assert.False(t, timer.End.IsZero())
+		err = s.Commit()
+		assert.NoError(t, err)
+		assertTimerRunning(t, 3, false)
+	})
+	t.Run("stop without running timer", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		timer := &TaskTimerStop{TaskID: 2}
+		err := timer.Create(s, u)
+		assert.Error(t, err)
+		assert.True(t, IsErrNoRunningTaskTimer(err))
+	})
+}
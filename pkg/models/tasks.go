@@ -28,6 +28,7 @@ import (
 	"code.vikunja.io/api/pkg/db"
 	"code.vikunja.io/api/pkg/events"
 	"code.vikunja.io/api/pkg/log"
+	"code.vikunja.io/api/pkg/modules/rrule"
 	"code.vikunja.io/api/pkg/user"
 	"code.vikunja.io/api/pkg/utils"
 	"code.vikunja.io/web"
@@ -67,6 +68,8 @@ type Task struct {
 	RepeatAfter int64 `xorm:"bigint INDEX null" json:"repeat_after"`
 	// Can have three possible values which will trigger when the task is marked as done: 0 = repeats after the amount specified in repeat_after, 1 = repeats all dates each months (ignoring repeat_after), 3 = repeats from the current date rather than the last set date.
 	RepeatMode TaskRepeatMode `xorm:"not null default 0" json:"repeat_mode"`
+	// A recurrence rule as defined in RFC 5545, for example "FREQ=WEEKLY;INTERVAL=2;BYDAY=TU" to repeat the task every second tuesday. If this is set, it takes precedence over repeat_after and repeat_mode: when marking the task as done, the due date will be moved to the next occurrence of the rule and all other dates and reminders keep their distance to it.
+	RepeatRule string `xorm:"varchar(500) null" json:"repeat_rule"`
 	// The task priority. Can be anything you want, it is possible to sort by this later.
 	Priority int64 `xorm:"bigint null" json:"priority"`
 	// When this task starts.
@@ -96,6 +99,14 @@ type Task struct {
 	// All attachments this task has
 	Attachments []*TaskAttachment `xorm:"-" json:"attachments"`
 
+	// The sum of all finished time entries of all users on this task in seconds. You can only read this property, use the time tracking endpoints to modify it.
+	TimeTracked int64 `xorm:"-" json:"time_tracked"`
+
+	// The values of all custom fields of this task, with the id of the custom field as key. Only fields of the list
+	// or namespace of this task can be set. Pass null as the value of a field to remove it. If this property is
+	// omitted when updating a task, all values stay the same.
+	CustomFields map[int64]interface{} `xorm:"-" json:"custom_fields"`
+
 	// True if a task is a favorite task. Favorite tasks show up in a separate "Important" list. This value depends on the user making the call to the api.
 	IsFavorite bool `xorm:"-" json:"is_favorite"`
 
@@ -107,6 +118,8 @@ type Task struct {
 	Created time.Time `xorm:"created not null" json:"created"`
 	// A timestamp when this task was last updated. You cannot change this value.
 	Updated time.Time `xorm:"updated not null" json:"updated"`
+	// A timestamp when this task was moved to the trash. Deleted tasks are hidden everywhere until they are restored or purged.
+	Deleted time.Time `xorm:"deleted null" json:"-"`
 
 	// BucketID is the ID of the kanban bucket this task belongs to.
 	BucketID int64 `xorm:"bigint null" json:"bucket_id"`
@@ -131,7 +144,10 @@ type Task struct {
 
 type TaskWithComments struct {
 	Task
-	Comments []*TaskComment `xorm:"-" json:"comments"`
+	Comments    []*TaskComment   `xorm:"-" json:"comments"`
+	TimeEntries []*TaskTimeEntry `xorm:"-" json:"time_entries"`
+	// The change history of the task. Only used for the data export, it is not imported again.
+	History []*TaskChange `xorm:"-" json:"history"`
 }
 
 // TableName returns the table name for listtasks
@@ -167,6 +183,7 @@ type taskOptions struct {
 	filters            []*taskFilter
 	filterConcat       taskFilterConcatinator
 	filterIncludeNulls bool
+	filterExpression   *taskFilterExpression
 }
 
 // ReadAll is a dummy function to still have that endpoint documented
@@ -178,13 +195,14 @@ type taskOptions struct {
 // @Param page query int false "The page number. Used for pagination. If not provided, the first page of results is returned."
 // @Param per_page query int false "The maximum number of items per page. Note this parameter is limited by the configured maximum of items per page."
 // @Param s query string false "Search tasks by task text."
-// @Param sort_by query string false "The sorting parameter. You can pass this multiple times to get the tasks ordered by multiple different parametes, along with `order_by`. Possible values to sort by are `id`, `title`, `description`, `done`, `done_at`, `due_date`, `created_by_id`, `list_id`, `repeat_after`, `priority`, `start_date`, `end_date`, `hex_color`, `percent_done`, `uid`, `created`, `updated`. Default is `id`."
+// @Param sort_by query string false "The sorting parameter. You can pass this multiple times to get the tasks ordered by multiple different parametes, along with `order_by`. Possible values to sort by are `id`, `title`, `description`, `done`, `done_at`, `due_date`, `created_by_id`, `list_id`, `repeat_after`, `priority`, `start_date`, `end_date`, `hex_color`, `percent_done`, `uid`, `created`, `updated`, `time_tracked`, `last_commented`, `assignees` (the first username), `labels` (the first label title) and `custom_field_<id>` to sort by the value of a custom field. Default is `id`."
 // @Param order_by query string false "The ordering parameter. Possible values to order by are `asc` or `desc`. Default is `asc`."
-// @Param filter_by query string false "The name of the field to filter by. Allowed values are all task properties. Task properties which are their own object require passing in the id of that entity. Accepts an array for multiple filters which will be chanied together, all supplied filter must match."
+// @Param filter_by query string false "The name of the field to filter by. Allowed values are all task properties. Task properties which are their own object require passing in the id of that entity. Additionally, `blocked` (blocked by an undone task), `has_subtasks` and `has_attachments` accept `true` or `false` and `last_commented` accepts a date. Accepts an array for multiple filters which will be chanied together, all supplied filter must match."
 // @Param filter_value query string false "The value to filter for."
 // @Param filter_comparator query string false "The comparator to use for a filter. Available values are `equals`, `greater`, `greater_equals`, `less`, `less_equals`, `like` and `in`. `in` expects comma-separated values in `filter_value`. Defaults to `equals`"
 // @Param filter_concat query string false "The concatinator to use for filters. Available values are `and` or `or`. Defaults to `or`."
 // @Param filter_include_nulls query string false "If set to true the result will include filtered fields whose value is set to `null`. Available values are `true` or `false`. Defaults to `false`."
+// @Param filter query string false "A filter query like `(priority >= 3 && assignees in me) || labels in urgent`. See the filter documentation for the full syntax. Combined with all other filter parameters using `and`."
 // @Security JWTKeyAuth
 // @Success 200 {array} models.Task "The tasks"
 // @Failure 500 {object} models.Message "Internal error"
@@ -195,6 +213,12 @@ func (t *Task) ReadAll(s *xorm.Session, a web.Auth, search string, page int, per
 
 func getFilterCond(f *taskFilter, includeNulls bool) (cond builder.Cond, err error) {
 	field := "`" + f.field + "`"
+	switch f.field {
+	case taskPropertyTimeTracked:
+		field = taskTimeTrackedSubquery
+	case taskPropertyLastCommented:
+		field = taskLastCommentedSubquery
+	}
 	switch f.comparator {
 	case taskFilterComparatorEquals:
 		cond = &builder.Eq{field: f.value}
@@ -296,7 +320,24 @@ func getRawTasksForLists(s *xorm.Session, lists []*List, a web.Auth, opts *taskO
 		if err := param.validate(); err != nil {
 			return nil, 0, 0, err
 		}
-		orderby += param.sortBy + " " + param.orderBy.String()
+		switch fieldID, isCustomField := getCustomFieldIDFromTaskProperty(param.sortBy); {
+		case param.sortBy == taskPropertyTimeTracked:
+			orderby += taskTimeTrackedSubquery + " " + param.orderBy.String()
+		case param.sortBy == taskPropertyLastCommented:
+			orderby += taskLastCommentedSubquery + " " + param.orderBy.String()
+		case param.sortBy == taskPropertyAssignees:
+			orderby += taskAssigneesSortSubquery + " " + param.orderBy.String()
+		case param.sortBy == taskPropertyLabels:
+			orderby += taskLabelsSortSubquery + " " + param.orderBy.String()
+		case isCustomField:
+			subquery, err := getCustomFieldSortSubquery(s, fieldID)
+			if err != nil {
+				return nil, 0, 0, err
+			}
+			orderby += subquery + " " + param.orderBy.String()
+		default:
+			orderby += param.sortBy + " " + param.orderBy.String()
+		}
 
 		// Postgres sorts by default entries with null values after ones with values.
 		// To make that consistent with the sort order we have and other dbms, we're adding a separate clause here.
@@ -363,6 +404,24 @@ func getRawTasksForLists(s *xorm.Session, lists []*List, a web.Auth, opts *taskO
 			continue
 		}
 
+		if subquery, is := getTaskBoolRelationSubquery(f.field); is {
+			filter, err := getTaskBoolRelationFilterCond(f, subquery)
+			if err != nil {
+				return nil, 0, 0, err
+			}
+			filters = append(filters, filter)
+			continue
+		}
+
+		if fieldID, is := getCustomFieldIDFromTaskProperty(f.field); is {
+			filter, err := getCustomFieldFilterCond(s, fieldID, f, opts.filterIncludeNulls)
+			if err != nil {
+				return nil, 0, 0, err
+			}
+			filters = append(filters, filter)
+			continue
+		}
+
 		filter, err := getFilterCond(f, opts.filterIncludeNulls)
 		if err != nil {
 			return nil, 0, 0, err
@@ -461,6 +520,14 @@ func getRawTasksForLists(s *xorm.Session, lists []*List, a web.Auth, opts *taskO
 		}
 	}
 
+	if opts.filterExpression != nil {
+		expressionCond, err := opts.filterExpression.toCond(s, a, opts.filterIncludeNulls)
+		if err != nil {
+			return nil, 0, 0, err
+		}
+		filterCond = builder.And(filterCond, expressionCond)
+	}
+
 	limit, start := getLimitFromPageIndex(opts.page, opts.perPage)
 	cond := builder.And(listCond, where, filterCond)
 
@@ -728,6 +795,16 @@ func addMoreInfoToTasks(s *xorm.Session, taskMap map[int64]*Task, a web.Auth) (e
 		return err
 	}
 
+	taskTimeTracked, err := getTimeTrackedForTasks(s, taskIDs)
+	if err != nil {
+		return err
+	}
+
+	taskCustomFields, err := getCustomFieldValuesForTasks(s, taskIDs)
+	if err != nil {
+		return err
+	}
+
 	// Get all identifiers
 	lists, err := GetListsByIDs(s, listIDs)
 	if err != nil {
@@ -750,6 +827,10 @@ func addMoreInfoToTasks(s *xorm.Session, taskMap map[int64]*Task, a web.Auth) (e
 		task.setIdentifier(lists[task.ListID])
 
 		task.IsFavorite = taskFavorites[task.ID]
+
+		task.TimeTracked = taskTimeTracked[task.ID]
+
+		task.CustomFields = taskCustomFields[task.ID]
 	}
 
 	// Get all related tasks
@@ -869,6 +950,10 @@ func createTask(s *xorm.Session, t *Task, a web.Auth, updateAssignees bool) (err
 		return ErrTaskCannotBeEmpty{}
 	}
 
+	if err := validateTaskRepeatRule(t.RepeatRule); err != nil {
+		return err
+	}
+
 	// Check if the list exists
 	l, err := GetListSimpleByID(s, t.ListID)
 	if err != nil {
@@ -893,8 +978,9 @@ func createTask(s *xorm.Session, t *Task, a web.Auth, updateAssignees bool) (err
 	}
 
 	// Get the index for this task
+	// Tasks in the trash are included to make sure the index is still unique once they are restored.
 	latestTask := &Task{}
-	_, err = s.Where("list_id = ?", t.ListID).OrderBy("id desc").Get(latestTask)
+	_, err = s.Unscoped().Where("list_id = ?", t.ListID).OrderBy("id desc").Get(latestTask)
 	if err != nil {
 		return err
 	}
@@ -921,6 +1007,13 @@ func createTask(s *xorm.Session, t *Task, a web.Auth, updateAssignees bool) (err
 		return err
 	}
 
+	if err := t.updateCustomFieldValues(s); err != nil {
+		return err
+	}
+	if err := t.reloadCustomFieldValues(s); err != nil {
+		return err
+	}
+
 	t.setIdentifier(l)
 
 	if t.IsFavorite {
@@ -968,6 +1061,10 @@ func (t *Task) Update(s *xorm.Session, a web.Auth) (err error) {
 		t.ListID = ot.ListID
 	}
 
+	if err := validateTaskRepeatRule(t.RepeatRule); err != nil {
+		return err
+	}
+
 	// Get the reminders
 	reminders, err := getRemindersForTasks(s, []int64{t.ID})
 	if err != nil {
@@ -979,6 +1076,11 @@ func (t *Task) Update(s *xorm.Session, a web.Auth) (err error) {
 		ot.Reminders[i] = r.Reminder
 	}
 
+	// Keep the original values to be able to save all changes in the history. The reminders are modified in place
+	// when a repeating task is marked done, so they need their own copy.
+	originalTask := ot
+	originalTask.Reminders = append([]time.Time(nil), ot.Reminders...)
+
 	// When a repeating task is marked as done, we update all deadlines and reminders and set it as undone
 	updateDone(&ot, t)
 
@@ -996,6 +1098,16 @@ func (t *Task) Update(s *xorm.Session, a web.Auth) (err error) {
 		return err
 	}
 
+	// Update the custom fields
+	if err := t.updateCustomFieldValues(s); err != nil {
+		return err
+	}
+	if t.CustomFields == nil && ot.ListID != t.ListID {
+		if err := t.removeUnavailableCustomFieldValues(s); err != nil {
+			return err
+		}
+	}
+
 	// All columns to update in a separate variable to be able to add to them
 	colsToUpdate := []string{
 		"title",
@@ -1013,13 +1125,14 @@ func (t *Task) Update(s *xorm.Session, a web.Auth) (err error) {
 		"bucket_id",
 		"position",
 		"repeat_mode",
+		"repeat_rule",
 		"kanban_position",
 	}
 
 	// If the task is being moved between lists, make sure to move the bucket + index as well
 	if t.ListID != 0 && ot.ListID != t.ListID {
 		latestTask := &Task{}
-		_, err = s.Where("list_id = ?", t.ListID).OrderBy("id desc").Get(latestTask)
+		_, err = s.Unscoped().Where("list_id = ?", t.ListID).OrderBy("id desc").Get(latestTask)
 		if err != nil {
 			return err
 		}
@@ -1119,6 +1232,10 @@ func (t *Task) Update(s *xorm.Session, a web.Auth) (err error) {
 	if t.RepeatMode == TaskRepeatModeDefault {
 		ot.RepeatMode = TaskRepeatModeDefault
 	}
+	// Repeat rule
+	if t.RepeatRule == "" {
+		ot.RepeatRule = ""
+	}
 	// Is Favorite
 	if !t.IsFavorite {
 		ot.IsFavorite = false
@@ -1131,6 +1248,10 @@ func (t *Task) Update(s *xorm.Session, a web.Auth) (err error) {
 	if err != nil {
 		return err
 	}
+
+	if err := recordTaskUpdateChanges(s, a, &originalTask, t); err != nil {
+		return err
+	}
 	// Get the task updated timestamp in a new struct - if we'd just try to put it into t which we already have, it
 	// would still contain the old updated date.
 	nt := &Task{}
@@ -1140,6 +1261,10 @@ func (t *Task) Update(s *xorm.Session, a web.Auth) (err error) {
 	}
 	t.Updated = nt.Updated
 
+	if err := t.reloadCustomFieldValues(s); err != nil {
+		return err
+	}
+
 	doer, _ := user.GetFromAuth(a)
 	err = events.Dispatch(&TaskUpdatedEvent{
 		Task: t,
@@ -1152,6 +1277,17 @@ func (t *Task) Update(s *xorm.Session, a web.Auth) (err error) {
 	return updateListLastUpdated(s, &List{ID: t.ListID})
 }
 
+func validateTaskRepeatRule(rule string) error {
+	if rule == "" {
+		return nil
+	}
+
+	if _, err := rrule.Parse(rule); err != nil {
+		return ErrInvalidTaskRepeatRule{Rule: rule, Reason: err.Error()}
+	}
+	return nil
+}
+
 func addOneMonthToDate(d time.Time) time.Time {
 	return time.Date(d.Year(), d.Month()+1, d.Day(), d.Hour(), d.Minute(), d.Second(), d.Nanosecond(), config.GetTimeZone())
 }
@@ -1283,6 +1419,74 @@ func setTaskDatesFromCurrentDateRepeat(oldTask, newTask *Task) {
 	newTask.Done = false
 }
 
+func setTaskDatesFromRepeatRule(oldTask, newTask *Task) {
+	rule, err := rrule.Parse(oldTask.RepeatRule)
+	if err != nil {
+		log.Errorf("Could not parse repeat rule '%s' of task %d: %s", oldTask.RepeatRule, oldTask.ID, err)
+		return
+	}
+
+	// Current time in an extra variable to base all calculations on the same time
+	now := time.Now()
+
+	// The series starts at the first date the task has. All other dates keep their distance to it.
+	seriesStart := now
+	switch {
+	case !oldTask.DueDate.IsZero():
+		seriesStart = oldTask.DueDate
+	case !oldTask.StartDate.IsZero():
+		seriesStart = oldTask.StartDate
+	case !oldTask.EndDate.IsZero():
+		seriesStart = oldTask.EndDate
+	case len(oldTask.Reminders) > 0:
+		seriesStart = oldTask.Reminders[0]
+		for _, r := range oldTask.Reminders {
+			if r.Before(seriesStart) {
+				seriesStart = r
+			}
+		}
+	}
+
+	// Because the start of the series moves with every occurrence, the number of occurrences is
+	// converted to a fixed end date the first time the task repeats.
+	if rule.Count > 0 {
+		rule = rule.WithoutCount(seriesStart)
+		newTask.RepeatRule = rule.String()
+	}
+
+	// Like the default repeat mode, the next occurrence is always in the future
+	after := now
+	if seriesStart.After(now) {
+		after = seriesStart
+	}
+
+	next, exists := rule.Next(seriesStart, after)
+	if !exists {
+		// The series ended, the task stays done
+		return
+	}
+	diff := next.Sub(seriesStart)
+
+	if !oldTask.DueDate.IsZero() {
+		newTask.DueDate = oldTask.DueDate.Add(diff)
+	}
+
+	if !oldTask.StartDate.IsZero() {
+		newTask.StartDate = oldTask.StartDate.Add(diff)
+	}
+
+	if !oldTask.EndDate.IsZero() {
+		newTask.EndDate = oldTask.EndDate.Add(diff)
+	}
+
+	newTask.Reminders = oldTask.Reminders
+	for in, r := range oldTask.Reminders {
+		newTask.Reminders[in] = r.Add(diff)
+	}
+
+	newTask.Done = false
+}
+
 // This helper function updates the reminders, doneAt, start and end dates of the *old* task
 // and saves the new values in the newTask object.
 // We make a few assumtions here:
@@ -1290,12 +1494,14 @@ func setTaskDatesFromCurrentDateRepeat(oldTask, newTask *Task) {
 //   2. Because of 1., this functions should not be used to update values other than Done in the same go
 func updateDone(oldTask *Task, newTask *Task) {
 	if !oldTask.Done && newTask.Done {
-		switch oldTask.RepeatMode {
-		case TaskRepeatModeMonth:
+		switch {
+		case oldTask.RepeatRule != "":
+			setTaskDatesFromRepeatRule(oldTask, newTask)
+		case oldTask.RepeatMode == TaskRepeatModeMonth:
 			setTaskDatesMonthRepeat(oldTask, newTask)
-		case TaskRepeatModeFromCurrentDate:
+		case oldTask.RepeatMode == TaskRepeatModeFromCurrentDate:
 			setTaskDatesFromCurrentDateRepeat(oldTask, newTask)
-		case TaskRepeatModeDefault:
+		case oldTask.RepeatMode == TaskRepeatModeDefault:
 			setTaskDatesDefault(oldTask, newTask)
 		}
 
@@ -1351,8 +1557,34 @@ func (t *Task) updateReminders(s *xorm.Session, reminders []time.Time) (err erro
 // @Failure 500 {object} models.Message "Internal error"
 // @Router /tasks/{id} [delete]
 func (t *Task) Delete(s *xorm.Session, a web.Auth) (err error) {
+	return t.moveToTrash(s, a, utils.GetTimeWithoutNanoSeconds(time.Now()))
+}
+
+// moveToTrash marks a task as deleted. Everything related to the task is kept until it is purged from the trash
+// so that it can be restored with all its labels, assignees, relations and attachments.
+func (t *Task) moveToTrash(s *xorm.Session, a web.Auth, now time.Time) (err error) {
+	err = markAsDeleted(s, "tasks", builder.Eq{"id": t.ID}, now)
+	if err != nil {
+		return err
+	}
+
+	doer, _ := user.GetFromAuth(a)
+	err = events.Dispatch(&TaskDeletedEvent{
+		Task: t,
+		Doer: doer,
+	})
+	if err != nil {
+		return
+	}
+
+	err = updateListLastUpdated(s, &List{ID: t.ListID})
+	return
+}
+
+// purge permanently removes a task and everything related to it.
+func (t *Task) purge(s *xorm.Session) (err error) {
 
-	if _, err = s.ID(t.ID).Delete(Task{}); err != nil {
+	if _, err = s.Unscoped().ID(t.ID).Delete(Task{}); err != nil {
 		return err
 	}
 
@@ -1362,7 +1594,7 @@ func (t *Task) Delete(s *xorm.Session, a web.Auth) (err error) {
 	}
 
 	// Delete Favorites
-	err = removeFromFavorite(s, t.ID, a, FavoriteKindTask)
+	_, err = s.Where("entity_id = ? AND kind = ?", t.ID, FavoriteKindTask).Delete(&Favorite{})
 	if err != nil {
 		return
 	}
@@ -1380,7 +1612,7 @@ func (t *Task) Delete(s *xorm.Session, a web.Auth) (err error) {
 	}
 	for _, attachment := range attachments {
 		// Using the attachment delete method here because that takes care of removing all files properly
-		err = attachment.Delete(s, a)
+		err = attachment.Delete(s, nil)
 		if err != nil && !IsErrTaskAttachmentDoesNotExist(err) {
 			return err
 		}
@@ -1404,16 +1636,20 @@ func (t *Task) Delete(s *xorm.Session, a web.Auth) (err error) {
 		return
 	}
 
-	doer, _ := user.GetFromAuth(a)
-	err = events.Dispatch(&TaskDeletedEvent{
-		Task: t,
-		Doer: doer,
-	})
+	// Delete all time entries
+	_, err = s.Where("task_id = ?", t.ID).Delete(&TaskTimeEntry{})
 	if err != nil {
 		return
 	}
 
-	err = updateListLastUpdated(s, &List{ID: t.ListID})
+	// Delete all custom field values
+	_, err = s.Where("task_id = ?", t.ID).Delete(&TaskCustomFieldValue{})
+	if err != nil {
+		return
+	}
+
+	// Delete the change history
+	_, err = s.Where("task_id = ?", t.ID).Delete(&TaskChange{})
 	return
 }
 
@@ -170,6 +170,42 @@ func TestTask_Update(t *testing.T) {
 		assert.Error(t, err)
 		assert.True(t, IsErrTaskDoesNotExist(err))
 	})
+	t.Run("repeat rule", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		task := &Task{
+			ID:         1,
+			Title:      "test10000",
+			ListID:     1,
+			RepeatRule: "FREQ=WEEKLY;INTERVAL=2;BYDAY=TU",
+		}
+		err := task.Update(s, u)
+		assert.NoError(t, err)
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		db.AssertExists(t, "tasks", map[string]interface{}{
+			"id":          1,
+			"repeat_rule": "FREQ=WEEKLY;INTERVAL=2;BYDAY=TU",
+		}, false)
+	})
+	t.Run("invalid repeat rule", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		task := &Task{
+			ID:         1,
+			Title:      "test10000",
+			ListID:     1,
+			RepeatRule: "FREQ=FORTNIGHTLY",
+		}
+		err := task.Update(s, u)
+		assert.Error(t, err)
+		assert.True(t, IsErrInvalidTaskRepeatRule(err))
+	})
 	t.Run("full bucket", func(t *testing.T) {
 		db.LoadAndAssertFixtures(t)
 		s := db.NewSession()
@@ -337,12 +373,22 @@ func TestTask_Delete(t *testing.T) {
 		}
 		err := task.Delete(s, &user.User{ID: 1})
 		assert.NoError(t, err)
-		err = s.Commit()
+
+		_, err = GetTaskByIDSimple(s, 1)
+		assert.Error(t, err)
+		assert.True(t, IsErrTaskDoesNotExist(err))
+
+		// The task is only moved to the trash
+		trashed, err := getTaskIncludingTrashed(s, 1)
 		assert.NoError(t, err)
+		assert.False(t, trashed.Deleted.IsZero())
 
-		db.AssertMissing(t, "tasks", map[string]interface{}{
-			"id": 1,
-		})
+		_, err = GetListSimplByTaskID(s, 1)
+		assert.Error(t, err)
+		assert.True(t, IsErrTaskDoesNotExist(err))
+		db.AssertExists(t, "label_tasks", map[string]interface{}{
+			"task_id": 1,
+		}, false)
 	})
 }
 
@@ -691,6 +737,91 @@ func TestUpdateDone(t *testing.T) {
 				assert.False(t, newTask.Done)
 			})
 		})
+		t.Run("repeat rule", func(t *testing.T) {
+			t.Run("every second tuesday", func(t *testing.T) {
+				oldTask := &Task{
+					Done:       false,
+					RepeatRule: "FREQ=WEEKLY;INTERVAL=2;BYDAY=TU",
+					DueDate:    time.Date(2100, 9, 14, 9, 0, 0, 0, time.UTC),
+					StartDate:  time.Date(2100, 9, 13, 9, 0, 0, 0, time.UTC),
+					Reminders: []time.Time{
+						time.Date(2100, 9, 14, 8, 0, 0, 0, time.UTC),
+					},
+				}
+				newTask := &Task{
+					Done: true,
+				}
+
+				updateDone(oldTask, newTask)
+
+				assert.Equal(t, time.Date(2100, 9, 28, 9, 0, 0, 0, time.UTC), newTask.DueDate)
+				assert.Equal(t, time.Date(2100, 9, 27, 9, 0, 0, 0, time.UTC), newTask.StartDate)
+				assert.Equal(t, []time.Time{time.Date(2100, 9, 28, 8, 0, 0, 0, time.UTC)}, newTask.Reminders)
+				assert.False(t, newTask.Done)
+			})
+			t.Run("last weekday of the month", func(t *testing.T) {
+				oldTask := &Task{
+					Done:       false,
+					RepeatRule: "FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1",
+					DueDate:    time.Date(2100, 9, 30, 9, 0, 0, 0, time.UTC),
+				}
+				newTask := &Task{
+					Done: true,
+				}
+
+				updateDone(oldTask, newTask)
+
+				assert.Equal(t, time.Date(2100, 10, 29, 9, 0, 0, 0, time.UTC), newTask.DueDate)
+				assert.False(t, newTask.Done)
+			})
+			t.Run("due date in the past", func(t *testing.T) {
+				oldTask := &Task{
+					Done:       false,
+					RepeatRule: "FREQ=DAILY",
+					DueDate:    time.Unix(1550000000, 0),
+				}
+				newTask := &Task{
+					Done: true,
+				}
+
+				updateDone(oldTask, newTask)
+
+				assert.True(t, newTask.DueDate.After(time.Now()))
+				assert.True(t, newTask.DueDate.Before(time.Now().Add(24*time.Hour)))
+				assert.False(t, newTask.Done)
+			})
+			t.Run("count", func(t *testing.T) {
+				oldTask := &Task{
+					Done:       false,
+					RepeatRule: "FREQ=DAILY;COUNT=2",
+					DueDate:    time.Date(2100, 9, 14, 9, 0, 0, 0, time.UTC),
+				}
+				newTask := &Task{
+					Done: true,
+				}
+
+				updateDone(oldTask, newTask)
+
+				assert.Equal(t, time.Date(2100, 9, 15, 9, 0, 0, 0, time.UTC), newTask.DueDate)
+				assert.Equal(t, "FREQ=DAILY;UNTIL=21000915T090000Z", newTask.RepeatRule)
+				assert.False(t, newTask.Done)
+
+				// The series ends after the second occurrence
+				oldTask = &Task{
+					Done:       false,
+					RepeatRule: newTask.RepeatRule,
+					DueDate:    newTask.DueDate,
+				}
+				newTask = &Task{
+					Done: true,
+				}
+
+				updateDone(oldTask, newTask)
+
+				assert.True(t, newTask.DueDate.IsZero())
+				assert.True(t, newTask.Done)
+			})
+		})
 	})
 }
 
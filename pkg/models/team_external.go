@@ -0,0 +1,100 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"code.vikunja.io/api/pkg/user"
+
+	"xorm.io/builder"
+	"xorm.io/xorm"
+)
+
+// ExternalTeam is a team as it exists in an external auth provider, for example a group in an LDAP directory.
+type ExternalTeam struct {
+	// The id of the team in the external auth provider. Must be unique per issuer.
+	ExternalID string
+	// The name the team should have in Vikunja.
+	Name string
+}
+
+func getExternalTeam(s *xorm.Session, issuer, externalID string) (team *Team, exists bool, err error) {
+	team = &Team{}
+	exists, err = s.
+		Where("issuer = ? AND external_id = ?", issuer, externalID).
+		Get(team)
+	return
+}
+
+// SyncExternalTeamsForUser makes sure a user is a member of exactly the teams of an issuer they are a member of
+// in the external auth provider. Teams which don't exist yet are created. Memberships in teams of the same issuer
+// the user is not part of anymore in the external auth provider are removed.
+// Teams managed in Vikunja or by other issuers are not touched.
+func SyncExternalTeamsForUser(s *xorm.Session, u *user.User, issuer string, externalTeams []*ExternalTeam) (err error) {
+	teamIDs := make([]int64, 0, len(externalTeams))
+	for _, et := range externalTeams {
+		team, exists, err := getExternalTeam(s, issuer, et.ExternalID)
+		if err != nil {
+			return err
+		}
+
+		if !exists {
+			team = &Team{
+				Name:        et.Name,
+				CreatedByID: u.ID,
+				ExternalID:  et.ExternalID,
+				Issuer:      issuer,
+			}
+			if _, err := s.Insert(team); err != nil {
+				return err
+			}
+		}
+
+		if exists && team.Name != et.Name {
+			team.Name = et.Name
+			if _, err := s.Where("id = ?", team.ID).Cols("name").Update(team); err != nil {
+				return err
+			}
+		}
+
+		teamIDs = append(teamIDs, team.ID)
+
+		isMember, err := s.
+			Where("team_id = ? AND user_id = ?", team.ID, u.ID).
+			Exist(&TeamMember{})
+		if err != nil {
+			return err
+		}
+		if isMember {
+			continue
+		}
+
+		if _, err := s.Insert(&TeamMember{TeamID: team.ID, UserID: u.ID}); err != nil {
+			return err
+		}
+	}
+
+	cond := builder.And(
+		builder.Eq{"user_id": u.ID},
+		builder.In("team_id", builder.Select("id").From("teams").Where(builder.Eq{"issuer": issuer})),
+	)
+	if len(teamIDs) > 0 {
+		cond = builder.And(cond, builder.NotIn("team_id", teamIDs))
+	}
+
+	_, err = s.Where(cond).Delete(&TeamMember{})
+	return
+}
@@ -0,0 +1,133 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"testing"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/user"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSyncExternalTeamsForUser(t *testing.T) {
+	t.Run("create team and membership", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		err := SyncExternalTeamsForUser(s, &user.User{ID: 1}, "https://some.issuer", []*ExternalTeam{
+			{ExternalID: "external-team", Name: "External Team"},
+		})
+		assert.NoError(t, err)
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		team := &Team{}
+		exists, err := s.Where("external_id = ?", "external-team").Get(team)
+		assert.NoError(t, err)
+		assert.True(t, exists)
+		assert.Equal(t, "External Team", team.Name)
+		assert.Equal(t, "https://some.issuer", team.Issuer)
+		db.AssertExists(t, "team_members", map[string]interface{}{
+			"team_id": team.ID,
+			"user_id": 1,
+		}, false)
+	})
+	t.Run("reuse existing team", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		externalTeams := []*ExternalTeam{{ExternalID: "external-team", Name: "External Team"}}
+		err := SyncExternalTeamsForUser(s, &user.User{ID: 1}, "https://some.issuer", externalTeams)
+		assert.NoError(t, err)
+		err = SyncExternalTeamsForUser(s, &user.User{ID: 2}, "https://some.issuer", externalTeams)
+		assert.NoError(t, err)
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		count, err := s.Where("external_id = ?", "external-team").Count(&Team{})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+	})
+	t.Run("remove membership", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		err := SyncExternalTeamsForUser(s, &user.User{ID: 1}, "https://some.issuer", []*ExternalTeam{
+			{ExternalID: "external-team", Name: "External Team"},
+		})
+		assert.NoError(t, err)
+		err = SyncExternalTeamsForUser(s, &user.User{ID: 1}, "https://some.issuer", []*ExternalTeam{})
+		assert.NoError(t, err)
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		team := &Team{}
+		_, err = s.Where("external_id = ?", "external-team").Get(team)
+		assert.NoError(t, err)
+		db.AssertMissing(t, "team_members", map[string]interface{}{
+			"team_id": team.ID,
+			"user_id": 1,
+		})
+		// Memberships in teams managed in Vikunja are not touched
+		db.AssertExists(t, "team_members", map[string]interface{}{
+			"team_id": 1,
+			"user_id": 1,
+		}, false)
+	})
+}
+
+func TestTeamMember_CanEditExternallyManagedTeam(t *testing.T) {
+	t.Run("externally managed team", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		err := SyncExternalTeamsForUser(s, &user.User{ID: 1}, "https://some.issuer", []*ExternalTeam{
+			{ExternalID: "external-team", Name: "External Team"},
+		})
+		assert.NoError(t, err)
+		team, _, err := getExternalTeam(s, "https://some.issuer", "external-team")
+		assert.NoError(t, err)
+		_, err = s.Where("team_id = ? AND user_id = ?", team.ID, 1).Cols("admin").Update(&TeamMember{Admin: true})
+		assert.NoError(t, err)
+
+		tm := &TeamMember{TeamID: team.ID, Username: "user3"}
+		can, err := tm.CanCreate(s, &user.User{ID: 1})
+		assert.Error(t, err)
+		assert.True(t, IsErrTeamIsExternallyManaged(err))
+		assert.False(t, can)
+
+		can, err = tm.CanDelete(s, &user.User{ID: 1})
+		assert.Error(t, err)
+		assert.True(t, IsErrTeamIsExternallyManaged(err))
+		assert.False(t, can)
+	})
+	t.Run("team managed in vikunja", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		tm := &TeamMember{TeamID: 1, Username: "user3"}
+		can, err := tm.CanCreate(s, &user.User{ID: 1})
+		assert.NoError(t, err)
+		assert.True(t, can)
+	})
+}
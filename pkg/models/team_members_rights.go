@@ -23,12 +23,34 @@ import (
 
 // CanCreate checks if the user can add a new tem member
 func (tm *TeamMember) CanCreate(s *xorm.Session, a web.Auth) (bool, error) {
-	return tm.IsAdmin(s, a)
+	return tm.canEditMembers(s, a)
 }
 
 // CanDelete checks if the user can delete a new team member
 func (tm *TeamMember) CanDelete(s *xorm.Session, a web.Auth) (bool, error) {
-	return tm.IsAdmin(s, a)
+	return tm.canEditMembers(s, a)
+}
+
+// Members of externally managed teams are synced from the auth provider and can't be changed in Vikunja.
+func (tm *TeamMember) canEditMembers(s *xorm.Session, a web.Auth) (bool, error) {
+	isAdmin, err := tm.IsAdmin(s, a)
+	if err != nil || !isAdmin {
+		return false, err
+	}
+
+	team := &Team{}
+	exists, err := s.Where("id = ?", tm.TeamID).Get(team)
+	if err != nil {
+		return false, err
+	}
+	if !exists {
+		return false, ErrTeamDoesNotExist{TeamID: tm.TeamID}
+	}
+	if team.isExternallyManaged() {
+		return false, ErrTeamIsExternallyManaged{TeamID: tm.TeamID}
+	}
+
+	return true, nil
 }
 
 // CanUpdate checks if the user can modify a team member's right
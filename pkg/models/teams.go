@@ -39,6 +39,12 @@ type Team struct {
 	Description string `xorm:"longtext null" json:"description"`
 	CreatedByID int64  `xorm:"bigint not null INDEX" json:"-"`
 
+	// The id of this team in the external auth provider which manages it, for example the DN of an LDAP group.
+	// Empty for teams which are managed in Vikunja.
+	ExternalID string `xorm:"varchar(250) null INDEX" json:"external_id"`
+	// The issuer of the external auth provider which manages this team.
+	Issuer string `xorm:"text null" json:"-"`
+
 	// The user who created this team.
 	CreatedBy *user.User `xorm:"-" json:"created_by"`
 	// An array of all members in this team.
@@ -58,6 +64,10 @@ func (Team) TableName() string {
 	return "teams"
 }
 
+func (t *Team) isExternallyManaged() bool {
+	return t.ExternalID != ""
+}
+
 // TeamMember defines the relationship between a user and a team
 type TeamMember struct {
 	// The unique, numeric id of this team member relation.
@@ -264,6 +274,9 @@ func (t *Team) Create(s *xorm.Session, a web.Auth) (err error) {
 
 	t.CreatedByID = doer.ID
 	t.CreatedBy = doer
+	// Only teams synced from an external auth provider can be externally managed
+	t.ExternalID = ""
+	t.Issuer = ""
 
 	_, err = s.Insert(t)
 	if err != nil {
@@ -327,7 +340,7 @@ func (t *Team) Delete(s *xorm.Session, a web.Auth) (err error) {
 
 // Update is the handler to create a team
 // @Summary Updates a team
-// @Description Updates a team.
+// @Description Updates a team. The name of teams managed by an external auth provider cannot be changed.
 // @tags team
 // @Accept json
 // @Produce json
@@ -345,12 +358,20 @@ func (t *Team) Update(s *xorm.Session, a web.Auth) (err error) {
 	}
 
 	// Check if the team exists
-	_, err = GetTeamByID(s, t.ID)
+	existing, err := GetTeamByID(s, t.ID)
 	if err != nil {
 		return
 	}
 
-	_, err = s.ID(t.ID).Update(t)
+	// The name of externally managed teams comes from the auth provider and would be overwritten with the next sync
+	if existing.isExternallyManaged() {
+		t.Name = existing.Name
+	}
+
+	_, err = s.
+		ID(t.ID).
+		Cols("name", "description").
+		Update(t)
 	if err != nil {
 		return
 	}
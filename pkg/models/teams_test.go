@@ -49,6 +49,25 @@ func TestTeam_Create(t *testing.T) {
 			"description": "Lorem Ispum",
 		}, false)
 	})
+	t.Run("external id", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		team := &Team{
+			Name:       "Testteam293",
+			ExternalID: "cn=admins,dc=example,dc=com",
+		}
+		err := team.Create(s, doer)
+		assert.NoError(t, err)
+		err = s.Commit()
+		assert.NoError(t, err)
+		db.AssertExists(t, "teams", map[string]interface{}{
+			"id":          team.ID,
+			"name":        "Testteam293",
+			"external_id": "",
+		}, false)
+	})
 	t.Run("empty name", func(t *testing.T) {
 		db.LoadAndAssertFixtures(t)
 		s := db.NewSession()
@@ -160,6 +179,48 @@ func TestTeam_Update(t *testing.T) {
 		assert.Error(t, err)
 		assert.True(t, IsErrTeamNameCannotBeEmpty(err))
 	})
+	t.Run("external id", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		team := &Team{
+			ID:         1,
+			Name:       "SomethingNew",
+			ExternalID: "cn=admins,dc=example,dc=com",
+		}
+		err := team.Update(s, u)
+		assert.NoError(t, err)
+		assert.Equal(t, "SomethingNew", team.Name)
+		assert.Empty(t, team.ExternalID)
+	})
+	t.Run("externally managed", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := s.
+			ID(1).
+			Cols("external_id", "issuer").
+			Update(&Team{ExternalID: "cn=admins,dc=example,dc=com", Issuer: "ldap"})
+		assert.NoError(t, err)
+
+		team := &Team{
+			ID:          1,
+			Name:        "SomethingNew",
+			Description: "New description",
+		}
+		err = team.Update(s, u)
+		assert.NoError(t, err)
+		err = s.Commit()
+		assert.NoError(t, err)
+		db.AssertExists(t, "teams", map[string]interface{}{
+			"id":          1,
+			"name":        "testteam1",
+			"description": "New description",
+			"external_id": "cn=admins,dc=example,dc=com",
+		}, false)
+	})
 	t.Run("nonexisting", func(t *testing.T) {
 		db.LoadAndAssertFixtures(t)
 		s := db.NewSession()
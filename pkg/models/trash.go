@@ -0,0 +1,616 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"sort"
+	"time"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/cron"
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/events"
+	"code.vikunja.io/api/pkg/log"
+	"code.vikunja.io/api/pkg/user"
+	"code.vikunja.io/web"
+	"xorm.io/builder"
+	"xorm.io/xorm"
+)
+
+// The kinds of items which can end up in the trash
+const (
+	TrashItemKindTask      = "task"
+	TrashItemKindList      = "list"
+	TrashItemKindNamespace = "namespace"
+)
+
+// TrashItem represents a deleted task, list or namespace in the trash.
+type TrashItem struct {
+	// The kind of the deleted item. Can be either `task`, `list` or `namespace`.
+	Kind string `json:"kind"`
+	// The unique, numeric id of the deleted item.
+	ID int64 `json:"id"`
+	// The title of the deleted item.
+	Title string `json:"title"`
+	// The id of the list a deleted task belongs to or the id of the namespace a deleted list belongs to. 0 for namespaces.
+	ParentID int64 `json:"parent_id"`
+	// A timestamp when this item was moved to the trash.
+	Deleted time.Time `json:"deleted"`
+	// A timestamp when this item will be removed permanently. Not set if the trash is never purged automatically.
+	PurgeAt time.Time `json:"purge_at"`
+
+	web.CRUDable `json:"-"`
+	web.Rights   `json:"-"`
+}
+
+// formatTrashTime formats a time the same way xorm stores it in the database so it can be used to compare it with
+// the deleted column.
+func formatTrashTime(t time.Time) string {
+	return t.In(config.GetTimeZone()).Format(dbTimeFormat)
+}
+
+// markAsDeleted moves everything in table matching cond which is not already in the trash to the trash.
+func markAsDeleted(s *xorm.Session, table string, cond builder.Cond, now time.Time) (err error) {
+	_, err = s.
+		Unscoped().
+		Table(table).
+		Where(builder.And(cond, builder.IsNull{"deleted"})).
+		Update(map[string]interface{}{"deleted": formatTrashTime(now)})
+	return
+}
+
+// markAsRestored removes everything in table matching cond from the trash.
+func markAsRestored(s *xorm.Session, table string, cond builder.Cond) (err error) {
+	_, err = s.
+		Unscoped().
+		Table(table).
+		Where(cond).
+		Update(map[string]interface{}{"deleted": nil})
+	return
+}
+
+func getTaskIncludingTrashed(s *xorm.Session, taskID int64) (t *Task, err error) {
+	t = &Task{}
+	exists, err := s.Unscoped().Where("id = ?", taskID).Get(t)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrTaskDoesNotExist{ID: taskID}
+	}
+	return
+}
+
+func getListIncludingTrashed(s *xorm.Session, listID int64) (l *List, err error) {
+	l = &List{}
+	exists, err := s.Unscoped().Where("id = ?", listID).Get(l)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrListDoesNotExist{ID: listID}
+	}
+	return
+}
+
+func getNamespaceIncludingTrashed(s *xorm.Session, namespaceID int64) (n *Namespace, err error) {
+	n = &Namespace{}
+	exists, err := s.Unscoped().Where("id = ?", namespaceID).Get(n)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrNamespaceDoesNotExist{ID: namespaceID}
+	}
+	return
+}
+
+// getNamespaceAdminCond returns a condition matching all namespaces the user is admin of.
+func getNamespaceAdminCond(userID int64) builder.Cond {
+	return builder.Or(
+		builder.Eq{"namespaces.owner_id": userID},
+		builder.In("namespaces.id", builder.
+			Select("un.namespace_id").
+			From("users_namespaces", "un").
+			Where(builder.Eq{"un.user_id": userID, "un.right": RightAdmin})),
+		builder.In("namespaces.id", builder.
+			Select("tn.namespace_id").
+			From("team_namespaces", "tn").
+			Join("INNER", "team_members tm", "tm.team_id = tn.team_id").
+			Where(builder.Eq{"tm.user_id": userID, "tn.right": RightAdmin})),
+	)
+}
+
+// getListAdminCond returns a condition matching all lists the user is admin of, either directly or through
+// the namespace the list belongs to.
+func getListAdminCond(userID int64) builder.Cond {
+	return builder.Or(
+		builder.Eq{"lists.owner_id": userID},
+		builder.In("lists.namespace_id", builder.
+			Select("namespaces.id").
+			From("namespaces").
+			Where(getNamespaceAdminCond(userID))),
+		builder.In("lists.id", builder.
+			Select("ul.list_id").
+			From("users_lists", "ul").
+			Where(builder.Eq{"ul.user_id": userID, "ul.right": RightAdmin})),
+		builder.In("lists.id", builder.
+			Select("tl.list_id").
+			From("team_lists", "tl").
+			Join("INNER", "team_members tm", "tm.team_id = tl.team_id").
+			Where(builder.Eq{"tm.user_id": userID, "tl.right": RightAdmin})),
+	)
+}
+
+func getTrashItems(s *xorm.Session, userID int64, search string) (items []*TrashItem, err error) {
+	items = []*TrashItem{}
+
+	namespaces := []*Namespace{}
+	err = s.
+		Unscoped().
+		Where(builder.And(
+			builder.NotNull{"namespaces.deleted"},
+			getNamespaceAdminCond(userID),
+			db.ILIKE("namespaces.title", search),
+		)).
+		Find(&namespaces)
+	if err != nil {
+		return
+	}
+	for _, n := range namespaces {
+		items = append(items, &TrashItem{
+			Kind:    TrashItemKindNamespace,
+			ID:      n.ID,
+			Title:   n.Title,
+			Deleted: n.Deleted,
+		})
+	}
+
+	// Lists which were deleted together with their namespace are only restored with it and are therefore not shown
+	// on their own.
+	lists := []*List{}
+	err = s.
+		Unscoped().
+		Where(builder.And(
+			builder.NotNull{"lists.deleted"},
+			builder.In("lists.namespace_id", builder.
+				Select("id").
+				From("namespaces").
+				Where(builder.IsNull{"deleted"})),
+			getListAdminCond(userID),
+			db.ILIKE("lists.title", search),
+		)).
+		Find(&lists)
+	if err != nil {
+		return
+	}
+	for _, l := range lists {
+		items = append(items, &TrashItem{
+			Kind:     TrashItemKindList,
+			ID:       l.ID,
+			Title:    l.Title,
+			ParentID: l.NamespaceID,
+			Deleted:  l.Deleted,
+		})
+	}
+
+	// Same goes for tasks which were deleted together with their list.
+	tasks := []*Task{}
+	err = s.
+		Unscoped().
+		Where(builder.And(
+			builder.NotNull{"tasks.deleted"},
+			builder.In("tasks.list_id", builder.
+				Select("lists.id").
+				From("lists").
+				Where(builder.And(
+					builder.IsNull{"lists.deleted"},
+					getListAdminCond(userID),
+				))),
+			db.ILIKE("tasks.title", search),
+		)).
+		Find(&tasks)
+	if err != nil {
+		return
+	}
+	for _, t := range tasks {
+		items = append(items, &TrashItem{
+			Kind:     TrashItemKindTask,
+			ID:       t.ID,
+			Title:    t.Title,
+			ParentID: t.ListID,
+			Deleted:  t.Deleted,
+		})
+	}
+
+	retentionDays := config.ServiceTrashRetentionDays.GetInt()
+	if retentionDays > 0 {
+		for _, item := range items {
+			item.PurgeAt = item.Deleted.Add(time.Duration(retentionDays) * 24 * time.Hour)
+		}
+	}
+
+	return
+}
+
+// ReadAll returns all tasks, lists and namespaces in the trash the user is admin of.
+// @Summary Get all items in the trash
+// @Description Returns all deleted tasks, lists and namespaces the user has admin rights on, the most recently deleted first. Tasks and lists which were deleted together with their list or namespace are not returned on their own.
+// @tags trash
+// @Accept json
+// @Produce json
+// @Param page query int false "The page number. Used for pagination. If not provided, the first page of results is returned."
+// @Param per_page query int false "The maximum number of items per page. Note this parameter is limited by the configured maximum of items per page."
+// @Param s query string false "Search items by their title."
+// @Security JWTKeyAuth
+// @Success 200 {array} models.TrashItem "The items in the trash."
+// @Failure 403 {object} web.HTTPError "Link shares cannot access the trash."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /trash [get]
+func (ti *TrashItem) ReadAll(s *xorm.Session, a web.Auth, search string, page int, perPage int) (result interface{}, resultCount int, numberOfTotalItems int64, err error) {
+	if _, is := a.(*LinkSharing); is {
+		return nil, 0, 0, ErrGenericForbidden{}
+	}
+
+	items, err := getTrashItems(s, a.GetID(), search)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Deleted.Equal(items[j].Deleted) {
+			return items[i].ID > items[j].ID
+		}
+		return items[i].Deleted.After(items[j].Deleted)
+	})
+
+	numberOfTotalItems = int64(len(items))
+	limit, start := getLimitFromPageIndex(page, perPage)
+	if limit > 0 {
+		if start > len(items) {
+			start = len(items)
+		}
+		end := start + limit
+		if end > len(items) {
+			end = len(items)
+		}
+		items = items[start:end]
+	}
+
+	return items, len(items), numberOfTotalItems, nil
+}
+
+// TaskRestore holds everything needed to restore a task from the trash
+type TaskRestore struct {
+	// The id of the task to restore
+	TaskID int64 `json:"-" param:"task"`
+
+	// The restored task
+	Task *Task `json:"task,omitempty"`
+
+	web.Rights   `json:"-"`
+	web.CRUDable `json:"-"`
+}
+
+// Create restores a task from the trash
+// @Summary Restore a task from the trash
+// @Description Restores a deleted task with all its labels, assignees, relations, comments and attachments. The list of the task must not be in the trash.
+// @tags trash
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param task path int true "Task ID"
+// @Success 201 {object} models.TaskRestore "The restored task."
+// @Failure 403 {object} web.HTTPError "The user is not admin on the list of the task."
+// @Failure 404 {object} web.HTTPError "The task is not in the trash."
+// @Failure 412 {object} web.HTTPError "The list of the task is still in the trash."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /tasks/{task}/restore [put]
+func (tr *TaskRestore) Create(s *xorm.Session, a web.Auth) (err error) {
+	if tr.Task.Deleted.IsZero() {
+		return ErrTrashItemDoesNotExist{Kind: TrashItemKindTask, ID: tr.TaskID}
+	}
+
+	_, err = GetListSimpleByID(s, tr.Task.ListID)
+	if IsErrListDoesNotExist(err) {
+		return ErrTrashItemParentIsDeleted{Kind: TrashItemKindTask, ID: tr.TaskID, ParentID: tr.Task.ListID}
+	}
+	if err != nil {
+		return err
+	}
+
+	err = tr.Task.restore(s, a)
+	if err != nil {
+		return err
+	}
+
+	tr.Task = &Task{ID: tr.TaskID}
+	return tr.Task.ReadOne(s, a)
+}
+
+// restore brings back a task from the trash.
+func (t *Task) restore(s *xorm.Session, a web.Auth) (err error) {
+	err = markAsRestored(s, "tasks", builder.Eq{"id": t.ID})
+	if err != nil {
+		return err
+	}
+
+	doer, _ := user.GetFromAuth(a)
+	err = events.Dispatch(&TaskRestoredEvent{
+		Task: t,
+		Doer: doer,
+	})
+	if err != nil {
+		return
+	}
+
+	return updateListLastUpdated(s, &List{ID: t.ListID})
+}
+
+// ListRestore holds everything needed to restore a list from the trash
+type ListRestore struct {
+	// The id of the list to restore
+	ListID int64 `json:"-" param:"list"`
+
+	// The restored list
+	List *List `json:"list,omitempty"`
+
+	web.Rights   `json:"-"`
+	web.CRUDable `json:"-"`
+}
+
+// Create restores a list from the trash
+// @Summary Restore a list from the trash
+// @Description Restores a deleted list together with all tasks which were deleted with it. The namespace of the list must not be in the trash.
+// @tags trash
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param list path int true "List ID"
+// @Success 201 {object} models.ListRestore "The restored list."
+// @Failure 403 {object} web.HTTPError "The user is not admin on the list."
+// @Failure 404 {object} web.HTTPError "The list is not in the trash."
+// @Failure 412 {object} web.HTTPError "The namespace of the list is still in the trash."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /lists/{list}/restore [put]
+func (lr *ListRestore) Create(s *xorm.Session, a web.Auth) (err error) {
+	if lr.List.Deleted.IsZero() {
+		return ErrTrashItemDoesNotExist{Kind: TrashItemKindList, ID: lr.ListID}
+	}
+
+	_, err = GetNamespaceByID(s, lr.List.NamespaceID)
+	if IsErrNamespaceDoesNotExist(err) {
+		return ErrTrashItemParentIsDeleted{Kind: TrashItemKindList, ID: lr.ListID, ParentID: lr.List.NamespaceID}
+	}
+	if err != nil {
+		return err
+	}
+
+	err = lr.List.restore(s, a)
+	if err != nil {
+		return err
+	}
+
+	lr.List, err = GetListSimpleByID(s, lr.ListID)
+	if err != nil {
+		return err
+	}
+	return lr.List.ReadOne(s, a)
+}
+
+// restore brings back a list from the trash together with all tasks which were moved to the trash with it.
+func (l *List) restore(s *xorm.Session, a web.Auth) (err error) {
+	err = markAsRestored(s, "lists", builder.Eq{"id": l.ID})
+	if err != nil {
+		return
+	}
+
+	tasks := []*Task{}
+	err = s.
+		Unscoped().
+		Where(builder.And(
+			builder.Eq{"list_id": l.ID},
+			builder.Gte{"deleted": formatTrashTime(l.Deleted)},
+		)).
+		Find(&tasks)
+	if err != nil {
+		return
+	}
+
+	for _, t := range tasks {
+		err = t.restore(s, a)
+		if err != nil {
+			return err
+		}
+	}
+
+	return events.Dispatch(&ListRestoredEvent{
+		List: l,
+		Doer: a,
+	})
+}
+
+// NamespaceRestore holds everything needed to restore a namespace from the trash
+type NamespaceRestore struct {
+	// The id of the namespace to restore
+	NamespaceID int64 `json:"-" param:"namespace"`
+
+	// The restored namespace
+	Namespace *Namespace `json:"namespace,omitempty"`
+
+	web.Rights   `json:"-"`
+	web.CRUDable `json:"-"`
+}
+
+// Create restores a namespace from the trash
+// @Summary Restore a namespace from the trash
+// @Description Restores a deleted namespace together with all lists and tasks which were deleted with it.
+// @tags trash
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param namespace path int true "Namespace ID"
+// @Success 201 {object} models.NamespaceRestore "The restored namespace."
+// @Failure 403 {object} web.HTTPError "The user is not admin on the namespace."
+// @Failure 404 {object} web.HTTPError "The namespace is not in the trash."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /namespaces/{namespace}/restore [put]
+func (nr *NamespaceRestore) Create(s *xorm.Session, a web.Auth) (err error) {
+	if nr.Namespace.Deleted.IsZero() {
+		return ErrTrashItemDoesNotExist{Kind: TrashItemKindNamespace, ID: nr.NamespaceID}
+	}
+
+	err = nr.Namespace.restore(s, a)
+	if err != nil {
+		return err
+	}
+
+	nr.Namespace = &Namespace{ID: nr.NamespaceID}
+	return nr.Namespace.ReadOne(s, a)
+}
+
+// restore brings back a namespace from the trash together with all lists which were moved to the trash with it.
+func (n *Namespace) restore(s *xorm.Session, a web.Auth) (err error) {
+	err = markAsRestored(s, "namespaces", builder.Eq{"id": n.ID})
+	if err != nil {
+		return
+	}
+
+	lists := []*List{}
+	err = s.
+		Unscoped().
+		Where(builder.And(
+			builder.Eq{"namespace_id": n.ID},
+			builder.Gte{"deleted": formatTrashTime(n.Deleted)},
+		)).
+		Find(&lists)
+	if err != nil {
+		return
+	}
+
+	for _, l := range lists {
+		err = l.restore(s, a)
+		if err != nil {
+			return err
+		}
+	}
+
+	return events.Dispatch(&NamespaceRestoredEvent{
+		Namespace: n,
+		Doer:      a,
+	})
+}
+
+// purgeTrash permanently removes all namespaces, lists and tasks in the trash matching the respective condition.
+// A nil condition skips that kind of item. Purging a namespace or list also purges everything in it.
+func purgeTrash(s *xorm.Session, namespaceCond, listCond, taskCond builder.Cond) (err error) {
+	if namespaceCond != nil {
+		namespaces := []*Namespace{}
+		err = s.Unscoped().Where(builder.And(builder.NotNull{"deleted"}, namespaceCond)).Find(&namespaces)
+		if err != nil {
+			return
+		}
+
+		for _, n := range namespaces {
+			lists := []*List{}
+			err = s.Unscoped().Where("namespace_id = ?", n.ID).Find(&lists)
+			if err != nil {
+				return
+			}
+
+			for _, l := range lists {
+				err = l.purge(s)
+				if err != nil {
+					return
+				}
+			}
+
+			err = n.purge(s)
+			if err != nil {
+				return
+			}
+		}
+	}
+
+	if listCond != nil {
+		lists := []*List{}
+		err = s.Unscoped().Where(builder.And(builder.NotNull{"deleted"}, listCond)).Find(&lists)
+		if err != nil {
+			return
+		}
+
+		for _, l := range lists {
+			err = l.purge(s)
+			if err != nil {
+				return
+			}
+		}
+	}
+
+	if taskCond != nil {
+		tasks := []*Task{}
+		err = s.Unscoped().Where(builder.And(builder.NotNull{"deleted"}, taskCond)).Find(&tasks)
+		if err != nil {
+			return
+		}
+
+		for _, t := range tasks {
+			err = t.purge(s)
+			if err != nil {
+				return
+			}
+		}
+	}
+
+	return
+}
+
+// purgeTrashDeletedBefore permanently removes everything which was moved to the trash before the given time.
+func purgeTrashDeletedBefore(s *xorm.Session, before time.Time) error {
+	cond := builder.Lt{"deleted": formatTrashTime(before)}
+	return purgeTrash(s, cond, cond, cond)
+}
+
+// RegisterTrashPurgeCron registers a cron function which permanently removes everything which is in the trash
+// longer than the configured retention period.
+func RegisterTrashPurgeCron() {
+	retentionDays := config.ServiceTrashRetentionDays.GetInt()
+	if retentionDays <= 0 {
+		log.Info("Trash retention is disabled, not purging the trash")
+		return
+	}
+
+	err := cron.Schedule("0 * * * *", func() {
+		s := db.NewSession()
+		defer s.Close()
+
+		before := time.Now().Add(-time.Duration(retentionDays) * 24 * time.Hour)
+		err := purgeTrashDeletedBefore(s, before)
+		if err != nil {
+			log.Errorf("[Trash Purge Cron] Could not purge the trash: %s", err)
+			_ = s.Rollback()
+			return
+		}
+
+		if err := s.Commit(); err != nil {
+			log.Errorf("[Trash Purge Cron] Could not commit purging the trash: %s", err)
+		}
+	})
+	if err != nil {
+		log.Fatalf("Could not register trash purge cron: %s", err)
+	}
+}
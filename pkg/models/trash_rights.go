@@ -0,0 +1,64 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"code.vikunja.io/web"
+	"xorm.io/xorm"
+)
+
+// CanCreate checks if the user can restore a task. Only admins of the list the task belongs to can do that.
+func (tr *TaskRestore) CanCreate(s *xorm.Session, a web.Auth) (bool, error) {
+	t, err := getTaskIncludingTrashed(s, tr.TaskID)
+	if err != nil {
+		return false, err
+	}
+	tr.Task = t
+
+	l, err := getListIncludingTrashed(s, t.ListID)
+	if err != nil {
+		return false, err
+	}
+	return l.isAdmin(s, a)
+}
+
+// CanCreate checks if the user can restore a list. Only admins of the list can do that.
+func (lr *ListRestore) CanCreate(s *xorm.Session, a web.Auth) (bool, error) {
+	l, err := getListIncludingTrashed(s, lr.ListID)
+	if err != nil {
+		return false, err
+	}
+	lr.List = l
+	return l.isAdmin(s, a)
+}
+
+// CanCreate checks if the user can restore a namespace. Only admins of the namespace can do that.
+func (nr *NamespaceRestore) CanCreate(s *xorm.Session, a web.Auth) (bool, error) {
+	// Link shares don't have access to namespaces
+	if _, is := a.(*LinkSharing); is {
+		return false, nil
+	}
+
+	n, err := getNamespaceIncludingTrashed(s, nr.NamespaceID)
+	if err != nil {
+		return false, err
+	}
+	nr.Namespace = n
+
+	is, _, err := n.checkRightOnLoaded(s, a, RightAdmin)
+	return is, err
+}
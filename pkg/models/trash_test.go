@@ -0,0 +1,259 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"testing"
+	"time"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/user"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrashItem_ReadAll(t *testing.T) {
+	u := &user.User{ID: 1}
+
+	t.Run("deleted task", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		err := (&Task{ID: 1, ListID: 1}).Delete(s, u)
+		assert.NoError(t, err)
+
+		ti := &TrashItem{}
+		result, count, total, err := ti.ReadAll(s, u, "", 1, 50)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, count)
+		assert.Equal(t, int64(1), total)
+		items := result.([]*TrashItem)
+		assert.Equal(t, TrashItemKindTask, items[0].Kind)
+		assert.Equal(t, int64(1), items[0].ID)
+		assert.Equal(t, int64(1), items[0].ParentID)
+		assert.Equal(t, 30*24*time.Hour, items[0].PurgeAt.Sub(items[0].Deleted))
+	})
+	t.Run("tasks of a deleted list are not shown on their own", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		err := (&List{ID: 1}).Delete(s, u)
+		assert.NoError(t, err)
+
+		ti := &TrashItem{}
+		result, count, _, err := ti.ReadAll(s, u, "", 1, 50)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, count)
+		items := result.([]*TrashItem)
+		assert.Equal(t, TrashItemKindList, items[0].Kind)
+		assert.Equal(t, int64(1), items[0].ID)
+		assert.Equal(t, int64(1), items[0].ParentID)
+	})
+	t.Run("no admin rights", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		err := (&Task{ID: 1, ListID: 1}).Delete(s, u)
+		assert.NoError(t, err)
+
+		ti := &TrashItem{}
+		_, count, _, err := ti.ReadAll(s, &user.User{ID: 13}, "", 1, 50)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, count)
+	})
+	t.Run("link share", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		ti := &TrashItem{}
+		_, _, _, err := ti.ReadAll(s, &LinkSharing{ID: 1, ListID: 1, Right: RightAdmin}, "", 1, 50)
+		assert.Error(t, err)
+		assert.True(t, IsErrGenericForbidden(err))
+	})
+}
+
+func TestTaskRestore_Create(t *testing.T) {
+	u := &user.User{ID: 1}
+
+	t.Run("normal", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		err := (&Task{ID: 1, ListID: 1}).Delete(s, u)
+		assert.NoError(t, err)
+
+		tr := &TaskRestore{TaskID: 1}
+		can, err := tr.CanCreate(s, u)
+		assert.NoError(t, err)
+		assert.True(t, can)
+		err = tr.Create(s, u)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), tr.Task.ID)
+		assert.Len(t, tr.Task.Labels, 1)
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		_, err = GetTaskByIDSimple(s, 1)
+		assert.NoError(t, err)
+	})
+	t.Run("not in the trash", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		tr := &TaskRestore{TaskID: 1}
+		can, err := tr.CanCreate(s, u)
+		assert.NoError(t, err)
+		assert.True(t, can)
+		err = tr.Create(s, u)
+		assert.Error(t, err)
+		assert.True(t, IsErrTrashItemDoesNotExist(err))
+	})
+	t.Run("list is deleted", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		err := (&List{ID: 1}).Delete(s, u)
+		assert.NoError(t, err)
+
+		tr := &TaskRestore{TaskID: 1}
+		can, err := tr.CanCreate(s, u)
+		assert.NoError(t, err)
+		assert.True(t, can)
+		err = tr.Create(s, u)
+		assert.Error(t, err)
+		assert.True(t, IsErrTrashItemParentIsDeleted(err))
+	})
+	t.Run("no admin rights", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		err := (&Task{ID: 1, ListID: 1}).Delete(s, u)
+		assert.NoError(t, err)
+
+		tr := &TaskRestore{TaskID: 1}
+		can, err := tr.CanCreate(s, &user.User{ID: 13})
+		assert.NoError(t, err)
+		assert.False(t, can)
+	})
+}
+
+func TestListRestore_Create(t *testing.T) {
+	u := &user.User{ID: 1}
+
+	t.Run("restores tasks deleted with the list", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		// Task 2 was deleted before the list and therefore stays in the trash
+		err := (&Task{ID: 2, ListID: 1}).moveToTrash(s, u, time.Now().Add(-time.Hour))
+		assert.NoError(t, err)
+		err = (&List{ID: 1}).Delete(s, u)
+		assert.NoError(t, err)
+
+		lr := &ListRestore{ListID: 1}
+		can, err := lr.CanCreate(s, u)
+		assert.NoError(t, err)
+		assert.True(t, can)
+		err = lr.Create(s, u)
+		assert.NoError(t, err)
+		assert.Equal(t, "Test1", lr.List.Title)
+
+		_, err = GetTaskByIDSimple(s, 1)
+		assert.NoError(t, err)
+		_, err = GetTaskByIDSimple(s, 2)
+		assert.Error(t, err)
+		assert.True(t, IsErrTaskDoesNotExist(err))
+	})
+	t.Run("no admin rights", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		err := (&List{ID: 1}).Delete(s, u)
+		assert.NoError(t, err)
+
+		lr := &ListRestore{ListID: 1}
+		can, err := lr.CanCreate(s, &user.User{ID: 13})
+		assert.NoError(t, err)
+		assert.False(t, can)
+	})
+}
+
+func TestNamespaceRestore_Create(t *testing.T) {
+	u := &user.User{ID: 1}
+
+	t.Run("normal", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		err := (&Namespace{ID: 1}).Delete(s, u)
+		assert.NoError(t, err)
+
+		nr := &NamespaceRestore{NamespaceID: 1}
+		can, err := nr.CanCreate(s, u)
+		assert.NoError(t, err)
+		assert.True(t, can)
+		err = nr.Create(s, u)
+		assert.NoError(t, err)
+
+		_, err = GetListSimpleByID(s, 1)
+		assert.NoError(t, err)
+		_, err = GetTaskByIDSimple(s, 1)
+		assert.NoError(t, err)
+	})
+	t.Run("link share", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		nr := &NamespaceRestore{NamespaceID: 1}
+		can, err := nr.CanCreate(s, &LinkSharing{ID: 1, ListID: 1, Right: RightAdmin})
+		assert.NoError(t, err)
+		assert.False(t, can)
+	})
+}
+
+func TestPurgeTrash(t *testing.T) {
+	u := &user.User{ID: 1}
+
+	db.LoadAndAssertFixtures(t)
+	s := db.NewSession()
+	defer s.Close()
+
+	err := (&Task{ID: 1, ListID: 1}).moveToTrash(s, u, time.Now().Add(-40*24*time.Hour))
+	assert.NoError(t, err)
+	err = (&Task{ID: 2, ListID: 1}).Delete(s, u)
+	assert.NoError(t, err)
+
+	err = purgeTrashDeletedBefore(s, time.Now().Add(-30*24*time.Hour))
+	assert.NoError(t, err)
+	err = s.Commit()
+	assert.NoError(t, err)
+
+	db.AssertMissing(t, "tasks", map[string]interface{}{"id": 1})
+	db.AssertMissing(t, "label_tasks", map[string]interface{}{"task_id": 1})
+	db.AssertExists(t, "tasks", map[string]interface{}{"id": 2}, false)
+}
@@ -67,6 +67,20 @@ func SetupTests() {
 		"saved_filters",
 		"subscriptions",
 		"favorites",
+		"webhooks",
+		"task_time_entries",
+		"custom_fields",
+		"task_custom_field_values",
+		"task_templates",
+		"task_changes",
+		"api_tokens",
+		"sessions",
+		"webauthn_credentials",
+		"app_passwords",
+		"oauth2_clients",
+		"oauth2_grants",
+		"notification_channels",
+		"notification_digest_items",
 	)
 	if err != nil {
 		log.Fatal(err)
@@ -21,6 +21,7 @@ import (
 
 	"code.vikunja.io/api/pkg/cron"
 	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/events"
 	"code.vikunja.io/api/pkg/log"
 	"code.vikunja.io/api/pkg/notifications"
 	"code.vikunja.io/api/pkg/user"
@@ -182,7 +183,14 @@ func DeleteUser(s *xorm.Session, u *user.User) (err error) {
 
 	// Delete everything not shared with anybody else
 	for _, n := range namespacesToDelete {
-		err = deleteNamespace(s, n, u, false)
+		err = n.purge(s)
+		if err != nil {
+			return err
+		}
+		err = events.Dispatch(&NamespaceDeletedEvent{
+			Namespace: n,
+			Doer:      u,
+		})
 		if err != nil {
 			return err
 		}
@@ -193,6 +201,74 @@ func DeleteUser(s *xorm.Session, u *user.User) (err error) {
 		if err != nil {
 			return err
 		}
+		err = l.purge(s)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Everything the user moved to the trash would otherwise stay around until the trash is purged
+	err = purgeTrash(s, builder.Eq{"owner_id": u.ID}, builder.Eq{"owner_id": u.ID}, nil)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.Where("owner_id = ?", u.ID).Delete(&APIToken{})
+	if err != nil {
+		return err
+	}
+
+	err = user.DeleteAllSessionsForUser(s, u.ID)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.Where("user_id = ?", u.ID).Delete(&user.WebAuthnCredential{})
+	if err != nil {
+		return err
+	}
+
+	_, err = s.Where("user_id = ?", u.ID).Delete(&user.TOTPRecoveryCode{})
+	if err != nil {
+		return err
+	}
+
+	_, err = s.Where("user_id = ?", u.ID).Delete(&user.AppPassword{})
+	if err != nil {
+		return err
+	}
+
+	_, err = s.Where("user_id = ?", u.ID).Delete(&OAuth2Grant{})
+	if err != nil {
+		return err
+	}
+
+	// Applications the user registered stop working for everyone else as well
+	_, err = s.
+		Where(builder.In("client_id", builder.Select("client_id").From("oauth2_clients").Where(builder.Eq{"owner_id": u.ID}))).
+		Delete(&OAuth2Grant{})
+	if err != nil {
+		return err
+	}
+
+	_, err = s.Where("owner_id = ?", u.ID).Delete(&OAuth2Client{})
+	if err != nil {
+		return err
+	}
+
+	err = notifications.DeleteNotificationPreferences(s, u.ID)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.Where("notifiable_id = ?", u.ID).Delete(&notifications.NotificationChannel{})
+	if err != nil {
+		return err
+	}
+
+	_, err = s.Where("notifiable_id = ?", u.ID).Delete(&notifications.DigestItem{})
+	if err != nil {
+		return err
 	}
 
 	_, err = s.Where("id = ?", u.ID).Delete(u)
@@ -0,0 +1,413 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/log"
+	"code.vikunja.io/api/pkg/user"
+	"code.vikunja.io/api/pkg/utils"
+	"code.vikunja.io/api/pkg/version"
+	"code.vikunja.io/web"
+	"xorm.io/builder"
+	"xorm.io/xorm"
+)
+
+// Webhook represents a url which is called with a signed payload whenever an event happens in a list or namespace.
+type Webhook struct {
+	// The unique, numeric id of this webhook.
+	ID int64 `xorm:"bigint autoincr not null unique pk" json:"id" param:"webhook"`
+	// The url Vikunja will send a POST request with the event payload to.
+	TargetURL string `xorm:"text not null" json:"target_url" valid:"required"`
+	// The names of all events this webhook should be called for. See /webhooks/events for all available events.
+	Events []string `xorm:"JSON not null" json:"events" valid:"required"`
+	// If provided, all requests to the target url will contain a hex-encoded HMAC-SHA256 signature of the request body
+	// with this secret in the X-Vikunja-Signature header. You can only set it, not retrieve it after the webhook has been created.
+	Secret string `xorm:"text null" json:"secret"`
+
+	// The list this webhook belongs to. Either this or the namespace id is set.
+	ListID int64 `xorm:"bigint null index" json:"list_id" param:"list"`
+	// The namespace this webhook belongs to. Either this or the list id is set.
+	NamespaceID int64 `xorm:"bigint null index" json:"namespace_id" param:"namespace"`
+
+	// The user who created this webhook.
+	CreatedBy   *user.User `xorm:"-" json:"created_by"`
+	CreatedByID int64      `xorm:"bigint not null" json:"-"`
+
+	// A timestamp when this webhook was created. You cannot change this value.
+	Created time.Time `xorm:"created not null" json:"created"`
+	// A timestamp when this webhook was last updated. You cannot change this value.
+	Updated time.Time `xorm:"updated not null" json:"updated"`
+
+	web.CRUDable `xorm:"-" json:"-"`
+	web.Rights   `xorm:"-" json:"-"`
+}
+
+// TableName returns a better table name for webhooks
+func (w *Webhook) TableName() string {
+	return "webhooks"
+}
+
+// GetAvailableWebhookEvents returns the names of all events a webhook can be registered for.
+func GetAvailableWebhookEvents() []string {
+	return []string{
+		(&TaskCreatedEvent{}).Name(),
+		(&TaskUpdatedEvent{}).Name(),
+		(&TaskDeletedEvent{}).Name(),
+		(&TaskRestoredEvent{}).Name(),
+		(&TaskAssigneeCreatedEvent{}).Name(),
+		(&TaskCommentCreatedEvent{}).Name(),
+		(&TaskCommentUpdatedEvent{}).Name(),
+		(&NamespaceUpdatedEvent{}).Name(),
+		(&NamespaceDeletedEvent{}).Name(),
+		(&NamespaceRestoredEvent{}).Name(),
+		(&BucketCreatedEvent{}).Name(),
+		(&BucketUpdatedEvent{}).Name(),
+		(&BucketDeletedEvent{}).Name(),
+		(&ListCreatedEvent{}).Name(),
+		(&ListUpdatedEvent{}).Name(),
+		(&ListDeletedEvent{}).Name(),
+		(&ListRestoredEvent{}).Name(),
+		(&ListSharedWithUserEvent{}).Name(),
+		(&ListSharedWithTeamEvent{}).Name(),
+		(&NamespaceSharedWithUserEvent{}).Name(),
+		(&NamespaceSharedWithTeamEvent{}).Name(),
+	}
+}
+
+func (w *Webhook) validate() error {
+	u, err := url.Parse(w.TargetURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ErrInvalidWebhookTargetURL{TargetURL: w.TargetURL}
+	}
+
+	available := make(map[string]bool)
+	for _, e := range GetAvailableWebhookEvents() {
+		available[e] = true
+	}
+	for _, e := range w.Events {
+		if !available[e] {
+			return ErrInvalidWebhookEvent{EventName: e}
+		}
+	}
+
+	return nil
+}
+
+func (w *Webhook) listensTo(eventName string) bool {
+	for _, e := range w.Events {
+		if e == eventName {
+			return true
+		}
+	}
+	return false
+}
+
+func getWebhookByID(s *xorm.Session, id int64) (w *Webhook, err error) {
+	w = &Webhook{}
+	exists, err := s.Where("id = ?", id).Get(w)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrWebhookDoesNotExist{WebhookID: id}
+	}
+	return
+}
+
+// Create creates a new webhook
+// @Summary Create a webhook for a list or namespace
+// @Description Creates a new webhook. The user needs to be admin of the list or namespace to do this.
+// @tags webhooks
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param list path int true "List ID"
+// @Param webhook body models.Webhook true "The new webhook"
+// @Success 201 {object} models.Webhook "The created webhook."
+// @Failure 400 {object} web.HTTPError "Invalid webhook object provided."
+// @Failure 403 {object} web.HTTPError "The user does not have admin access to the list."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /lists/{list}/webhooks [put]
+func (w *Webhook) Create(s *xorm.Session, a web.Auth) (err error) {
+	// The rights check only looks at one of both ids, a webhook with both would get events it has no access to.
+	if (w.ListID == 0) == (w.NamespaceID == 0) {
+		return ErrWebhookNeedsListOrNamespace{}
+	}
+
+	if err = w.validate(); err != nil {
+		return
+	}
+
+	w.ID = 0
+	w.CreatedByID = a.GetID()
+	_, err = s.Insert(w)
+	if err != nil {
+		return
+	}
+
+	w.Secret = ""
+	w.CreatedBy, err = user.GetFromAuth(a)
+	return
+}
+
+// ReadOne returns a single webhook
+// @Summary Get one webhook
+// @Description Returns one webhook by its ID.
+// @tags webhooks
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param list path int true "List ID"
+// @Param webhook path int true "Webhook ID"
+// @Success 200 {object} models.Webhook "The webhook"
+// @Failure 403 {object} web.HTTPError "The user does not have admin access to the list."
+// @Failure 404 {object} web.HTTPError "The webhook does not exist."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /lists/{list}/webhooks/{webhook} [get]
+func (w *Webhook) ReadOne(s *xorm.Session, a web.Auth) (err error) {
+	wh, err := getWebhookByID(s, w.ID)
+	if err != nil {
+		return
+	}
+	*w = *wh
+
+	w.Secret = ""
+	w.CreatedBy, err = user.GetUserByID(s, w.CreatedByID)
+	if user.IsErrUserDoesNotExist(err) {
+		return nil
+	}
+	return
+}
+
+// ReadAll returns all webhooks of a list or namespace
+// @Summary Get all webhooks of a list
+// @Description Returns all webhooks which exist for a given list. The user needs to be admin of the list to do this.
+// @tags webhooks
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param list path int true "List ID"
+// @Param page query int false "The page number. Used for pagination. If not provided, the first page of results is returned."
+// @Param per_page query int false "The maximum number of items per page. Note this parameter is limited by the configured maximum of items per page."
+// @Param s query string false "Search webhooks by their target url."
+// @Success 200 {array} models.Webhook "The webhooks"
+// @Failure 403 {object} web.HTTPError "The user does not have admin access to the list."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /lists/{list}/webhooks [get]
+func (w *Webhook) ReadAll(s *xorm.Session, a web.Auth, search string, page int, perPage int) (result interface{}, resultCount int, totalItems int64, err error) {
+	can, err := w.canDoWebhook(s, a)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	if !can {
+		return nil, 0, 0, ErrGenericForbidden{}
+	}
+
+	cond := builder.And(
+		builder.Eq{"list_id": w.ListID},
+		db.ILIKE("target_url", search),
+	)
+	if w.ListID == 0 {
+		cond = builder.And(
+			builder.Eq{"namespace_id": w.NamespaceID},
+			db.ILIKE("target_url", search),
+		)
+	}
+
+	limit, start := getLimitFromPageIndex(page, perPage)
+
+	webhooks := []*Webhook{}
+	query := s.Where(cond)
+	if limit > 0 {
+		query = query.Limit(limit, start)
+	}
+	err = query.Find(&webhooks)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	userIDs := make([]int64, 0, len(webhooks))
+	for _, wh := range webhooks {
+		userIDs = append(userIDs, wh.CreatedByID)
+	}
+
+	users, err := user.GetUsersByIDs(s, userIDs)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	for _, wh := range webhooks {
+		wh.Secret = ""
+		wh.CreatedBy = users[wh.CreatedByID]
+	}
+
+	totalItems, err = s.Where(cond).Count(&Webhook{})
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	return webhooks, len(webhooks), totalItems, nil
+}
+
+// Update updates a webhook
+// @Summary Update a webhook
+// @Description Updates the target url, events or secret of a webhook. If no secret is provided, the existing one is kept.
+// @tags webhooks
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param list path int true "List ID"
+// @Param webhook path int true "Webhook ID"
+// @Param webhook body models.Webhook true "The webhook with updated values"
+// @Success 200 {object} models.Webhook "The updated webhook."
+// @Failure 400 {object} web.HTTPError "Invalid webhook object provided."
+// @Failure 403 {object} web.HTTPError "The user does not have admin access to the list."
+// @Failure 404 {object} web.HTTPError "The webhook does not exist."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /lists/{list}/webhooks/{webhook} [post]
+func (w *Webhook) Update(s *xorm.Session, a web.Auth) (err error) {
+	if err = w.validate(); err != nil {
+		return
+	}
+
+	cols := []string{"target_url", "events"}
+	if w.Secret != "" {
+		cols = append(cols, "secret")
+	}
+
+	_, err = s.
+		Where("id = ?", w.ID).
+		Cols(cols...).
+		Update(w)
+	if err != nil {
+		return
+	}
+
+	return w.ReadOne(s, a)
+}
+
+// Delete removes a webhook
+// @Summary Delete a webhook
+// @Description Removes a webhook. No further events will be sent to its target url.
+// @tags webhooks
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param list path int true "List ID"
+// @Param webhook path int true "Webhook ID"
+// @Success 200 {object} models.Message "The webhook was successfully deleted."
+// @Failure 403 {object} web.HTTPError "The user does not have admin access to the list."
+// @Failure 404 {object} web.HTTPError "The webhook does not exist."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /lists/{list}/webhooks/{webhook} [delete]
+func (w *Webhook) Delete(s *xorm.Session, a web.Auth) (err error) {
+	_, err = s.Where("id = ?", w.ID).Delete(&Webhook{})
+	return
+}
+
+// getWebhooksForEvent returns all webhooks which listen to an event and belong to either the list or namespace.
+func getWebhooksForEvent(s *xorm.Session, eventName string, listID, namespaceID int64) (webhooks []*Webhook, err error) {
+	conds := []builder.Cond{}
+	if listID > 0 {
+		conds = append(conds, builder.Eq{"list_id": listID})
+	}
+	if namespaceID > 0 {
+		conds = append(conds, builder.Eq{"namespace_id": namespaceID})
+	}
+	if len(conds) == 0 {
+		return
+	}
+
+	all := []*Webhook{}
+	err = s.Where(builder.Or(conds...)).Find(&all)
+	if err != nil {
+		return
+	}
+
+	for _, w := range all {
+		if w.listensTo(eventName) {
+			webhooks = append(webhooks, w)
+		}
+	}
+	return
+}
+
+// webhookPayload is the body sent to the target url of a webhook.
+type webhookPayload struct {
+	EventName string          `json:"event_name"`
+	Time      time.Time       `json:"time"`
+	Data      json.RawMessage `json:"data"`
+}
+
+func signWebhookPayload(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// sendPayload sends the event data to the webhook's target url. It returns an error if the target did not
+// respond with a 2xx status code so that the delivery can be retried.
+func (w *Webhook) sendPayload(eventName string, eventTime time.Time, data json.RawMessage) (err error) {
+	body, err := json.Marshal(&webhookPayload{
+		EventName: eventName,
+		Time:      eventTime,
+		Data:      data,
+	})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(config.WebhooksTimeoutSeconds.GetInt64())*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.TargetURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Vikunja/"+version.Version)
+	if w.Secret != "" {
+		req.Header.Set("X-Vikunja-Signature", signWebhookPayload(w.Secret, body))
+	}
+
+	hc := utils.NewExternalHTTPClient(config.WebhooksAllowedHosts.GetStringSlice())
+	resp, err := hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook %d responded with status %d", w.ID, resp.StatusCode)
+	}
+
+	log.Debugf("Sent event %s to webhook %d", eventName, w.ID)
+	return nil
+}
@@ -0,0 +1,72 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"code.vikunja.io/web"
+	"xorm.io/xorm"
+)
+
+// CanRead checks if the user can see a webhook
+func (w *Webhook) CanRead(s *xorm.Session, a web.Auth) (bool, int, error) {
+	can, err := w.canDoExistingWebhook(s, a)
+	return can, int(RightAdmin), err
+}
+
+// CanCreate checks if the user can create a webhook for a list or namespace
+func (w *Webhook) CanCreate(s *xorm.Session, a web.Auth) (bool, error) {
+	return w.canDoWebhook(s, a)
+}
+
+// CanUpdate checks if the user can update a webhook
+func (w *Webhook) CanUpdate(s *xorm.Session, a web.Auth) (bool, error) {
+	return w.canDoExistingWebhook(s, a)
+}
+
+// CanDelete checks if the user can delete a webhook
+func (w *Webhook) CanDelete(s *xorm.Session, a web.Auth) (bool, error) {
+	return w.canDoExistingWebhook(s, a)
+}
+
+func (w *Webhook) canDoExistingWebhook(s *xorm.Session, a web.Auth) (bool, error) {
+	wh, err := getWebhookByID(s, w.ID)
+	if err != nil {
+		return false, err
+	}
+	return wh.canDoWebhook(s, a)
+}
+
+// Only admins of the list or namespace a webhook belongs to may manage it.
+func (w *Webhook) canDoWebhook(s *xorm.Session, a web.Auth) (bool, error) {
+	if _, is := a.(*LinkSharing); is {
+		return false, nil
+	}
+
+	if w.ListID > 0 && w.NamespaceID > 0 {
+		return false, nil
+	}
+
+	if w.ListID > 0 {
+		return (&List{ID: w.ListID}).IsAdmin(s, a)
+	}
+
+	if w.NamespaceID > 0 {
+		return (&Namespace{ID: w.NamespaceID}).IsAdmin(s, a)
+	}
+
+	return false, nil
+}
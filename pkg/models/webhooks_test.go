@@ -0,0 +1,274 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/events"
+	"code.vikunja.io/api/pkg/user"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhook_Create(t *testing.T) {
+	u := &user.User{ID: 1}
+
+	t.Run("normal", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		w := &Webhook{
+			TargetURL: "https://example.com/new",
+			Events:    []string{"task.created"},
+			Secret:    "secret",
+			ListID:    1,
+		}
+		err := w.Create(s, u)
+		assert.NoError(t, err)
+		assert.Equal(t, u.ID, w.CreatedByID)
+		assert.Empty(t, w.Secret)
+		err = s.Commit()
+		assert.NoError(t, err)
+		db.AssertExists(t, "webhooks", map[string]interface{}{
+			"id":            w.ID,
+			"target_url":    "https://example.com/new",
+			"secret":        "secret",
+			"list_id":       1,
+			"created_by_id": 1,
+		}, false)
+	})
+	t.Run("invalid target url", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		w := &Webhook{
+			TargetURL: "ftp://example.com",
+			Events:    []string{"task.created"},
+			ListID:    1,
+		}
+		err := w.Create(s, u)
+		assert.Error(t, err)
+		assert.True(t, IsErrInvalidWebhookTargetURL(err))
+	})
+	t.Run("invalid event", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		w := &Webhook{
+			TargetURL: "https://example.com/new",
+			Events:    []string{"task.created", "user.export.requested"},
+			ListID:    1,
+		}
+		err := w.Create(s, u)
+		assert.Error(t, err)
+		assert.True(t, IsErrInvalidWebhookEvent(err))
+	})
+	t.Run("list and namespace", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		// User 1 is admin of list 1 but has no access to namespace 2
+		w := &Webhook{
+			TargetURL:   "https://example.com/new",
+			Events:      []string{"task.created"},
+			ListID:      1,
+			NamespaceID: 2,
+		}
+		can, err := w.CanCreate(s, u)
+		assert.NoError(t, err)
+		assert.False(t, can)
+
+		err = w.Create(s, u)
+		assert.Error(t, err)
+		assert.True(t, IsErrWebhookNeedsListOrNamespace(err))
+	})
+}
+
+func TestWebhook_ReadAll(t *testing.T) {
+	db.LoadAndAssertFixtures(t)
+	s := db.NewSession()
+	defer s.Close()
+
+	w := &Webhook{ListID: 1}
+	result, count, total, err := w.ReadAll(s, &user.User{ID: 1}, "", 1, 50)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+	assert.Equal(t, int64(1), total)
+	webhooks := result.([]*Webhook)
+	assert.Equal(t, int64(1), webhooks[0].ID)
+	assert.Empty(t, webhooks[0].Secret)
+	assert.NotNil(t, webhooks[0].CreatedBy)
+}
+
+func TestWebhook_Update(t *testing.T) {
+	db.LoadAndAssertFixtures(t)
+	s := db.NewSession()
+	defer s.Close()
+
+	w := &Webhook{
+		ID:        1,
+		TargetURL: "https://example.com/updated",
+		Events:    []string{"task.deleted"},
+	}
+	err := w.Update(s, &user.User{ID: 1})
+	assert.NoError(t, err)
+	err = s.Commit()
+	assert.NoError(t, err)
+	db.AssertExists(t, "webhooks", map[string]interface{}{
+		"id":         1,
+		"target_url": "https://example.com/updated",
+		"secret":     "supersecret",
+	}, false)
+}
+
+func TestWebhook_Delete(t *testing.T) {
+	db.LoadAndAssertFixtures(t)
+	s := db.NewSession()
+	defer s.Close()
+
+	w := &Webhook{ID: 1}
+	err := w.Delete(s, &user.User{ID: 1})
+	assert.NoError(t, err)
+	err = s.Commit()
+	assert.NoError(t, err)
+	db.AssertMissing(t, "webhooks", map[string]interface{}{
+		"id": 1,
+	})
+}
+
+func TestWebhook_Rights(t *testing.T) {
+	t.Run("list admin", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		can, err := (&Webhook{ListID: 1}).CanCreate(s, &user.User{ID: 1})
+		assert.NoError(t, err)
+		assert.True(t, can)
+	})
+	t.Run("namespace admin", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		can, err := (&Webhook{ID: 2}).CanUpdate(s, &user.User{ID: 1})
+		assert.NoError(t, err)
+		assert.True(t, can)
+	})
+	t.Run("no access", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		can, _, err := (&Webhook{ID: 1}).CanRead(s, &user.User{ID: 2})
+		assert.NoError(t, err)
+		assert.False(t, can)
+	})
+	t.Run("link share", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		can, err := (&Webhook{ListID: 1}).CanCreate(s, &LinkSharing{ID: 1, ListID: 1, Right: RightAdmin})
+		assert.NoError(t, err)
+		assert.False(t, can)
+	})
+	t.Run("nonexisting", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		can, err := (&Webhook{ID: 9999}).CanDelete(s, &user.User{ID: 1})
+		assert.Error(t, err)
+		assert.True(t, IsErrWebhookDoesNotExist(err))
+		assert.False(t, can)
+	})
+}
+
+func TestWebhook_sendPayload(t *testing.T) {
+	var receivedSignature string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedSignature = r.Header.Get("X-Vikunja-Signature")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	config.WebhooksAllowedHosts.Set([]string{"127.0.0.1"})
+	defer config.WebhooksAllowedHosts.Set([]string{})
+
+	t.Run("signed", func(t *testing.T) {
+		w := &Webhook{ID: 1, TargetURL: server.URL, Secret: "secret"}
+		err := w.sendPayload("task.created", time.Now(), []byte(`{}`))
+		assert.NoError(t, err)
+		assert.Len(t, receivedSignature, 64)
+	})
+	t.Run("failing target", func(t *testing.T) {
+		failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer failing.Close()
+
+		w := &Webhook{ID: 1, TargetURL: failing.URL}
+		err := w.sendPayload("task.created", time.Now(), []byte(`{}`))
+		assert.Error(t, err)
+	})
+	t.Run("private target", func(t *testing.T) {
+		config.WebhooksAllowedHosts.Set([]string{})
+		defer config.WebhooksAllowedHosts.Set([]string{"127.0.0.1"})
+
+		w := &Webhook{ID: 1, TargetURL: server.URL}
+		err := w.sendPayload("task.created", time.Now(), []byte(`{}`))
+		assert.Error(t, err)
+	})
+}
+
+func TestDispatchWebhookDeliveries_Handle(t *testing.T) {
+	db.LoadAndAssertFixtures(t)
+
+	event := &TaskCreatedEvent{
+		Task: &Task{ID: 1, ListID: 1},
+		Doer: &user.User{ID: 1},
+	}
+	events.TestListener(t, event, &DispatchWebhookDeliveries{EventName: event.Name()})
+	events.AssertDispatched(t, &WebhookDeliveryEvent{})
+}
+
+func TestSanitizeEventPayload(t *testing.T) {
+	payload, err := json.Marshal(&TaskAssigneeCreatedEvent{
+		Task:     &Task{ID: 1, ListID: 1, CreatedBy: &user.User{ID: 1, Email: "user1@example.com"}},
+		Assignee: &user.User{ID: 2, Email: "user2@example.com"},
+		Doer:     &user.User{ID: 9007199254740993, Username: "user3", Email: "user3@example.com"},
+	})
+	assert.NoError(t, err)
+
+	sanitized, err := SanitizeEventPayload(payload)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(sanitized), "@example.com")
+	assert.Contains(t, string(sanitized), `"username":"user3"`)
+	// Big ids must not lose their precision
+	assert.Contains(t, string(sanitized), "9007199254740993")
+}
@@ -0,0 +1,122 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package auth
+
+import (
+	"strings"
+	"time"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/models"
+	"code.vikunja.io/api/pkg/user"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// IsAPIToken checks if a raw token from the Authorization header is an api token instead of a jwt token.
+func IsAPIToken(rawToken string) bool {
+	return strings.HasPrefix(rawToken, models.APITokenPrefix)
+}
+
+// NewJWTFromAPIToken authenticates an api token and returns a jwt token with the claims of the user who owns it.
+// This allows all other parts of Vikunja to treat api tokens like regular user tokens. The token is never signed
+// and only lives for the current request.
+func NewJWTFromAPIToken(rawToken string) (token *jwt.Token, err error) {
+	s := db.NewSession()
+	defer s.Close()
+
+	apiToken, err := models.AuthenticateAPIToken(s, rawToken)
+	if err != nil {
+		_ = s.Rollback()
+		return nil, err
+	}
+
+	u, err := user.GetUserByID(s, apiToken.OwnerID)
+	if err != nil {
+		_ = s.Rollback()
+		return nil, err
+	}
+
+	if u.Status == user.StatusDisabled {
+		_ = s.Rollback()
+		return nil, &user.ErrAccountDisabled{UserID: u.ID}
+	}
+
+	if err := s.Commit(); err != nil {
+		_ = s.Rollback()
+		return nil, err
+	}
+
+	return newScopedUserJWT(u, apiToken.ExpiresAt, apiToken.Scopes), nil
+}
+
+// NewJWTFromAppPassword authenticates basic auth credentials with an app password which is not restricted to
+// CalDAV. The returned token works like one of an api token with access to all resources.
+func NewJWTFromAppPassword(username, password string) (token *jwt.Token, err error) {
+	s := db.NewSession()
+	defer s.Close()
+
+	u, err := user.CheckAppPassword(s, username, password, false)
+	if err != nil {
+		_ = s.Rollback()
+		return nil, err
+	}
+
+	u, err = user.GetUserByID(s, u.ID)
+	if err != nil {
+		_ = s.Rollback()
+		return nil, err
+	}
+
+	if err := s.Commit(); err != nil {
+		_ = s.Rollback()
+		return nil, err
+	}
+
+	// The token only lives for the current request
+	return newScopedUserJWT(u, time.Now().Add(time.Minute), models.GetAPITokenScopesForAllResources()), nil
+}
+
+func newScopedUserJWT(u *user.User, expiresAt time.Time, scopes models.APITokenScopes) *jwt.Token {
+	// Numbers are float64 because that's what they are in every decoded jwt token.
+	return &jwt.Token{
+		Method: jwt.SigningMethodHS256,
+		Valid:  true,
+		Claims: jwt.MapClaims{
+			"type":                     float64(AuthTypeUser),
+			"id":                       float64(u.ID),
+			"username":                 u.Username,
+			"email":                    u.Email,
+			"exp":                      float64(expiresAt.Unix()),
+			"name":                     u.Name,
+			"emailRemindersEnabled":    u.EmailRemindersEnabled,
+			"isLocalUser":              u.Issuer == user.IssuerLocal,
+			models.APITokenScopesClaim: scopes,
+		},
+	}
+}
+
+// GetAPITokenScopes returns the scopes of the api token the current request was authenticated with.
+// If the request was not authenticated with an api token, ok is false.
+func GetAPITokenScopes(token *jwt.Token) (scopes models.APITokenScopes, ok bool) {
+	claims, is := token.Claims.(jwt.MapClaims)
+	if !is {
+		return nil, false
+	}
+	scopes, ok = claims[models.APITokenScopesClaim].(models.APITokenScopes)
+	return
+}
@@ -17,10 +17,12 @@
 package auth
 
 import (
+	"errors"
 	"net/http"
 	"time"
 
 	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/db"
 	"code.vikunja.io/api/pkg/models"
 	"code.vikunja.io/api/pkg/user"
 	"code.vikunja.io/web"
@@ -39,29 +41,49 @@ const (
 // Token represents an authentification token
 type Token struct {
 	Token string `json:"token"`
+	// The refresh token of the session. It is only returned when logging in or refreshing a session.
+	RefreshToken string `json:"refresh_token,omitempty"`
 }
 
-// NewUserAuthTokenResponse creates a new user auth token response from a user object.
+// RefreshTokenRequest is used to get a new access token with the refresh token of a session.
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// NewUserAuthTokenResponse creates a new session for a user and responds with an access token and the refresh
+// token of that session.
 func NewUserAuthTokenResponse(u *user.User, c echo.Context) error {
-	t, err := NewUserJWTAuthtoken(u)
+	s := db.NewSession()
+	defer s.Close()
+
+	session, refreshToken, err := user.CreateSession(s, u, c.Request().UserAgent(), c.RealIP())
 	if err != nil {
+		_ = s.Rollback()
 		return err
 	}
 
-	return c.JSON(http.StatusOK, Token{Token: t})
-}
+	if err := s.Commit(); err != nil {
+		return err
+	}
 
-// NewUserJWTAuthtoken generates and signes a new jwt token for a user. This is a global function to be able to call it from integration tests.
-func NewUserJWTAuthtoken(u *user.User) (token string, err error) {
-	t := jwt.New(jwt.SigningMethodHS256)
+	t, err := NewUserJWTAuthtoken(u, session.ID)
+	if err != nil {
+		return err
+	}
 
-	var ttl = time.Duration(config.ServiceJWTTTL.GetInt64())
+	return c.JSON(http.StatusOK, Token{Token: t, RefreshToken: refreshToken})
+}
+
+// NewUserJWTAuthtoken generates and signes a new short-lived jwt token for a session of a user. This is a global function to be able to call it from integration tests.
+func NewUserJWTAuthtoken(u *user.User, sessionID string) (token string, err error) {
+	var ttl = time.Duration(config.ServiceJWTTTLShort.GetInt64())
 	var exp = time.Now().Add(time.Second * ttl).Unix()
 
 	// Set claims
-	claims := t.Claims.(jwt.MapClaims)
+	claims := jwt.MapClaims{}
 	claims["type"] = AuthTypeUser
 	claims["id"] = u.ID
+	claims["sid"] = sessionID
 	claims["username"] = u.Username
 	claims["email"] = u.Email
 	claims["exp"] = exp
@@ -70,18 +92,58 @@ func NewUserJWTAuthtoken(u *user.User) (token string, err error) {
 	claims["isLocalUser"] = u.Issuer == user.IssuerLocal
 
 	// Generate encoded token and send it as response.
-	return t.SignedString([]byte(config.ServiceJWTSecret.GetString()))
+	return signJWT(claims)
+}
+
+// ParseJWT parses a signed jwt token and checks its signature and expiry.
+func ParseJWT(rawToken string) (*jwt.Token, error) {
+	token, err := jwt.Parse(rawToken, getJWTVerificationKeyForToken)
+	if err != nil {
+		return nil, err
+	}
+	if !token.Valid {
+		return nil, errors.New("invalid token")
+	}
+	return token, nil
+}
+
+// CheckUserSession makes sure the session a user token was issued for was not revoked. Link share tokens don't have sessions.
+// Tokens issued to an oauth2 client are checked against their grant instead and get the scopes of that grant.
+func CheckUserSession(token *jwt.Token, ipAddress string) error {
+	claims := token.Claims.(jwt.MapClaims)
+	typ, _ := claims["type"].(float64)
+	if int(typ) != AuthTypeUser {
+		return nil
+	}
+
+	if _, has := claims[oauth2GrantClaim]; has {
+		return checkOAuth2Grant(claims)
+	}
+
+	sessionID, _ := claims["sid"].(string)
+	userID, _ := claims["id"].(float64)
+	if sessionID == "" {
+		return &user.ErrSessionDoesNotExist{}
+	}
+
+	s := db.NewSession()
+	defer s.Close()
+
+	if err := user.CheckSession(s, sessionID, int64(userID), ipAddress); err != nil {
+		_ = s.Rollback()
+		return err
+	}
+
+	return s.Commit()
 }
 
 // NewLinkShareJWTAuthtoken creates a new jwt token from a link share
 func NewLinkShareJWTAuthtoken(share *models.LinkSharing) (token string, err error) {
-	t := jwt.New(jwt.SigningMethodHS256)
-
 	var ttl = time.Duration(config.ServiceJWTTTL.GetInt64())
 	var exp = time.Now().Add(time.Second * ttl).Unix()
 
 	// Set claims
-	claims := t.Claims.(jwt.MapClaims)
+	claims := jwt.MapClaims{}
 	claims["type"] = AuthTypeLinkShare
 	claims["id"] = share.ID
 	claims["hash"] = share.Hash
@@ -92,7 +154,7 @@ func NewLinkShareJWTAuthtoken(share *models.LinkSharing) (token string, err erro
 	claims["isLocalUser"] = true // Link shares are always local
 
 	// Generate encoded token and send it as response.
-	return t.SignedString([]byte(config.ServiceJWTSecret.GetString()))
+	return signJWT(claims)
 }
 
 // GetAuthFromClaims returns a web.Auth object from jwt claims
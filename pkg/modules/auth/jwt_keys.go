@@ -0,0 +1,367 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/models"
+	"code.vikunja.io/api/pkg/utils"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// These are all supported jwt signing algorithms
+const (
+	JWTAlgorithmHS256 = "HS256"
+	JWTAlgorithmRS256 = "RS256"
+	JWTAlgorithmEdDSA = "EdDSA"
+)
+
+// Keys are reloaded from the database regularly to pick up keys which were rotated by another process.
+const (
+	jwtKeyCacheTTL = time.Minute
+	// If a token has an unknown key id, the keys are reloaded, but not more often than this.
+	jwtKeyMinReloadInterval = 10 * time.Second
+)
+
+type jwtKey struct {
+	id         string
+	method     jwt.SigningMethod
+	privateKey crypto.Signer
+	retired    bool
+}
+
+var jwtKeyCache = struct {
+	sync.Mutex
+	keys   []*jwtKey
+	loaded time.Time
+}{}
+
+func isAsymmetricJWTAlgorithm() bool {
+	return config.ServiceJWTAlgorithm.GetString() != JWTAlgorithmHS256
+}
+
+func getJWTSigningMethod(algorithm string) (jwt.SigningMethod, error) {
+	switch algorithm {
+	case JWTAlgorithmRS256:
+		return jwt.SigningMethodRS256, nil
+	case JWTAlgorithmEdDSA:
+		return jwt.SigningMethodEdDSA, nil
+	}
+	return nil, fmt.Errorf("unsupported jwt algorithm %s, must be one of %s, %s or %s", algorithm, JWTAlgorithmHS256, JWTAlgorithmRS256, JWTAlgorithmEdDSA)
+}
+
+func parseJWTKey(key *models.JWTKey) (*jwtKey, error) {
+	method, err := getJWTSigningMethod(key.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode([]byte(key.PrivateKey))
+	if block == nil {
+		return nil, fmt.Errorf("could not decode jwt key %s", key.ID)
+	}
+	privateKey, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	signer, is := privateKey.(crypto.Signer)
+	if !is {
+		return nil, fmt.Errorf("jwt key %s can't be used for signing", key.ID)
+	}
+
+	return &jwtKey{
+		id:         key.ID,
+		method:     method,
+		privateKey: signer,
+		retired:    !key.RetiredAt.IsZero(),
+	}, nil
+}
+
+// Keys have to be kept until all tokens signed with them expired. Link share tokens live the longest.
+func getJWTKeyRetention() time.Duration {
+	return time.Duration(config.ServiceJWTTTL.GetInt64()) * time.Second
+}
+
+func loadJWTKeys() error {
+	s := db.NewSession()
+	defer s.Close()
+
+	keys, err := models.GetJWTKeys(s, time.Now().Add(-getJWTKeyRetention()))
+	if err != nil {
+		return err
+	}
+
+	jwtKeyCache.keys = make([]*jwtKey, 0, len(keys))
+	for _, key := range keys {
+		k, err := parseJWTKey(key)
+		if err != nil {
+			return err
+		}
+		jwtKeyCache.keys = append(jwtKeyCache.keys, k)
+	}
+	jwtKeyCache.loaded = time.Now()
+	return nil
+}
+
+func getJWTKeys() ([]*jwtKey, error) {
+	jwtKeyCache.Lock()
+	defer jwtKeyCache.Unlock()
+
+	if time.Since(jwtKeyCache.loaded) > jwtKeyCacheTTL {
+		if err := loadJWTKeys(); err != nil {
+			return nil, err
+		}
+	}
+	return jwtKeyCache.keys, nil
+}
+
+func getJWTVerificationKey(id string) (*jwtKey, error) {
+	keys, err := getJWTKeys()
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range keys {
+		if key.id == id {
+			return key, nil
+		}
+	}
+
+	// The key might have been created by another instance since we loaded the keys the last time
+	jwtKeyCache.Lock()
+	defer jwtKeyCache.Unlock()
+	if time.Since(jwtKeyCache.loaded) > jwtKeyMinReloadInterval {
+		if err := loadJWTKeys(); err != nil {
+			return nil, err
+		}
+		for _, key := range jwtKeyCache.keys {
+			if key.id == id {
+				return key, nil
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("unknown jwt key id %s", id)
+}
+
+func getJWTSigningKey() (*jwtKey, error) {
+	method, err := getJWTSigningMethod(config.ServiceJWTAlgorithm.GetString())
+	if err != nil {
+		return nil, err
+	}
+
+	findKey := func() (*jwtKey, error) {
+		keys, err := getJWTKeys()
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range keys {
+			if !key.retired && key.method == method {
+				return key, nil
+			}
+		}
+		return nil, nil
+	}
+
+	key, err := findKey()
+	if err != nil || key != nil {
+		return key, err
+	}
+
+	// There is no key for the configured algorithm yet, which happens when it is used for the first time
+	if _, err := RotateJWTKeys(); err != nil {
+		return nil, err
+	}
+
+	key, err = findKey()
+	if err != nil {
+		return nil, err
+	}
+	if key == nil {
+		return nil, fmt.Errorf("could not find a jwt key for %s", method.Alg())
+	}
+	return key, nil
+}
+
+// GenerateJWTKey creates a new private key for a jwt algorithm.
+func GenerateJWTKey(algorithm string) (key *models.JWTKey, err error) {
+	if _, err := getJWTSigningMethod(algorithm); err != nil {
+		return nil, err
+	}
+
+	var privateKey interface{}
+	switch algorithm {
+	case JWTAlgorithmRS256:
+		privateKey, err = rsa.GenerateKey(rand.Reader, 2048)
+	case JWTAlgorithmEdDSA:
+		_, privateKey, err = ed25519.GenerateKey(rand.Reader)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.JWTKey{
+		ID:         utils.MakeRandomString(20),
+		Algorithm:  algorithm,
+		PrivateKey: string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+	}, nil
+}
+
+// RotateJWTKeys creates a new key for the configured algorithm which is used to sign all new tokens.
+// The previous keys are still used to verify tokens until all tokens signed with them expired.
+func RotateJWTKeys() (key *models.JWTKey, err error) {
+	if !isAsymmetricJWTAlgorithm() {
+		return nil, fmt.Errorf("keys can only be rotated if service.jwtalgorithm is %s or %s, with %s tokens are signed with service.jwtsecret", JWTAlgorithmRS256, JWTAlgorithmEdDSA, JWTAlgorithmHS256)
+	}
+
+	key, err = GenerateJWTKey(config.ServiceJWTAlgorithm.GetString())
+	if err != nil {
+		return nil, err
+	}
+
+	s := db.NewSession()
+	defer s.Close()
+
+	err = models.RotateJWTKeys(s, key, time.Now().Add(-getJWTKeyRetention()))
+	if err != nil {
+		_ = s.Rollback()
+		return nil, err
+	}
+
+	if err := s.Commit(); err != nil {
+		_ = s.Rollback()
+		return nil, err
+	}
+
+	// Make sure the new key is used right away
+	jwtKeyCache.Lock()
+	jwtKeyCache.loaded = time.Time{}
+	jwtKeyCache.Unlock()
+
+	return key, nil
+}
+
+func signJWT(claims jwt.MapClaims) (string, error) {
+	if !isAsymmetricJWTAlgorithm() {
+		t := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		return t.SignedString([]byte(config.ServiceJWTSecret.GetString()))
+	}
+
+	key, err := getJWTSigningKey()
+	if err != nil {
+		return "", err
+	}
+
+	t := jwt.NewWithClaims(key.method, claims)
+	t.Header["kid"] = key.id
+	return t.SignedString(key.privateKey)
+}
+
+func getJWTVerificationKeyForToken(t *jwt.Token) (interface{}, error) {
+	if !isAsymmetricJWTAlgorithm() {
+		if t.Method.Alg() != JWTAlgorithmHS256 {
+			return nil, fmt.Errorf("unexpected jwt signing method=%v", t.Header["alg"])
+		}
+		return []byte(config.ServiceJWTSecret.GetString()), nil
+	}
+
+	kid, _ := t.Header["kid"].(string)
+	key, err := getJWTVerificationKey(kid)
+	if err != nil {
+		return nil, err
+	}
+	// Never trust the algorithm of the token itself
+	if t.Method.Alg() != key.method.Alg() {
+		return nil, fmt.Errorf("unexpected jwt signing method=%v", t.Header["alg"])
+	}
+	return key.privateKey.Public(), nil
+}
+
+// JWK is a public key in the JSON Web Key format as defined in RFC 7517.
+type JWK struct {
+	KeyType   string `json:"kty"`
+	Use       string `json:"use"`
+	KeyID     string `json:"kid"`
+	Algorithm string `json:"alg"`
+	// RSA keys
+	Modulus  string `json:"n,omitempty"`
+	Exponent string `json:"e,omitempty"`
+	// Ed25519 keys
+	Curve string `json:"crv,omitempty"`
+	X     string `json:"x,omitempty"`
+}
+
+// JWKS is a set of public keys.
+type JWKS struct {
+	Keys []*JWK `json:"keys"`
+}
+
+// GetJWKS returns the public keys of all keys which can currently be used to verify tokens.
+// It is empty when tokens are signed with the shared secret.
+func GetJWKS() (jwks *JWKS, err error) {
+	jwks = &JWKS{Keys: []*JWK{}}
+	if !isAsymmetricJWTAlgorithm() {
+		return jwks, nil
+	}
+
+	keys, err := getJWTKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, key := range keys {
+		jwk := &JWK{
+			Use:       "sig",
+			KeyID:     key.id,
+			Algorithm: key.method.Alg(),
+		}
+		switch publicKey := key.privateKey.Public().(type) {
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.Modulus = base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes())
+			jwk.Exponent = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(publicKey)
+		default:
+			continue
+		}
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+
+	return jwks, nil
+}
@@ -0,0 +1,153 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/models"
+	"code.vikunja.io/api/pkg/user"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupJWTKeyTest(t *testing.T, algorithm string) {
+	db.LoadAndAssertFixtures(t)
+	s := db.NewSession()
+	defer s.Close()
+	_, err := s.Where("1 = 1").Delete(&models.JWTKey{})
+	assert.NoError(t, err)
+	assert.NoError(t, s.Commit())
+
+	jwtKeyCache.loaded = time.Time{}
+	config.ServiceJWTAlgorithm.Set(algorithm)
+	t.Cleanup(func() {
+		config.ServiceJWTAlgorithm.Set(JWTAlgorithmHS256)
+		jwtKeyCache.loaded = time.Time{}
+	})
+}
+
+func TestSignJWT(t *testing.T) {
+	u := &user.User{ID: 1, Username: "user1"}
+
+	for _, algorithm := range []string{JWTAlgorithmHS256, JWTAlgorithmRS256, JWTAlgorithmEdDSA} {
+		t.Run(algorithm, func(t *testing.T) {
+			setupJWTKeyTest(t, algorithm)
+
+			raw, err := NewUserJWTAuthtoken(u, "session")
+			assert.NoError(t, err)
+
+			token, err := ParseJWT(raw)
+			assert.NoError(t, err)
+			assert.Equal(t, algorithm, token.Method.Alg())
+			assert.Equal(t, "user1", token.Claims.(jwt.MapClaims)["username"])
+		})
+	}
+}
+
+func TestParseJWT(t *testing.T) {
+	t.Run("token signed with the secret while using keys", func(t *testing.T) {
+		setupJWTKeyTest(t, JWTAlgorithmHS256)
+		raw, err := NewUserJWTAuthtoken(&user.User{ID: 1}, "session")
+		assert.NoError(t, err)
+
+		config.ServiceJWTAlgorithm.Set(JWTAlgorithmRS256)
+		_, err = ParseJWT(raw)
+		assert.Error(t, err)
+	})
+	t.Run("wrong algorithm for the key", func(t *testing.T) {
+		setupJWTKeyTest(t, JWTAlgorithmRS256)
+		_, err := NewUserJWTAuthtoken(&user.User{ID: 1}, "session")
+		assert.NoError(t, err)
+		key, err := getJWTSigningKey()
+		assert.NoError(t, err)
+
+		// The public key must not be usable as hmac secret
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"id": 1})
+		token.Header["kid"] = key.id
+		raw, err := token.SignedString([]byte("secret"))
+		assert.NoError(t, err)
+		_, err = ParseJWT(raw)
+		assert.Error(t, err)
+	})
+}
+
+func TestRotateJWTKeys(t *testing.T) {
+	t.Run("normal", func(t *testing.T) {
+		setupJWTKeyTest(t, JWTAlgorithmEdDSA)
+
+		oldToken, err := NewUserJWTAuthtoken(&user.User{ID: 1}, "session")
+		assert.NoError(t, err)
+		oldKey, err := getJWTSigningKey()
+		assert.NoError(t, err)
+
+		newKey, err := RotateJWTKeys()
+		assert.NoError(t, err)
+		assert.NotEqual(t, oldKey.id, newKey.ID)
+
+		newToken, err := NewUserJWTAuthtoken(&user.User{ID: 1}, "session")
+		assert.NoError(t, err)
+		token, err := ParseJWT(newToken)
+		assert.NoError(t, err)
+		assert.Equal(t, newKey.ID, token.Header["kid"])
+
+		// Tokens signed with the old key are still valid
+		token, err = ParseJWT(oldToken)
+		assert.NoError(t, err)
+		assert.Equal(t, oldKey.id, token.Header["kid"])
+
+		jwks, err := GetJWKS()
+		assert.NoError(t, err)
+		assert.Len(t, jwks.Keys, 2)
+		kids := []string{}
+		for _, jwk := range jwks.Keys {
+			kids = append(kids, jwk.KeyID)
+			assert.Equal(t, "OKP", jwk.KeyType)
+			assert.NotEmpty(t, jwk.X)
+		}
+		assert.ElementsMatch(t, []string{oldKey.id, newKey.ID}, kids)
+	})
+	t.Run("hs256", func(t *testing.T) {
+		setupJWTKeyTest(t, JWTAlgorithmHS256)
+
+		_, err := RotateJWTKeys()
+		assert.Error(t, err)
+
+		jwks, err := GetJWKS()
+		assert.NoError(t, err)
+		assert.Len(t, jwks.Keys, 0)
+	})
+}
+
+func TestGetJWKS(t *testing.T) {
+	setupJWTKeyTest(t, JWTAlgorithmRS256)
+
+	key, err := getJWTSigningKey()
+	assert.NoError(t, err)
+
+	jwks, err := GetJWKS()
+	assert.NoError(t, err)
+	assert.Len(t, jwks.Keys, 1)
+	assert.Equal(t, key.id, jwks.Keys[0].KeyID)
+	assert.Equal(t, "RSA", jwks.Keys[0].KeyType)
+	assert.Equal(t, JWTAlgorithmRS256, jwks.Keys[0].Algorithm)
+	assert.Equal(t, "AQAB", jwks.Keys[0].Exponent)
+}
@@ -0,0 +1,216 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package ldap
+
+import (
+	"crypto/tls"
+	"fmt"
+	"strings"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/log"
+	"code.vikunja.io/api/pkg/models"
+	"code.vikunja.io/api/pkg/user"
+
+	petname "github.com/dustinkirkland/golang-petname"
+	"github.com/go-ldap/ldap/v3"
+	"xorm.io/xorm"
+)
+
+// IssuerLDAP is the issuer of all users and teams which were created from the ldap directory.
+const IssuerLDAP = `ldap`
+
+// connection is the subset of an ldap connection we need. It exists so tests can replace the
+// directory with an in-memory stand-in.
+type connection interface {
+	Bind(username, password string) error
+	Search(searchRequest *ldap.SearchRequest) (*ldap.SearchResult, error)
+	Close()
+}
+
+// dial opens a new connection to the configured ldap server.
+var dial = func() (connection, error) {
+	scheme := "ldap"
+	if config.AuthLDAPUseTLS.GetBool() {
+		scheme = "ldaps"
+	}
+	url := fmt.Sprintf("%s://%s:%d", scheme, config.AuthLDAPHost.GetString(), config.AuthLDAPPort.GetInt())
+
+	return ldap.DialURL(url, ldap.DialWithTLSConfig(&tls.Config{
+		ServerName:         config.AuthLDAPHost.GetString(),
+		InsecureSkipVerify: !config.AuthLDAPVerifyTLS.GetBool(), //nolint:gosec
+	}))
+}
+
+// AuthenticateUserInLDAP checks the credentials of a user against the ldap directory and returns the matching
+// Vikunja user. If the user does not exist in Vikunja yet, it is created.
+// If group sync is enabled, the user's team memberships are updated to match their ldap groups.
+func AuthenticateUserInLDAP(s *xorm.Session, username, password string) (u *user.User, err error) {
+	if username == "" || password == "" {
+		return nil, user.ErrNoUsernamePassword{}
+	}
+
+	l, err := dial()
+	if err != nil {
+		return nil, err
+	}
+	defer l.Close()
+
+	if config.AuthLDAPBindDN.GetString() != "" {
+		err = l.Bind(config.AuthLDAPBindDN.GetString(), config.AuthLDAPBindPassword.GetString())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	entry, err := searchUser(l, username)
+	if err != nil {
+		return nil, err
+	}
+
+	// Verify the password by binding as the user
+	err = l.Bind(entry.DN, password)
+	if err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+			return nil, user.ErrWrongUsernameOrPassword{}
+		}
+		return nil, err
+	}
+
+	u, err = getOrCreateUser(s, entry)
+	if err != nil {
+		return nil, err
+	}
+
+	if !config.AuthLDAPGroupSyncEnabled.GetBool() {
+		return u, nil
+	}
+
+	err = models.SyncExternalTeamsForUser(s, u, IssuerLDAP, getTeamsFromEntry(entry))
+	return u, err
+}
+
+func searchUser(l connection, username string) (entry *ldap.Entry, err error) {
+	req := ldap.NewSearchRequest(
+		config.AuthLDAPBaseDN.GetString(),
+		ldap.ScopeWholeSubtree,
+		ldap.NeverDerefAliases,
+		0,
+		0,
+		false,
+		fmt.Sprintf(config.AuthLDAPUserFilter.GetString(), ldap.EscapeFilter(username)),
+		[]string{
+			"dn",
+			config.AuthLDAPAttributeUsername.GetString(),
+			config.AuthLDAPAttributeEmail.GetString(),
+			config.AuthLDAPAttributeDisplayname.GetString(),
+			config.AuthLDAPAttributeMemberOf.GetString(),
+		},
+		nil,
+	)
+
+	res, err := l.Search(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// A filter which matches more than one user is a configuration error, we don't want to guess which one it is.
+	if len(res.Entries) != 1 {
+		if len(res.Entries) > 1 {
+			log.Warningf("LDAP user filter matched %d entries for username %s", len(res.Entries), username)
+		}
+		return nil, user.ErrWrongUsernameOrPassword{}
+	}
+
+	return res.Entries[0], nil
+}
+
+func getOrCreateUser(s *xorm.Session, entry *ldap.Entry) (u *user.User, err error) {
+	username := entry.GetAttributeValue(config.AuthLDAPAttributeUsername.GetString())
+	email := entry.GetAttributeValue(config.AuthLDAPAttributeEmail.GetString())
+	name := entry.GetAttributeValue(config.AuthLDAPAttributeDisplayname.GetString())
+
+	if email == "" {
+		return nil, &user.ErrNoLDAPEmailProvided{}
+	}
+
+	u, err = user.GetUserWithEmail(s, &user.User{
+		Issuer:  IssuerLDAP,
+		Subject: username,
+	})
+	if err != nil && !user.IsErrUserDoesNotExist(err) {
+		return nil, err
+	}
+
+	if user.IsErrUserDoesNotExist(err) {
+		uu := &user.User{
+			Username: username,
+			Email:    email,
+			Name:     name,
+			Status:   user.StatusActive,
+			Issuer:   IssuerLDAP,
+			Subject:  username,
+		}
+
+		u, err = user.CreateUser(s, uu)
+		if err != nil && !user.IsErrUsernameExists(err) {
+			return nil, err
+		}
+
+		// If the username is already taken by another user, create a random one
+		if user.IsErrUsernameExists(err) {
+			uu.Username = petname.Generate(3, "-")
+			u, err = user.CreateUser(s, uu)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		err = models.CreateNewNamespaceForUser(s, u)
+		return u, err
+	}
+
+	// Keep email and name in sync with the directory
+	if email != u.Email || name != u.Name {
+		err = user.UpdateUserEmailAndName(s, u, email, name)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return u, nil
+}
+
+// getTeamsFromEntry returns a team for every group the user is a member of. The team is named after the
+// value of the first component of the group's DN, usually its cn.
+func getTeamsFromEntry(entry *ldap.Entry) (teams []*models.ExternalTeam) {
+	groups := entry.GetAttributeValues(config.AuthLDAPAttributeMemberOf.GetString())
+	teams = make([]*models.ExternalTeam, 0, len(groups))
+	for _, group := range groups {
+		dn, err := ldap.ParseDN(group)
+		if err != nil || len(dn.RDNs) == 0 || len(dn.RDNs[0].Attributes) == 0 {
+			log.Warningf("Could not parse LDAP group DN %s, not syncing it: %v", group, err)
+			continue
+		}
+
+		teams = append(teams, &models.ExternalTeam{
+			ExternalID: strings.ToLower(group),
+			Name:       dn.RDNs[0].Attributes[0].Value,
+		})
+	}
+	return
+}
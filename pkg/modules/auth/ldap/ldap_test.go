@@ -0,0 +1,237 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package ldap
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/models"
+	"code.vikunja.io/api/pkg/user"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeDirectory is an in-memory stand-in for an ldap server.
+type fakeDirectory struct {
+	entries   []*ldap.Entry
+	passwords map[string]string
+}
+
+func (d *fakeDirectory) Bind(username, password string) error {
+	if p, has := d.passwords[username]; has && p == password {
+		return nil
+	}
+	return ldap.NewError(ldap.LDAPResultInvalidCredentials, errors.New("invalid credentials"))
+}
+
+func (d *fakeDirectory) Search(searchRequest *ldap.SearchRequest) (*ldap.SearchResult, error) {
+	res := &ldap.SearchResult{}
+	for _, entry := range d.entries {
+		uid := entry.GetAttributeValue("uid")
+		if strings.Contains(searchRequest.Filter, "(uid="+ldap.EscapeFilter(uid)+")") {
+			res.Entries = append(res.Entries, entry)
+		}
+	}
+	return res, nil
+}
+
+func (d *fakeDirectory) Close() {}
+
+func setupFakeDirectory(t *testing.T, groupSync bool, entries ...*ldap.Entry) *fakeDirectory {
+	d := &fakeDirectory{
+		entries: entries,
+		passwords: map[string]string{
+			"uid=jdoe,ou=people,dc=example,dc=com":   "secret",
+			"uid=user1,ou=people,dc=example,dc=com":  "secret",
+			"uid=nomail,ou=people,dc=example,dc=com": "secret",
+		},
+	}
+	dial = func() (connection, error) {
+		return d, nil
+	}
+	config.AuthLDAPGroupSyncEnabled.Set(groupSync)
+	t.Cleanup(func() {
+		config.AuthLDAPGroupSyncEnabled.Set(false)
+	})
+	return d
+}
+
+func newUserEntry(uid, mail string, groups ...string) *ldap.Entry {
+	return ldap.NewEntry("uid="+uid+",ou=people,dc=example,dc=com", map[string][]string{
+		"uid":         {uid},
+		"mail":        {mail},
+		"displayName": {"John Doe"},
+		"memberOf":    groups,
+	})
+}
+
+func TestAuthenticateUserInLDAP(t *testing.T) {
+	t.Run("new user", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+		setupFakeDirectory(t, false, newUserEntry("jdoe", "jdoe@example.com"))
+
+		u, err := AuthenticateUserInLDAP(s, "jdoe", "secret")
+		assert.NoError(t, err)
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		db.AssertExists(t, "users", map[string]interface{}{
+			"id":       u.ID,
+			"username": "jdoe",
+			"email":    "jdoe@example.com",
+			"name":     "John Doe",
+			"issuer":   IssuerLDAP,
+			"subject":  "jdoe",
+		}, false)
+		db.AssertExists(t, "namespaces", map[string]interface{}{
+			"owner_id": u.ID,
+		}, false)
+	})
+	t.Run("existing user", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+		d := setupFakeDirectory(t, false, newUserEntry("jdoe", "jdoe@example.com"))
+
+		u, err := AuthenticateUserInLDAP(s, "jdoe", "secret")
+		assert.NoError(t, err)
+		_, err = s.
+			ID(u.ID).
+			Cols("digest_interval", "timezone", "language").
+			Update(&user.User{DigestInterval: user.DigestIntervalDaily, Timezone: "Europe/Berlin", Language: "de-DE"})
+		assert.NoError(t, err)
+
+		d.entries[0] = newUserEntry("jdoe", "new@example.com")
+		u2, err := AuthenticateUserInLDAP(s, "jdoe", "secret")
+		assert.NoError(t, err)
+		assert.Equal(t, u.ID, u2.ID)
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		// Settings of the user are kept
+		db.AssertExists(t, "users", map[string]interface{}{
+			"id":              u.ID,
+			"email":           "new@example.com",
+			"name":            "John Doe",
+			"digest_interval": user.DigestIntervalDaily,
+			"timezone":        "Europe/Berlin",
+			"language":        "de-DE",
+		}, false)
+	})
+	t.Run("username taken by a local user", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+		setupFakeDirectory(t, false, newUserEntry("user1", "user1@ldap.example.com"))
+
+		u, err := AuthenticateUserInLDAP(s, "user1", "secret")
+		assert.NoError(t, err)
+		assert.NotEqual(t, int64(1), u.ID)
+		assert.NotEqual(t, "user1", u.Username)
+	})
+	t.Run("wrong password", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+		setupFakeDirectory(t, false, newUserEntry("jdoe", "jdoe@example.com"))
+
+		_, err := AuthenticateUserInLDAP(s, "jdoe", "wrong")
+		assert.Error(t, err)
+		assert.True(t, user.IsErrWrongUsernameOrPassword(err))
+	})
+	t.Run("unknown user", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+		setupFakeDirectory(t, false, newUserEntry("jdoe", "jdoe@example.com"))
+
+		_, err := AuthenticateUserInLDAP(s, "unknown", "secret")
+		assert.Error(t, err)
+		assert.True(t, user.IsErrWrongUsernameOrPassword(err))
+	})
+	t.Run("no email", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+		setupFakeDirectory(t, false, newUserEntry("nomail", ""))
+
+		_, err := AuthenticateUserInLDAP(s, "nomail", "secret")
+		assert.Error(t, err)
+		assert.True(t, user.IsErrNoLDAPEmailProvided(err))
+	})
+}
+
+func TestLDAPGroupSync(t *testing.T) {
+	t.Run("creates teams and memberships", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+		setupFakeDirectory(t, true, newUserEntry("jdoe", "jdoe@example.com",
+			"cn=developers,ou=groups,dc=example,dc=com",
+			"cn=ops,ou=groups,dc=example,dc=com",
+		))
+
+		u, err := AuthenticateUserInLDAP(s, "jdoe", "secret")
+		assert.NoError(t, err)
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		db.AssertExists(t, "teams", map[string]interface{}{
+			"name":        "developers",
+			"external_id": "cn=developers,ou=groups,dc=example,dc=com",
+			"issuer":      IssuerLDAP,
+		}, false)
+		db.AssertExists(t, "teams", map[string]interface{}{
+			"name":        "ops",
+			"external_id": "cn=ops,ou=groups,dc=example,dc=com",
+			"issuer":      IssuerLDAP,
+		}, false)
+		db.AssertExists(t, "team_members", map[string]interface{}{
+			"user_id": u.ID,
+			"admin":   false,
+		}, false)
+	})
+	t.Run("removes memberships of groups the user left", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+		d := setupFakeDirectory(t, true, newUserEntry("jdoe", "jdoe@example.com",
+			"cn=developers,ou=groups,dc=example,dc=com",
+			"cn=ops,ou=groups,dc=example,dc=com",
+		))
+
+		u, err := AuthenticateUserInLDAP(s, "jdoe", "secret")
+		assert.NoError(t, err)
+
+		d.entries[0] = newUserEntry("jdoe", "jdoe@example.com", "cn=developers,ou=groups,dc=example,dc=com")
+		_, err = AuthenticateUserInLDAP(s, "jdoe", "secret")
+		assert.NoError(t, err)
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		count, err := s.Where("user_id = ?", u.ID).Count(&models.TeamMember{})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+	})
+}
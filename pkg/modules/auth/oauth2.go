@@ -0,0 +1,177 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package auth
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/models"
+	"code.vikunja.io/api/pkg/user"
+
+	"github.com/golang-jwt/jwt/v4"
+	"xorm.io/xorm"
+)
+
+// The claims which tell access tokens of oauth2 clients apart from regular user tokens.
+const (
+	oauth2GrantClaim  = "gid"
+	oauth2ClientClaim = "client_id"
+)
+
+// OAuth2Token is the response of the oauth2 token endpoint.
+type OAuth2Token struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+	RefreshToken string `json:"refresh_token"`
+	Scope        string `json:"scope"`
+}
+
+// OAuth2Introspection is the response of the oauth2 token introspection endpoint as defined in RFC 7662.
+type OAuth2Introspection struct {
+	Active    bool   `json:"active"`
+	Scope     string `json:"scope,omitempty"`
+	ClientID  string `json:"client_id,omitempty"`
+	Username  string `json:"username,omitempty"`
+	Subject   string `json:"sub,omitempty"`
+	ExpiresAt int64  `json:"exp,omitempty"`
+	TokenType string `json:"token_type,omitempty"`
+}
+
+// NewOAuth2Token creates a signed access token for a grant and returns it together with the refresh token of the grant.
+func NewOAuth2Token(u *user.User, grant *models.OAuth2Grant, refreshToken string) (token *OAuth2Token, err error) {
+	var ttl = config.ServiceJWTTTLShort.GetInt64()
+	var exp = time.Now().Add(time.Second * time.Duration(ttl)).Unix()
+
+	// The scopes are not part of the token, they are loaded from the grant on every request
+	claims := jwt.MapClaims{}
+	claims["type"] = AuthTypeUser
+	claims["id"] = u.ID
+	claims[oauth2GrantClaim] = grant.ID
+	claims[oauth2ClientClaim] = grant.ClientID
+	claims["username"] = u.Username
+	claims["email"] = u.Email
+	claims["exp"] = exp
+	claims["name"] = u.Name
+	claims["emailRemindersEnabled"] = u.EmailRemindersEnabled
+	claims["isLocalUser"] = u.Issuer == user.IssuerLocal
+
+	accessToken, err := signJWT(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	return &OAuth2Token{
+		AccessToken:  accessToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    ttl,
+		RefreshToken: refreshToken,
+		Scope:        strings.Join(grant.Scopes, " "),
+	}, nil
+}
+
+func checkOAuth2Grant(claims jwt.MapClaims) error {
+	grantID, _ := claims[oauth2GrantClaim].(string)
+	userID, _ := claims["id"].(float64)
+
+	s := db.NewSession()
+	defer s.Close()
+
+	grant, err := models.CheckOAuth2Grant(s, grantID, int64(userID))
+	if err != nil {
+		_ = s.Rollback()
+		return err
+	}
+
+	u, err := user.GetUserByID(s, grant.UserID)
+	if err != nil {
+		_ = s.Rollback()
+		return err
+	}
+
+	if u.Status != user.StatusActive {
+		_ = s.Rollback()
+		return &user.ErrAccountDisabled{UserID: u.ID}
+	}
+
+	// From here on, the token is treated like an api token with the scopes of the grant
+	claims[models.APITokenScopesClaim] = grant.Scopes
+	return s.Commit()
+}
+
+// IntrospectOAuth2Token returns whether an access or refresh token issued to a client is still active.
+// Tokens issued to other clients are always reported as inactive.
+func IntrospectOAuth2Token(s *xorm.Session, client *models.OAuth2Client, rawToken string) (introspection *OAuth2Introspection, err error) {
+	introspection = &OAuth2Introspection{}
+
+	token, err := ParseJWT(rawToken)
+	if err == nil {
+		claims := token.Claims.(jwt.MapClaims)
+		grantID, _ := claims[oauth2GrantClaim].(string)
+		clientID, _ := claims[oauth2ClientClaim].(string)
+		userID, _ := claims["id"].(float64)
+		exp, _ := claims["exp"].(float64)
+		if grantID == "" || clientID != client.ClientID {
+			return introspection, nil
+		}
+
+		grant, err := models.CheckOAuth2Grant(s, grantID, int64(userID))
+		if models.IsErrOAuth2GrantDoesNotExist(err) {
+			return introspection, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		return newOAuth2Introspection(s, grant, int64(exp), "access_token")
+	}
+
+	grant, err := models.GetOAuth2GrantByRefreshToken(s, client, rawToken)
+	if models.IsErrOAuth2InvalidGrant(err) {
+		return introspection, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return newOAuth2Introspection(s, grant, 0, "refresh_token")
+}
+
+func newOAuth2Introspection(s *xorm.Session, grant *models.OAuth2Grant, exp int64, tokenType string) (*OAuth2Introspection, error) {
+	u, err := user.GetUserByID(s, grant.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if u.Status != user.StatusActive {
+		return &OAuth2Introspection{}, nil
+	}
+
+	return &OAuth2Introspection{
+		Active:    true,
+		Scope:     strings.Join(grant.Scopes, " "),
+		ClientID:  grant.ClientID,
+		Username:  u.Username,
+		Subject:   strconv.FormatInt(u.ID, 10),
+		ExpiresAt: exp,
+		TokenType: tokenType,
+	}, nil
+}
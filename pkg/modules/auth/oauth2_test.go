@@ -0,0 +1,58 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package auth
+
+import (
+	"testing"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/models"
+	"code.vikunja.io/api/pkg/user"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckOAuth2Grant(t *testing.T) {
+	newClaims := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"id":             float64(1),
+			oauth2GrantClaim: "f1e2d3c4b5a6f7e8d9c0b1a2f3e4d5c6b7a8f9e0",
+		}
+	}
+
+	t.Run("active user", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+
+		claims := newClaims()
+		err := checkOAuth2Grant(claims)
+		assert.NoError(t, err)
+		assert.NotNil(t, claims[models.APITokenScopesClaim])
+	})
+	t.Run("disabled user", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		_, err := s.Where("id = ?", 1).Cols("status").Update(&user.User{Status: user.StatusDisabled})
+		assert.NoError(t, err)
+		assert.NoError(t, s.Commit())
+		s.Close()
+
+		err = checkOAuth2Grant(newClaims())
+		assert.Error(t, err)
+		assert.True(t, user.IsErrAccountDisabled(err))
+	})
+}
@@ -52,6 +52,7 @@ type Provider struct {
 	AuthURL         string `json:"auth_url"`
 	ClientID        string `json:"client_id"`
 	ClientSecret    string `json:"-"`
+	GroupsClaim     string `json:"-"`
 	openIDProvider  *oidc.Provider
 	Oauth2Config    *oauth2.Config `json:"-"`
 }
@@ -192,6 +193,22 @@ func HandleCallback(c echo.Context) error {
 		return handler.HandleHTTPError(err, c)
 	}
 
+	if provider.GroupsClaim != "" {
+		teams, err := getTeamsFromToken(idToken, provider.GroupsClaim)
+		if err != nil {
+			_ = s.Rollback()
+			log.Errorf("Error getting groups claim for provider %s: %v", provider.Name, err)
+			return handler.HandleHTTPError(err, c)
+		}
+
+		err = models.SyncExternalTeamsForUser(s, u, idToken.Issuer, teams)
+		if err != nil {
+			_ = s.Rollback()
+			log.Errorf("Error syncing teams for provider %s: %v", provider.Name, err)
+			return handler.HandleHTTPError(err, c)
+		}
+	}
+
 	err = s.Commit()
 	if err != nil {
 		return handler.HandleHTTPError(err, c)
@@ -251,19 +268,7 @@ func getOrCreateUser(s *xorm.Session, cl *claims, issuer, subject string) (u *us
 
 	// If it exists, check if the email address changed and change it if not
 	if cl.Email != u.Email || cl.Name != u.Name {
-		if cl.Email != u.Email {
-			u.Email = cl.Email
-		}
-		if cl.Name != u.Name {
-			u.Name = cl.Name
-		}
-		u, err = user.UpdateUser(s, &user.User{
-			ID:      u.ID,
-			Email:   u.Email,
-			Name:    u.Name,
-			Issuer:  issuer,
-			Subject: subject,
-		})
+		err = user.UpdateUserEmailAndName(s, u, cl.Email, cl.Name)
 		if err != nil {
 			return nil, err
 		}
@@ -271,3 +276,41 @@ func getOrCreateUser(s *xorm.Session, cl *claims, issuer, subject string) (u *us
 
 	return
 }
+
+func getTeamsFromToken(idToken *oidc.IDToken, groupsClaim string) (teams []*models.ExternalTeam, err error) {
+	rawClaims := make(map[string]interface{})
+	err = idToken.Claims(&rawClaims)
+	if err != nil {
+		return nil, err
+	}
+
+	return getTeamsFromGroupsClaim(rawClaims[groupsClaim]), nil
+}
+
+// getTeamsFromGroupsClaim returns a team for every group in a groups claim. Providers either send a list of group
+// names or a single group as a string.
+func getTeamsFromGroupsClaim(claim interface{}) (teams []*models.ExternalTeam) {
+	var groups []string
+	switch c := claim.(type) {
+	case string:
+		groups = []string{c}
+	case []interface{}:
+		for _, g := range c {
+			if group, is := g.(string); is {
+				groups = append(groups, group)
+			}
+		}
+	}
+
+	teams = make([]*models.ExternalTeam, 0, len(groups))
+	for _, group := range groups {
+		if group == "" {
+			continue
+		}
+		teams = append(teams, &models.ExternalTeam{
+			ExternalID: group,
+			Name:       group,
+		})
+	}
+	return
+}
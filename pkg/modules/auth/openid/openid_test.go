@@ -20,6 +20,7 @@ import (
 	"testing"
 
 	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/user"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -80,6 +81,12 @@ func TestGetOrCreateUser(t *testing.T) {
 		s := db.NewSession()
 		defer s.Close()
 
+		_, err := s.
+			ID(14).
+			Cols("digest_interval", "timezone", "language").
+			Update(&user.User{DigestInterval: user.DigestIntervalDaily, Timezone: "Europe/Berlin", Language: "de-DE"})
+		assert.NoError(t, err)
+
 		cl := &claims{
 			Email: "other-email-address@some.service.com",
 		}
@@ -88,9 +95,33 @@ func TestGetOrCreateUser(t *testing.T) {
 		err = s.Commit()
 		assert.NoError(t, err)
 
+		// Settings of the user are kept
 		db.AssertExists(t, "users", map[string]interface{}{
-			"id":    u.ID,
-			"email": cl.Email,
+			"id":              u.ID,
+			"email":           cl.Email,
+			"username":        "user14",
+			"digest_interval": user.DigestIntervalDaily,
+			"timezone":        "Europe/Berlin",
+			"language":        "de-DE",
 		}, false)
 	})
 }
+
+func TestGetTeamsFromGroupsClaim(t *testing.T) {
+	t.Run("list of groups", func(t *testing.T) {
+		teams := getTeamsFromGroupsClaim([]interface{}{"developers", "ops", ""})
+		assert.Len(t, teams, 2)
+		assert.Equal(t, "developers", teams[0].ExternalID)
+		assert.Equal(t, "developers", teams[0].Name)
+		assert.Equal(t, "ops", teams[1].ExternalID)
+	})
+	t.Run("single group", func(t *testing.T) {
+		teams := getTeamsFromGroupsClaim("developers")
+		assert.Len(t, teams, 1)
+		assert.Equal(t, "developers", teams[0].ExternalID)
+	})
+	t.Run("no groups", func(t *testing.T) {
+		teams := getTeamsFromGroupsClaim(nil)
+		assert.Len(t, teams, 0)
+	})
+}
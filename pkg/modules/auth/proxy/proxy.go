@@ -0,0 +1,165 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package proxy
+
+import (
+	"net"
+	"strings"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/log"
+	"code.vikunja.io/api/pkg/models"
+	"code.vikunja.io/api/pkg/modules/auth"
+	"code.vikunja.io/api/pkg/user"
+	"code.vikunja.io/web/handler"
+
+	petname "github.com/dustinkirkland/golang-petname"
+	"github.com/labstack/echo/v4"
+	"xorm.io/xorm"
+)
+
+// IssuerProxy is the issuer of all users which were created from the headers of a trusted proxy.
+const IssuerProxy = `proxy`
+
+// HandleLogin authenticates a user from the headers a trusted reverse proxy sets
+// @Summary Authenticate a user through a trusted proxy
+// @Description Authenticates the user from the headers set by an authenticating reverse proxy in front of Vikunja and returns a jwt token for them. The request must come directly from one of the configured trusted proxies. Users are created if they don't exist yet.
+// @tags auth
+// @Produce json
+// @Success 200 {object} auth.Token
+// @Failure 403 {object} web.HTTPError "The request does not come from a trusted proxy."
+// @Failure 412 {object} web.HTTPError "The proxy did not provide a user and an email address."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /auth/proxy [post]
+func HandleLogin(c echo.Context) error {
+	// We explicitly don't use c.RealIP() here because that trusts the X-Forwarded-For header which anyone can set.
+	ip := getRemoteIP(c.Request().RemoteAddr)
+	if !isTrustedProxy(ip, config.AuthProxyTrustedProxies.GetStringSlice()) {
+		return handler.HandleHTTPError(&user.ErrUntrustedProxy{IP: ip}, c)
+	}
+
+	username := strings.TrimSpace(c.Request().Header.Get(config.AuthProxyUserHeader.GetString()))
+	email := strings.TrimSpace(c.Request().Header.Get(config.AuthProxyEmailHeader.GetString()))
+	if username == "" || email == "" {
+		return handler.HandleHTTPError(&user.ErrNoProxyUserProvided{}, c)
+	}
+
+	s := db.NewSession()
+	defer s.Close()
+
+	u, err := getOrCreateUser(s, username, email)
+	if err != nil {
+		_ = s.Rollback()
+		log.Errorf("Error creating new user from proxy headers: %v", err)
+		return handler.HandleHTTPError(err, c)
+	}
+
+	if u.Status == user.StatusDisabled {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(&user.ErrAccountDisabled{UserID: u.ID}, c)
+	}
+
+	if err := s.Commit(); err != nil {
+		return handler.HandleHTTPError(err, c)
+	}
+
+	return auth.NewUserAuthTokenResponse(u, c)
+}
+
+func getRemoteIP(remoteAddr string) string {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		return remoteAddr
+	}
+	return host
+}
+
+// isTrustedProxy checks if an ip is in one of the trusted proxy ranges. Entries without a prefix length are
+// treated as a single address.
+func isTrustedProxy(rawIP string, trustedProxies []string) bool {
+	ip := net.ParseIP(rawIP)
+	if ip == nil {
+		return false
+	}
+
+	for _, trusted := range trustedProxies {
+		if !strings.Contains(trusted, "/") {
+			if trustedIP := net.ParseIP(trusted); trustedIP != nil && trustedIP.Equal(ip) {
+				return true
+			}
+			continue
+		}
+
+		_, cidr, err := net.ParseCIDR(trusted)
+		if err != nil {
+			log.Errorf("Invalid trusted proxy range %s: %s", trusted, err)
+			continue
+		}
+		if cidr.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func getOrCreateUser(s *xorm.Session, username, email string) (u *user.User, err error) {
+	u, err = user.GetUserWithEmail(s, &user.User{
+		Issuer:  IssuerProxy,
+		Subject: username,
+	})
+	if err != nil && !user.IsErrUserDoesNotExist(err) {
+		return nil, err
+	}
+
+	if user.IsErrUserDoesNotExist(err) {
+		uu := &user.User{
+			Username: username,
+			Email:    email,
+			Status:   user.StatusActive,
+			Issuer:   IssuerProxy,
+			Subject:  username,
+		}
+
+		u, err = user.CreateUser(s, uu)
+		if err != nil && !user.IsErrUsernameExists(err) {
+			return nil, err
+		}
+
+		// If the username is already taken by another user, create a random one
+		if user.IsErrUsernameExists(err) {
+			uu.Username = petname.Generate(3, "-")
+			u, err = user.CreateUser(s, uu)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		err = models.CreateNewNamespaceForUser(s, u)
+		return u, err
+	}
+
+	if email != u.Email {
+		err = user.UpdateUserEmailAndName(s, u, email, u.Name)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return u, nil
+}
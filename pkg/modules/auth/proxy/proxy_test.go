@@ -0,0 +1,102 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package proxy
+
+import (
+	"testing"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/user"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsTrustedProxy(t *testing.T) {
+	trusted := []string{"10.0.0.0/8", "192.168.1.10", "fd00::/8"}
+
+	assert.True(t, isTrustedProxy("10.1.2.3", trusted))
+	assert.True(t, isTrustedProxy("192.168.1.10", trusted))
+	assert.True(t, isTrustedProxy("fd00::1", trusted))
+	assert.False(t, isTrustedProxy("192.168.1.11", trusted))
+	assert.False(t, isTrustedProxy("8.8.8.8", trusted))
+	assert.False(t, isTrustedProxy("not an ip", trusted))
+	assert.False(t, isTrustedProxy("10.1.2.3", []string{}))
+}
+
+func TestGetRemoteIP(t *testing.T) {
+	assert.Equal(t, "10.1.2.3", getRemoteIP("10.1.2.3:4567"))
+	assert.Equal(t, "fd00::1", getRemoteIP("[fd00::1]:4567"))
+	assert.Equal(t, "10.1.2.3", getRemoteIP("10.1.2.3"))
+}
+
+func TestGetOrCreateUser(t *testing.T) {
+	t.Run("new user", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		u, err := getOrCreateUser(s, "proxyuser", "proxyuser@example.com")
+		assert.NoError(t, err)
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		db.AssertExists(t, "users", map[string]interface{}{
+			"id":       u.ID,
+			"username": "proxyuser",
+			"email":    "proxyuser@example.com",
+			"issuer":   IssuerProxy,
+			"subject":  "proxyuser",
+		}, false)
+	})
+	t.Run("existing user", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		u, err := getOrCreateUser(s, "proxyuser", "proxyuser@example.com")
+		assert.NoError(t, err)
+		_, err = s.
+			ID(u.ID).
+			Cols("digest_interval", "timezone", "language").
+			Update(&user.User{DigestInterval: user.DigestIntervalDaily, Timezone: "Europe/Berlin", Language: "de-DE"})
+		assert.NoError(t, err)
+
+		u2, err := getOrCreateUser(s, "proxyuser", "new@example.com")
+		assert.NoError(t, err)
+		assert.Equal(t, u.ID, u2.ID)
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		// Settings of the user are kept
+		db.AssertExists(t, "users", map[string]interface{}{
+			"id":              u.ID,
+			"email":           "new@example.com",
+			"digest_interval": user.DigestIntervalDaily,
+			"timezone":        "Europe/Berlin",
+			"language":        "de-DE",
+		}, false)
+	})
+	t.Run("username taken by a local user", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		u, err := getOrCreateUser(s, "user1", "user1@proxy.example.com")
+		assert.NoError(t, err)
+		assert.NotEqual(t, int64(1), u.ID)
+		assert.NotEqual(t, "user1", u.Username)
+	})
+}
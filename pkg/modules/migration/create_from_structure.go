@@ -59,6 +59,13 @@ func insertFromStructure(s *xorm.Session, str []*models.NamespaceWithListsAndTas
 		}
 
 		log.Debugf("[creating structure] Created namespace %d", n.ID)
+
+		// Old custom field id as key, new field as value
+		customFields := make(map[int64]*models.CustomField)
+		err = createCustomFields(s, n.CustomFields, 0, n.ID, user, customFields)
+		if err != nil {
+			return
+		}
 		log.Debugf("[creating structure] Creating %d lists", len(n.Lists))
 
 		// Create all lists
@@ -78,6 +85,11 @@ func insertFromStructure(s *xorm.Session, str []*models.NamespaceWithListsAndTas
 
 			log.Debugf("[creating structure] Created list %d", l.ID)
 
+			err = createCustomFields(s, l.CustomFields, l.ID, 0, user, customFields)
+			if err != nil {
+				return
+			}
+
 			backgroundFile, is := originalBackgroundInformation.(*bytes.Buffer)
 			if is {
 				log.Debugf("[creating structure] Creating a background file for list %d", l.ID)
@@ -132,6 +144,7 @@ func insertFromStructure(s *xorm.Session, str []*models.NamespaceWithListsAndTas
 				setBucketOrDefault(&t.Task)
 
 				t.ListID = l.ID
+				t.CustomFields = mapCustomFieldValues(t.CustomFields, customFields)
 				err = t.Create(s, user)
 				if err != nil {
 					return
@@ -154,6 +167,7 @@ func insertFromStructure(s *xorm.Session, str []*models.NamespaceWithListsAndTas
 						if rt.ID == 0 {
 							setBucketOrDefault(rt)
 							rt.ListID = t.ListID
+							rt.CustomFields = mapCustomFieldValues(rt.CustomFields, customFields)
 							err = rt.Create(s, user)
 							if err != nil {
 								return
@@ -230,6 +244,21 @@ func insertFromStructure(s *xorm.Session, str []*models.NamespaceWithListsAndTas
 					}
 					log.Debugf("[creating structure] Created new comment %d", comment.ID)
 				}
+
+				for _, entry := range t.TimeEntries {
+					// Running timers can't be imported since they would never be stopped
+					if entry.End.IsZero() {
+						log.Debugf("[creating structure] Skipping running time entry for task %d", t.ID)
+						continue
+					}
+
+					entry.TaskID = t.ID
+					err = entry.Create(s, user)
+					if err != nil {
+						return
+					}
+					log.Debugf("[creating structure] Created new time entry %d", entry.ID)
+				}
 			}
 
 			// All tasks brought their own bucket with them, therefore the newly created default bucket is just extra space
@@ -255,3 +284,40 @@ func insertFromStructure(s *xorm.Session, str []*models.NamespaceWithListsAndTas
 
 	return nil
 }
+
+// createCustomFields creates all custom fields for a list or namespace and puts them in fieldMap with their old
+// id as key.
+func createCustomFields(s *xorm.Session, fields []*models.CustomField, listID, namespaceID int64, user *user.User, fieldMap map[int64]*models.CustomField) (err error) {
+	for _, f := range fields {
+		oldID := f.ID
+		f.ID = 0
+		f.ListID = listID
+		f.NamespaceID = namespaceID
+		err = f.Create(s, user)
+		if err != nil {
+			return
+		}
+		fieldMap[oldID] = f
+		log.Debugf("[creating structure] Created custom field %d, old ID was %d", f.ID, oldID)
+	}
+	return
+}
+
+// mapCustomFieldValues changes the keys of the custom field values of a task to the ids of the newly created
+// fields. Values of fields which were not created and of user fields are dropped since the users usually
+// don't exist on this instance.
+func mapCustomFieldValues(values map[int64]interface{}, fieldMap map[int64]*models.CustomField) map[int64]interface{} {
+	if values == nil {
+		return nil
+	}
+
+	mapped := make(map[int64]interface{}, len(values))
+	for oldID, value := range values {
+		f, exists := fieldMap[oldID]
+		if !exists || f.Type == models.CustomFieldTypeUser {
+			continue
+		}
+		mapped[f.ID] = value
+	}
+	return mapped
+}
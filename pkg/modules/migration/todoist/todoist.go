@@ -21,8 +21,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/url"
+	"regexp"
 	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"code.vikunja.io/api/pkg/config"
@@ -34,6 +36,73 @@ import (
 	"code.vikunja.io/api/pkg/utils"
 )
 
+var (
+	recurrenceIntervalRegex = regexp.MustCompile(`^every (other|\d+)? ?(day|week|month|year)s?$`)
+	recurrenceWeekdaysRegex = regexp.MustCompile(`^every (other )?((?:mon|tues|wednes|thurs|fri|satur|sun)day(?:(?:, ?| and )(?:mon|tues|wednes|thurs|fri|satur|sun)day)*)$`)
+	recurrenceWeekdayRegex  = regexp.MustCompile(`(mon|tues|wednes|thurs|fri|satur|sun)day`)
+
+	recurrenceFrequencies = map[string]string{
+		"day":   "DAILY",
+		"week":  "WEEKLY",
+		"month": "MONTHLY",
+		"year":  "YEARLY",
+	}
+)
+
+// Todoist only gives us the natural language string the user entered for a recurring due date.
+// This converts the most common english ones to a repeat rule, everything else is dropped.
+func parseRecurrence(due *dueDate) string {
+	if due.Lang != "" && due.Lang != "en" {
+		return ""
+	}
+
+	recurrence := strings.ToLower(strings.TrimSpace(due.String))
+	// We don't care about the time, the due date already has it
+	if at := strings.Index(recurrence, " at "); at != -1 {
+		recurrence = recurrence[:at]
+	}
+
+	switch recurrence {
+	case "daily":
+		return "FREQ=DAILY"
+	case "weekly":
+		return "FREQ=WEEKLY"
+	case "monthly":
+		return "FREQ=MONTHLY"
+	case "yearly":
+		return "FREQ=YEARLY"
+	case "every weekday", "every workday":
+		return "FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR"
+	}
+
+	if matches := recurrenceIntervalRegex.FindStringSubmatch(recurrence); matches != nil {
+		rule := "FREQ=" + recurrenceFrequencies[matches[2]]
+		switch matches[1] {
+		case "", "1":
+		case "other":
+			rule += ";INTERVAL=2"
+		default:
+			rule += ";INTERVAL=" + matches[1]
+		}
+		return rule
+	}
+
+	if matches := recurrenceWeekdaysRegex.FindStringSubmatch(recurrence); matches != nil {
+		days := []string{}
+		for _, day := range recurrenceWeekdayRegex.FindAllString(matches[2], -1) {
+			days = append(days, strings.ToUpper(day[:2]))
+		}
+		rule := "FREQ=WEEKLY"
+		if matches[1] != "" {
+			rule += ";INTERVAL=2"
+		}
+		return rule + ";BYDAY=" + strings.Join(days, ",")
+	}
+
+	log.Debugf("[Todoist Migration] Could not convert recurring due date '%s' to a repeat rule", due.String)
+	return ""
+}
+
 // Migration is the todoist migration struct
 type Migration struct {
 	Code string `json:"code"`
@@ -349,6 +418,10 @@ func convertTodoistToVikunja(sync *sync, doneItems map[int64]*doneItem) (fullVik
 				return nil, err
 			}
 			task.DueDate = dueDate.In(config.GetTimeZone())
+
+			if i.Due.IsRecurring {
+				task.RepeatRule = parseRecurrence(i.Due)
+			}
 		}
 
 		// Put all labels together from earlier
@@ -642,3 +642,23 @@ func TestConvertTodoistToVikunja(t *testing.T) {
 		t.Errorf("converted todoist data = %v, want %v, diff: %v", hierachie, expectedHierachie, diff)
 	}
 }
+
+func TestParseRecurrence(t *testing.T) {
+	tests := map[string]string{
+		"every day":                          "FREQ=DAILY",
+		"Every 3 days":                       "FREQ=DAILY;INTERVAL=3",
+		"every other week":                   "FREQ=WEEKLY;INTERVAL=2",
+		"every month at 9am":                 "FREQ=MONTHLY",
+		"every weekday":                      "FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR",
+		"every tuesday":                      "FREQ=WEEKLY;BYDAY=TU",
+		"every other tuesday":                "FREQ=WEEKLY;INTERVAL=2;BYDAY=TU",
+		"every monday, wednesday and friday": "FREQ=WEEKLY;BYDAY=MO,WE,FR",
+		"every 3rd friday":                   "",
+	}
+	for recurrence, want := range tests {
+		t.Run(recurrence, func(t *testing.T) {
+			got := parseRecurrence(&dueDate{String: recurrence, Lang: "en", IsRecurring: true})
+			assert.Equal(t, want, got)
+		})
+	}
+}
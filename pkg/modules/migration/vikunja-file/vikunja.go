@@ -140,6 +140,9 @@ func (v *FileMigrator) Migrate(user *user.User, file io.ReaderAt, size int64) er
 				for _, comment := range t.Comments {
 					comment.ID = 0
 				}
+				for _, entry := range t.TimeEntries {
+					entry.ID = 0
+				}
 				for _, attachment := range t.Attachments {
 					af, err := storedFiles[attachment.File.ID].Open()
 					if err != nil {
@@ -0,0 +1,603 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package rrule
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.vikunja.io/api/pkg/config"
+)
+
+// Frequency is the FREQ part of a recurrence rule
+type Frequency int
+
+// All supported frequencies
+const (
+	FrequencyMinutely Frequency = iota + 1
+	FrequencyHourly
+	FrequencyDaily
+	FrequencyWeekly
+	FrequencyMonthly
+	FrequencyYearly
+)
+
+var frequencies = map[string]Frequency{
+	"MINUTELY": FrequencyMinutely,
+	"HOURLY":   FrequencyHourly,
+	"DAILY":    FrequencyDaily,
+	"WEEKLY":   FrequencyWeekly,
+	"MONTHLY":  FrequencyMonthly,
+	"YEARLY":   FrequencyYearly,
+}
+
+func (f Frequency) String() string {
+	for name, freq := range frequencies {
+		if freq == f {
+			return name
+		}
+	}
+	return ""
+}
+
+var weekdays = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+func weekdayName(d time.Weekday) string {
+	return strings.ToUpper(d.String()[:2])
+}
+
+// WeekdayNum is one entry of the BYDAY part, like "TU" (every tuesday), "2TU" (the second tuesday)
+// or "-1FR" (the last friday).
+type WeekdayNum struct {
+	Weekday time.Weekday
+	// N is the nth occurrence of the weekday in the month or year. 0 means every occurrence.
+	N int
+}
+
+func (w WeekdayNum) String() string {
+	if w.N == 0 {
+		return weekdayName(w.Weekday)
+	}
+	return strconv.Itoa(w.N) + weekdayName(w.Weekday)
+}
+
+// Rule is a recurrence rule as defined in RFC 5545, section 3.3.10.
+// The BYSECOND, BYMINUTE, BYHOUR, BYYEARDAY and BYWEEKNO parts are not supported.
+type Rule struct {
+	Freq       Frequency
+	Interval   int
+	Count      int
+	Until      time.Time
+	ByMonth    []time.Month
+	ByMonthDay []int
+	ByDay      []WeekdayNum
+	BySetPos   []int
+	WeekStart  time.Weekday
+}
+
+// The largest COUNT and INTERVAL a rule can have. Larger values are not useful for tasks but make calculating
+// the occurrences of a rule very expensive.
+const (
+	MaxCount    = 1000
+	MaxInterval = 10000
+)
+
+// untilFormats holds all formats the UNTIL part can have
+var untilFormats = []string{
+	"20060102T150405Z",
+	"20060102T150405",
+	"20060102",
+}
+
+func parseInts(value string, min, max int) (ints []int, err error) {
+	for _, part := range strings.Split(value, ",") {
+		i, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, err
+		}
+		if i == 0 || i < min || i > max {
+			return nil, fmt.Errorf("%d is out of range", i)
+		}
+		ints = append(ints, i)
+	}
+	return
+}
+
+func parseWeekdayNum(value string) (w WeekdayNum, err error) {
+	if len(value) < 2 {
+		return w, fmt.Errorf("invalid weekday %s", value)
+	}
+
+	day, exists := weekdays[value[len(value)-2:]]
+	if !exists {
+		return w, fmt.Errorf("invalid weekday %s", value)
+	}
+	w.Weekday = day
+
+	if len(value) > 2 {
+		w.N, err = strconv.Atoi(value[:len(value)-2])
+		if err != nil {
+			return w, err
+		}
+		if w.N == 0 || w.N < -53 || w.N > 53 {
+			return w, fmt.Errorf("invalid weekday %s", value)
+		}
+	}
+	return
+}
+
+// Parse parses a recurrence rule like "FREQ=WEEKLY;INTERVAL=2;BYDAY=TU". A leading "RRULE:" is ignored.
+func Parse(rule string) (r *Rule, err error) {
+	rule = strings.TrimPrefix(strings.TrimSpace(strings.ToUpper(rule)), "RRULE:")
+	if rule == "" {
+		return nil, fmt.Errorf("empty rule")
+	}
+
+	r = &Rule{
+		Interval:  1,
+		WeekStart: time.Monday,
+	}
+
+	for _, part := range strings.Split(rule, ";") {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 || kv[1] == "" {
+			return nil, fmt.Errorf("invalid rule part %s", part)
+		}
+
+		name, value := kv[0], kv[1]
+		switch name {
+		case "FREQ":
+			freq, exists := frequencies[value]
+			if !exists {
+				return nil, fmt.Errorf("invalid frequency %s", value)
+			}
+			r.Freq = freq
+		case "INTERVAL":
+			r.Interval, err = strconv.Atoi(value)
+			if err != nil || r.Interval < 1 || r.Interval > MaxInterval {
+				return nil, fmt.Errorf("invalid interval %s", value)
+			}
+		case "COUNT":
+			r.Count, err = strconv.Atoi(value)
+			if err != nil || r.Count < 1 || r.Count > MaxCount {
+				return nil, fmt.Errorf("invalid count %s", value)
+			}
+		case "UNTIL":
+			for _, format := range untilFormats {
+				loc := config.GetTimeZone()
+				if strings.HasSuffix(format, "Z") {
+					loc = time.UTC
+				}
+				r.Until, err = time.ParseInLocation(format, value, loc)
+				if err != nil {
+					continue
+				}
+				// A date without a time includes the whole day
+				if format == "20060102" {
+					r.Until = r.Until.Add(24*time.Hour - time.Second)
+				}
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("invalid until %s", value)
+			}
+		case "BYMONTH":
+			months, err := parseInts(value, 1, 12)
+			if err != nil {
+				return nil, fmt.Errorf("invalid month in %s: %s", value, err)
+			}
+			for _, m := range months {
+				r.ByMonth = append(r.ByMonth, time.Month(m))
+			}
+		case "BYMONTHDAY":
+			r.ByMonthDay, err = parseInts(value, -31, 31)
+			if err != nil {
+				return nil, fmt.Errorf("invalid month day in %s: %s", value, err)
+			}
+		case "BYSETPOS":
+			r.BySetPos, err = parseInts(value, -366, 366)
+			if err != nil {
+				return nil, fmt.Errorf("invalid set position in %s: %s", value, err)
+			}
+		case "BYDAY":
+			for _, day := range strings.Split(value, ",") {
+				w, err := parseWeekdayNum(day)
+				if err != nil {
+					return nil, err
+				}
+				r.ByDay = append(r.ByDay, w)
+			}
+		case "WKST":
+			day, exists := weekdays[value]
+			if !exists {
+				return nil, fmt.Errorf("invalid week start %s", value)
+			}
+			r.WeekStart = day
+		case "BYSECOND", "BYMINUTE", "BYHOUR", "BYYEARDAY", "BYWEEKNO":
+			return nil, fmt.Errorf("%s is not supported", name)
+		default:
+			return nil, fmt.Errorf("unknown rule part %s", name)
+		}
+	}
+
+	if r.Freq == 0 {
+		return nil, fmt.Errorf("the rule has no frequency")
+	}
+	if r.Count > 0 && !r.Until.IsZero() {
+		return nil, fmt.Errorf("count and until cannot be used together")
+	}
+	if len(r.BySetPos) > 0 && len(r.ByMonth) == 0 && len(r.ByMonthDay) == 0 && len(r.ByDay) == 0 {
+		return nil, fmt.Errorf("set position can only be used together with another by part")
+	}
+	if r.Freq != FrequencyMonthly && r.Freq != FrequencyYearly {
+		for _, d := range r.ByDay {
+			if d.N != 0 {
+				return nil, fmt.Errorf("numbered weekdays can only be used with a monthly or yearly frequency")
+			}
+		}
+	}
+	if r.Freq == FrequencyWeekly && len(r.ByMonthDay) > 0 {
+		return nil, fmt.Errorf("month days cannot be used with a weekly frequency")
+	}
+
+	return r, nil
+}
+
+func joinInts(ints []int) string {
+	strs := make([]string, 0, len(ints))
+	for _, i := range ints {
+		strs = append(strs, strconv.Itoa(i))
+	}
+	return strings.Join(strs, ",")
+}
+
+// String returns the rule in its RFC 5545 representation, without the "RRULE:" prefix.
+func (r *Rule) String() string {
+	parts := []string{"FREQ=" + r.Freq.String()}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if !r.Until.IsZero() {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format("20060102T150405Z"))
+	}
+	if len(r.ByMonth) > 0 {
+		months := make([]int, 0, len(r.ByMonth))
+		for _, m := range r.ByMonth {
+			months = append(months, int(m))
+		}
+		parts = append(parts, "BYMONTH="+joinInts(months))
+	}
+	if len(r.ByMonthDay) > 0 {
+		parts = append(parts, "BYMONTHDAY="+joinInts(r.ByMonthDay))
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, 0, len(r.ByDay))
+		for _, d := range r.ByDay {
+			days = append(days, d.String())
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if len(r.BySetPos) > 0 {
+		parts = append(parts, "BYSETPOS="+joinInts(r.BySetPos))
+	}
+	if r.WeekStart != time.Monday {
+		parts = append(parts, "WKST="+weekdayName(r.WeekStart))
+	}
+	return strings.Join(parts, ";")
+}
+
+// Next returns the first occurrence of the rule after the given time for a series starting at dtstart.
+// If the series has ended before that, it returns false.
+func (r *Rule) Next(dtstart, after time.Time) (next time.Time, exists bool) {
+	r.each(dtstart, after, func(occurrence time.Time) bool {
+		next = occurrence
+		exists = true
+		return false
+	})
+	return
+}
+
+// WithoutCount returns a copy of the rule where the COUNT part is replaced with an UNTIL part
+// set to the last occurrence of the series starting at dtstart.
+// This is useful if the start of the series moves with every occurrence.
+func (r *Rule) WithoutCount(dtstart time.Time) *Rule {
+	rule := *r
+	if r.Count == 0 {
+		return &rule
+	}
+
+	r.each(dtstart, time.Time{}, func(occurrence time.Time) bool {
+		rule.Until = occurrence
+		return true
+	})
+	rule.Count = 0
+	return &rule
+}
+
+// maxEmptyPeriods limits how many periods without any occurrence are searched through. Without this,
+// rules which never produce an occurrence (like every 30th of february) would loop forever.
+const maxEmptyPeriods = 1000
+
+// maxPeriods limits how many periods are searched through in total, in case a rule was not created with Parse.
+const maxPeriods = 100000
+
+// each calls fn with every occurrence of the rule after the given time in chronological order
+// until fn returns false or the series ends. As defined in the rfc, dtstart is always the first occurrence.
+func (r *Rule) each(dtstart, after time.Time, fn func(occurrence time.Time) bool) {
+	if !r.Until.IsZero() && dtstart.After(r.Until) {
+		return
+	}
+	if dtstart.After(after) && !fn(dtstart) {
+		return
+	}
+
+	count := 1
+	first := 0
+	if r.Count == 0 {
+		// Without a count we don't need to know how many occurrences came before, so all periods
+		// until shortly before the searched time can be skipped.
+		first = r.periodsBetween(dtstart, after) - 1
+		if first < 0 {
+			first = 0
+		}
+	}
+
+	empty := 0
+	for period := first; empty < maxEmptyPeriods && period-first < maxPeriods; period++ {
+		occurrences := r.occurrencesInPeriod(dtstart, period)
+		if len(occurrences) == 0 {
+			empty++
+			continue
+		}
+		empty = 0
+
+		for _, o := range occurrences {
+			if !o.After(dtstart) {
+				continue
+			}
+			count++
+			if r.Count > 0 && count > r.Count {
+				return
+			}
+			if !r.Until.IsZero() && o.After(r.Until) {
+				return
+			}
+			if !o.After(after) {
+				continue
+			}
+			if !fn(o) {
+				return
+			}
+		}
+	}
+}
+
+// periodsBetween returns how many whole periods of the rule lie between two dates.
+func (r *Rule) periodsBetween(from, to time.Time) int {
+	if !to.After(from) {
+		return 0
+	}
+
+	var periods int
+	switch r.Freq {
+	case FrequencyMinutely:
+		periods = int(to.Sub(from) / time.Minute)
+	case FrequencyHourly:
+		periods = int(to.Sub(from) / time.Hour)
+	case FrequencyDaily:
+		periods = int(to.Sub(from) / (24 * time.Hour))
+	case FrequencyWeekly:
+		periods = int(to.Sub(from) / (7 * 24 * time.Hour))
+	case FrequencyMonthly:
+		periods = (to.Year()-from.Year())*12 + int(to.Month()) - int(from.Month())
+	case FrequencyYearly:
+		periods = to.Year() - from.Year()
+	}
+	return periods / r.Interval
+}
+
+// date returns the date of a time without its time of day. All calculations with days are done in UTC
+// to not be affected by daylight saving time.
+func date(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func daysIn(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// occurrencesInPeriod returns all occurrences in the nth period of the series in chronological order.
+func (r *Rule) occurrencesInPeriod(dtstart time.Time, period int) []time.Time {
+	switch r.Freq {
+	case FrequencyMinutely:
+		candidate := dtstart.Add(time.Duration(period*r.Interval) * time.Minute)
+		return r.setPositions(r.filter(dtstart, []time.Time{candidate}))
+	case FrequencyHourly:
+		candidate := dtstart.Add(time.Duration(period*r.Interval) * time.Hour)
+		return r.setPositions(r.filter(dtstart, []time.Time{candidate}))
+	}
+
+	start := date(dtstart)
+	var end time.Time
+	switch r.Freq {
+	case FrequencyDaily:
+		start = start.AddDate(0, 0, period*r.Interval)
+		end = start.AddDate(0, 0, 1)
+	case FrequencyWeekly:
+		offset := (int(start.Weekday()) - int(r.WeekStart) + 7) % 7
+		start = start.AddDate(0, 0, period*r.Interval*7-offset)
+		end = start.AddDate(0, 0, 7)
+	case FrequencyMonthly:
+		start = time.Date(start.Year(), start.Month()+time.Month(period*r.Interval), 1, 0, 0, 0, 0, time.UTC)
+		end = start.AddDate(0, 1, 0)
+	case FrequencyYearly:
+		start = time.Date(start.Year()+period*r.Interval, time.January, 1, 0, 0, 0, 0, time.UTC)
+		end = start.AddDate(1, 0, 0)
+	}
+
+	candidates := []time.Time{}
+	for day := start; day.Before(end); day = day.AddDate(0, 0, 1) {
+		candidates = append(candidates, time.Date(
+			day.Year(),
+			day.Month(),
+			day.Day(),
+			dtstart.Hour(),
+			dtstart.Minute(),
+			dtstart.Second(),
+			0,
+			dtstart.Location(),
+		))
+	}
+
+	return r.setPositions(r.filter(dtstart, candidates))
+}
+
+// filter returns all candidates matching the rule. If the rule does not specify the day for its
+// frequency, the day is taken from dtstart, like the rfc demands.
+func (r *Rule) filter(dtstart time.Time, candidates []time.Time) (matching []time.Time) {
+	byMonth := r.ByMonth
+	byMonthDay := r.ByMonthDay
+	byDay := r.ByDay
+
+	switch r.Freq {
+	case FrequencyWeekly:
+		if len(byDay) == 0 {
+			byDay = []WeekdayNum{{Weekday: dtstart.Weekday()}}
+		}
+	case FrequencyMonthly:
+		if len(byDay) == 0 && len(byMonthDay) == 0 {
+			byMonthDay = []int{dtstart.Day()}
+		}
+	case FrequencyYearly:
+		if len(byDay) == 0 && len(byMonthDay) == 0 {
+			byMonthDay = []int{dtstart.Day()}
+			if len(byMonth) == 0 {
+				byMonth = []time.Month{dtstart.Month()}
+			}
+		}
+	}
+
+	// Numbered weekdays are relative to the year only with a yearly frequency and no months.
+	weekdaysInYear := r.Freq == FrequencyYearly && len(byMonth) == 0
+
+	for _, c := range candidates {
+		if len(byMonth) > 0 && !monthMatches(c, byMonth) {
+			continue
+		}
+		if len(byMonthDay) > 0 && !monthDayMatches(c, byMonthDay) {
+			continue
+		}
+		if len(byDay) > 0 && !weekdayMatches(c, byDay, weekdaysInYear) {
+			continue
+		}
+		matching = append(matching, c)
+	}
+
+	return
+}
+
+func monthMatches(t time.Time, months []time.Month) bool {
+	for _, m := range months {
+		if t.Month() == m {
+			return true
+		}
+	}
+	return false
+}
+
+func monthDayMatches(t time.Time, days []int) bool {
+	for _, d := range days {
+		if d < 0 {
+			d = daysIn(t.Year(), t.Month()) + d + 1
+		}
+		if t.Day() == d {
+			return true
+		}
+	}
+	return false
+}
+
+func weekdayMatches(t time.Time, days []WeekdayNum, inYear bool) bool {
+	for _, d := range days {
+		if t.Weekday() != d.Weekday {
+			continue
+		}
+		if d.N == 0 {
+			return true
+		}
+
+		day := t.Day()
+		total := daysIn(t.Year(), t.Month())
+		if inYear {
+			day = t.YearDay()
+			total = time.Date(t.Year(), time.December, 31, 0, 0, 0, 0, time.UTC).YearDay()
+		}
+
+		if d.N > 0 && (day-1)/7+1 == d.N {
+			return true
+		}
+		if d.N < 0 && (total-day)/7+1 == -d.N {
+			return true
+		}
+	}
+	return false
+}
+
+// setPositions returns only the occurrences at the positions specified in BYSETPOS.
+func (r *Rule) setPositions(occurrences []time.Time) []time.Time {
+	if len(r.BySetPos) == 0 {
+		return occurrences
+	}
+
+	positions := make(map[int]bool)
+	for _, pos := range r.BySetPos {
+		if pos < 0 {
+			pos = len(occurrences) + pos
+		} else {
+			pos--
+		}
+		if pos >= 0 && pos < len(occurrences) {
+			positions[pos] = true
+		}
+	}
+
+	selected := make([]int, 0, len(positions))
+	for pos := range positions {
+		selected = append(selected, pos)
+	}
+	sort.Ints(selected)
+
+	result := make([]time.Time, 0, len(selected))
+	for _, pos := range selected {
+		result = append(result, occurrences[pos])
+	}
+	return result
+}
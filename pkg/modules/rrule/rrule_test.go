@@ -0,0 +1,230 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package rrule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		rules := []string{
+			"FREQ=WEEKLY;INTERVAL=2;BYDAY=TU",
+			"FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1",
+			"FREQ=MONTHLY;BYDAY=-1FR",
+			"FREQ=YEARLY;BYMONTH=1,6;BYMONTHDAY=1,-1",
+			"FREQ=DAILY;UNTIL=20211231T230000Z",
+			"FREQ=WEEKLY;COUNT=10;BYDAY=MO,WE;WKST=SU",
+		}
+		for _, rule := range rules {
+			r, err := Parse(rule)
+			assert.NoError(t, err)
+			assert.Equal(t, rule, r.String())
+		}
+	})
+	t.Run("normalized", func(t *testing.T) {
+		r, err := Parse("RRULE:freq=daily;count=5;interval=1")
+		assert.NoError(t, err)
+		assert.Equal(t, "FREQ=DAILY;COUNT=5", r.String())
+	})
+	t.Run("limits", func(t *testing.T) {
+		r, err := Parse("FREQ=MINUTELY;COUNT=1000;INTERVAL=10000")
+		assert.NoError(t, err)
+		assert.Equal(t, MaxCount, r.Count)
+		assert.Equal(t, MaxInterval, r.Interval)
+
+		_, err = Parse("FREQ=MINUTELY;COUNT=1001")
+		assert.Error(t, err)
+		_, err = Parse("FREQ=MINUTELY;INTERVAL=10001")
+		assert.Error(t, err)
+	})
+	t.Run("invalid", func(t *testing.T) {
+		rules := []string{
+			"",
+			"FREQ=FORTNIGHTLY",
+			"INTERVAL=2",
+			"FREQ=DAILY;INTERVAL=0",
+			"FREQ=DAILY;BYHOUR=9",
+			"FREQ=DAILY;FOO=BAR",
+			"FREQ=WEEKLY;BYDAY=2TU",
+			"FREQ=MONTHLY;BYDAY=XX",
+			"FREQ=MONTHLY;BYMONTHDAY=32",
+			"FREQ=DAILY;COUNT=2;UNTIL=20210101",
+			"FREQ=DAILY;BYSETPOS=1",
+			"FREQ=MINUTELY;COUNT=2000000000",
+			"FREQ=DAILY;INTERVAL=10001",
+		}
+		for _, rule := range rules {
+			_, err := Parse(rule)
+			assert.Error(t, err, rule)
+		}
+	})
+}
+
+func TestRule_Next(t *testing.T) {
+	at := func(year int, month time.Month, day, hour int) time.Time {
+		return time.Date(year, month, day, hour, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name       string
+		rule       string
+		dtstart    time.Time
+		after      time.Time
+		wantNext   time.Time
+		wantExists bool
+	}{
+		{
+			name:       "every second tuesday",
+			rule:       "FREQ=WEEKLY;INTERVAL=2;BYDAY=TU",
+			dtstart:    at(2021, 9, 14, 9),
+			after:      at(2021, 9, 14, 9),
+			wantNext:   at(2021, 9, 28, 9),
+			wantExists: true,
+		},
+		{
+			name:       "last weekday of the month",
+			rule:       "FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1",
+			dtstart:    at(2021, 9, 30, 9),
+			after:      at(2021, 9, 30, 9),
+			wantNext:   at(2021, 10, 29, 9),
+			wantExists: true,
+		},
+		{
+			name:       "weekdays only",
+			rule:       "FREQ=DAILY;BYDAY=MO,TU,WE,TH,FR",
+			dtstart:    at(2021, 9, 17, 9),
+			after:      at(2021, 9, 17, 9),
+			wantNext:   at(2021, 9, 20, 9),
+			wantExists: true,
+		},
+		{
+			name:       "last friday",
+			rule:       "FREQ=MONTHLY;BYDAY=-1FR",
+			dtstart:    at(2021, 9, 24, 9),
+			after:      at(2021, 9, 24, 9),
+			wantNext:   at(2021, 10, 29, 9),
+			wantExists: true,
+		},
+		{
+			name:       "skips months without the day",
+			rule:       "FREQ=MONTHLY;BYMONTHDAY=31",
+			dtstart:    at(2021, 8, 31, 9),
+			after:      at(2021, 8, 31, 9),
+			wantNext:   at(2021, 10, 31, 9),
+			wantExists: true,
+		},
+		{
+			name:       "yearly on a leap day",
+			rule:       "FREQ=YEARLY",
+			dtstart:    at(2020, 2, 29, 9),
+			after:      at(2020, 2, 29, 9),
+			wantNext:   at(2024, 2, 29, 9),
+			wantExists: true,
+		},
+		{
+			name:       "every six hours",
+			rule:       "FREQ=HOURLY;INTERVAL=6",
+			dtstart:    at(2021, 9, 1, 9),
+			after:      at(2021, 9, 1, 20),
+			wantNext:   at(2021, 9, 1, 21),
+			wantExists: true,
+		},
+		{
+			name:       "far in the future",
+			rule:       "FREQ=DAILY",
+			dtstart:    at(2021, 9, 1, 9),
+			after:      at(2030, 1, 1, 12),
+			wantNext:   at(2030, 1, 2, 9),
+			wantExists: true,
+		},
+		{
+			name:       "dtstart is the first occurrence",
+			rule:       "FREQ=WEEKLY;BYDAY=TU",
+			dtstart:    at(2021, 9, 13, 9),
+			after:      at(2021, 9, 1, 9),
+			wantNext:   at(2021, 9, 13, 9),
+			wantExists: true,
+		},
+		{
+			name:       "within count",
+			rule:       "FREQ=DAILY;COUNT=3",
+			dtstart:    at(2021, 9, 1, 9),
+			after:      at(2021, 9, 2, 9),
+			wantNext:   at(2021, 9, 3, 9),
+			wantExists: true,
+		},
+		{
+			name:    "after count",
+			rule:    "FREQ=DAILY;COUNT=3",
+			dtstart: at(2021, 9, 1, 9),
+			after:   at(2021, 9, 3, 9),
+		},
+		{
+			name:    "after until",
+			rule:    "FREQ=WEEKLY;UNTIL=20210920T000000Z",
+			dtstart: at(2021, 9, 6, 9),
+			after:   at(2021, 9, 13, 9),
+		},
+		{
+			name:    "never matches",
+			rule:    "FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=30",
+			dtstart: at(2021, 1, 30, 9),
+			after:   at(2021, 1, 30, 9),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := Parse(tt.rule)
+			assert.NoError(t, err)
+
+			next, exists := r.Next(tt.dtstart, tt.after)
+			assert.Equal(t, tt.wantExists, exists)
+			if tt.wantExists {
+				assert.Equal(t, tt.wantNext, next)
+			}
+		})
+	}
+}
+
+func TestRule_WithoutCount(t *testing.T) {
+	r, err := Parse("FREQ=WEEKLY;COUNT=3")
+	assert.NoError(t, err)
+
+	dtstart := time.Date(2021, 9, 6, 9, 0, 0, 0, time.UTC)
+	rule := r.WithoutCount(dtstart)
+	assert.Equal(t, 0, rule.Count)
+	assert.Equal(t, time.Date(2021, 9, 20, 9, 0, 0, 0, time.UTC), rule.Until)
+	assert.Equal(t, 3, r.Count)
+
+	t.Run("max count", func(t *testing.T) {
+		r, err := Parse("FREQ=MINUTELY;COUNT=1000")
+		assert.NoError(t, err)
+
+		rule := r.WithoutCount(dtstart)
+		assert.Equal(t, dtstart.Add(999*time.Minute), rule.Until)
+	})
+	t.Run("count above the limit", func(t *testing.T) {
+		r := &Rule{Freq: FrequencyMinutely, Interval: 1, Count: 2000000000}
+
+		rule := r.WithoutCount(dtstart)
+		assert.Equal(t, dtstart.Add((maxPeriods-1)*time.Minute), rule.Until)
+	})
+}
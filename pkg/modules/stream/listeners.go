@@ -0,0 +1,83 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package stream
+
+import (
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/events"
+	"code.vikunja.io/api/pkg/models"
+	"github.com/ThreeDotsLabs/watermill/message"
+)
+
+func getStreamedEvents() []string {
+	return []string{
+		(&models.TaskCreatedEvent{}).Name(),
+		(&models.TaskUpdatedEvent{}).Name(),
+		(&models.TaskDeletedEvent{}).Name(),
+		(&models.TaskRestoredEvent{}).Name(),
+		(&models.TaskAssigneeCreatedEvent{}).Name(),
+		(&models.TaskCommentCreatedEvent{}).Name(),
+		(&models.TaskCommentUpdatedEvent{}).Name(),
+		(&models.BucketCreatedEvent{}).Name(),
+		(&models.BucketUpdatedEvent{}).Name(),
+		(&models.BucketDeletedEvent{}).Name(),
+		(&models.ListCreatedEvent{}).Name(),
+		(&models.ListUpdatedEvent{}).Name(),
+		(&models.ListDeletedEvent{}).Name(),
+		(&models.ListRestoredEvent{}).Name(),
+	}
+}
+
+func registerListeners() {
+	for _, eventName := range getStreamedEvents() {
+		events.RegisterListener(eventName, &SendEventToStream{EventName: eventName})
+	}
+}
+
+// SendEventToStream represents a listener
+type SendEventToStream struct {
+	EventName string
+}
+
+// Name defines the name for the SendEventToStream listener
+func (s *SendEventToStream) Name() string {
+	return "stream.send.event"
+}
+
+// Handle is executed when the event SendEventToStream listens on is fired
+func (s *SendEventToStream) Handle(msg *message.Message) (err error) {
+	sess := db.NewSession()
+	defer sess.Close()
+
+	listID, namespaceID, err := models.GetListAndNamespaceIDFromEvent(sess, msg.Payload)
+	if err != nil {
+		return err
+	}
+
+	// Link shares and all other users of the list receive the event, they must not see anyone's email address
+	data, err := models.SanitizeEventPayload(msg.Payload)
+	if err != nil {
+		return err
+	}
+
+	return publish(&Message{
+		Event:       s.EventName,
+		ListID:      listID,
+		NamespaceID: namespaceID,
+		Data:        data,
+	})
+}
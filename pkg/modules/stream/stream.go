@@ -0,0 +1,185 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/log"
+	"code.vikunja.io/api/pkg/models"
+	"code.vikunja.io/api/pkg/red"
+	"code.vikunja.io/web"
+	"xorm.io/xorm"
+)
+
+const (
+	logPrefix = "[Stream] "
+
+	// The redis channel used to distribute events to all api instances
+	redisChannel = "vikunja:stream"
+
+	// How many messages are buffered for a single client before we start dropping them
+	clientBufferSize = 64
+)
+
+// Message is a single event sent to connected clients.
+type Message struct {
+	Event       string          `json:"event"`
+	ListID      int64           `json:"list_id"`
+	NamespaceID int64           `json:"namespace_id"`
+	Data        json.RawMessage `json:"data"`
+}
+
+// Client represents a single connection to the stream.
+type Client struct {
+	auth     web.Auth
+	messages chan *Message
+}
+
+// Messages returns the channel all messages for this client are sent to.
+func (c *Client) Messages() <-chan *Message {
+	return c.messages
+}
+
+var (
+	clients     = make(map[*Client]bool)
+	clientsLock sync.RWMutex
+)
+
+// Subscribe registers a new client which will receive all events it is allowed to see.
+// Make sure to call Unsubscribe once the connection is closed.
+func Subscribe(a web.Auth) *Client {
+	c := &Client{
+		auth:     a,
+		messages: make(chan *Message, clientBufferSize),
+	}
+
+	clientsLock.Lock()
+	clients[c] = true
+	clientsLock.Unlock()
+
+	return c
+}
+
+// Unsubscribe removes a client. It will not receive any more messages.
+func Unsubscribe(c *Client) {
+	clientsLock.Lock()
+	delete(clients, c)
+	clientsLock.Unlock()
+}
+
+// Init registers all listeners needed to send events to clients. If redis is enabled, it also starts
+// receiving events from all other api instances.
+func Init() {
+	registerListeners()
+
+	if config.RedisEnabled.GetBool() {
+		go receiveFromRedis()
+	}
+}
+
+// publish sends a message to all clients. If redis is enabled, it sends the message to all api instances
+// which then send it to their clients.
+func publish(m *Message) error {
+	if !config.RedisEnabled.GetBool() {
+		broadcast(m)
+		return nil
+	}
+
+	payload, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+
+	return red.GetRedis().Publish(context.Background(), redisChannel, payload).Err()
+}
+
+func receiveFromRedis() {
+	sub := red.GetRedis().Subscribe(context.Background(), redisChannel)
+	defer sub.Close()
+
+	log.Debugf(logPrefix + "Receiving events from other instances through redis")
+
+	for msg := range sub.Channel() {
+		m := &Message{}
+		err := json.Unmarshal([]byte(msg.Payload), m)
+		if err != nil {
+			log.Errorf(logPrefix+"Could not decode message from redis: %s", err)
+			continue
+		}
+		broadcast(m)
+	}
+}
+
+// broadcast sends a message to all clients connected to this instance which can read the list
+// or namespace the message belongs to.
+func broadcast(m *Message) {
+	clientsLock.RLock()
+	cs := make([]*Client, 0, len(clients))
+	for c := range clients {
+		cs = append(cs, c)
+	}
+	clientsLock.RUnlock()
+
+	if len(cs) == 0 {
+		return
+	}
+
+	s := db.NewSession()
+	defer s.Close()
+
+	for _, c := range cs {
+		can, err := canReceive(s, c.auth, m)
+		if err != nil {
+			log.Errorf(logPrefix+"Could not check if client can receive event %s: %s", m.Event, err)
+			continue
+		}
+		if !can {
+			continue
+		}
+
+		select {
+		case c.messages <- m:
+		default:
+			log.Debugf(logPrefix+"Client is too slow, dropping event %s", m.Event)
+		}
+	}
+}
+
+func canReceive(s *xorm.Session, a web.Auth, m *Message) (bool, error) {
+	if m.ListID > 0 {
+		can, _, err := (&models.List{ID: m.ListID}).CanRead(s, a)
+		if err == nil || !models.IsErrListDoesNotExist(err) {
+			return can, err
+		}
+		// The list was deleted, therefore we fall back to the namespace it was in.
+	}
+
+	if m.NamespaceID > 0 {
+		can, _, err := (&models.Namespace{ID: m.NamespaceID}).CanRead(s, a)
+		if models.IsErrNamespaceDoesNotExist(err) {
+			return false, nil
+		}
+		return can, err
+	}
+
+	return false, nil
+}
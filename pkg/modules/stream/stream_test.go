@@ -0,0 +1,89 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package stream
+
+import (
+	"encoding/json"
+	"testing"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/models"
+	"code.vikunja.io/api/pkg/user"
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBroadcast(t *testing.T) {
+	t.Run("client with access", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+
+		c := Subscribe(&user.User{ID: 1})
+		defer Unsubscribe(c)
+
+		broadcast(&Message{Event: "task.created", ListID: 1, Data: []byte(`{}`)})
+		assert.Len(t, c.Messages(), 1)
+	})
+	t.Run("client without access", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+
+		c := Subscribe(&user.User{ID: 13})
+		defer Unsubscribe(c)
+
+		broadcast(&Message{Event: "task.created", ListID: 1, Data: []byte(`{}`)})
+		assert.Len(t, c.Messages(), 0)
+	})
+	t.Run("deleted list", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+
+		c := Subscribe(&user.User{ID: 1})
+		defer Unsubscribe(c)
+
+		broadcast(&Message{Event: "list.deleted", ListID: 9999, NamespaceID: 1, Data: []byte(`{}`)})
+		assert.Len(t, c.Messages(), 1)
+	})
+	t.Run("unsubscribed client", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+
+		c := Subscribe(&user.User{ID: 1})
+		Unsubscribe(c)
+
+		broadcast(&Message{Event: "task.created", ListID: 1, Data: []byte(`{}`)})
+		assert.Len(t, c.Messages(), 0)
+	})
+}
+
+func TestSendEventToStream_Handle(t *testing.T) {
+	db.LoadAndAssertFixtures(t)
+
+	c := Subscribe(&user.User{ID: 1})
+	defer Unsubscribe(c)
+
+	event := &models.TaskCreatedEvent{
+		Task: &models.Task{ID: 1, ListID: 1},
+		Doer: &user.User{ID: 1, Username: "user1", Email: "user1@example.com"},
+	}
+	payload, err := json.Marshal(event)
+	assert.NoError(t, err)
+
+	err = (&SendEventToStream{EventName: event.Name()}).Handle(message.NewMessage("1", payload))
+	assert.NoError(t, err)
+
+	m := <-c.Messages()
+	assert.Equal(t, event.Name(), m.Event)
+	assert.Contains(t, string(m.Data), `"username":"user1"`)
+	assert.NotContains(t, string(m.Data), "user1@example.com")
+}
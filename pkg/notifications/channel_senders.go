@@ -0,0 +1,96 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package notifications
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// sendToMatrix sends a message to a matrix room through the client-server api of the homeserver.
+func sendToMatrix(ctx context.Context, channel *NotificationChannel, text *Text) error {
+	txnID := strconv.FormatInt(time.Now().UnixNano(), 10)
+	endpoint := strings.TrimSuffix(channel.URL, "/") +
+		"/_matrix/client/r0/rooms/" + url.PathEscape(channel.Target) +
+		"/send/m.room.message/" + txnID
+
+	return doChannelRequest(ctx, http.MethodPut, endpoint, map[string]string{
+		"Authorization": "Bearer " + channel.Token,
+	}, map[string]string{
+		"msgtype": "m.text",
+		"body":    text.String(),
+	})
+}
+
+// sendToSlack sends a message to a slack compatible incoming webhook, like the ones from Slack or Mattermost.
+func sendToSlack(ctx context.Context, channel *NotificationChannel, text *Text) error {
+	return doChannelRequest(ctx, http.MethodPost, channel.URL, nil, map[string]string{
+		"text": text.String(),
+	})
+}
+
+// sendToNtfy publishes a message to a ntfy topic.
+func sendToNtfy(ctx context.Context, channel *NotificationChannel, text *Text) error {
+	headers := map[string]string{}
+	if channel.Token != "" {
+		headers["Authorization"] = "Bearer " + channel.Token
+	}
+
+	message := map[string]interface{}{
+		"topic":   channel.Target,
+		"title":   text.Title,
+		"message": text.Message,
+	}
+	if text.ActionURL != "" {
+		message["click"] = text.ActionURL
+		message["actions"] = []map[string]string{
+			{
+				"action": "view",
+				"label":  text.ActionText,
+				"url":    text.ActionURL,
+			},
+		}
+	}
+
+	return doChannelRequest(ctx, http.MethodPost, strings.TrimSuffix(channel.URL, "/"), headers, message)
+}
+
+// sendToGotify sends a message to a gotify server.
+func sendToGotify(ctx context.Context, channel *NotificationChannel, text *Text) error {
+	message := map[string]interface{}{
+		"title":    text.Title,
+		"message":  text.Message,
+		"priority": 5,
+	}
+	if text.ActionURL != "" {
+		message["extras"] = map[string]interface{}{
+			"client::notification": map[string]interface{}{
+				"click": map[string]string{
+					"url": text.ActionURL,
+				},
+			},
+		}
+	}
+
+	return doChannelRequest(ctx, http.MethodPost, strings.TrimSuffix(channel.URL, "/")+"/message", map[string]string{
+		"X-Gotify-Key": channel.Token,
+	}, message)
+}
@@ -0,0 +1,183 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/log"
+	"code.vikunja.io/api/pkg/utils"
+	"code.vikunja.io/api/pkg/version"
+
+	"xorm.io/xorm"
+)
+
+// These are all types of channels users can configure to get their notifications through
+const (
+	ChannelTypeMatrix = "matrix"
+	ChannelTypeSlack  = "slack"
+	ChannelTypeNtfy   = "ntfy"
+	ChannelTypeGotify = "gotify"
+)
+
+// ChannelSender sends the text of a notification to a channel.
+type ChannelSender func(ctx context.Context, channel *NotificationChannel, text *Text) error
+
+var channelSenders = map[string]ChannelSender{
+	ChannelTypeMatrix: sendToMatrix,
+	ChannelTypeSlack:  sendToSlack,
+	ChannelTypeNtfy:   sendToNtfy,
+	ChannelTypeGotify: sendToGotify,
+}
+
+// RegisterChannelType makes a new type of channel available or replaces the sender of an existing one.
+func RegisterChannelType(channelType string, sender ChannelSender) {
+	channelSenders[channelType] = sender
+}
+
+// IsValidChannelType checks if a channel type exists.
+func IsValidChannelType(channelType string) bool {
+	_, exists := channelSenders[channelType]
+	return exists
+}
+
+// NotificationChannel is a chat room, webhook or push topic a notifiable gets their notifications through.
+type NotificationChannel struct {
+	// The unique, numeric id of this channel.
+	ID           int64 `xorm:"bigint autoincr not null unique pk" json:"id" param:"channel"`
+	NotifiableID int64 `xorm:"bigint not null index" json:"-"`
+	// The type of the channel. Can be `matrix`, `slack`, `ntfy` or `gotify`.
+	// Use `slack` for Mattermost and other services with Slack compatible incoming webhooks.
+	Type string `xorm:"varchar(50) not null" json:"type"`
+	// A name to tell multiple channels apart.
+	Title string `xorm:"varchar(250) null" json:"title"`
+	// The url of the matrix homeserver, ntfy or gotify server or the full url of the slack webhook.
+	URL string `xorm:"text not null" json:"url"`
+	// The matrix room id or the ntfy topic. Not used for other channel types.
+	Target string `xorm:"varchar(250) null" json:"target"`
+	// The matrix access token, gotify app token or ntfy access token.
+	// You can only set it, not retrieve it after the channel has been created.
+	Token string `xorm:"text null" json:"token"`
+	// Whether notifications are sent to this channel.
+	Enabled bool `xorm:"bool not null default true" json:"enabled"`
+
+	// A timestamp when this channel was created. You cannot change this value.
+	Created time.Time `xorm:"created not null" json:"created"`
+	// A timestamp when this channel was last updated. You cannot change this value.
+	Updated time.Time `xorm:"updated not null" json:"updated"`
+}
+
+// TableName returns the table name for notification channels
+func (*NotificationChannel) TableName() string {
+	return "notification_channels"
+}
+
+// GetNotificationChannels returns all channels of a notifiable.
+func GetNotificationChannels(s *xorm.Session, notifiableID int64) (channels []*NotificationChannel, err error) {
+	channels = []*NotificationChannel{}
+	err = s.
+		Where("notifiable_id = ?", notifiableID).
+		OrderBy("id asc").
+		Find(&channels)
+	return
+}
+
+// SendToChannel sends the text of a notification to a single channel.
+func SendToChannel(channel *NotificationChannel, text *Text) error {
+	send, exists := channelSenders[channel.Type]
+	if !exists {
+		return fmt.Errorf("notification channel type %s does not exist", channel.Type)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(config.NotificationChannelsTimeoutSeconds.GetInt64())*time.Second)
+	defer cancel()
+
+	return send(ctx, channel, text)
+}
+
+// notifyChannels sends a notification to all enabled channels of a notifiable. Because these are external
+// services which might be down at any time, failures are only logged and don't prevent other channels from working.
+func notifyChannels(notifiable Notifiable, notification Notification, lang string) error {
+	if !config.NotificationChannelsEnabled.GetBool() {
+		return nil
+	}
+
+	text := RenderText(notification, lang)
+	if text == nil {
+		return nil
+	}
+
+	s := db.NewSession()
+	defer s.Close()
+
+	channels := []*NotificationChannel{}
+	err := s.
+		Where("notifiable_id = ? AND enabled = ?", notifiable.RouteForDB(), true).
+		Find(&channels)
+	if err != nil {
+		return err
+	}
+
+	for _, channel := range channels {
+		err = SendToChannel(channel, text)
+		if err != nil {
+			log.Errorf("Could not send notification %s to %s channel %d: %s", notification.Name(), channel.Type, channel.ID, err)
+			continue
+		}
+		log.Debugf("Sent notification %s to %s channel %d", notification.Name(), channel.Type, channel.ID)
+	}
+
+	return nil
+}
+
+// doChannelRequest sends a json body to a channel and returns an error if it did not respond with a 2xx status code.
+func doChannelRequest(ctx context.Context, method, url string, headers map[string]string, body interface{}) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Vikunja/"+version.Version)
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	hc := utils.NewExternalHTTPClient(config.NotificationChannelsAllowedHosts.GetStringSlice())
+	resp, err := hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("channel responded with status %d", resp.StatusCode)
+	}
+
+	return nil
+}
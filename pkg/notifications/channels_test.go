@@ -0,0 +1,193 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package notifications
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/db"
+	"github.com/stretchr/testify/assert"
+)
+
+type channelRequest struct {
+	Method  string
+	Path    string
+	Headers http.Header
+	Body    map[string]interface{}
+}
+
+func newChannelTestServer(t *testing.T) (*httptest.Server, *[]*channelRequest) {
+	requests := []*channelRequest{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := &channelRequest{
+			Method:  r.Method,
+			Path:    r.URL.EscapedPath(),
+			Headers: r.Header,
+		}
+		err := json.NewDecoder(r.Body).Decode(&req.Body)
+		assert.NoError(t, err)
+		requests = append(requests, req)
+	}))
+	return server, &requests
+}
+
+func TestSendToChannel(t *testing.T) {
+	text := &Text{
+		Title:      "Title",
+		Message:    "Message",
+		ActionText: "Open",
+		ActionURL:  "https://example.com",
+	}
+
+	t.Run("matrix", func(t *testing.T) {
+		server, requests := newChannelTestServer(t)
+		defer server.Close()
+
+		err := SendToChannel(&NotificationChannel{
+			Type:   ChannelTypeMatrix,
+			URL:    server.URL + "/",
+			Target: "!room:example.com",
+			Token:  "token",
+		}, text)
+		assert.NoError(t, err)
+		assert.Len(t, *requests, 1)
+		req := (*requests)[0]
+		assert.Equal(t, http.MethodPut, req.Method)
+		assert.Contains(t, req.Path, "/_matrix/client/r0/rooms/%21room:example.com/send/m.room.message/")
+		assert.Equal(t, "Bearer token", req.Headers.Get("Authorization"))
+		assert.Equal(t, "m.text", req.Body["msgtype"])
+		assert.Equal(t, text.String(), req.Body["body"])
+	})
+	t.Run("slack", func(t *testing.T) {
+		server, requests := newChannelTestServer(t)
+		defer server.Close()
+
+		err := SendToChannel(&NotificationChannel{
+			Type: ChannelTypeSlack,
+			URL:  server.URL + "/hooks/abc",
+		}, text)
+		assert.NoError(t, err)
+		assert.Len(t, *requests, 1)
+		req := (*requests)[0]
+		assert.Equal(t, http.MethodPost, req.Method)
+		assert.Equal(t, "/hooks/abc", req.Path)
+		assert.Equal(t, text.String(), req.Body["text"])
+	})
+	t.Run("ntfy", func(t *testing.T) {
+		server, requests := newChannelTestServer(t)
+		defer server.Close()
+
+		err := SendToChannel(&NotificationChannel{
+			Type:   ChannelTypeNtfy,
+			URL:    server.URL,
+			Target: "tasks",
+		}, text)
+		assert.NoError(t, err)
+		assert.Len(t, *requests, 1)
+		req := (*requests)[0]
+		assert.Equal(t, http.MethodPost, req.Method)
+		assert.Empty(t, req.Headers.Get("Authorization"))
+		assert.Equal(t, "tasks", req.Body["topic"])
+		assert.Equal(t, "Title", req.Body["title"])
+		assert.Equal(t, "Message", req.Body["message"])
+		assert.Equal(t, "https://example.com", req.Body["click"])
+	})
+	t.Run("gotify", func(t *testing.T) {
+		server, requests := newChannelTestServer(t)
+		defer server.Close()
+
+		err := SendToChannel(&NotificationChannel{
+			Type:  ChannelTypeGotify,
+			URL:   server.URL,
+			Token: "apptoken",
+		}, text)
+		assert.NoError(t, err)
+		assert.Len(t, *requests, 1)
+		req := (*requests)[0]
+		assert.Equal(t, "/message", req.Path)
+		assert.Equal(t, "apptoken", req.Headers.Get("X-Gotify-Key"))
+		assert.Equal(t, "Title", req.Body["title"])
+		assert.Equal(t, "Message", req.Body["message"])
+	})
+	t.Run("error status", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+		}))
+		defer server.Close()
+
+		err := SendToChannel(&NotificationChannel{
+			Type: ChannelTypeSlack,
+			URL:  server.URL,
+		}, text)
+		assert.Error(t, err)
+	})
+	t.Run("unknown type", func(t *testing.T) {
+		err := SendToChannel(&NotificationChannel{Type: "carrier-pigeon"}, text)
+		assert.Error(t, err)
+	})
+	t.Run("private address", func(t *testing.T) {
+		server, requests := newChannelTestServer(t)
+		defer server.Close()
+		config.NotificationChannelsAllowedHosts.Set([]string{})
+		defer config.NotificationChannelsAllowedHosts.Set([]string{"127.0.0.1"})
+
+		err := SendToChannel(&NotificationChannel{
+			Type: ChannelTypeSlack,
+			URL:  server.URL,
+		}, text)
+		assert.Error(t, err)
+		assert.Len(t, *requests, 0)
+	})
+}
+
+type channelTestNotifiable struct {
+}
+
+// RouteForMail routes a test notification for mail
+func (t *channelTestNotifiable) RouteForMail() (string, error) {
+	return "channel@email.com", nil
+}
+
+// RouteForDB routes a test notification for db
+func (t *channelTestNotifiable) RouteForDB() int64 {
+	return 44
+}
+
+func TestNotifyChannels(t *testing.T) {
+	server, requests := newChannelTestServer(t)
+	defer server.Close()
+
+	s := db.NewSession()
+	defer s.Close()
+
+	_, err := s.Insert(&NotificationChannel{
+		NotifiableID: 44,
+		Type:         ChannelTypeSlack,
+		URL:          server.URL,
+		Enabled:      true,
+	})
+	assert.NoError(t, err)
+
+	err = Notify(&channelTestNotifiable{}, &testNotification{Test: "To the chat"})
+	assert.NoError(t, err)
+	assert.Len(t, *requests, 1)
+	assert.Equal(t, "Test Notification\n\nTo the chat", (*requests)[0].Body["text"])
+}
@@ -35,5 +35,8 @@ func InitDB() (err error) {
 func GetTables() []interface{} {
 	return []interface{}{
 		&DatabaseNotification{},
+		&NotificationPreference{},
+		&NotificationChannel{},
+		&DigestItem{},
 	}
 }
@@ -0,0 +1,114 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package notifications
+
+import (
+	"time"
+
+	"code.vikunja.io/api/pkg/db"
+
+	"xorm.io/xorm"
+)
+
+// DigestItem is a short summary of a notification. It is queued instead of sending a mail right away
+// if the notifiable gets their notifications as a digest.
+type DigestItem struct {
+	ID           int64  `xorm:"bigint autoincr not null unique pk" json:"-"`
+	NotifiableID int64  `xorm:"bigint not null index" json:"-"`
+	Notification string `xorm:"varchar(250) not null" json:"-"`
+
+	// The list and task the notification is about. Digests are grouped by them.
+	ListID    int64  `xorm:"bigint null" json:"-"`
+	TaskID    int64  `xorm:"bigint null" json:"-"`
+	TaskTitle string `xorm:"text null" json:"-"`
+	// A single line describing what happened.
+	Text string `xorm:"text not null" json:"-"`
+
+	Created time.Time `xorm:"created not null" json:"-"`
+}
+
+// TableName returns the table name for digest items
+func (*DigestItem) TableName() string {
+	return "notification_digest_items"
+}
+
+// NotificationWithDigest is a notification which can be part of a digest.
+// All other notifications are always sent right away.
+type NotificationWithDigest interface {
+	Notification
+	ToDigest(lang string) *DigestItem
+}
+
+// NotifiableWithDigest is a notifiable which can get their mail notifications as a digest.
+type NotifiableWithDigest interface {
+	Notifiable
+	WantsDigest() bool
+}
+
+// queueForDigest saves a notification for the next digest if the notifiable wants one and the notification
+// can be part of it. Returns false if the notification should be sent right away.
+func queueForDigest(notifiable Notifiable, notification Notification, lang string) (queued bool, err error) {
+	nd, is := notifiable.(NotifiableWithDigest)
+	if !is || !nd.WantsDigest() {
+		return false, nil
+	}
+
+	n, is := notification.(NotificationWithDigest)
+	if !is {
+		return false, nil
+	}
+
+	item := n.ToDigest(lang)
+	if item == nil {
+		return false, nil
+	}
+
+	item.ID = 0
+	item.NotifiableID = notifiable.RouteForDB()
+	item.Notification = notification.Name()
+
+	s := db.NewSession()
+	defer s.Close()
+
+	_, err = s.Insert(item)
+	return err == nil, err
+}
+
+// GetDigestItems returns all queued digest items of a notifiable, oldest first.
+func GetDigestItems(s *xorm.Session, notifiableID int64) (items []*DigestItem, err error) {
+	items = []*DigestItem{}
+	err = s.
+		Where("notifiable_id = ?", notifiableID).
+		OrderBy("id asc").
+		Find(&items)
+	return
+}
+
+// DeleteDigestItems removes digest items after they were sent.
+func DeleteDigestItems(s *xorm.Session, items []*DigestItem) (err error) {
+	if len(items) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ID)
+	}
+
+	_, err = s.In("id", ids).Delete(&DigestItem{})
+	return
+}
@@ -0,0 +1,91 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package notifications
+
+import (
+	"testing"
+
+	"code.vikunja.io/api/pkg/db"
+	"github.com/stretchr/testify/assert"
+)
+
+type digestTestNotification struct {
+	testNotification
+}
+
+// ToDigest returns the summary of digestTestNotification for the notification digest
+func (n *digestTestNotification) ToDigest(lang string) *DigestItem {
+	return &DigestItem{
+		ListID:    1,
+		TaskID:    2,
+		TaskTitle: "Task",
+		Text:      n.Test,
+	}
+}
+
+type digestTestNotifiable struct {
+	wantsDigest bool
+}
+
+// RouteForMail routes a test notification for mail
+func (t *digestTestNotifiable) RouteForMail() (string, error) {
+	return "digest@email.com", nil
+}
+
+// RouteForDB routes a test notification for db
+func (t *digestTestNotifiable) RouteForDB() int64 {
+	return 45
+}
+
+// WantsDigest returns whether the test notifiable wants a digest
+func (t *digestTestNotifiable) WantsDigest() bool {
+	return t.wantsDigest
+}
+
+func TestQueueForDigest(t *testing.T) {
+	t.Run("queued", func(t *testing.T) {
+		queued, err := queueForDigest(&digestTestNotifiable{wantsDigest: true}, &digestTestNotification{testNotification{Test: "queued"}}, "en")
+		assert.NoError(t, err)
+		assert.True(t, queued)
+		db.AssertExists(t, "notification_digest_items", map[string]interface{}{
+			"notifiable_id": 45,
+			"notification":  "test.notification",
+			"text":          "queued",
+		}, false)
+
+		s := db.NewSession()
+		defer s.Close()
+
+		items, err := GetDigestItems(s, 45)
+		assert.NoError(t, err)
+		assert.Len(t, items, 1)
+
+		err = DeleteDigestItems(s, items)
+		assert.NoError(t, err)
+		db.AssertMissing(t, "notification_digest_items", map[string]interface{}{"notifiable_id": 45})
+	})
+	t.Run("notifiable does not want a digest", func(t *testing.T) {
+		queued, err := queueForDigest(&digestTestNotifiable{}, &digestTestNotification{testNotification{Test: "right away"}}, "en")
+		assert.NoError(t, err)
+		assert.False(t, queued)
+	})
+	t.Run("notification can't be part of a digest", func(t *testing.T) {
+		queued, err := queueForDigest(&digestTestNotifiable{wantsDigest: true}, &testNotification{Test: "right away"}, "en")
+		assert.NoError(t, err)
+		assert.False(t, queued)
+	})
+}
@@ -28,6 +28,7 @@ type Mail struct {
 	greeting   string
 	introLines []string
 	outroLines []string
+	language   string
 }
 
 // NewMail creates a new mail object with a default greeting
@@ -66,6 +67,13 @@ func (m *Mail) Action(text, url string) *Mail {
 	return m
 }
 
+// Language sets the language of the parts of the mail which are not part of the message itself.
+// The mail is rendered in english if no language is set.
+func (m *Mail) Language(lang string) *Mail {
+	m.language = lang
+	return m
+}
+
 // Line adds a line of text to the mail
 func (m *Mail) Line(line string) *Mail {
 	if m.actionURL == "" {
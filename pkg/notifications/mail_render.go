@@ -22,6 +22,7 @@ import (
 	templatetext "text/template"
 
 	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/i18n"
 	"code.vikunja.io/api/pkg/mail"
 	"code.vikunja.io/api/pkg/utils"
 
@@ -73,7 +74,7 @@ const mailTemplateHTML = `
 
 {{ if .ActionURL }}
 	<p style="color: #9CA3AF;font-size:12px;border-top: 1px solid #dbdbdb;margin-top:20px;padding-top:20px;">
-		If the button above doesn't work, copy the url below and paste it in your browsers address bar:<br/>
+		{{ .ActionFallback }}<br/>
 		{{ .ActionURL }}
 	</p>
 {{ end }}
@@ -109,6 +110,8 @@ func RenderMail(m *Mail) (mailOpts *mail.Opts, err error) {
 	data["OutroLines"] = m.outroLines
 	data["ActionText"] = m.actionText
 	data["ActionURL"] = m.actionURL
+	//#nosec - the translations are no user input
+	data["ActionFallback"] = templatehtml.HTML(i18n.T(m.language, "mail.action_fallback"))
 	data["Boundary"] = boundary
 	data["FrontendURL"] = config.ServiceFrontendurl.GetString()
 
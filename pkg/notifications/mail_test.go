@@ -173,3 +173,16 @@ And one more, because why not?
 </html>
 `, mailopts.HTMLMessage)
 }
+
+func TestRenderMailWithLanguage(t *testing.T) {
+	mail := NewMail().
+		Subject("Testmail").
+		Line("Eine Zeile").
+		Action("Die Aktion", "https://example.com").
+		Language("de")
+
+	mailopts, err := RenderMail(mail)
+	assert.NoError(t, err)
+	assert.Contains(t, mailopts.HTMLMessage, "Falls der Button oben nicht funktioniert, kopiere die URL unten und füge sie in die Adresszeile deines Browsers ein:<br/>")
+	assert.NotContains(t, mailopts.HTMLMessage, "If the button above doesn't work")
+}
@@ -34,7 +34,7 @@ func SetupTests() {
 		log.Fatal(err)
 	}
 
-	err = x.Sync2(&DatabaseNotification{})
+	err = x.Sync2(GetTables()...)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -46,6 +46,8 @@ func TestMain(m *testing.M) {
 	config.InitDefaultConfig()
 	// We need to set the root path even if we're not using the config, otherwise fixtures are not loaded correctly
 	config.ServiceRootpath.Set(os.Getenv("VIKUNJA_SERVICE_ROOTPATH"))
+	// The channel test servers listen on localhost
+	config.NotificationChannelsAllowedHosts.Set([]string{"127.0.0.1"})
 
 	SetupTests()
 
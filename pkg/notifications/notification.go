@@ -20,11 +20,13 @@ import (
 	"encoding/json"
 
 	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/i18n"
 )
 
 // Notification is a notification which can be sent via mail or db.
+// The language is the one the notifiable wants to get their notifications in.
 type Notification interface {
-	ToMail() *Mail
+	ToMail(lang string) *Mail
 	ToDB() interface{}
 	Name() string
 }
@@ -46,6 +48,20 @@ type Notifiable interface {
 	RouteForDB() int64
 }
 
+// NotifiableWithLanguage is a notifiable which wants their notifications in a specific language.
+type NotifiableWithLanguage interface {
+	Notifiable
+	GetLanguage() string
+}
+
+// getLanguage returns the language a notifiable wants their notifications in, english by default.
+func getLanguage(notifiable Notifiable) string {
+	if nl, is := notifiable.(NotifiableWithLanguage); is && nl.GetLanguage() != "" {
+		return nl.GetLanguage()
+	}
+	return i18n.DefaultLanguage
+}
+
 // Notify notifies a notifiable of a notification
 func Notify(notifiable Notifiable, notification Notification) (err error) {
 	if isUnderTest {
@@ -53,19 +69,48 @@ func Notify(notifiable Notifiable, notification Notification) (err error) {
 		return nil
 	}
 
-	err = notifyMail(notifiable, notification)
+	disabled, err := getDisabledChannels(notifiable.RouteForDB(), notification.Name())
 	if err != nil {
-		return
+		return err
+	}
+
+	lang := getLanguage(notifiable)
+
+	if !disabled[ChannelMail] {
+		var queued bool
+		queued, err = queueForDigest(notifiable, notification, lang)
+		if err != nil {
+			return
+		}
+
+		if !queued {
+			err = notifyMail(notifiable, notification, lang)
+			if err != nil {
+				return
+			}
+		}
+	}
+
+	if !disabled[ChannelChat] {
+		err = notifyChannels(notifiable, notification, lang)
+		if err != nil {
+			return
+		}
+	}
+
+	if disabled[ChannelInApp] {
+		return nil
 	}
 
 	return notifyDB(notifiable, notification)
 }
 
-func notifyMail(notifiable Notifiable, notification Notification) error {
-	mail := notification.ToMail()
+func notifyMail(notifiable Notifiable, notification Notification, lang string) error {
+	mail := notification.ToMail(lang)
 	if mail == nil {
 		return nil
 	}
+	mail.Language(lang)
 
 	to, err := notifiable.RouteForMail()
 	if err != nil {
@@ -30,7 +30,7 @@ type testNotification struct {
 }
 
 // ToMail returns the mail notification for testNotification
-func (n *testNotification) ToMail() *Mail {
+func (n *testNotification) ToMail(lang string) *Mail {
 	return NewMail().
 		Subject("Test Notification").
 		Line(n.Test)
@@ -0,0 +1,181 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package notifications
+
+import (
+	"time"
+
+	"code.vikunja.io/api/pkg/db"
+
+	"xorm.io/xorm"
+)
+
+// These are all channels notifications can be sent through
+const (
+	ChannelMail  = "mail"
+	ChannelInApp = "in_app"
+	// ChannelChat covers all chat and push channels a user configured, see NotificationChannel.
+	ChannelChat = "chat"
+)
+
+// ConfigurableNotification is a notification users can turn on or off for every channel it is sent through.
+type ConfigurableNotification struct {
+	Name     string
+	Channels []string
+}
+
+var configurableNotifications []*ConfigurableNotification
+
+// RegisterConfigurableNotification makes it possible for users to turn off a notification for some channels.
+// Notifications which are not registered are always sent, which is what we want for security related ones.
+func RegisterConfigurableNotification(name string, channels ...string) {
+	configurableNotifications = append(configurableNotifications, &ConfigurableNotification{
+		Name:     name,
+		Channels: channels,
+	})
+}
+
+// GetConfigurableNotifications returns all notifications users can turn on or off.
+func GetConfigurableNotifications() []*ConfigurableNotification {
+	return configurableNotifications
+}
+
+// IsConfigurableNotification checks if users can turn a notification on or off for a channel.
+func IsConfigurableNotification(name, channel string) bool {
+	for _, n := range configurableNotifications {
+		if n.Name != name {
+			continue
+		}
+		for _, c := range n.Channels {
+			if c == channel {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// NotificationPreference is whether a notifiable wants to get a notification through a channel.
+type NotificationPreference struct {
+	ID           int64 `xorm:"bigint autoincr not null unique pk" json:"-"`
+	NotifiableID int64 `xorm:"bigint not null unique(preference)" json:"-"`
+	// The name of the notification, like `task.comment`.
+	Notification string `xorm:"varchar(250) not null unique(preference)" json:"notification"`
+	// The channel this preference is about, like `mail`, `in_app` or `chat`.
+	Channel string `xorm:"varchar(50) not null unique(preference)" json:"channel"`
+	// Whether the notification is sent through this channel.
+	Enabled bool `xorm:"bool not null default true" json:"enabled"`
+
+	Created time.Time `xorm:"created not null" json:"-"`
+	Updated time.Time `xorm:"updated not null" json:"-"`
+}
+
+// TableName returns the table name for notification preferences
+func (*NotificationPreference) TableName() string {
+	return "notification_preferences"
+}
+
+// GetNotificationPreferences returns the preferences of a notifiable for all configurable notifications and channels.
+// Notifications are enabled unless the notifiable turned them off.
+func GetNotificationPreferences(s *xorm.Session, notifiableID int64) (preferences []*NotificationPreference, err error) {
+	saved := []*NotificationPreference{}
+	err = s.Where("notifiable_id = ?", notifiableID).Find(&saved)
+	if err != nil {
+		return nil, err
+	}
+
+	preferences = []*NotificationPreference{}
+	for _, n := range configurableNotifications {
+		for _, channel := range n.Channels {
+			preference := &NotificationPreference{
+				NotifiableID: notifiableID,
+				Notification: n.Name,
+				Channel:      channel,
+				Enabled:      true,
+			}
+			for _, p := range saved {
+				if p.Notification == n.Name && p.Channel == channel {
+					preference.Enabled = p.Enabled
+				}
+			}
+			preferences = append(preferences, preference)
+		}
+	}
+
+	return
+}
+
+// SetNotificationPreference turns a notification on or off for a channel. The notification must be configurable.
+func SetNotificationPreference(s *xorm.Session, preference *NotificationPreference) (err error) {
+	existing := &NotificationPreference{}
+	exists, err := s.
+		Where("notifiable_id = ? AND notification = ? AND channel = ?", preference.NotifiableID, preference.Notification, preference.Channel).
+		Get(existing)
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		preference.ID = 0
+		_, err = s.Insert(preference)
+		return
+	}
+
+	preference.ID = existing.ID
+	_, err = s.
+		Where("id = ?", existing.ID).
+		Cols("enabled").
+		Update(preference)
+	return
+}
+
+// DeleteNotificationPreferences removes all preferences of a notifiable.
+func DeleteNotificationPreferences(s *xorm.Session, notifiableID int64) (err error) {
+	_, err = s.Where("notifiable_id = ?", notifiableID).Delete(&NotificationPreference{})
+	return
+}
+
+// getDisabledChannels returns all channels a notifiable turned a notification off for.
+func getDisabledChannels(notifiableID int64, name string) (disabled map[string]bool, err error) {
+	disabled = make(map[string]bool)
+
+	configurable := false
+	for _, n := range configurableNotifications {
+		if n.Name == name {
+			configurable = true
+		}
+	}
+	if !configurable {
+		return
+	}
+
+	s := db.NewSession()
+	defer s.Close()
+
+	preferences := []*NotificationPreference{}
+	err = s.
+		Where("notifiable_id = ? AND notification = ? AND enabled = ?", notifiableID, name, false).
+		Find(&preferences)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, p := range preferences {
+		disabled[p.Channel] = true
+	}
+	return
+}
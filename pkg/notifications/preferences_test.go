@@ -0,0 +1,80 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package notifications
+
+import (
+	"testing"
+
+	"code.vikunja.io/api/pkg/db"
+	"github.com/stretchr/testify/assert"
+)
+
+type otherTestNotifiable struct {
+}
+
+// RouteForMail routes a test notification for mail
+func (t *otherTestNotifiable) RouteForMail() (string, error) {
+	return "other@email.com", nil
+}
+
+// RouteForDB routes a test notification for db
+func (t *otherTestNotifiable) RouteForDB() int64 {
+	return 43
+}
+
+func TestNotificationPreferences(t *testing.T) {
+	RegisterConfigurableNotification("test.notification", ChannelMail, ChannelInApp)
+	defer func() {
+		configurableNotifications = nil
+	}()
+
+	t.Run("defaults to enabled", func(t *testing.T) {
+		s := db.NewSession()
+		defer s.Close()
+
+		preferences, err := GetNotificationPreferences(s, 43)
+		assert.NoError(t, err)
+		assert.Len(t, preferences, 2)
+		for _, p := range preferences {
+			assert.True(t, p.Enabled)
+		}
+	})
+	t.Run("disabled channel is skipped", func(t *testing.T) {
+		s := db.NewSession()
+		defer s.Close()
+
+		err := SetNotificationPreference(s, &NotificationPreference{
+			NotifiableID: 43,
+			Notification: "test.notification",
+			Channel:      ChannelInApp,
+			Enabled:      false,
+		})
+		assert.NoError(t, err)
+
+		preferences, err := GetNotificationPreferences(s, 43)
+		assert.NoError(t, err)
+		for _, p := range preferences {
+			assert.Equal(t, p.Channel != ChannelInApp, p.Enabled)
+		}
+
+		err = Notify(&otherTestNotifiable{}, &testNotification{Test: "disabled"})
+		assert.NoError(t, err)
+		db.AssertMissing(t, "notifications", map[string]interface{}{
+			"notifiable_id": 43,
+		})
+	})
+}
@@ -32,6 +32,12 @@ func Fake() {
 	sentTestNotifications = nil
 }
 
+// Unfake sends notifications again after Fake was called
+func Unfake() {
+	isUnderTest = false
+	sentTestNotifications = nil
+}
+
 // AssertSent asserts a notification has been sent
 func AssertSent(t *testing.T, n Notification) {
 	var found bool
@@ -44,3 +50,8 @@ func AssertSent(t *testing.T, n Notification) {
 
 	assert.True(t, found, "Failed to assert "+n.Name()+" has been sent.")
 }
+
+// AssertNothingSent asserts no notification has been sent
+func AssertNothingSent(t *testing.T) {
+	assert.Empty(t, sentTestNotifications, "Failed to assert no notification has been sent.")
+}
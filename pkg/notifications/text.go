@@ -0,0 +1,75 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package notifications
+
+import "strings"
+
+// Text is the plain text version of a notification, used for chat and push channels.
+type Text struct {
+	Title      string
+	Message    string
+	ActionText string
+	ActionURL  string
+}
+
+// NotificationWithText is a notification which has its own rendering for chat and push channels.
+type NotificationWithText interface {
+	Notification
+	ToText(lang string) *Text
+}
+
+// RenderText returns the plain text version of a notification in a language. Unless the notification has a ToText method,
+// it is derived from the mail lines. Returns nil if the notification is neither sent as text nor as mail.
+func RenderText(notification Notification, lang string) *Text {
+	if n, is := notification.(NotificationWithText); is {
+		return n.ToText(lang)
+	}
+
+	m := notification.ToMail(lang)
+	if m == nil {
+		return nil
+	}
+
+	paragraphs := []string{}
+	if m.greeting != "" {
+		paragraphs = append(paragraphs, m.greeting)
+	}
+	paragraphs = append(paragraphs, m.introLines...)
+	paragraphs = append(paragraphs, m.outroLines...)
+
+	return &Text{
+		Title:      m.subject,
+		Message:    strings.Join(paragraphs, "\n\n"),
+		ActionText: m.actionText,
+		ActionURL:  m.actionURL,
+	}
+}
+
+// String returns the whole text in one string, including the title and the action.
+func (t *Text) String() string {
+	parts := []string{}
+	if t.Title != "" {
+		parts = append(parts, t.Title)
+	}
+	if t.Message != "" {
+		parts = append(parts, t.Message)
+	}
+	if t.ActionURL != "" {
+		parts = append(parts, t.ActionText+": "+t.ActionURL)
+	}
+	return strings.Join(parts, "\n\n")
+}
@@ -0,0 +1,72 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package notifications
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testTextNotification struct {
+	testNotification
+}
+
+// ToText returns the text notification for testTextNotification
+func (n *testTextNotification) ToText(lang string) *Text {
+	return &Text{
+		Title:   "Custom",
+		Message: n.Test,
+	}
+}
+
+func TestRenderText(t *testing.T) {
+	t.Run("derived from mail", func(t *testing.T) {
+		n := &testNotification{Test: "Line from mail"}
+		text := RenderText(n, "en")
+		assert.Equal(t, "Test Notification", text.Title)
+		assert.Equal(t, "Line from mail", text.Message)
+		assert.Equal(t, "Test Notification\n\nLine from mail", text.String())
+	})
+	t.Run("with greeting and action", func(t *testing.T) {
+		text := RenderText(&testMailNotification{}, "en")
+		assert.Equal(t, "Hi,\n\nBefore the action\n\nAfter the action", text.Message)
+		assert.Equal(t, "Open", text.ActionText)
+		assert.Equal(t, "https://example.com", text.ActionURL)
+		assert.Equal(t, "Subject\n\nHi,\n\nBefore the action\n\nAfter the action\n\nOpen: https://example.com", text.String())
+	})
+	t.Run("own renderer", func(t *testing.T) {
+		n := &testTextNotification{testNotification{Test: "Own text"}}
+		text := RenderText(n, "en")
+		assert.Equal(t, "Custom", text.Title)
+		assert.Equal(t, "Own text", text.Message)
+	})
+}
+
+type testMailNotification struct {
+	testNotification
+}
+
+// ToMail returns the mail notification for testMailNotification
+func (n *testMailNotification) ToMail(lang string) *Mail {
+	return NewMail().
+		Subject("Subject").
+		Greeting("Hi,").
+		Line("Before the action").
+		Action("Open", "https://example.com").
+		Line("After the action")
+}
@@ -36,33 +36,47 @@ import (
 )
 
 type vikunjaInfos struct {
-	Version                    string    `json:"version"`
-	FrontendURL                string    `json:"frontend_url"`
-	Motd                       string    `json:"motd"`
-	LinkSharingEnabled         bool      `json:"link_sharing_enabled"`
-	MaxFileSize                string    `json:"max_file_size"`
-	RegistrationEnabled        bool      `json:"registration_enabled"`
-	AvailableMigrators         []string  `json:"available_migrators"`
-	TaskAttachmentsEnabled     bool      `json:"task_attachments_enabled"`
-	EnabledBackgroundProviders []string  `json:"enabled_background_providers"`
-	TotpEnabled                bool      `json:"totp_enabled"`
-	Legal                      legalInfo `json:"legal"`
-	CaldavEnabled              bool      `json:"caldav_enabled"`
-	AuthInfo                   authInfo  `json:"auth"`
-	EmailRemindersEnabled      bool      `json:"email_reminders_enabled"`
-	UserDeletionEnabled        bool      `json:"user_deletion_enabled"`
-	TaskCommentsEnabled        bool      `json:"task_comments_enabled"`
+	Version                     string    `json:"version"`
+	FrontendURL                 string    `json:"frontend_url"`
+	Motd                        string    `json:"motd"`
+	LinkSharingEnabled          bool      `json:"link_sharing_enabled"`
+	MaxFileSize                 string    `json:"max_file_size"`
+	RegistrationEnabled         bool      `json:"registration_enabled"`
+	AvailableMigrators          []string  `json:"available_migrators"`
+	TaskAttachmentsEnabled      bool      `json:"task_attachments_enabled"`
+	EnabledBackgroundProviders  []string  `json:"enabled_background_providers"`
+	TotpEnabled                 bool      `json:"totp_enabled"`
+	WebAuthnEnabled             bool      `json:"webauthn_enabled"`
+	OAuth2ServerEnabled         bool      `json:"oauth2_server_enabled"`
+	Legal                       legalInfo `json:"legal"`
+	CaldavEnabled               bool      `json:"caldav_enabled"`
+	AuthInfo                    authInfo  `json:"auth"`
+	EmailRemindersEnabled       bool      `json:"email_reminders_enabled"`
+	UserDeletionEnabled         bool      `json:"user_deletion_enabled"`
+	TaskCommentsEnabled         bool      `json:"task_comments_enabled"`
+	WebhooksEnabled             bool      `json:"webhooks_enabled"`
+	NotificationChannelsEnabled bool      `json:"notification_channels_enabled"`
 }
 
 type authInfo struct {
 	Local         localAuthInfo  `json:"local"`
 	OpenIDConnect openIDAuthInfo `json:"openid_connect"`
+	LDAP          ldapAuthInfo   `json:"ldap"`
+	Proxy         proxyAuthInfo  `json:"proxy"`
 }
 
 type localAuthInfo struct {
 	Enabled bool `json:"enabled"`
 }
 
+type ldapAuthInfo struct {
+	Enabled bool `json:"enabled"`
+}
+
+type proxyAuthInfo struct {
+	Enabled bool `json:"enabled"`
+}
+
 type openIDAuthInfo struct {
 	Enabled     bool               `json:"enabled"`
 	RedirectURL string             `json:"redirect_url"`
@@ -83,18 +97,22 @@ type legalInfo struct {
 // @Router /info [get]
 func Info(c echo.Context) error {
 	info := vikunjaInfos{
-		Version:                version.Version,
-		FrontendURL:            config.ServiceFrontendurl.GetString(),
-		Motd:                   config.ServiceMotd.GetString(),
-		LinkSharingEnabled:     config.ServiceEnableLinkSharing.GetBool(),
-		MaxFileSize:            config.FilesMaxSize.GetString(),
-		RegistrationEnabled:    config.ServiceEnableRegistration.GetBool(),
-		TaskAttachmentsEnabled: config.ServiceEnableTaskAttachments.GetBool(),
-		TotpEnabled:            config.ServiceEnableTotp.GetBool(),
-		CaldavEnabled:          config.ServiceEnableCaldav.GetBool(),
-		EmailRemindersEnabled:  config.ServiceEnableEmailReminders.GetBool(),
-		UserDeletionEnabled:    config.ServiceEnableUserDeletion.GetBool(),
-		TaskCommentsEnabled:    config.ServiceEnableTaskComments.GetBool(),
+		Version:                     version.Version,
+		FrontendURL:                 config.ServiceFrontendurl.GetString(),
+		Motd:                        config.ServiceMotd.GetString(),
+		LinkSharingEnabled:          config.ServiceEnableLinkSharing.GetBool(),
+		MaxFileSize:                 config.FilesMaxSize.GetString(),
+		RegistrationEnabled:         config.ServiceEnableRegistration.GetBool(),
+		TaskAttachmentsEnabled:      config.ServiceEnableTaskAttachments.GetBool(),
+		TotpEnabled:                 config.ServiceEnableTotp.GetBool(),
+		WebAuthnEnabled:             config.ServiceEnableWebAuthn.GetBool(),
+		OAuth2ServerEnabled:         config.ServiceEnableOAuth2Server.GetBool(),
+		CaldavEnabled:               config.ServiceEnableCaldav.GetBool(),
+		EmailRemindersEnabled:       config.ServiceEnableEmailReminders.GetBool(),
+		UserDeletionEnabled:         config.ServiceEnableUserDeletion.GetBool(),
+		TaskCommentsEnabled:         config.ServiceEnableTaskComments.GetBool(),
+		WebhooksEnabled:             config.WebhooksEnabled.GetBool(),
+		NotificationChannelsEnabled: config.NotificationChannelsEnabled.GetBool(),
 		AvailableMigrators: []string{
 			(&vikunja_file.FileMigrator{}).Name(),
 		},
@@ -110,6 +128,12 @@ func Info(c echo.Context) error {
 				Enabled:     config.AuthOpenIDEnabled.GetBool(),
 				RedirectURL: config.AuthOpenIDRedirectURL.GetString(),
 			},
+			LDAP: ldapAuthInfo{
+				Enabled: config.AuthLDAPEnabled.GetBool(),
+			},
+			Proxy: proxyAuthInfo{
+				Enabled: config.AuthProxyEnabled.GetBool(),
+			},
 		},
 	}
 
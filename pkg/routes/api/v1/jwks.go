@@ -0,0 +1,43 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package v1
+
+import (
+	"net/http"
+
+	"code.vikunja.io/api/pkg/modules/auth"
+	"code.vikunja.io/web/handler"
+	"github.com/labstack/echo/v4"
+)
+
+// JWKS returns the public keys to verify jwt tokens
+// @Summary Get the public keys to verify jwt tokens
+// @Description Returns the public keys of all keys which are currently used to sign or verify jwt tokens in the JSON Web Key Set format. The `kid` header of a token is the id of the key it was signed with. The set is empty if tokens are signed with the shared secret.
+// @tags service
+// @Produce json
+// @Success 200 {object} auth.JWKS
+// @Failure 500 {object} models.Message "Internal server error."
+// @Router /.well-known/jwks.json [get]
+func JWKS(c echo.Context) error {
+	jwks, err := auth.GetJWKS()
+	if err != nil {
+		return handler.HandleHTTPError(err, c)
+	}
+
+	c.Response().Header().Set("Cache-Control", "public, max-age=300")
+	return c.JSON(http.StatusOK, jwks)
+}
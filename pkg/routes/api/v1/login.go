@@ -21,14 +21,18 @@ import (
 
 	"code.vikunja.io/api/pkg/modules/keyvalue"
 
+	"code.vikunja.io/api/pkg/config"
 	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/log"
 	"code.vikunja.io/api/pkg/models"
 	"code.vikunja.io/api/pkg/modules/auth"
+	"code.vikunja.io/api/pkg/modules/auth/ldap"
 	user2 "code.vikunja.io/api/pkg/user"
 	"code.vikunja.io/web/handler"
 
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/labstack/echo/v4"
+	"xorm.io/xorm"
 )
 
 // Login is the login handler
@@ -40,7 +44,7 @@ import (
 // @Param credentials body user.Login true "The login credentials"
 // @Success 200 {object} auth.Token
 // @Failure 400 {object} models.Message "Invalid user password model."
-// @Failure 412 {object} models.Message "Invalid totp passcode."
+// @Failure 412 {object} models.Message "Invalid totp passcode or webauthn response."
 // @Failure 403 {object} models.Message "Invalid username or password."
 // @Router /login [post]
 func Login(c echo.Context) error {
@@ -53,7 +57,7 @@ func Login(c echo.Context) error {
 	defer s.Close()
 
 	// Check user
-	user, err := user2.CheckUserCredentials(s, &u)
+	user, err := checkLoginCredentials(s, &u)
 	if err != nil {
 		_ = s.Rollback()
 		return handler.HandleHTTPError(err, c)
@@ -70,7 +74,32 @@ func Login(c echo.Context) error {
 		return handler.HandleHTTPError(err, c)
 	}
 
-	if totpEnabled {
+	webAuthnEnabled, err := user2.WebAuthnEnabledForUser(s, user)
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	switch {
+	case webAuthnEnabled && u.WebAuthn != nil:
+		err = user2.FinishWebAuthnLogin(s, user, u.WebAuthn)
+		if err != nil {
+			_ = s.Rollback()
+			return handler.HandleHTTPError(err, c)
+		}
+	case totpEnabled && (u.TOTPPasscode != "" || u.TOTPRecoveryCode != "" || !webAuthnEnabled):
+		if u.TOTPRecoveryCode != "" {
+			err = user2.UseTOTPRecoveryCode(s, user, u.TOTPRecoveryCode)
+			if err != nil {
+				if user2.IsErrInvalidTOTPRecoveryCode(err) {
+					user2.HandleFailedTOTPAuth(s, user)
+				}
+				_ = s.Rollback()
+				return handler.HandleHTTPError(err, c)
+			}
+			break
+		}
+
 		if u.TOTPPasscode == "" {
 			_ = s.Rollback()
 			return handler.HandleHTTPError(user2.ErrInvalidTOTPPasscode{}, c)
@@ -87,6 +116,9 @@ func Login(c echo.Context) error {
 			_ = s.Rollback()
 			return handler.HandleHTTPError(err, c)
 		}
+	case webAuthnEnabled:
+		_ = s.Rollback()
+		return handler.HandleHTTPError(&user2.ErrWebAuthnRequired{}, c)
 	}
 
 	if err := keyvalue.Del(user.GetFailedTOTPAttemptsKey()); err != nil {
@@ -105,6 +137,22 @@ func Login(c echo.Context) error {
 	return auth.NewUserAuthTokenResponse(user, c)
 }
 
+// checkLoginCredentials authenticates a user against the ldap directory if it is enabled.
+// If local authentication is enabled as well, it falls back to local users when that fails.
+func checkLoginCredentials(s *xorm.Session, u *user2.Login) (*user2.User, error) {
+	if config.AuthLDAPEnabled.GetBool() {
+		usr, err := ldap.AuthenticateUserInLDAP(s, u.Username, u.Password)
+		if err == nil || !config.AuthLocalEnabled.GetBool() || user2.IsErrNoLDAPEmailProvided(err) {
+			return usr, err
+		}
+		if !user2.IsErrWrongUsernameOrPassword(err) {
+			log.Errorf("Could not authenticate user %s against ldap, falling back to local users: %s", u.Username, err)
+		}
+	}
+
+	return user2.CheckUserCredentials(s, u)
+}
+
 // RenewToken gives a new token to every user with a valid token
 // If the token is valid is checked in the middleware.
 // @Summary Renew user token
@@ -156,6 +204,60 @@ func RenewToken(c echo.Context) (err error) {
 		return handler.HandleHTTPError(err, c)
 	}
 
-	// Create token
-	return auth.NewUserAuthTokenResponse(user, c)
+	// The new token belongs to the same session
+	sessionID, _ := claims["sid"].(string)
+	t, err := auth.NewUserJWTAuthtoken(user, sessionID)
+	if err != nil {
+		return handler.HandleHTTPError(err, c)
+	}
+	return c.JSON(http.StatusOK, auth.Token{Token: t})
+}
+
+// RefreshToken exchanges the refresh token of a session for a new access and refresh token
+// @Summary Refresh a session
+// @Description Returns a new short-lived access token and a new refresh token for the session the provided refresh token belongs to. Every refresh token can only be used once.
+// @tags user
+// @Accept json
+// @Produce json
+// @Param token body auth.RefreshTokenRequest true "The refresh token"
+// @Success 200 {object} auth.Token
+// @Failure 401 {object} web.HTTPError "The refresh token is invalid or the session expired."
+// @Failure 412 {object} web.HTTPError "The user account is disabled."
+// @Router /user/token/refresh [post]
+func RefreshToken(c echo.Context) error {
+	r := &auth.RefreshTokenRequest{}
+	if err := c.Bind(r); err != nil {
+		return c.JSON(http.StatusBadRequest, models.Message{Message: "Please provide a refresh token."})
+	}
+
+	s := db.NewSession()
+	defer s.Close()
+
+	session, refreshToken, err := user2.RefreshSession(s, r.RefreshToken, c.RealIP())
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	user, err := user2.GetUserWithEmail(s, &user2.User{ID: session.UserID})
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	if user.Status == user2.StatusDisabled {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(&user2.ErrAccountDisabled{UserID: user.ID}, c)
+	}
+
+	if err := s.Commit(); err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	t, err := auth.NewUserJWTAuthtoken(user, session.ID)
+	if err != nil {
+		return handler.HandleHTTPError(err, c)
+	}
+	return c.JSON(http.StatusOK, auth.Token{Token: t, RefreshToken: refreshToken})
 }
@@ -0,0 +1,246 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package v1
+
+import (
+	"net/http"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/models"
+	"code.vikunja.io/api/pkg/modules/auth"
+	"code.vikunja.io/api/pkg/user"
+	"code.vikunja.io/web"
+	"code.vikunja.io/web/handler"
+	"github.com/labstack/echo/v4"
+	"xorm.io/xorm"
+)
+
+// oauth2Error is an error response as defined in the oauth2 spec. Oauth2 client libraries expect it from the
+// token and introspection endpoints.
+type oauth2Error struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description,omitempty"`
+}
+
+func handleOAuth2Error(err error, c echo.Context) error {
+	oerr, is := err.(interface {
+		OAuth2Error() string
+		HTTPError() web.HTTPError
+	})
+	if !is {
+		return handler.HandleHTTPError(err, c)
+	}
+
+	httpErr := oerr.HTTPError()
+	if httpErr.HTTPCode == http.StatusUnauthorized {
+		c.Response().Header().Set(echo.HeaderWWWAuthenticate, `Basic realm="vikunja"`)
+	}
+	return c.JSON(httpErr.HTTPCode, oauth2Error{
+		Error:            oerr.OAuth2Error(),
+		ErrorDescription: httpErr.Message,
+	})
+}
+
+// Clients can send their credentials either with basic auth or in the request body.
+func authenticateOAuth2Client(s *xorm.Session, c echo.Context) (*models.OAuth2Client, error) {
+	clientID, clientSecret, ok := c.Request().BasicAuth()
+	if !ok {
+		clientID = c.FormValue("client_id")
+		clientSecret = c.FormValue("client_secret")
+	}
+	return models.AuthenticateOAuth2Client(s, clientID, clientSecret)
+}
+
+// OAuth2GetAuthorization returns what a user needs to know to authorize an oauth2 client
+// @Summary Get an oauth2 authorization request
+// @Description Validates an authorization request of an oauth2 client and returns the client and the scopes it asks for, to show them to the user before they authorize it. The parameters are the ones the client sent to the authorization page of the frontend.
+// @tags oauth2
+// @Produce json
+// @Security JWTKeyAuth
+// @Param response_type query string true "Must be `code`."
+// @Param client_id query string true "The client id."
+// @Param redirect_uri query string false "One of the redirect uris of the client."
+// @Param scope query string true "The requested scopes, separated by spaces."
+// @Param state query string false "An opaque value which is passed back to the client."
+// @Param code_challenge query string true "The pkce code challenge."
+// @Param code_challenge_method query string true "Must be `S256`."
+// @Success 200 {object} models.OAuth2Authorization
+// @Failure 400 {object} web.HTTPError "The request is invalid."
+// @Failure 404 {object} web.HTTPError "The client does not exist."
+// @Failure 500 {object} models.Message "Internal server error."
+// @Router /oauth2/authorize [get]
+func OAuth2GetAuthorization(c echo.Context) error {
+	request := &models.OAuth2AuthorizationRequest{}
+	if err := bindModel(c, request); err != nil {
+		return err
+	}
+
+	s := db.NewSession()
+	defer s.Close()
+
+	authorization, err := models.GetOAuth2Authorization(s, request)
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	return c.JSON(http.StatusOK, authorization)
+}
+
+// OAuth2Authorize authorizes an oauth2 client to act on behalf of the current user
+// @Summary Authorize an oauth2 client
+// @Description Records the consent of the current user and returns the url the user needs to be redirected to. It contains the authorization code the client can exchange for tokens.
+// @tags oauth2
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param request body models.OAuth2AuthorizationRequest true "The authorization request of the client."
+// @Success 200 {object} models.OAuth2AuthorizationResponse
+// @Failure 400 {object} web.HTTPError "The request is invalid."
+// @Failure 404 {object} web.HTTPError "The client does not exist."
+// @Failure 500 {object} models.Message "Internal server error."
+// @Router /oauth2/authorize [post]
+func OAuth2Authorize(c echo.Context) error {
+	request := &models.OAuth2AuthorizationRequest{}
+	if err := bindModel(c, request); err != nil {
+		return err
+	}
+
+	a, err := auth.GetAuthFromClaims(c)
+	if err != nil {
+		return handler.HandleHTTPError(err, c)
+	}
+
+	s := db.NewSession()
+	defer s.Close()
+
+	response, err := models.AuthorizeOAuth2Client(s, a, request)
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// OAuth2Token issues tokens to oauth2 clients
+// @Summary Get oauth2 tokens
+// @Description Exchanges an authorization code or a refresh token for a new access and refresh token. Every code and refresh token can only be used once. Confidential clients need to authenticate with their client secret, either with basic auth or in the request body.
+// @tags oauth2
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Param grant_type formData string true "Either `authorization_code` or `refresh_token`."
+// @Param client_id formData string false "The client id, if the client does not use basic auth."
+// @Param client_secret formData string false "The client secret of confidential clients, if the client does not use basic auth."
+// @Param code formData string false "The authorization code."
+// @Param redirect_uri formData string false "The redirect uri of the authorization request."
+// @Param code_verifier formData string false "The pkce code verifier."
+// @Param refresh_token formData string false "The refresh token."
+// @Success 200 {object} auth.OAuth2Token
+// @Failure 400 {object} v1.oauth2Error "The request or grant is invalid."
+// @Failure 401 {object} v1.oauth2Error "The client credentials are wrong."
+// @Failure 500 {object} models.Message "Internal server error."
+// @Router /oauth2/token [post]
+func OAuth2Token(c echo.Context) error {
+	s := db.NewSession()
+	defer s.Close()
+
+	client, err := authenticateOAuth2Client(s, c)
+	if err != nil {
+		_ = s.Rollback()
+		return handleOAuth2Error(err, c)
+	}
+
+	var grant *models.OAuth2Grant
+	var refreshToken string
+	switch grantType := c.FormValue("grant_type"); grantType {
+	case "authorization_code":
+		grant, refreshToken, err = models.ExchangeOAuth2AuthorizationCode(
+			s,
+			client,
+			c.FormValue("code"),
+			c.FormValue("redirect_uri"),
+			c.FormValue("code_verifier"),
+		)
+	case "refresh_token":
+		grant, refreshToken, err = models.RefreshOAuth2Grant(s, client, c.FormValue("refresh_token"))
+	default:
+		err = models.ErrOAuth2UnsupportedGrantType{GrantType: grantType}
+	}
+	if err != nil {
+		_ = s.Rollback()
+		return handleOAuth2Error(err, c)
+	}
+
+	u, err := user.GetUserByID(s, grant.UserID)
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	// Disabled users must not get any new tokens
+	if u.Status != user.StatusActive {
+		_ = s.Rollback()
+		return handleOAuth2Error(models.ErrOAuth2InvalidGrant{}, c)
+	}
+
+	if err := s.Commit(); err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	token, err := auth.NewOAuth2Token(u, grant, refreshToken)
+	if err != nil {
+		return handler.HandleHTTPError(err, c)
+	}
+
+	c.Response().Header().Set("Cache-Control", "no-store")
+	c.Response().Header().Set("Pragma", "no-cache")
+	return c.JSON(http.StatusOK, token)
+}
+
+// OAuth2Introspect returns whether a token of an oauth2 client is active
+// @Summary Introspect an oauth2 token
+// @Description Returns whether an access or refresh token is still active, together with its scopes and user as defined in RFC 7662. The client needs to authenticate and only gets information about its own tokens.
+// @tags oauth2
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Param token formData string true "The access or refresh token."
+// @Param client_id formData string false "The client id, if the client does not use basic auth."
+// @Param client_secret formData string false "The client secret of confidential clients, if the client does not use basic auth."
+// @Success 200 {object} auth.OAuth2Introspection
+// @Failure 401 {object} v1.oauth2Error "The client credentials are wrong."
+// @Failure 500 {object} models.Message "Internal server error."
+// @Router /oauth2/introspect [post]
+func OAuth2Introspect(c echo.Context) error {
+	s := db.NewSession()
+	defer s.Close()
+
+	client, err := authenticateOAuth2Client(s, c)
+	if err != nil {
+		_ = s.Rollback()
+		return handleOAuth2Error(err, c)
+	}
+
+	introspection, err := auth.IntrospectOAuth2Token(s, client, c.FormValue("token"))
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	return c.JSON(http.StatusOK, introspection)
+}
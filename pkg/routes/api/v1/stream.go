@@ -0,0 +1,81 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package v1
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"code.vikunja.io/api/pkg/modules/auth"
+	"code.vikunja.io/api/pkg/modules/stream"
+	"code.vikunja.io/web/handler"
+	"github.com/labstack/echo/v4"
+)
+
+// The interval in which a comment is sent to keep the connection open through proxies
+const streamKeepAliveInterval = 30 * time.Second
+
+// Stream sends all task, bucket, comment and list events the current user or link share can see as server-sent events.
+// @Summary Receive changes in real time
+// @Description Opens a server-sent events stream which receives all task, bucket, comment and list events for all lists the authenticated user or link share has access to. The name of the server-sent event is the event name, the data contains the event payload.
+// @Description Because browsers can't set headers for EventSource requests, the jwt token can also be passed as `token` query parameter.
+// @tags service
+// @Produce text/event-stream
+// @Security JWTKeyAuth
+// @Param token query string false "The jwt token, if it is not provided via the Authorization header."
+// @Success 200 {object} stream.Message "A stream of events."
+// @Failure 400 {object} web.HTTPError "Invalid token."
+// @Router /stream [get]
+func Stream(c echo.Context) error {
+	a, err := auth.GetAuthFromClaims(c)
+	if err != nil {
+		return handler.HandleHTTPError(err, c)
+	}
+
+	client := stream.Subscribe(a)
+	defer stream.Unsubscribe(client)
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "text/event-stream")
+	res.Header().Set("Cache-Control", "no-cache")
+	res.Header().Set("Connection", "keep-alive")
+	// Disable response buffering in nginx
+	res.Header().Set("X-Accel-Buffering", "no")
+	res.WriteHeader(http.StatusOK)
+	res.Flush()
+
+	keepAlive := time.NewTicker(streamKeepAliveInterval)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-c.Request().Context().Done():
+			return nil
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(res, ": keep-alive\n\n"); err != nil {
+				return nil
+			}
+			res.Flush()
+		case m := <-client.Messages():
+			if _, err := fmt.Fprintf(res, "event: %s\ndata: %s\n\n", m.Event, m.Data); err != nil {
+				return nil
+			}
+			res.Flush()
+		}
+	}
+}
@@ -0,0 +1,134 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package v1
+
+import (
+	"net/http"
+	"strconv"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/models"
+	"code.vikunja.io/api/pkg/user"
+	"code.vikunja.io/web/handler"
+	"github.com/labstack/echo/v4"
+)
+
+// UserAppPasswords returns all app passwords of the current user
+// @Summary Get all app passwords
+// @Description Returns all app passwords of the current user. The passwords themselves are never returned.
+// @tags user
+// @Produce json
+// @Security JWTKeyAuth
+// @Success 200 {array} user.AppPassword
+// @Failure 500 {object} models.Message "Internal server error."
+// @Router /user/settings/app-passwords [get]
+func UserAppPasswords(c echo.Context) error {
+	u, err := user.GetCurrentUser(c)
+	if err != nil {
+		return handler.HandleHTTPError(err, c)
+	}
+
+	s := db.NewSession()
+	defer s.Close()
+
+	appPasswords, err := user.GetAppPasswordsForUser(s, u)
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	return c.JSON(http.StatusOK, appPasswords)
+}
+
+// UserAppPasswordCreate creates a new app password
+// @Summary Create an app password
+// @Description Creates a new app password for the current user. The generated password is only returned once in the response. App passwords can only be used for CalDAV unless caldav_only is set to false, in that case they can also be used with basic auth for the api.
+// @tags user
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param appPassword body user.AppPassword true "The app password with its name."
+// @Success 201 {object} user.AppPassword
+// @Failure 400 {object} web.HTTPError "The app password has no name."
+// @Failure 500 {object} models.Message "Internal server error."
+// @Router /user/settings/app-passwords [put]
+func UserAppPasswordCreate(c echo.Context) error {
+	appPassword := &user.AppPassword{CaldavOnly: true}
+	if err := bindModel(c, appPassword); err != nil {
+		return err
+	}
+
+	u, err := user.GetCurrentUser(c)
+	if err != nil {
+		return handler.HandleHTTPError(err, c)
+	}
+
+	s := db.NewSession()
+	defer s.Close()
+
+	err = user.CreateAppPassword(s, u, appPassword)
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	if err := s.Commit(); err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	return c.JSON(http.StatusCreated, appPassword)
+}
+
+// UserAppPasswordDelete removes an app password of the current user
+// @Summary Remove an app password
+// @Description Removes an app password of the current user. It can't be used to authenticate afterwards.
+// @tags user
+// @Produce json
+// @Security JWTKeyAuth
+// @Param apppassword path int true "The id of the app password"
+// @Success 200 {object} models.Message "Successfully removed."
+// @Failure 404 {object} web.HTTPError "The app password does not exist."
+// @Failure 500 {object} models.Message "Internal server error."
+// @Router /user/settings/app-passwords/{apppassword} [delete]
+func UserAppPasswordDelete(c echo.Context) error {
+	appPasswordID, err := strconv.ParseInt(c.Param("apppassword"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid app password id.")
+	}
+
+	u, err := user.GetCurrentUser(c)
+	if err != nil {
+		return handler.HandleHTTPError(err, c)
+	}
+
+	s := db.NewSession()
+	defer s.Close()
+
+	err = user.DeleteAppPassword(s, u, appPasswordID)
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	if err := s.Commit(); err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	return c.JSON(http.StatusOK, models.Message{Message: "The app password was removed successfully."})
+}
@@ -0,0 +1,101 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package v1
+
+import (
+	"net/http"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/models"
+	"code.vikunja.io/api/pkg/notifications"
+	"code.vikunja.io/api/pkg/user"
+	"code.vikunja.io/web/handler"
+	"github.com/labstack/echo/v4"
+)
+
+// UserNotificationPreferences returns the notification settings of the current user
+// @Summary Get the notification settings
+// @Description Returns for every notification which can be turned on or off and every channel it is sent through if the current user gets it.
+// @tags user
+// @Produce json
+// @Security JWTKeyAuth
+// @Success 200 {array} notifications.NotificationPreference
+// @Failure 500 {object} models.Message "Internal server error."
+// @Router /user/settings/notifications [get]
+func UserNotificationPreferences(c echo.Context) error {
+	u, err := user.GetCurrentUser(c)
+	if err != nil {
+		return handler.HandleHTTPError(err, c)
+	}
+
+	s := db.NewSession()
+	defer s.Close()
+
+	preferences, err := models.GetNotificationPreferences(s, u)
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	return c.JSON(http.StatusOK, preferences)
+}
+
+// UpdateUserNotificationPreferences turns notifications on or off for the current user
+// @Summary Change the notification settings
+// @Description Turns notifications on or off per channel for the current user. Only the passed preferences are changed. Returns all notification settings afterwards.
+// @tags user
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param preferences body []notifications.NotificationPreference true "The notification preferences to change."
+// @Success 200 {array} notifications.NotificationPreference
+// @Failure 400 {object} web.HTTPError "The notification can't be turned on or off for this channel."
+// @Failure 500 {object} models.Message "Internal server error."
+// @Router /user/settings/notifications [post]
+func UpdateUserNotificationPreferences(c echo.Context) error {
+	preferences := []*notifications.NotificationPreference{}
+	if err := c.Bind(&preferences); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid notification preferences.")
+	}
+
+	u, err := user.GetCurrentUser(c)
+	if err != nil {
+		return handler.HandleHTTPError(err, c)
+	}
+
+	s := db.NewSession()
+	defer s.Close()
+
+	err = models.UpdateNotificationPreferences(s, u, preferences)
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	all, err := models.GetNotificationPreferences(s, u)
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	if err := s.Commit(); err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	return c.JSON(http.StatusOK, all)
+}
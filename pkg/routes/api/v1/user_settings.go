@@ -50,6 +50,22 @@ type UserSettings struct {
 	DefaultListID int64 `json:"default_list_id"`
 	// The day when the week starts for this user. 0 = sunday, 1 = monday, etc.
 	WeekStart int `json:"week_start"`
+	// If set, mails about comments, assignments and other changes are collected and sent as one digest mail
+	// instead of one mail per change. Can be `hourly`, `daily` or `weekly`. Leave empty to get every mail right away.
+	// The overdue tasks reminder becomes part of the digest. Turning the digest off sends everything still queued for it.
+	DigestInterval string `json:"digest_interval"`
+	// The hour of the day (0-23) daily and weekly digests are sent at. Weekly digests are sent on the first day of the week.
+	DigestHour int `json:"digest_hour"`
+	// The timezone of the user, like `Europe/Berlin`. Used for reminders, digests and dates in mails.
+	// If empty, the timezone of this Vikunja instance is used.
+	Timezone string `json:"timezone"`
+	// The start of a daily time window in the format `HH:MM` in which the user does not get any task reminders.
+	// Reminders falling in that window are sent once it ends. Leave start and end empty to get reminders at any time.
+	QuietHoursStart string `json:"quiet_hours_start"`
+	// The end of the quiet hours in the format `HH:MM`. Can be before the start to span midnight, like `22:00` to `07:00`.
+	QuietHoursEnd string `json:"quiet_hours_end"`
+	// The language the user gets their notifications in, like `de`. If empty, notifications are sent in english.
+	Language string `json:"language"`
 }
 
 // GetUserAvatarProvider returns the currently set user avatar
@@ -170,6 +186,8 @@ func UpdateGeneralUserSettings(c echo.Context) error {
 		return handler.HandleHTTPError(err, c)
 	}
 
+	hadDigest := user.WantsDigest()
+
 	user.Name = us.Name
 	user.EmailRemindersEnabled = us.EmailRemindersEnabled
 	user.DiscoverableByEmail = us.DiscoverableByEmail
@@ -177,6 +195,12 @@ func UpdateGeneralUserSettings(c echo.Context) error {
 	user.OverdueTasksRemindersEnabled = us.OverdueTasksRemindersEnabled
 	user.DefaultListID = us.DefaultListID
 	user.WeekStart = us.WeekStart
+	user.DigestInterval = us.DigestInterval
+	user.DigestHour = us.DigestHour
+	user.Timezone = us.Timezone
+	user.QuietHoursStart = us.QuietHoursStart
+	user.QuietHoursEnd = us.QuietHoursEnd
+	user.Language = us.Language
 
 	_, err = user2.UpdateUser(s, user)
 	if err != nil {
@@ -184,6 +208,14 @@ func UpdateGeneralUserSettings(c echo.Context) error {
 		return handler.HandleHTTPError(err, c)
 	}
 
+	if hadDigest && !user.WantsDigest() {
+		err = models.SendPendingDigest(s, user)
+		if err != nil {
+			_ = s.Rollback()
+			return handler.HandleHTTPError(err, c)
+		}
+	}
+
 	if err := s.Commit(); err != nil {
 		_ = s.Rollback()
 		return handler.HandleHTTPError(err, c)
@@ -73,6 +73,12 @@ func UserShow(c echo.Context) error {
 			OverdueTasksRemindersEnabled: u.OverdueTasksRemindersEnabled,
 			DefaultListID:                u.DefaultListID,
 			WeekStart:                    u.WeekStart,
+			DigestInterval:               u.DigestInterval,
+			DigestHour:                   u.DigestHour,
+			Timezone:                     u.Timezone,
+			QuietHoursStart:              u.QuietHoursStart,
+			QuietHoursEnd:                u.QuietHoursEnd,
+			Language:                     u.Language,
 		},
 		DeletionScheduledAt: u.DeletionScheduledAt,
 		IsLocalUser:         u.Issuer == user.IssuerLocal,
@@ -68,13 +68,13 @@ func UserTOTPEnroll(c echo.Context) error {
 
 // UserTOTPEnable is the handler to enable totp for a user
 // @Summary Enable a previously enrolled totp setting.
-// @Description Enables a previously enrolled totp setting by providing a totp passcode.
+// @Description Enables a previously enrolled totp setting by providing a totp passcode. Returns a set of one-time recovery codes which can be used instead of a totp passcode if the user loses access to their totp device.
 // @tags user
 // @Accept json
 // @Produce json
 // @Param totp body user.TOTPPasscode true "The totp passcode."
 // @Security JWTKeyAuth
-// @Success 200 {object} models.Message "Successfully enabled"
+// @Success 200 {object} user.TOTPRecoveryCodes "Successfully enabled. Contains the recovery codes which are only shown once."
 // @Failure 400 {object} web.HTTPError "Something's invalid."
 // @Failure 404 {object} web.HTTPError "User does not exist."
 // @Failure 412 {object} web.HTTPError "TOTP is not enrolled."
@@ -100,7 +100,7 @@ func UserTOTPEnable(c echo.Context) error {
 	s := db.NewSession()
 	defer s.Close()
 
-	err = user.EnableTOTP(s, passcode)
+	codes, err := user.EnableTOTP(s, passcode)
 	if err != nil {
 		_ = s.Rollback()
 		return handler.HandleHTTPError(err, c)
@@ -111,7 +111,7 @@ func UserTOTPEnable(c echo.Context) error {
 		return handler.HandleHTTPError(err, c)
 	}
 
-	return c.JSON(http.StatusOK, models.Message{Message: "TOTP was enabled successfully."})
+	return c.JSON(http.StatusOK, codes)
 }
 
 // UserTOTPDisable disables totp settings for the current user.
@@ -171,6 +171,73 @@ func UserTOTPDisable(c echo.Context) error {
 	return c.JSON(http.StatusOK, models.Message{Message: "TOTP was enabled successfully."})
 }
 
+// UserTOTPRegenerateRecoveryCodes creates new recovery codes for the current user.
+// @Summary Regenerate totp recovery codes
+// @Description Creates a new set of totp recovery codes for the current user. All previous recovery codes become invalid.
+// @tags user
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param totp body user.Login true "The current user's password (only password is enough)."
+// @Success 200 {object} user.TOTPRecoveryCodes "The new recovery codes. They are only shown once."
+// @Failure 400 {object} web.HTTPError "Something's invalid."
+// @Failure 412 {object} web.HTTPError "TOTP is not enabled."
+// @Failure 500 {object} models.Message "Internal server error."
+// @Router /user/settings/totp/recovery-codes [post]
+func UserTOTPRegenerateRecoveryCodes(c echo.Context) error {
+	login := &user.Login{}
+	if err := c.Bind(login); err != nil {
+		log.Debugf("Invalid model error. Internal error was: %s", err.Error())
+		if he, is := err.(*echo.HTTPError); is {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid model provided. Error was: %s", he.Message))
+		}
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid model provided.")
+	}
+
+	u, err := user.GetCurrentUser(c)
+	if err != nil {
+		return handler.HandleHTTPError(err, c)
+	}
+
+	s := db.NewSession()
+	defer s.Close()
+
+	u, err = user.GetUserByID(s, u.ID)
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	err = user.CheckUserPassword(u, login.Password)
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	enabled, err := user.TOTPEnabledForUser(s, u)
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+	if !enabled {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(user.ErrTOTPNotEnabled{}, c)
+	}
+
+	codes, err := user.GenerateTOTPRecoveryCodes(s, u)
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	if err := s.Commit(); err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	return c.JSON(http.StatusOK, codes)
+}
+
 // UserTOTPQrCode is the handler to show a qr code to enroll the user into totp
 // @Summary Totp QR Code
 // @Description Returns a qr code for easier setup at end user's devices.
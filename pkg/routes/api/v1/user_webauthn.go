@@ -0,0 +1,259 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package v1
+
+import (
+	"net/http"
+	"strconv"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/models"
+	"code.vikunja.io/api/pkg/modules/auth"
+	"code.vikunja.io/api/pkg/user"
+	"code.vikunja.io/web/handler"
+	"github.com/labstack/echo/v4"
+)
+
+// UserWebAuthnCredentials returns all webauthn credentials of the current user
+// @Summary Get all webauthn credentials
+// @Description Returns all webauthn credentials like security keys or passkeys of the current user.
+// @tags user
+// @Produce json
+// @Security JWTKeyAuth
+// @Success 200 {array} user.WebAuthnCredential
+// @Failure 500 {object} models.Message "Internal server error."
+// @Router /user/settings/webauthn [get]
+func UserWebAuthnCredentials(c echo.Context) error {
+	u, err := user.GetCurrentUser(c)
+	if err != nil {
+		return handler.HandleHTTPError(err, c)
+	}
+
+	s := db.NewSession()
+	defer s.Close()
+
+	credentials, err := user.GetWebAuthnCredentialsForUser(s, u)
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	return c.JSON(http.StatusOK, credentials)
+}
+
+// UserWebAuthnRegisterBegin starts the registration of a new webauthn credential
+// @Summary Start registering a webauthn credential
+// @Description Returns the options which need to be passed to navigator.credentials.create() in the browser. The response of the browser then needs to be sent to the finish endpoint together with the session id.
+// @tags user
+// @Produce json
+// @Security JWTKeyAuth
+// @Success 200 {object} user.WebAuthnCeremony
+// @Failure 500 {object} models.Message "Internal server error."
+// @Router /user/settings/webauthn/register/begin [post]
+func UserWebAuthnRegisterBegin(c echo.Context) error {
+	u, err := user.GetCurrentUser(c)
+	if err != nil {
+		return handler.HandleHTTPError(err, c)
+	}
+
+	s := db.NewSession()
+	defer s.Close()
+
+	u, err = user.GetUserByID(s, u.ID)
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	ceremony, err := user.BeginWebAuthnRegistration(s, u)
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	return c.JSON(http.StatusOK, ceremony)
+}
+
+// UserWebAuthnRegisterFinish saves a new webauthn credential
+// @Summary Finish registering a webauthn credential
+// @Description Verifies the response of the authenticator and saves the new credential. From now on, the credential can be used as second factor or to log in without a password.
+// @tags user
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param registration body user.WebAuthnRegistration true "The name of the new credential and the response of the authenticator."
+// @Success 201 {object} user.WebAuthnCredential
+// @Failure 400 {object} web.HTTPError "Something's invalid."
+// @Failure 412 {object} web.HTTPError "The webauthn session or response is invalid."
+// @Failure 500 {object} models.Message "Internal server error."
+// @Router /user/settings/webauthn/register/finish [post]
+func UserWebAuthnRegisterFinish(c echo.Context) error {
+	registration := &user.WebAuthnRegistration{}
+	if err := bindModel(c, registration); err != nil {
+		return err
+	}
+
+	u, err := user.GetCurrentUser(c)
+	if err != nil {
+		return handler.HandleHTTPError(err, c)
+	}
+
+	s := db.NewSession()
+	defer s.Close()
+
+	u, err = user.GetUserByID(s, u.ID)
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	credential, err := user.FinishWebAuthnRegistration(s, u, registration)
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	if err := s.Commit(); err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	return c.JSON(http.StatusCreated, credential)
+}
+
+// UserWebAuthnDelete removes a webauthn credential of the current user
+// @Summary Remove a webauthn credential
+// @Description Removes a webauthn credential of the current user. It can't be used to log in afterwards.
+// @tags user
+// @Produce json
+// @Security JWTKeyAuth
+// @Param credential path int true "The id of the credential"
+// @Success 200 {object} models.Message "Successfully removed."
+// @Failure 404 {object} web.HTTPError "The credential does not exist."
+// @Failure 500 {object} models.Message "Internal server error."
+// @Router /user/settings/webauthn/{credential} [delete]
+func UserWebAuthnDelete(c echo.Context) error {
+	credentialID, err := strconv.ParseInt(c.Param("credential"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid credential id.")
+	}
+
+	u, err := user.GetCurrentUser(c)
+	if err != nil {
+		return handler.HandleHTTPError(err, c)
+	}
+
+	s := db.NewSession()
+	defer s.Close()
+
+	err = user.DeleteWebAuthnCredential(s, u, credentialID)
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	if err := s.Commit(); err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	return c.JSON(http.StatusOK, models.Message{Message: "The credential was removed successfully."})
+}
+
+// LoginWebAuthnBegin starts a webauthn login
+// @Summary Start a webauthn login
+// @Description Returns the options which need to be passed to navigator.credentials.get() in the browser. Only the credentials of the given user are allowed. If a username and password are provided, the response can be passed to the login endpoint as second factor. With only a username, the authenticator needs to verify the user and the response can be used to log in without a password through the /login/webauthn endpoint.
+// @tags user
+// @Accept json
+// @Produce json
+// @Param credentials body user.Login true "The username. The password is only needed when using webauthn as second factor."
+// @Success 200 {object} user.WebAuthnCeremony
+// @Failure 403 {object} models.Message "Invalid username or password."
+// @Failure 500 {object} models.Message "Internal server error."
+// @Router /login/webauthn/begin [post]
+func LoginWebAuthnBegin(c echo.Context) error {
+	login := &user.Login{}
+	if err := bindModel(c, login); err != nil {
+		return err
+	}
+
+	s := db.NewSession()
+	defer s.Close()
+
+	var u *user.User
+	var err error
+	passwordless := login.Password == ""
+	if passwordless {
+		u, err = user.GetUserByUsername(s, login.Username)
+		if user.IsErrUserDoesNotExist(err) {
+			err = user.ErrWrongUsernameOrPassword{}
+		}
+	} else {
+		u, err = checkLoginCredentials(s, login)
+	}
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	ceremony, err := user.BeginWebAuthnLogin(s, u, passwordless)
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	if err := s.Commit(); err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	return c.JSON(http.StatusOK, ceremony)
+}
+
+// LoginWebAuthn logs a user in with a webauthn credential
+// @Summary Log in without a password
+// @Description Logs a user in with a webauthn credential, without a password. The login needs to be started with the /login/webauthn/begin endpoint providing only the username.
+// @tags user
+// @Accept json
+// @Produce json
+// @Param login body user.WebAuthnLogin true "The session id and response of the authenticator."
+// @Success 200 {object} auth.Token
+// @Failure 412 {object} web.HTTPError "The webauthn session or response is invalid."
+// @Failure 500 {object} models.Message "Internal server error."
+// @Router /login/webauthn [post]
+func LoginWebAuthn(c echo.Context) error {
+	login := &user.WebAuthnLogin{}
+	if err := bindModel(c, login); err != nil {
+		return err
+	}
+
+	s := db.NewSession()
+	defer s.Close()
+
+	u, err := user.FinishPasswordlessWebAuthnLogin(s, login)
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	if err := s.Commit(); err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	return auth.NewUserAuthTokenResponse(u, c)
+}
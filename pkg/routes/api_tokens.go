@@ -0,0 +1,152 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package routes
+
+import (
+	"net/http"
+	"strings"
+
+	"code.vikunja.io/api/pkg/models"
+	"code.vikunja.io/api/pkg/modules/auth"
+	"code.vikunja.io/web/handler"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+)
+
+type apiTokenRouteScope struct {
+	// The route path relative to /api/v1. It matches the route itself and all routes below it.
+	path string
+	// If set, the rule only applies to this http method.
+	method string
+	// An empty resource means the route can't be used with api tokens at all.
+	resource string
+	// If not set, GET requests need the read permission and all others the write permission.
+	permission models.APITokenPermission
+}
+
+// apiTokenRouteScopes maps all authenticated routes to the resource an api token needs a scope for.
+// The first matching rule wins, routes without a matching rule can't be used with api tokens.
+var apiTokenRouteScopes = []*apiTokenRouteScope{
+	// Api tokens can't be used to manage the account or to get other tokens
+	{path: "/user/token"},
+	{path: "/user/tokens"},
+	{path: "/user/sessions"},
+	{path: "/user/password"},
+	{path: "/user/settings/email"},
+	{path: "/user/settings/totp"},
+	{path: "/user/settings/webauthn"},
+	{path: "/user/settings/app-passwords"},
+	// Notification channels send data to arbitrary urls
+	{path: "/user/settings/notifications/channels"},
+	{path: "/oauth2"},
+	{path: "/user/export"},
+	{path: "/user/deletion"},
+	{path: "/migration"},
+
+	{path: "/user", resource: models.APITokenResourceUser},
+	{path: "/users", resource: models.APITokenResourceUser},
+	{path: "/tokenTest", resource: models.APITokenResourceUser},
+	{path: "/teams", resource: models.APITokenResourceTeams},
+	{path: "/notifications", resource: models.APITokenResourceNotifications},
+	{path: "/subscriptions", resource: models.APITokenResourceNotifications},
+	{path: "/stream", resource: models.APITokenResourceTasks, permission: models.APITokenPermissionRead},
+
+	{path: "/trash", resource: models.APITokenResourceTrash},
+	{path: "/tasks/:task/restore", resource: models.APITokenResourceTrash},
+	{path: "/lists/:list/restore", resource: models.APITokenResourceTrash},
+	{path: "/namespaces/:namespace/restore", resource: models.APITokenResourceTrash},
+
+	{path: "/webhooks/events", resource: models.APITokenResourceLists},
+	{path: "/lists/:list/shares", resource: models.APITokenResourceLists, permission: models.APITokenPermissionAdmin},
+	{path: "/lists/:list/webhooks", resource: models.APITokenResourceLists, permission: models.APITokenPermissionAdmin},
+	{path: "/lists/:list/teams", resource: models.APITokenResourceLists, permission: models.APITokenPermissionAdmin},
+	{path: "/lists/:list/users", resource: models.APITokenResourceLists, permission: models.APITokenPermissionAdmin},
+	{path: "/lists/:list/tasks", resource: models.APITokenResourceTasks},
+	{path: "/lists/:list/buckets", resource: models.APITokenResourceTasks},
+	{path: "/lists/:list/templates", resource: models.APITokenResourceTasks},
+	{path: "/lists/:list", method: http.MethodPut, resource: models.APITokenResourceTasks}, // Creates a task
+	{path: "/lists", resource: models.APITokenResourceLists},
+	{path: "/backgrounds", resource: models.APITokenResourceLists},
+
+	{path: "/namespaces/:namespace/webhooks", resource: models.APITokenResourceNamespaces, permission: models.APITokenPermissionAdmin},
+	{path: "/namespaces/:namespace/teams", resource: models.APITokenResourceNamespaces, permission: models.APITokenPermissionAdmin},
+	{path: "/namespaces/:namespace/users", resource: models.APITokenResourceNamespaces, permission: models.APITokenPermissionAdmin},
+	{path: "/namespaces/:namespace/lists", resource: models.APITokenResourceLists},
+	{path: "/namespaces", resource: models.APITokenResourceNamespaces},
+
+	{path: "/tasktemplates", resource: models.APITokenResourceTasks},
+	{path: "/tasks", resource: models.APITokenResourceTasks},
+	{path: "/labels", resource: models.APITokenResourceLabels},
+	{path: "/filters", resource: models.APITokenResourceFilters},
+}
+
+// getAPITokenScopeForRoute returns the resource and permission an api token needs to access a route.
+// An empty resource means the route can't be used with api tokens.
+func getAPITokenScopeForRoute(method, path string) (resource string, permission models.APITokenPermission) {
+	path = strings.TrimPrefix(path, "/api/v1")
+
+	for _, rule := range apiTokenRouteScopes {
+		if rule.method != "" && rule.method != method {
+			continue
+		}
+		if path != rule.path && !strings.HasPrefix(path, rule.path+"/") {
+			continue
+		}
+
+		if rule.resource == "" {
+			return "", ""
+		}
+
+		permission = rule.permission
+		if permission == "" {
+			permission = models.APITokenPermissionWrite
+			if method == http.MethodGet {
+				permission = models.APITokenPermissionRead
+			}
+		}
+
+		return rule.resource, permission
+	}
+
+	return "", ""
+}
+
+// checkAPITokenScopes makes sure requests authenticated with an api token only access routes the token has a scope for.
+func checkAPITokenScopes(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		token, is := c.Get("user").(*jwt.Token)
+		if !is {
+			return next(c)
+		}
+
+		scopes, isAPIToken := auth.GetAPITokenScopes(token)
+		if !isAPIToken {
+			return next(c)
+		}
+
+		resource, permission := getAPITokenScopeForRoute(c.Request().Method, c.Path())
+		if resource == "" || !scopes.Allows(resource, permission) {
+			return handler.HandleHTTPError(models.ErrAPITokenMissingScope{
+				Resource:   resource,
+				Permission: permission,
+			}, c)
+		}
+
+		return next(c)
+	}
+}
@@ -0,0 +1,52 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package routes
+
+import (
+	"code.vikunja.io/api/pkg/modules/auth"
+	"code.vikunja.io/web/handler"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+)
+
+// authenticateWithAppPassword authenticates requests which use basic auth with an app password.
+// App passwords are treated like api tokens with access to all resources, which means they are
+// also subject to the route restrictions of api tokens.
+func authenticateWithAppPassword(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		username, password, ok := c.Request().BasicAuth()
+		if !ok {
+			return next(c)
+		}
+
+		token, err := auth.NewJWTFromAppPassword(username, password)
+		if err != nil {
+			return handler.HandleHTTPError(err, c)
+		}
+
+		c.Set("user", token)
+		return next(c)
+	}
+}
+
+// isAuthenticatedWithAppPassword skips the jwt middleware if the request was already authenticated
+// with an app password.
+func isAuthenticatedWithAppPassword(c echo.Context) bool {
+	_, is := c.Get("user").(*jwt.Token)
+	return is
+}
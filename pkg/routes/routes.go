@@ -47,8 +47,6 @@
 package routes
 
 import (
-	"errors"
-	"fmt"
 	"strings"
 	"time"
 
@@ -62,6 +60,7 @@ import (
 	"code.vikunja.io/api/pkg/models"
 	"code.vikunja.io/api/pkg/modules/auth"
 	"code.vikunja.io/api/pkg/modules/auth/openid"
+	"code.vikunja.io/api/pkg/modules/auth/proxy"
 	"code.vikunja.io/api/pkg/modules/background"
 	backgroundHandler "code.vikunja.io/api/pkg/modules/background/handler"
 	"code.vikunja.io/api/pkg/modules/background/unsplash"
@@ -83,7 +82,6 @@ import (
 	"github.com/asaskevich/govalidator"
 	"github.com/getsentry/sentry-go"
 	sentryecho "github.com/getsentry/sentry-go/echo"
-	"github.com/golang-jwt/jwt/v4"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 	elog "github.com/labstack/gommon/log"
@@ -203,6 +201,9 @@ func RegisterRoutes(e *echo.Echo) {
 	// healthcheck
 	e.GET("/health", HealthcheckHandler)
 
+	// Public keys to verify jwt tokens
+	e.GET("/.well-known/jwks.json", apiv1.JWKS)
+
 	// CORS_SHIT
 	if config.CorsEnable.GetBool() {
 		e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
@@ -223,6 +224,23 @@ func RegisterRoutes(e *echo.Echo) {
 	registerAPIRoutes(a)
 }
 
+// Custom parse function to make the jwt middleware work with the github.com/golang-jwt/jwt/v4 package.
+// See https://github.com/labstack/echo/pull/1916#issuecomment-878046299
+func parseJWTToken(rawToken string, c echo.Context) (interface{}, error) {
+	if auth.IsAPIToken(rawToken) {
+		return auth.NewJWTFromAPIToken(rawToken)
+	}
+
+	token, err := auth.ParseJWT(rawToken)
+	if err != nil {
+		return nil, err
+	}
+	if err := auth.CheckUserSession(token, c.RealIP()); err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
 func registerAPIRoutes(a *echo.Group) {
 
 	// This is the group with no auth
@@ -246,9 +264,12 @@ func registerAPIRoutes(a *echo.Group) {
 	rateLimiter := createRateLimiter(rate)
 	ur.Use(RateLimit(rateLimiter, "ip"))
 
+	if config.AuthLocalEnabled.GetBool() || config.AuthLDAPEnabled.GetBool() {
+		ur.POST("/login", apiv1.Login)
+	}
+
 	if config.AuthLocalEnabled.GetBool() {
 		// User stuff
-		ur.POST("/login", apiv1.Login)
 		ur.POST("/register", apiv1.RegisterUser)
 		ur.POST("/user/password/token", apiv1.UserRequestResetPasswordToken)
 		ur.POST("/user/password/reset", apiv1.UserResetPassword)
@@ -259,6 +280,22 @@ func registerAPIRoutes(a *echo.Group) {
 		ur.POST("/auth/openid/:provider/callback", openid.HandleCallback)
 	}
 
+	if config.AuthProxyEnabled.GetBool() {
+		ur.POST("/auth/proxy", proxy.HandleLogin)
+	}
+
+	if config.ServiceEnableWebAuthn.GetBool() {
+		ur.POST("/login/webauthn/begin", apiv1.LoginWebAuthnBegin)
+		ur.POST("/login/webauthn", apiv1.LoginWebAuthn)
+	}
+
+	ur.POST("/user/token/refresh", apiv1.RefreshToken)
+
+	if config.ServiceEnableOAuth2Server.GetBool() {
+		ur.POST("/oauth2/token", apiv1.OAuth2Token)
+		ur.POST("/oauth2/introspect", apiv1.OAuth2Introspect)
+	}
+
 	// Testing
 	if config.ServiceTestingtoken.GetString() != "" {
 		n.PATCH("/test/:table", apiv1.HandleTesting)
@@ -277,27 +314,22 @@ func registerAPIRoutes(a *echo.Group) {
 
 	// ===== Routes with Authetication =====
 	// Authetification
-	a.Use(middleware.JWTWithConfig(middleware.JWTConfig{
-		// Custom parse function to make the middleware work with the github.com/golang-jwt/jwt/v4 package.
-		// See https://github.com/labstack/echo/pull/1916#issuecomment-878046299
-		ParseTokenFunc: func(auth string, c echo.Context) (interface{}, error) {
-			keyFunc := func(t *jwt.Token) (interface{}, error) {
-				if t.Method.Alg() != "HS256" {
-					return nil, fmt.Errorf("unexpected jwt signing method=%v", t.Header["alg"])
-				}
-				return []byte(config.ServiceJWTSecret.GetString()), nil
-			}
+	// The event stream is its own group because browsers can't set headers when using the EventSource api,
+	// which is why it also accepts the token as query parameter.
+	st := a.Group("/stream")
+	st.Use(middleware.JWTWithConfig(middleware.JWTConfig{
+		TokenLookup:    "header:" + echo.HeaderAuthorization + ",query:token",
+		ParseTokenFunc: parseJWTToken,
+	}))
+	st.Use(checkAPITokenScopes)
+	st.GET("", apiv1.Stream)
 
-			token, err := jwt.Parse(auth, keyFunc)
-			if err != nil {
-				return nil, err
-			}
-			if !token.Valid {
-				return nil, errors.New("invalid token")
-			}
-			return token, nil
-		},
+	a.Use(authenticateWithAppPassword)
+	a.Use(middleware.JWTWithConfig(middleware.JWTConfig{
+		Skipper:        isAuthenticatedWithAppPassword,
+		ParseTokenFunc: parseJWTToken,
 	}))
+	a.Use(checkAPITokenScopes)
 
 	// Rate limit
 	setupRateLimit(a, config.RateLimitKind.GetString())
@@ -314,22 +346,82 @@ func registerAPIRoutes(a *echo.Group) {
 	u.POST("/password", apiv1.UserChangePassword)
 	u.GET("s", apiv1.UserList)
 	u.POST("/token", apiv1.RenewToken)
+	u.GET("/sessions", apiv1.UserListSessions)
+	u.DELETE("/sessions/:session", apiv1.UserDeleteSession)
 	u.POST("/settings/email", apiv1.UpdateUserEmail)
 	u.GET("/settings/avatar", apiv1.GetUserAvatarProvider)
 	u.POST("/settings/avatar", apiv1.ChangeUserAvatarProvider)
 	u.PUT("/settings/avatar/upload", apiv1.UploadAvatar)
 	u.POST("/settings/general", apiv1.UpdateGeneralUserSettings)
+	u.GET("/settings/notifications", apiv1.UserNotificationPreferences)
+	u.POST("/settings/notifications", apiv1.UpdateUserNotificationPreferences)
 	u.POST("/export/request", apiv1.RequestUserDataExport)
 	u.POST("/export/download", apiv1.DownloadUserDataExport)
 
+	if config.NotificationChannelsEnabled.GetBool() {
+		notificationChannelHandler := &handler.WebHandler{
+			EmptyStruct: func() handler.CObject {
+				return &models.NotificationChannel{}
+			},
+		}
+		u.GET("/settings/notifications/channels", notificationChannelHandler.ReadAllWeb)
+		u.PUT("/settings/notifications/channels", notificationChannelHandler.CreateWeb)
+		u.GET("/settings/notifications/channels/:channel", notificationChannelHandler.ReadOneWeb)
+		u.POST("/settings/notifications/channels/:channel", notificationChannelHandler.UpdateWeb)
+		u.DELETE("/settings/notifications/channels/:channel", notificationChannelHandler.DeleteWeb)
+	}
+
 	if config.ServiceEnableTotp.GetBool() {
 		u.GET("/settings/totp", apiv1.UserTOTP)
 		u.POST("/settings/totp/enroll", apiv1.UserTOTPEnroll)
 		u.POST("/settings/totp/enable", apiv1.UserTOTPEnable)
 		u.POST("/settings/totp/disable", apiv1.UserTOTPDisable)
+		u.POST("/settings/totp/recovery-codes", apiv1.UserTOTPRegenerateRecoveryCodes)
 		u.GET("/settings/totp/qrcode", apiv1.UserTOTPQrCode)
 	}
 
+	if config.ServiceEnableWebAuthn.GetBool() {
+		u.GET("/settings/webauthn", apiv1.UserWebAuthnCredentials)
+		u.POST("/settings/webauthn/register/begin", apiv1.UserWebAuthnRegisterBegin)
+		u.POST("/settings/webauthn/register/finish", apiv1.UserWebAuthnRegisterFinish)
+		u.DELETE("/settings/webauthn/:credential", apiv1.UserWebAuthnDelete)
+	}
+
+	u.GET("/settings/app-passwords", apiv1.UserAppPasswords)
+	u.PUT("/settings/app-passwords", apiv1.UserAppPasswordCreate)
+	u.DELETE("/settings/app-passwords/:apppassword", apiv1.UserAppPasswordDelete)
+
+	apiTokenHandler := &handler.WebHandler{
+		EmptyStruct: func() handler.CObject {
+			return &models.APIToken{}
+		},
+	}
+	u.GET("/tokens", apiTokenHandler.ReadAllWeb)
+	u.PUT("/tokens", apiTokenHandler.CreateWeb)
+	u.DELETE("/tokens/:token", apiTokenHandler.DeleteWeb)
+
+	if config.ServiceEnableOAuth2Server.GetBool() {
+		a.GET("/oauth2/authorize", apiv1.OAuth2GetAuthorization)
+		a.POST("/oauth2/authorize", apiv1.OAuth2Authorize)
+
+		oauth2ClientHandler := &handler.WebHandler{
+			EmptyStruct: func() handler.CObject {
+				return &models.OAuth2Client{}
+			},
+		}
+		a.GET("/oauth2/clients", oauth2ClientHandler.ReadAllWeb)
+		a.PUT("/oauth2/clients", oauth2ClientHandler.CreateWeb)
+		a.DELETE("/oauth2/clients/:client", oauth2ClientHandler.DeleteWeb)
+
+		oauth2GrantHandler := &handler.WebHandler{
+			EmptyStruct: func() handler.CObject {
+				return &models.OAuth2Grant{}
+			},
+		}
+		a.GET("/oauth2/grants", oauth2GrantHandler.ReadAllWeb)
+		a.DELETE("/oauth2/grants/:grant", oauth2GrantHandler.DeleteWeb)
+	}
+
 	// User deletion
 	if config.ServiceEnableUserDeletion.GetBool() {
 		u.POST("/deletion/request", apiv1.UserRequestDeletion)
@@ -361,6 +453,59 @@ func registerAPIRoutes(a *echo.Group) {
 		a.DELETE("/lists/:list/shares/:share", listSharingHandler.DeleteWeb)
 	}
 
+	if config.WebhooksEnabled.GetBool() {
+		webhookHandler := &handler.WebHandler{
+			EmptyStruct: func() handler.CObject {
+				return &models.Webhook{}
+			},
+		}
+		a.GET("/webhooks/events", apiv1.GetAvailableWebhookEvents)
+		a.PUT("/lists/:list/webhooks", webhookHandler.CreateWeb)
+		a.GET("/lists/:list/webhooks", webhookHandler.ReadAllWeb)
+		a.GET("/lists/:list/webhooks/:webhook", webhookHandler.ReadOneWeb)
+		a.POST("/lists/:list/webhooks/:webhook", webhookHandler.UpdateWeb)
+		a.DELETE("/lists/:list/webhooks/:webhook", webhookHandler.DeleteWeb)
+		a.PUT("/namespaces/:namespace/webhooks", webhookHandler.CreateWeb)
+		a.GET("/namespaces/:namespace/webhooks", webhookHandler.ReadAllWeb)
+		a.GET("/namespaces/:namespace/webhooks/:webhook", webhookHandler.ReadOneWeb)
+		a.POST("/namespaces/:namespace/webhooks/:webhook", webhookHandler.UpdateWeb)
+		a.DELETE("/namespaces/:namespace/webhooks/:webhook", webhookHandler.DeleteWeb)
+	}
+
+	customFieldHandler := &handler.WebHandler{
+		EmptyStruct: func() handler.CObject {
+			return &models.CustomField{}
+		},
+	}
+	a.PUT("/lists/:list/customfields", customFieldHandler.CreateWeb)
+	a.GET("/lists/:list/customfields", customFieldHandler.ReadAllWeb)
+	a.GET("/lists/:list/customfields/:customfield", customFieldHandler.ReadOneWeb)
+	a.POST("/lists/:list/customfields/:customfield", customFieldHandler.UpdateWeb)
+	a.DELETE("/lists/:list/customfields/:customfield", customFieldHandler.DeleteWeb)
+	a.PUT("/namespaces/:namespace/customfields", customFieldHandler.CreateWeb)
+	a.GET("/namespaces/:namespace/customfields", customFieldHandler.ReadAllWeb)
+	a.GET("/namespaces/:namespace/customfields/:customfield", customFieldHandler.ReadOneWeb)
+	a.POST("/namespaces/:namespace/customfields/:customfield", customFieldHandler.UpdateWeb)
+	a.DELETE("/namespaces/:namespace/customfields/:customfield", customFieldHandler.DeleteWeb)
+
+	taskTemplateHandler := &handler.WebHandler{
+		EmptyStruct: func() handler.CObject {
+			return &models.TaskTemplate{}
+		},
+	}
+	a.PUT("/lists/:list/templates", taskTemplateHandler.CreateWeb)
+	a.GET("/lists/:list/templates", taskTemplateHandler.ReadAllWeb)
+	a.GET("/lists/:list/templates/:tasktemplate", taskTemplateHandler.ReadOneWeb)
+	a.POST("/lists/:list/templates/:tasktemplate", taskTemplateHandler.UpdateWeb)
+	a.DELETE("/lists/:list/templates/:tasktemplate", taskTemplateHandler.DeleteWeb)
+
+	taskTemplateInstanceHandler := &handler.WebHandler{
+		EmptyStruct: func() handler.CObject {
+			return &models.TaskTemplateInstance{}
+		},
+	}
+	a.PUT("/tasktemplates/:tasktemplate/instantiate", taskTemplateInstanceHandler.CreateWeb)
+
 	taskCollectionHandler := &handler.WebHandler{
 		EmptyStruct: func() handler.CObject {
 			return &models.TaskCollection{}
@@ -468,6 +613,38 @@ func registerAPIRoutes(a *echo.Group) {
 		a.GET("/tasks/:task/comments/:commentid", taskCommentHandler.ReadOneWeb)
 	}
 
+	taskTimeEntryHandler := &handler.WebHandler{
+		EmptyStruct: func() handler.CObject {
+			return &models.TaskTimeEntry{}
+		},
+	}
+	a.GET("/tasks/:task/time", taskTimeEntryHandler.ReadAllWeb)
+	a.PUT("/tasks/:task/time", taskTimeEntryHandler.CreateWeb)
+	a.GET("/tasks/:task/time/:timeentry", taskTimeEntryHandler.ReadOneWeb)
+	a.POST("/tasks/:task/time/:timeentry", taskTimeEntryHandler.UpdateWeb)
+	a.DELETE("/tasks/:task/time/:timeentry", taskTimeEntryHandler.DeleteWeb)
+
+	taskChangeHandler := &handler.WebHandler{
+		EmptyStruct: func() handler.CObject {
+			return &models.TaskChange{}
+		},
+	}
+	a.GET("/tasks/:task/history", taskChangeHandler.ReadAllWeb)
+
+	taskTimerStartHandler := &handler.WebHandler{
+		EmptyStruct: func() handler.CObject {
+			return &models.TaskTimerStart{}
+		},
+	}
+	a.POST("/tasks/:task/time/start", taskTimerStartHandler.CreateWeb)
+
+	taskTimerStopHandler := &handler.WebHandler{
+		EmptyStruct: func() handler.CObject {
+			return &models.TaskTimerStop{}
+		},
+	}
+	a.POST("/tasks/:task/time/stop", taskTimerStopHandler.CreateWeb)
+
 	labelHandler := &handler.WebHandler{
 		EmptyStruct: func() handler.CObject {
 			return &models.Label{}
@@ -579,6 +756,35 @@ func registerAPIRoutes(a *echo.Group) {
 	a.GET("/notifications", notificationHandler.ReadAllWeb)
 	a.POST("/notifications/:notificationid", notificationHandler.UpdateWeb)
 
+	// Trash
+	trashHandler := &handler.WebHandler{
+		EmptyStruct: func() handler.CObject {
+			return &models.TrashItem{}
+		},
+	}
+	a.GET("/trash", trashHandler.ReadAllWeb)
+
+	taskRestoreHandler := &handler.WebHandler{
+		EmptyStruct: func() handler.CObject {
+			return &models.TaskRestore{}
+		},
+	}
+	a.PUT("/tasks/:task/restore", taskRestoreHandler.CreateWeb)
+
+	listRestoreHandler := &handler.WebHandler{
+		EmptyStruct: func() handler.CObject {
+			return &models.ListRestore{}
+		},
+	}
+	a.PUT("/lists/:list/restore", listRestoreHandler.CreateWeb)
+
+	namespaceRestoreHandler := &handler.WebHandler{
+		EmptyStruct: func() handler.CObject {
+			return &models.NamespaceRestore{}
+		},
+	}
+	a.PUT("/namespaces/:namespace/restore", namespaceRestoreHandler.CreateWeb)
+
 	// Migrations
 	m := a.Group("/migration")
 	registerMigrations(m)
@@ -681,7 +887,15 @@ func caldavBasicAuth(username, password string, c echo.Context) (bool, error) {
 	}
 	s := db.NewSession()
 	defer s.Close()
-	u, err := user.CheckUserCredentials(s, creds)
+	u, err := user.CheckAppPassword(s, username, password, true)
+	if err != nil && !user.IsErrWrongUsernameOrPassword(err) {
+		_ = s.Rollback()
+		log.Errorf("Error during basic auth with an app password for caldav: %v", err)
+		return false, nil
+	}
+	if err != nil {
+		u, err = user.CheckUserCredentials(s, creds)
+	}
 	if err != nil {
 		_ = s.Rollback()
 		log.Errorf("Error during basic auth for caldav: %v", err)
@@ -0,0 +1,136 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package user
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"code.vikunja.io/api/pkg/utils"
+
+	"xorm.io/xorm"
+)
+
+// AppPassword is a password for a single device or client which can be used with basic auth instead of the
+// account password. This allows users with totp or accounts from a third party auth provider to use CalDAV clients.
+type AppPassword struct {
+	// The unique, numeric id of this app password.
+	ID     int64 `xorm:"bigint autoincr not null unique pk" json:"id" param:"apppassword"`
+	UserID int64 `xorm:"bigint not null INDEX" json:"-"`
+	// A name to recognize the device or client this password is used for.
+	Name string `xorm:"varchar(250) not null" json:"name" minLength:"1" maxLength:"250"`
+	// The actual password. It is only returned once after creating it, Vikunja only stores a hash of it.
+	Password     string `xorm:"-" json:"password,omitempty"`
+	PasswordHash string `xorm:"varchar(64) not null unique" json:"-"`
+	// If true, this password can only be used for CalDAV. Otherwise it can also be used with basic auth for the api,
+	// with the same restrictions as api tokens.
+	CaldavOnly bool `xorm:"bool not null default true" json:"caldav_only"`
+	// The last time this password was used.
+	LastUsed time.Time `xorm:"DATETIME null" json:"last_used"`
+
+	// A timestamp when this app password was created. You cannot change this value.
+	Created time.Time `xorm:"created not null" json:"created"`
+}
+
+// TableName returns the table name for app passwords
+func (*AppPassword) TableName() string {
+	return "app_passwords"
+}
+
+// CreateAppPassword creates a new app password for a user. The generated password is only available on the
+// returned struct.
+func CreateAppPassword(s *xorm.Session, u *User, appPassword *AppPassword) (err error) {
+	if appPassword.Name == "" {
+		return &ErrEmptyAppPasswordName{}
+	}
+
+	b := make([]byte, 16)
+	if _, err = rand.Read(b); err != nil {
+		return err
+	}
+
+	appPassword.ID = 0
+	appPassword.UserID = u.ID
+	appPassword.Password = hex.EncodeToString(b)
+	appPassword.PasswordHash = utils.Sha256(appPassword.Password)
+	appPassword.LastUsed = time.Time{}
+
+	_, err = s.Insert(appPassword)
+	return
+}
+
+// GetAppPasswordsForUser returns all app passwords of a user.
+func GetAppPasswordsForUser(s *xorm.Session, u *User) (appPasswords []*AppPassword, err error) {
+	appPasswords = []*AppPassword{}
+	err = s.
+		Where("user_id = ?", u.ID).
+		OrderBy("id asc").
+		Find(&appPasswords)
+	return
+}
+
+// DeleteAppPassword revokes an app password of a user.
+func DeleteAppPassword(s *xorm.Session, u *User, appPasswordID int64) (err error) {
+	deleted, err := s.
+		Where("id = ? AND user_id = ?", appPasswordID, u.ID).
+		Delete(&AppPassword{})
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return &ErrAppPasswordDoesNotExist{AppPasswordID: appPasswordID}
+	}
+	return nil
+}
+
+// CheckAppPassword checks basic auth credentials against the app passwords of a user and returns the user.
+// App passwords which are restricted to CalDAV are only accepted if caldav is true.
+func CheckAppPassword(s *xorm.Session, username, password string, caldav bool) (u *User, err error) {
+	if username == "" || password == "" {
+		return nil, ErrNoUsernamePassword{}
+	}
+
+	u, err = getUserByUsernameOrEmail(s, username)
+	if err != nil {
+		if IsErrUserDoesNotExist(err) {
+			return nil, ErrWrongUsernameOrPassword{}
+		}
+		return nil, err
+	}
+
+	appPassword := &AppPassword{}
+	exists, err := s.
+		Where("user_id = ? AND password_hash = ?", u.ID, utils.Sha256(password)).
+		Get(appPassword)
+	if err != nil {
+		return nil, err
+	}
+	if !exists || (appPassword.CaldavOnly && !caldav) {
+		return nil, ErrWrongUsernameOrPassword{}
+	}
+
+	if u.Status == StatusDisabled {
+		return nil, &ErrAccountDisabled{UserID: u.ID}
+	}
+
+	_, err = s.
+		Where("id = ?", appPassword.ID).
+		Cols("last_used").
+		Update(&AppPassword{LastUsed: time.Now()})
+	return u, err
+}
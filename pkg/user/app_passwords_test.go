@@ -0,0 +1,145 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package user
+
+import (
+	"testing"
+
+	"code.vikunja.io/api/pkg/db"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateAppPassword(t *testing.T) {
+	t.Run("normal", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		appPassword := &AppPassword{Name: "Thunderbird", CaldavOnly: true}
+		err := CreateAppPassword(s, &User{ID: 1}, appPassword)
+		assert.NoError(t, err)
+		assert.Len(t, appPassword.Password, 32)
+		assert.NotEqual(t, appPassword.Password, appPassword.PasswordHash)
+
+		u, err := CheckAppPassword(s, "user1", appPassword.Password, true)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), u.ID)
+	})
+	t.Run("without name", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		err := CreateAppPassword(s, &User{ID: 1}, &AppPassword{})
+		assert.Error(t, err)
+		assert.True(t, IsErrEmptyAppPasswordName(err))
+	})
+}
+
+func TestGetAppPasswordsForUser(t *testing.T) {
+	db.LoadAndAssertFixtures(t)
+	s := db.NewSession()
+	defer s.Close()
+
+	appPasswords, err := GetAppPasswordsForUser(s, &User{ID: 1})
+	assert.NoError(t, err)
+	assert.Len(t, appPasswords, 2)
+
+	appPasswords, err = GetAppPasswordsForUser(s, &User{ID: 2})
+	assert.NoError(t, err)
+	assert.Len(t, appPasswords, 0)
+}
+
+func TestDeleteAppPassword(t *testing.T) {
+	t.Run("normal", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		err := DeleteAppPassword(s, &User{ID: 1}, 1)
+		assert.NoError(t, err)
+		db.AssertMissing(t, "app_passwords", map[string]interface{}{
+			"id": 1,
+		})
+	})
+	t.Run("of another user", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		err := DeleteAppPassword(s, &User{ID: 2}, 1)
+		assert.Error(t, err)
+		assert.True(t, IsErrAppPasswordDoesNotExist(err))
+		db.AssertExists(t, "app_passwords", map[string]interface{}{
+			"id": 1,
+		}, false)
+	})
+}
+
+func TestCheckAppPassword(t *testing.T) {
+	t.Run("caldav", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		u, err := CheckAppPassword(s, "user1", "5f3f1b8d0a9c4e7f2b6d1a3c8e9f0b2d", true)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), u.ID)
+
+		appPassword := &AppPassword{}
+		_, err = s.Where("id = ?", 1).Get(appPassword)
+		assert.NoError(t, err)
+		assert.False(t, appPassword.LastUsed.IsZero())
+	})
+	t.Run("caldav only password for the api", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := CheckAppPassword(s, "user1", "5f3f1b8d0a9c4e7f2b6d1a3c8e9f0b2d", false)
+		assert.Error(t, err)
+		assert.True(t, IsErrWrongUsernameOrPassword(err))
+	})
+	t.Run("api", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		u, err := CheckAppPassword(s, "user1", "9c2e4a6b8d0f1e3a5c7b9d2f4a6c8e0b", false)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), u.ID)
+	})
+	t.Run("wrong password", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := CheckAppPassword(s, "user1", "12345678", true)
+		assert.Error(t, err)
+		assert.True(t, IsErrWrongUsernameOrPassword(err))
+	})
+	t.Run("password of another user", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := CheckAppPassword(s, "user2", "5f3f1b8d0a9c4e7f2b6d1a3c8e9f0b2d", true)
+		assert.Error(t, err)
+		assert.True(t, IsErrWrongUsernameOrPassword(err))
+	})
+}
@@ -36,6 +36,10 @@ func GetTables() []interface{} {
 	return []interface{}{
 		&User{},
 		&TOTP{},
+		&TOTPRecoveryCode{},
 		&Token{},
+		&Session{},
+		&WebAuthnCredential{},
+		&AppPassword{},
 	}
 }
@@ -19,7 +19,9 @@ package user
 import (
 	"fmt"
 	"net/http"
+	"strings"
 
+	"code.vikunja.io/api/pkg/i18n"
 	"code.vikunja.io/web"
 )
 
@@ -452,3 +454,472 @@ func (err *ErrAccountDisabled) HTTPError() web.HTTPError {
 		Message:  "This account is disabled. Check your emails or ask your administrator.",
 	}
 }
+
+// ErrSessionDoesNotExist represents a "SessionDoesNotExist" kind of error.
+type ErrSessionDoesNotExist struct {
+	SessionID string
+}
+
+// IsErrSessionDoesNotExist checks if an error is a ErrSessionDoesNotExist.
+func IsErrSessionDoesNotExist(err error) bool {
+	_, ok := err.(*ErrSessionDoesNotExist)
+	return ok
+}
+
+func (err *ErrSessionDoesNotExist) Error() string {
+	return fmt.Sprintf("Session does not exist [SessionID: %s]", err.SessionID)
+}
+
+// ErrCodeSessionDoesNotExist holds the unique world-error code of this error
+const ErrCodeSessionDoesNotExist = 1021
+
+// HTTPError holds the http error description
+func (err *ErrSessionDoesNotExist) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusNotFound,
+		Code:     ErrCodeSessionDoesNotExist,
+		Message:  "This session does not exist.",
+	}
+}
+
+// ErrInvalidRefreshToken represents a "InvalidRefreshToken" kind of error.
+type ErrInvalidRefreshToken struct{}
+
+// IsErrInvalidRefreshToken checks if an error is a ErrInvalidRefreshToken.
+func IsErrInvalidRefreshToken(err error) bool {
+	_, ok := err.(*ErrInvalidRefreshToken)
+	return ok
+}
+
+func (err *ErrInvalidRefreshToken) Error() string {
+	return "Refresh token is invalid or expired"
+}
+
+// ErrCodeInvalidRefreshToken holds the unique world-error code of this error
+const ErrCodeInvalidRefreshToken = 1022
+
+// HTTPError holds the http error description
+func (err *ErrInvalidRefreshToken) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusUnauthorized,
+		Code:     ErrCodeInvalidRefreshToken,
+		Message:  "The refresh token is invalid or the session has expired. Please log in again.",
+	}
+}
+
+// ErrNoLDAPEmailProvided represents a "NoLDAPEmailProvided" kind of error.
+type ErrNoLDAPEmailProvided struct{}
+
+// IsErrNoLDAPEmailProvided checks if an error is a ErrNoLDAPEmailProvided.
+func IsErrNoLDAPEmailProvided(err error) bool {
+	_, ok := err.(*ErrNoLDAPEmailProvided)
+	return ok
+}
+
+func (err *ErrNoLDAPEmailProvided) Error() string {
+	return "No email provided by ldap"
+}
+
+// ErrCodeNoLDAPEmailProvided holds the unique world-error code of this error
+const ErrCodeNoLDAPEmailProvided = 1023
+
+// HTTPError holds the http error description
+func (err *ErrNoLDAPEmailProvided) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusPreconditionFailed,
+		Code:     ErrCodeNoLDAPEmailProvided,
+		Message:  "No email address available. Please make sure your account in the LDAP directory has an email address.",
+	}
+}
+
+// ErrUntrustedProxy represents a "UntrustedProxy" kind of error.
+type ErrUntrustedProxy struct {
+	IP string
+}
+
+// IsErrUntrustedProxy checks if an error is a ErrUntrustedProxy.
+func IsErrUntrustedProxy(err error) bool {
+	_, ok := err.(*ErrUntrustedProxy)
+	return ok
+}
+
+func (err *ErrUntrustedProxy) Error() string {
+	return fmt.Sprintf("Request does not come from a trusted proxy [IP: %s]", err.IP)
+}
+
+// ErrCodeUntrustedProxy holds the unique world-error code of this error
+const ErrCodeUntrustedProxy = 1024
+
+// HTTPError holds the http error description
+func (err *ErrUntrustedProxy) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusForbidden,
+		Code:     ErrCodeUntrustedProxy,
+		Message:  "This request does not come from a trusted proxy.",
+	}
+}
+
+// ErrNoProxyUserProvided represents a "NoProxyUserProvided" kind of error.
+type ErrNoProxyUserProvided struct{}
+
+// IsErrNoProxyUserProvided checks if an error is a ErrNoProxyUserProvided.
+func IsErrNoProxyUserProvided(err error) bool {
+	_, ok := err.(*ErrNoProxyUserProvided)
+	return ok
+}
+
+func (err *ErrNoProxyUserProvided) Error() string {
+	return "No user or email provided by the proxy"
+}
+
+// ErrCodeNoProxyUserProvided holds the unique world-error code of this error
+const ErrCodeNoProxyUserProvided = 1025
+
+// HTTPError holds the http error description
+func (err *ErrNoProxyUserProvided) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusPreconditionFailed,
+		Code:     ErrCodeNoProxyUserProvided,
+		Message:  "The proxy did not provide a user and an email address.",
+	}
+}
+
+// ErrWebAuthnRequired represents a "WebAuthnRequired" kind of error.
+type ErrWebAuthnRequired struct{}
+
+// IsErrWebAuthnRequired checks if an error is a ErrWebAuthnRequired.
+func IsErrWebAuthnRequired(err error) bool {
+	_, ok := err.(*ErrWebAuthnRequired)
+	return ok
+}
+
+func (err *ErrWebAuthnRequired) Error() string {
+	return "WebAuthn is required"
+}
+
+// ErrCodeWebAuthnRequired holds the unique world-error code of this error
+const ErrCodeWebAuthnRequired = 1026
+
+// HTTPError holds the http error description
+func (err *ErrWebAuthnRequired) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusPreconditionFailed,
+		Code:     ErrCodeWebAuthnRequired,
+		Message:  "Please authenticate with one of your security keys or passkeys.",
+	}
+}
+
+// ErrInvalidWebAuthnResponse represents a "InvalidWebAuthnResponse" kind of error.
+type ErrInvalidWebAuthnResponse struct{}
+
+// IsErrInvalidWebAuthnResponse checks if an error is a ErrInvalidWebAuthnResponse.
+func IsErrInvalidWebAuthnResponse(err error) bool {
+	_, ok := err.(*ErrInvalidWebAuthnResponse)
+	return ok
+}
+
+func (err *ErrInvalidWebAuthnResponse) Error() string {
+	return "Invalid webauthn response"
+}
+
+// ErrCodeInvalidWebAuthnResponse holds the unique world-error code of this error
+const ErrCodeInvalidWebAuthnResponse = 1027
+
+// HTTPError holds the http error description
+func (err *ErrInvalidWebAuthnResponse) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusPreconditionFailed,
+		Code:     ErrCodeInvalidWebAuthnResponse,
+		Message:  "The webauthn response is invalid.",
+	}
+}
+
+// ErrInvalidWebAuthnSession represents a "InvalidWebAuthnSession" kind of error.
+type ErrInvalidWebAuthnSession struct{}
+
+// IsErrInvalidWebAuthnSession checks if an error is a ErrInvalidWebAuthnSession.
+func IsErrInvalidWebAuthnSession(err error) bool {
+	_, ok := err.(*ErrInvalidWebAuthnSession)
+	return ok
+}
+
+func (err *ErrInvalidWebAuthnSession) Error() string {
+	return "Invalid webauthn session"
+}
+
+// ErrCodeInvalidWebAuthnSession holds the unique world-error code of this error
+const ErrCodeInvalidWebAuthnSession = 1028
+
+// HTTPError holds the http error description
+func (err *ErrInvalidWebAuthnSession) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusPreconditionFailed,
+		Code:     ErrCodeInvalidWebAuthnSession,
+		Message:  "The webauthn session does not exist or has expired. Please try again.",
+	}
+}
+
+// ErrWebAuthnCredentialDoesNotExist represents a "WebAuthnCredentialDoesNotExist" kind of error.
+type ErrWebAuthnCredentialDoesNotExist struct {
+	CredentialID int64
+}
+
+// IsErrWebAuthnCredentialDoesNotExist checks if an error is a ErrWebAuthnCredentialDoesNotExist.
+func IsErrWebAuthnCredentialDoesNotExist(err error) bool {
+	_, ok := err.(*ErrWebAuthnCredentialDoesNotExist)
+	return ok
+}
+
+func (err *ErrWebAuthnCredentialDoesNotExist) Error() string {
+	return fmt.Sprintf("WebAuthn credential does not exist [CredentialID: %d]", err.CredentialID)
+}
+
+// ErrCodeWebAuthnCredentialDoesNotExist holds the unique world-error code of this error
+const ErrCodeWebAuthnCredentialDoesNotExist = 1029
+
+// HTTPError holds the http error description
+func (err *ErrWebAuthnCredentialDoesNotExist) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusNotFound,
+		Code:     ErrCodeWebAuthnCredentialDoesNotExist,
+		Message:  "This webauthn credential does not exist.",
+	}
+}
+
+// ErrEmptyWebAuthnCredentialName represents a "EmptyWebAuthnCredentialName" kind of error.
+type ErrEmptyWebAuthnCredentialName struct{}
+
+// IsErrEmptyWebAuthnCredentialName checks if an error is a ErrEmptyWebAuthnCredentialName.
+func IsErrEmptyWebAuthnCredentialName(err error) bool {
+	_, ok := err.(*ErrEmptyWebAuthnCredentialName)
+	return ok
+}
+
+func (err *ErrEmptyWebAuthnCredentialName) Error() string {
+	return "WebAuthn credential name cannot be empty"
+}
+
+// ErrCodeEmptyWebAuthnCredentialName holds the unique world-error code of this error
+const ErrCodeEmptyWebAuthnCredentialName = 1030
+
+// HTTPError holds the http error description
+func (err *ErrEmptyWebAuthnCredentialName) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusBadRequest,
+		Code:     ErrCodeEmptyWebAuthnCredentialName,
+		Message:  "Please provide a name for the webauthn credential.",
+	}
+}
+
+// ErrInvalidTOTPRecoveryCode represents a "InvalidTOTPRecoveryCode" kind of error.
+type ErrInvalidTOTPRecoveryCode struct{}
+
+// IsErrInvalidTOTPRecoveryCode checks if an error is a ErrInvalidTOTPRecoveryCode.
+func IsErrInvalidTOTPRecoveryCode(err error) bool {
+	_, ok := err.(*ErrInvalidTOTPRecoveryCode)
+	return ok
+}
+
+func (err *ErrInvalidTOTPRecoveryCode) Error() string {
+	return "Invalid totp recovery code"
+}
+
+// ErrCodeInvalidTOTPRecoveryCode holds the unique world-error code of this error
+const ErrCodeInvalidTOTPRecoveryCode = 1031
+
+// HTTPError holds the http error description
+func (err *ErrInvalidTOTPRecoveryCode) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusPreconditionFailed,
+		Code:     ErrCodeInvalidTOTPRecoveryCode,
+		Message:  "Invalid totp recovery code.",
+	}
+}
+
+// ErrEmptyAppPasswordName represents a "EmptyAppPasswordName" kind of error.
+type ErrEmptyAppPasswordName struct{}
+
+// IsErrEmptyAppPasswordName checks if an error is a ErrEmptyAppPasswordName.
+func IsErrEmptyAppPasswordName(err error) bool {
+	_, ok := err.(*ErrEmptyAppPasswordName)
+	return ok
+}
+
+func (err *ErrEmptyAppPasswordName) Error() string {
+	return "App password name cannot be empty"
+}
+
+// ErrCodeEmptyAppPasswordName holds the unique world-error code of this error
+const ErrCodeEmptyAppPasswordName = 1032
+
+// HTTPError holds the http error description
+func (err *ErrEmptyAppPasswordName) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusBadRequest,
+		Code:     ErrCodeEmptyAppPasswordName,
+		Message:  "Please provide a name for the app password.",
+	}
+}
+
+// ErrAppPasswordDoesNotExist represents a "AppPasswordDoesNotExist" kind of error.
+type ErrAppPasswordDoesNotExist struct {
+	AppPasswordID int64
+}
+
+// IsErrAppPasswordDoesNotExist checks if an error is a ErrAppPasswordDoesNotExist.
+func IsErrAppPasswordDoesNotExist(err error) bool {
+	_, ok := err.(*ErrAppPasswordDoesNotExist)
+	return ok
+}
+
+func (err *ErrAppPasswordDoesNotExist) Error() string {
+	return fmt.Sprintf("App password does not exist [AppPasswordID: %d]", err.AppPasswordID)
+}
+
+// ErrCodeAppPasswordDoesNotExist holds the unique world-error code of this error
+const ErrCodeAppPasswordDoesNotExist = 1033
+
+// HTTPError holds the http error description
+func (err *ErrAppPasswordDoesNotExist) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusNotFound,
+		Code:     ErrCodeAppPasswordDoesNotExist,
+		Message:  "This app password does not exist.",
+	}
+}
+
+// ErrInvalidDigestInterval represents a "InvalidDigestInterval" kind of error.
+type ErrInvalidDigestInterval struct {
+	Interval string
+}
+
+// IsErrInvalidDigestInterval checks if an error is a ErrInvalidDigestInterval.
+func IsErrInvalidDigestInterval(err error) bool {
+	_, ok := err.(*ErrInvalidDigestInterval)
+	return ok
+}
+
+func (err *ErrInvalidDigestInterval) Error() string {
+	return fmt.Sprintf("Invalid digest interval [Interval: %s]", err.Interval)
+}
+
+// ErrCodeInvalidDigestInterval holds the unique world-error code of this error
+const ErrCodeInvalidDigestInterval = 1034
+
+// HTTPError holds the http error description
+func (err *ErrInvalidDigestInterval) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusBadRequest,
+		Code:     ErrCodeInvalidDigestInterval,
+		Message:  "The digest interval must be empty, 'hourly', 'daily' or 'weekly'.",
+	}
+}
+
+// ErrInvalidDigestHour represents a "InvalidDigestHour" kind of error.
+type ErrInvalidDigestHour struct {
+	Hour int
+}
+
+// IsErrInvalidDigestHour checks if an error is a ErrInvalidDigestHour.
+func IsErrInvalidDigestHour(err error) bool {
+	_, ok := err.(*ErrInvalidDigestHour)
+	return ok
+}
+
+func (err *ErrInvalidDigestHour) Error() string {
+	return fmt.Sprintf("Invalid digest hour [Hour: %d]", err.Hour)
+}
+
+// ErrCodeInvalidDigestHour holds the unique world-error code of this error
+const ErrCodeInvalidDigestHour = 1035
+
+// HTTPError holds the http error description
+func (err *ErrInvalidDigestHour) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusBadRequest,
+		Code:     ErrCodeInvalidDigestHour,
+		Message:  "The digest hour must be between 0 and 23.",
+	}
+}
+
+// ErrInvalidTimezone represents a "InvalidTimezone" kind of error.
+type ErrInvalidTimezone struct {
+	Timezone string
+}
+
+// IsErrInvalidTimezone checks if an error is a ErrInvalidTimezone.
+func IsErrInvalidTimezone(err error) bool {
+	_, ok := err.(*ErrInvalidTimezone)
+	return ok
+}
+
+func (err *ErrInvalidTimezone) Error() string {
+	return fmt.Sprintf("Invalid timezone [Timezone: %s]", err.Timezone)
+}
+
+// ErrCodeInvalidTimezone holds the unique world-error code of this error
+const ErrCodeInvalidTimezone = 1036
+
+// HTTPError holds the http error description
+func (err *ErrInvalidTimezone) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusBadRequest,
+		Code:     ErrCodeInvalidTimezone,
+		Message:  fmt.Sprintf("The timezone '%s' does not exist. Please use a name from the IANA time zone database, like 'Europe/Berlin'.", err.Timezone),
+	}
+}
+
+// ErrInvalidQuietHours represents a "InvalidQuietHours" kind of error.
+type ErrInvalidQuietHours struct {
+	Start string
+	End   string
+}
+
+// IsErrInvalidQuietHours checks if an error is a ErrInvalidQuietHours.
+func IsErrInvalidQuietHours(err error) bool {
+	_, ok := err.(*ErrInvalidQuietHours)
+	return ok
+}
+
+func (err *ErrInvalidQuietHours) Error() string {
+	return fmt.Sprintf("Invalid quiet hours [Start: %s, End: %s]", err.Start, err.End)
+}
+
+// ErrCodeInvalidQuietHours holds the unique world-error code of this error
+const ErrCodeInvalidQuietHours = 1037
+
+// HTTPError holds the http error description
+func (err *ErrInvalidQuietHours) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusBadRequest,
+		Code:     ErrCodeInvalidQuietHours,
+		Message:  "Quiet hours need a start and an end in the format 'HH:MM' which are not the same.",
+	}
+}
+
+// ErrInvalidLanguage represents a "InvalidLanguage" kind of error.
+type ErrInvalidLanguage struct {
+	Language string
+}
+
+// IsErrInvalidLanguage checks if an error is a ErrInvalidLanguage.
+func IsErrInvalidLanguage(err error) bool {
+	_, ok := err.(*ErrInvalidLanguage)
+	return ok
+}
+
+func (err *ErrInvalidLanguage) Error() string {
+	return fmt.Sprintf("Invalid language [Language: %s]", err.Language)
+}
+
+// ErrCodeInvalidLanguage holds the unique world-error code of this error
+const ErrCodeInvalidLanguage = 1038
+
+// HTTPError holds the http error description
+func (err *ErrInvalidLanguage) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusBadRequest,
+		Code:     ErrCodeInvalidLanguage,
+		Message:  fmt.Sprintf("The language '%s' is not available. Available languages are: %s.", err.Language, strings.Join(i18n.GetAvailableLanguages(), ", ")),
+	}
+}
@@ -17,9 +17,8 @@
 package user
 
 import (
-	"strconv"
-
 	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/i18n"
 	"code.vikunja.io/api/pkg/notifications"
 )
 
@@ -31,25 +30,25 @@ type EmailConfirmNotification struct {
 }
 
 // ToMail returns the mail notification for EmailConfirmNotification
-func (n *EmailConfirmNotification) ToMail() *notifications.Mail {
+func (n *EmailConfirmNotification) ToMail(lang string) *notifications.Mail {
 
-	subject := n.User.GetName() + ", please confirm your email address at Vikunja"
+	subject := i18n.T(lang, "notifications.email_confirm.subject", i18n.Params{"name": n.User.GetName()})
 	if n.IsNew {
-		subject = n.User.GetName() + " + Vikunja = <3"
+		subject = i18n.T(lang, "notifications.email_confirm.subject_new", i18n.Params{"name": n.User.GetName()})
 	}
 
 	nn := notifications.NewMail().
 		Subject(subject).
-		Greeting("Hi " + n.User.GetName() + ",")
+		Greeting(i18n.T(lang, "mail.greeting", i18n.Params{"name": n.User.GetName()}))
 
 	if n.IsNew {
-		nn.Line("Welcome to Vikunja!")
+		nn.Line(i18n.T(lang, "notifications.email_confirm.welcome"))
 	}
 
 	return nn.
-		Line("To confirm your email address, click the link below:").
-		Action("Confirm your email address", config.ServiceFrontendurl.GetString()+"?userEmailConfirm="+n.ConfirmToken).
-		Line("Have a nice day!")
+		Line(i18n.T(lang, "notifications.email_confirm.message")).
+		Action(i18n.T(lang, "notifications.email_confirm.action"), config.ServiceFrontendurl.GetString()+"?userEmailConfirm="+n.ConfirmToken).
+		Line(i18n.T(lang, "mail.have_a_nice_day"))
 }
 
 // ToDB returns the EmailConfirmNotification notification in a format which can be saved in the db
@@ -68,12 +67,12 @@ type PasswordChangedNotification struct {
 }
 
 // ToMail returns the mail notification for PasswordChangedNotification
-func (n *PasswordChangedNotification) ToMail() *notifications.Mail {
+func (n *PasswordChangedNotification) ToMail(lang string) *notifications.Mail {
 	return notifications.NewMail().
-		Subject("Your Password on Vikunja was changed").
-		Greeting("Hi " + n.User.GetName() + ",").
-		Line("Your account password was successfully changed.").
-		Line("If this wasn't you, it could mean someone compromised your account. In this case contact your server's administrator.")
+		Subject(i18n.T(lang, "notifications.password_changed.subject")).
+		Greeting(i18n.T(lang, "mail.greeting", i18n.Params{"name": n.User.GetName()})).
+		Line(i18n.T(lang, "notifications.password_changed.message")).
+		Line(i18n.T(lang, "notifications.password_changed.not_you"))
 }
 
 // ToDB returns the PasswordChangedNotification notification in a format which can be saved in the db
@@ -93,14 +92,14 @@ type ResetPasswordNotification struct {
 }
 
 // ToMail returns the mail notification for ResetPasswordNotification
-func (n *ResetPasswordNotification) ToMail() *notifications.Mail {
+func (n *ResetPasswordNotification) ToMail(lang string) *notifications.Mail {
 	return notifications.NewMail().
-		Subject("Reset your password on Vikunja").
-		Greeting("Hi "+n.User.GetName()+",").
-		Line("To reset your password, click the link below:").
-		Action("Reset your password", config.ServiceFrontendurl.GetString()+"?userPasswordReset="+n.Token.Token).
-		Line("This link will be valid for 24 hours.").
-		Line("Have a nice day!")
+		Subject(i18n.T(lang, "notifications.password_reset.subject")).
+		Greeting(i18n.T(lang, "mail.greeting", i18n.Params{"name": n.User.GetName()})).
+		Line(i18n.T(lang, "notifications.password_reset.message")).
+		Action(i18n.T(lang, "notifications.password_reset.action"), config.ServiceFrontendurl.GetString()+"?userPasswordReset="+n.Token.Token).
+		Line(i18n.T(lang, "notifications.link_valid_24_hours")).
+		Line(i18n.T(lang, "mail.have_a_nice_day"))
 }
 
 // ToDB returns the ResetPasswordNotification notification in a format which can be saved in the db
@@ -119,13 +118,13 @@ type InvalidTOTPNotification struct {
 }
 
 // ToMail returns the mail notification for InvalidTOTPNotification
-func (n *InvalidTOTPNotification) ToMail() *notifications.Mail {
+func (n *InvalidTOTPNotification) ToMail(lang string) *notifications.Mail {
 	return notifications.NewMail().
-		Subject("Someone just tried to login to your Vikunja account, but failed").
-		Greeting("Hi "+n.User.GetName()+",").
-		Line("Someone just tried to log in into your account with correct username and password but a wrong TOTP passcode.").
-		Line("**If this was not you, someone else knows your password. You should set a new one immediately!**").
-		Action("Reset your password", config.ServiceFrontendurl.GetString()+"get-password-reset")
+		Subject(i18n.T(lang, "notifications.totp_invalid.subject")).
+		Greeting(i18n.T(lang, "mail.greeting", i18n.Params{"name": n.User.GetName()})).
+		Line(i18n.T(lang, "notifications.totp_invalid.message")).
+		Line(i18n.T(lang, "notifications.totp_invalid.warning")).
+		Action(i18n.T(lang, "notifications.password_reset.action"), config.ServiceFrontendurl.GetString()+"get-password-reset")
 }
 
 // ToDB returns the InvalidTOTPNotification notification in a format which can be saved in the db
@@ -144,13 +143,13 @@ type PasswordAccountLockedAfterInvalidTOTOPNotification struct {
 }
 
 // ToMail returns the mail notification for PasswordAccountLockedAfterInvalidTOTOPNotification
-func (n *PasswordAccountLockedAfterInvalidTOTOPNotification) ToMail() *notifications.Mail {
+func (n *PasswordAccountLockedAfterInvalidTOTOPNotification) ToMail(lang string) *notifications.Mail {
 	return notifications.NewMail().
-		Subject("We've disabled your account on Vikunja").
-		Greeting("Hi " + n.User.GetName() + ",").
-		Line("Someone tried to log in with your credentials but failed to provide a valid TOTP passcode.").
-		Line("After 10 failed attempts, we've disabled your account and reset your password. To set a new one, follow the instructions in the reset email we just sent you.").
-		Line("If you did not receive an email with reset instructions, you can always request a new one at [" + config.ServiceFrontendurl.GetString() + "get-password-reset](" + config.ServiceFrontendurl.GetString() + "get-password-reset).")
+		Subject(i18n.T(lang, "notifications.account_locked.subject")).
+		Greeting(i18n.T(lang, "mail.greeting", i18n.Params{"name": n.User.GetName()})).
+		Line(i18n.T(lang, "notifications.account_locked.message")).
+		Line(i18n.T(lang, "notifications.account_locked.disabled")).
+		Line(i18n.T(lang, "notifications.account_locked.reset", i18n.Params{"url": config.ServiceFrontendurl.GetString() + "get-password-reset"}))
 }
 
 // ToDB returns the PasswordAccountLockedAfterInvalidTOTOPNotification notification in a format which can be saved in the db
@@ -169,14 +168,14 @@ type FailedLoginAttemptNotification struct {
 }
 
 // ToMail returns the mail notification for FailedLoginAttemptNotification
-func (n *FailedLoginAttemptNotification) ToMail() *notifications.Mail {
+func (n *FailedLoginAttemptNotification) ToMail(lang string) *notifications.Mail {
 	return notifications.NewMail().
-		Subject("Someone just tried to login to your Vikunja account, but failed to provide a correct password").
-		Greeting("Hi "+n.User.GetName()+",").
-		Line("Someone just tried to log in into your account with a wrong password three times in a row.").
-		Line("If this was not you, this could be someone else trying to break into your account.").
-		Line("To enhance the security of you account you may want to set a stronger password or enable TOTP authentication in the settings:").
-		Action("Go to settings", config.ServiceFrontendurl.GetString()+"user/settings")
+		Subject(i18n.T(lang, "notifications.failed_login.subject")).
+		Greeting(i18n.T(lang, "mail.greeting", i18n.Params{"name": n.User.GetName()})).
+		Line(i18n.T(lang, "notifications.failed_login.message")).
+		Line(i18n.T(lang, "notifications.failed_login.not_you")).
+		Line(i18n.T(lang, "notifications.failed_login.advice")).
+		Action(i18n.T(lang, "notifications.failed_login.action"), config.ServiceFrontendurl.GetString()+"user/settings")
 }
 
 // ToDB returns the FailedLoginAttemptNotification notification in a format which can be saved in the db
@@ -196,17 +195,17 @@ type AccountDeletionConfirmNotification struct {
 }
 
 // ToMail returns the mail notification for AccountDeletionConfirmNotification
-func (n *AccountDeletionConfirmNotification) ToMail() *notifications.Mail {
+func (n *AccountDeletionConfirmNotification) ToMail(lang string) *notifications.Mail {
 	return notifications.NewMail().
-		Subject("Please confirm the deletion of your Vikunja account").
-		Greeting("Hi "+n.User.GetName()+",").
-		Line("You have requested the deletion of your account. To confirm this, please click the link below:").
-		Action("Confirm the deletion of my account", config.ServiceFrontendurl.GetString()+"?accountDeletionConfirm="+n.ConfirmToken).
-		Line("This link will be valid for 24 hours.").
-		Line("Once you confirm the deletion we will schedule the deletion of your account in three days and send you another email until then.").
-		Line("If you proceed with the deletion of your account, we will remove all of your namespaces, lists and tasks you created. Everything you shared with another user or team will transfer ownership to them.").
-		Line("If you did not requested the deletion or changed your mind, you can simply ignore this email.").
-		Line("Have a nice day!")
+		Subject(i18n.T(lang, "notifications.account_deletion_confirm.subject")).
+		Greeting(i18n.T(lang, "mail.greeting", i18n.Params{"name": n.User.GetName()})).
+		Line(i18n.T(lang, "notifications.account_deletion_confirm.message")).
+		Action(i18n.T(lang, "notifications.account_deletion_confirm.action"), config.ServiceFrontendurl.GetString()+"?accountDeletionConfirm="+n.ConfirmToken).
+		Line(i18n.T(lang, "notifications.link_valid_24_hours")).
+		Line(i18n.T(lang, "notifications.account_deletion_confirm.schedule")).
+		Line(i18n.T(lang, "notifications.account_deletion_confirm.consequences")).
+		Line(i18n.T(lang, "notifications.account_deletion_confirm.ignore")).
+		Line(i18n.T(lang, "mail.have_a_nice_day"))
 }
 
 // ToDB returns the AccountDeletionConfirmNotification notification in a format which can be saved in the db
@@ -226,21 +225,17 @@ type AccountDeletionNotification struct {
 }
 
 // ToMail returns the mail notification for AccountDeletionNotification
-func (n *AccountDeletionNotification) ToMail() *notifications.Mail {
-	durationString := "in " + strconv.Itoa(n.NotificationNumber) + " days"
-
-	if n.NotificationNumber == 1 {
-		durationString = "tomorrow"
-	}
+func (n *AccountDeletionNotification) ToMail(lang string) *notifications.Mail {
+	when := i18n.TN(lang, "notifications.account_deletion.when", int64(n.NotificationNumber))
 
 	return notifications.NewMail().
-		Subject("Your Vikunja account will be deleted "+durationString).
-		Greeting("Hi "+n.User.GetName()+",").
-		Line("You recently requested the deletion of your Vikunja account.").
-		Line("We will delete your account "+durationString+".").
-		Line("If you changed your mind, simply click the link below to cancel the deletion and follow the instructions there:").
-		Action("Abort the deletion", config.ServiceFrontendurl.GetString()).
-		Line("Have a nice day!")
+		Subject(i18n.T(lang, "notifications.account_deletion.subject", i18n.Params{"when": when})).
+		Greeting(i18n.T(lang, "mail.greeting", i18n.Params{"name": n.User.GetName()})).
+		Line(i18n.T(lang, "notifications.account_deletion.message")).
+		Line(i18n.T(lang, "notifications.account_deletion.scheduled", i18n.Params{"when": when})).
+		Line(i18n.T(lang, "notifications.account_deletion.abort_info")).
+		Action(i18n.T(lang, "notifications.account_deletion.action"), config.ServiceFrontendurl.GetString()).
+		Line(i18n.T(lang, "mail.have_a_nice_day"))
 }
 
 // ToDB returns the AccountDeletionNotification notification in a format which can be saved in the db
@@ -259,13 +254,13 @@ type AccountDeletedNotification struct {
 }
 
 // ToMail returns the mail notification for AccountDeletedNotification
-func (n *AccountDeletedNotification) ToMail() *notifications.Mail {
+func (n *AccountDeletedNotification) ToMail(lang string) *notifications.Mail {
 	return notifications.NewMail().
-		Subject("Your Vikunja Account has been deleted").
-		Greeting("Hi " + n.User.GetName() + ",").
-		Line("As requested, we've deleted your Vikunja account.").
-		Line("This deletion is permanent. If did not create a backup and need your data back now, talk to your administrator.").
-		Line("Have a nice day!")
+		Subject(i18n.T(lang, "notifications.account_deleted.subject")).
+		Greeting(i18n.T(lang, "mail.greeting", i18n.Params{"name": n.User.GetName()})).
+		Line(i18n.T(lang, "notifications.account_deleted.message")).
+		Line(i18n.T(lang, "notifications.account_deleted.permanent")).
+		Line(i18n.T(lang, "mail.have_a_nice_day"))
 }
 
 // ToDB returns the AccountDeletedNotification notification in a format which can be saved in the db
@@ -0,0 +1,218 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package user
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/cron"
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/log"
+	"code.vikunja.io/api/pkg/utils"
+	"xorm.io/xorm"
+)
+
+// Session is a login of a user on a device. Every session has a refresh token which is used to get new
+// short-lived access tokens. Deleting a session revokes all access tokens issued for it.
+type Session struct {
+	// The unique id of this session.
+	ID     string `xorm:"varchar(50) not null unique pk" json:"id" param:"session"`
+	UserID int64  `xorm:"bigint not null INDEX" json:"-"`
+	// Only a hash of the refresh token is stored.
+	RefreshTokenHash string `xorm:"varchar(45) not null unique" json:"-"`
+	// The user agent of the device which created this session.
+	DeviceInfo string `xorm:"text null" json:"device_info"`
+	// The ip address this session was last used from.
+	IPAddress string `xorm:"varchar(50) null" json:"ip_address"`
+	// The last time this session was used.
+	LastActive time.Time `xorm:"DATETIME not null" json:"last_active"`
+	// Whether this is the session of the current request.
+	IsCurrent bool `xorm:"-" json:"is_current"`
+
+	// A timestamp when this session was created.
+	Created time.Time `xorm:"created not null" json:"created"`
+}
+
+// TableName returns the table name for sessions
+func (*Session) TableName() string {
+	return "sessions"
+}
+
+// Only update the last activity of a session once per minute to not write to the db on every request.
+const sessionLastActiveInterval = time.Minute
+
+func newRefreshToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func getSessionTTL() time.Duration {
+	return time.Duration(config.ServiceJWTTTL.GetInt64()) * time.Second
+}
+
+// CreateSession creates a new session for a user and returns it together with its refresh token.
+func CreateSession(s *xorm.Session, u *User, deviceInfo, ipAddress string) (session *Session, refreshToken string, err error) {
+	refreshToken, err = newRefreshToken()
+	if err != nil {
+		return nil, "", err
+	}
+
+	session = &Session{
+		ID:               utils.MakeRandomString(40),
+		UserID:           u.ID,
+		RefreshTokenHash: utils.Sha256(refreshToken),
+		DeviceInfo:       deviceInfo,
+		IPAddress:        ipAddress,
+		LastActive:       time.Now(),
+	}
+	_, err = s.Insert(session)
+	return
+}
+
+// RefreshSession exchanges a refresh token for a new one. Every refresh token can only be used once.
+// Sessions which were not used for longer than the session lifetime can't be refreshed anymore.
+func RefreshSession(s *xorm.Session, refreshToken, ipAddress string) (session *Session, newToken string, err error) {
+	session = &Session{}
+	exists, err := s.Where("refresh_token_hash = ?", utils.Sha256(refreshToken)).Get(session)
+	if err != nil {
+		return nil, "", err
+	}
+	if !exists || session.LastActive.Add(getSessionTTL()).Before(time.Now()) {
+		return nil, "", &ErrInvalidRefreshToken{}
+	}
+
+	newToken, err = newRefreshToken()
+	if err != nil {
+		return nil, "", err
+	}
+
+	session.RefreshTokenHash = utils.Sha256(newToken)
+	session.IPAddress = ipAddress
+	session.LastActive = time.Now()
+	_, err = s.
+		Where("id = ?", session.ID).
+		Cols("refresh_token_hash", "ip_address", "last_active").
+		Update(session)
+	return
+}
+
+// CheckSession makes sure a session of a user still exists and updates its last activity.
+func CheckSession(s *xorm.Session, sessionID string, userID int64, ipAddress string) (err error) {
+	session := &Session{}
+	exists, err := s.Where("id = ? AND user_id = ?", sessionID, userID).Get(session)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return &ErrSessionDoesNotExist{SessionID: sessionID}
+	}
+
+	if time.Since(session.LastActive) < sessionLastActiveInterval && session.IPAddress == ipAddress {
+		return nil
+	}
+
+	session.IPAddress = ipAddress
+	session.LastActive = time.Now()
+	_, err = s.
+		Where("id = ?", session.ID).
+		Cols("ip_address", "last_active").
+		Update(session)
+	return
+}
+
+// GetSessionsForUser returns all sessions of a user, the most recently used first.
+func GetSessionsForUser(s *xorm.Session, u *User) (sessions []*Session, err error) {
+	sessions = []*Session{}
+	err = s.
+		Where("user_id = ?", u.ID).
+		OrderBy("last_active desc").
+		Find(&sessions)
+	return
+}
+
+// DeleteSession revokes one session of a user.
+func DeleteSession(s *xorm.Session, u *User, sessionID string) (err error) {
+	deleted, err := s.
+		Where("id = ? AND user_id = ?", sessionID, u.ID).
+		Delete(&Session{})
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return &ErrSessionDoesNotExist{SessionID: sessionID}
+	}
+	return nil
+}
+
+// SessionRevocationHook is called whenever all sessions of a user are revoked.
+type SessionRevocationHook func(s *xorm.Session, userID int64) error
+
+var sessionRevocationHooks []SessionRevocationHook
+
+// RegisterSessionRevocationHook registers a function which revokes other credentials of a user, like the ones
+// of oauth2 clients, whenever all their sessions are revoked.
+func RegisterSessionRevocationHook(hook SessionRevocationHook) {
+	sessionRevocationHooks = append(sessionRevocationHooks, hook)
+}
+
+// DeleteAllSessionsForUser revokes all sessions of a user, which logs them out everywhere.
+func DeleteAllSessionsForUser(s *xorm.Session, userID int64) (err error) {
+	_, err = s.
+		Where("user_id = ?", userID).
+		Delete(&Session{})
+	if err != nil {
+		return
+	}
+
+	for _, hook := range sessionRevocationHooks {
+		err = hook(s, userID)
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// RegisterSessionCleanupCron registers a cron function to remove all expired sessions.
+func RegisterSessionCleanupCron() {
+	const logPrefix = "[User Session Cleanup Cron] "
+
+	err := cron.Schedule("0 * * * *", func() {
+		s := db.NewSession()
+		defer s.Close()
+
+		deleted, err := s.
+			Where("last_active < ?", time.Now().Add(-getSessionTTL())).
+			Delete(&Session{})
+		if err != nil {
+			log.Errorf(logPrefix+"Error removing expired sessions: %s", err)
+			return
+		}
+		if deleted > 0 {
+			log.Debugf(logPrefix+"Deleted %d expired sessions", deleted)
+		}
+	})
+	if err != nil {
+		log.Fatalf("Could not register session cleanup cron: %s", err)
+	}
+}
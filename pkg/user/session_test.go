@@ -0,0 +1,140 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package user
+
+import (
+	"testing"
+
+	"code.vikunja.io/api/pkg/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateSession(t *testing.T) {
+	db.LoadAndAssertFixtures(t)
+	s := db.NewSession()
+	defer s.Close()
+
+	session, refreshToken, err := CreateSession(s, &User{ID: 1}, "test agent", "127.0.0.1")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, session.ID)
+	assert.NotEmpty(t, refreshToken)
+	assert.NotEqual(t, refreshToken, session.RefreshTokenHash)
+	db.AssertExists(t, "sessions", map[string]interface{}{
+		"id":          session.ID,
+		"user_id":     1,
+		"device_info": "test agent",
+	}, false)
+}
+
+func TestRefreshSession(t *testing.T) {
+	t.Run("normal", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		session, newToken, err := RefreshSession(s, "8d1e6a29a1e0c4f5a7d6b6b3f3e1f2e2c7b5a8d9f0e1a2b3c4d5e6f7a8b9c0d1", "127.0.0.1")
+		assert.NoError(t, err)
+		assert.Equal(t, "a9b8c7d6e5f4a3b2c1d0e9f8a7b6c5d4e3f2a1b0", session.ID)
+		assert.NotEmpty(t, newToken)
+	})
+	t.Run("used twice", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, _, err := RefreshSession(s, "8d1e6a29a1e0c4f5a7d6b6b3f3e1f2e2c7b5a8d9f0e1a2b3c4d5e6f7a8b9c0d1", "127.0.0.1")
+		assert.NoError(t, err)
+		_, _, err = RefreshSession(s, "8d1e6a29a1e0c4f5a7d6b6b3f3e1f2e2c7b5a8d9f0e1a2b3c4d5e6f7a8b9c0d1", "127.0.0.1")
+		assert.Error(t, err)
+		assert.True(t, IsErrInvalidRefreshToken(err))
+	})
+	t.Run("expired session", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, _, err := RefreshSession(s, "0f1e2d3c4b5a69788796a5b4c3d2e1f00f1e2d3c4b5a69788796a5b4c3d2e1f0", "127.0.0.1")
+		assert.Error(t, err)
+		assert.True(t, IsErrInvalidRefreshToken(err))
+	})
+	t.Run("nonexisting token", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, _, err := RefreshSession(s, "nonexisting", "127.0.0.1")
+		assert.Error(t, err)
+		assert.True(t, IsErrInvalidRefreshToken(err))
+	})
+}
+
+func TestCheckSession(t *testing.T) {
+	t.Run("normal", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		err := CheckSession(s, "a9b8c7d6e5f4a3b2c1d0e9f8a7b6c5d4e3f2a1b0", 1, "127.0.0.1")
+		assert.NoError(t, err)
+	})
+	t.Run("session of another user", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		err := CheckSession(s, "c0b9a8f7e6d5c4b3a2f1e0d9c8b7a6f5e4d3c2b1", 1, "127.0.0.1")
+		assert.Error(t, err)
+		assert.True(t, IsErrSessionDoesNotExist(err))
+	})
+}
+
+func TestDeleteSession(t *testing.T) {
+	t.Run("normal", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		err := DeleteSession(s, &User{ID: 1}, "a9b8c7d6e5f4a3b2c1d0e9f8a7b6c5d4e3f2a1b0")
+		assert.NoError(t, err)
+		db.AssertMissing(t, "sessions", map[string]interface{}{
+			"id": "a9b8c7d6e5f4a3b2c1d0e9f8a7b6c5d4e3f2a1b0",
+		})
+	})
+	t.Run("session of another user", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		err := DeleteSession(s, &User{ID: 1}, "c0b9a8f7e6d5c4b3a2f1e0d9c8b7a6f5e4d3c2b1")
+		assert.Error(t, err)
+		assert.True(t, IsErrSessionDoesNotExist(err))
+	})
+	t.Run("revoked on password change", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		err := UpdateUserPassword(s, &User{ID: 1}, "12345")
+		assert.NoError(t, err)
+		db.AssertMissing(t, "sessions", map[string]interface{}{
+			"user_id": 1,
+		})
+		db.AssertExists(t, "sessions", map[string]interface{}{
+			"user_id": 2,
+		}, false)
+	})
+}
@@ -0,0 +1,121 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package user
+
+import (
+	"time"
+
+	"code.vikunja.io/api/pkg/config"
+)
+
+const quietHoursFormat = "15:04"
+
+// GetTimeZone returns the time zone of the user or the one of this Vikunja instance if the user did not set one.
+func (u *User) GetTimeZone() *time.Location {
+	if u.Timezone == "" {
+		return config.GetTimeZone()
+	}
+
+	loc, err := time.LoadLocation(u.Timezone)
+	if err != nil {
+		return config.GetTimeZone()
+	}
+	return loc
+}
+
+// HasQuietHours returns whether the user set a time window they don't want to get reminders in.
+func (u *User) HasQuietHours() bool {
+	return u.QuietHoursStart != "" && u.QuietHoursEnd != ""
+}
+
+func validateTimezoneAndQuietHours(u *User) error {
+	if u.Timezone != "" {
+		if _, err := time.LoadLocation(u.Timezone); err != nil {
+			return &ErrInvalidTimezone{Timezone: u.Timezone}
+		}
+	}
+
+	if u.QuietHoursStart == "" && u.QuietHoursEnd == "" {
+		return nil
+	}
+
+	start, errStart := time.Parse(quietHoursFormat, u.QuietHoursStart)
+	end, errEnd := time.Parse(quietHoursFormat, u.QuietHoursEnd)
+	if errStart != nil || errEnd != nil || start.Equal(end) {
+		return &ErrInvalidQuietHours{Start: u.QuietHoursStart, End: u.QuietHoursEnd}
+	}
+
+	return nil
+}
+
+// getQuietHoursAround returns the quiet hours window of the user which ends after t, on the day of t in the
+// timezone of the user or the day after. Quiet hours can span midnight, like from 22:00 to 07:00.
+func (u *User) getQuietHoursAround(t time.Time) (start, end time.Time, err error) {
+	t = t.In(u.GetTimeZone())
+
+	startOfDay, err := time.Parse(quietHoursFormat, u.QuietHoursStart)
+	if err != nil {
+		return
+	}
+	endOfDay, err := time.Parse(quietHoursFormat, u.QuietHoursEnd)
+	if err != nil {
+		return
+	}
+
+	end = time.Date(t.Year(), t.Month(), t.Day(), endOfDay.Hour(), endOfDay.Minute(), 0, 0, t.Location())
+	if !end.After(t) {
+		end = end.AddDate(0, 0, 1)
+	}
+
+	start = time.Date(end.Year(), end.Month(), end.Day(), startOfDay.Hour(), startOfDay.Minute(), 0, 0, end.Location())
+	if !start.Before(end) {
+		start = start.AddDate(0, 0, -1)
+	}
+
+	return
+}
+
+// IsInQuietHours checks if a point in time is within the quiet hours of the user.
+func (u *User) IsInQuietHours(t time.Time) bool {
+	if !u.HasQuietHours() {
+		return false
+	}
+
+	start, _, err := u.getQuietHoursAround(t)
+	if err != nil {
+		return false
+	}
+
+	return !t.Before(start)
+}
+
+// GetQuietHoursEndingIn returns the quiet hours window of the user if it ends within the duration after t.
+// This is used to send everything which was held back during the quiet hours once they are over.
+func (u *User) GetQuietHoursEndingIn(t time.Time, d time.Duration) (start, end time.Time, ends bool) {
+	if !u.HasQuietHours() {
+		return
+	}
+
+	// Looking a nanosecond back to get the window which ends at t itself and not the one of the next day
+	start, end, err := u.getQuietHoursAround(t.Add(-time.Nanosecond))
+	if err != nil {
+		return
+	}
+
+	ends = !end.Before(t) && end.Before(t.Add(d))
+	return
+}
@@ -0,0 +1,97 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package user
+
+import (
+	"testing"
+	"time"
+
+	"code.vikunja.io/api/pkg/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUser_GetTimeZone(t *testing.T) {
+	u := &User{Timezone: "Europe/Berlin"}
+	assert.Equal(t, "Europe/Berlin", u.GetTimeZone().String())
+
+	u = &User{}
+	assert.Equal(t, config.GetTimeZone(), u.GetTimeZone())
+}
+
+func TestValidateTimezoneAndQuietHours(t *testing.T) {
+	assert.NoError(t, validateTimezoneAndQuietHours(&User{}))
+	assert.NoError(t, validateTimezoneAndQuietHours(&User{Timezone: "America/New_York", QuietHoursStart: "22:00", QuietHoursEnd: "07:00"}))
+
+	err := validateTimezoneAndQuietHours(&User{Timezone: "Mars/Olympus_Mons"})
+	assert.True(t, IsErrInvalidTimezone(err))
+
+	err = validateTimezoneAndQuietHours(&User{QuietHoursStart: "22:00"})
+	assert.True(t, IsErrInvalidQuietHours(err))
+
+	err = validateTimezoneAndQuietHours(&User{QuietHoursStart: "25:00", QuietHoursEnd: "07:00"})
+	assert.True(t, IsErrInvalidQuietHours(err))
+
+	err = validateTimezoneAndQuietHours(&User{QuietHoursStart: "07:00", QuietHoursEnd: "07:00"})
+	assert.True(t, IsErrInvalidQuietHours(err))
+}
+
+func TestUser_IsInQuietHours(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	assert.NoError(t, err)
+
+	t.Run("no quiet hours", func(t *testing.T) {
+		u := &User{}
+		assert.False(t, u.IsInQuietHours(time.Date(2021, 10, 1, 3, 0, 0, 0, berlin)))
+	})
+	t.Run("within a day", func(t *testing.T) {
+		u := &User{Timezone: "Europe/Berlin", QuietHoursStart: "12:00", QuietHoursEnd: "13:30"}
+		assert.False(t, u.IsInQuietHours(time.Date(2021, 10, 1, 11, 59, 0, 0, berlin)))
+		assert.True(t, u.IsInQuietHours(time.Date(2021, 10, 1, 12, 0, 0, 0, berlin)))
+		assert.True(t, u.IsInQuietHours(time.Date(2021, 10, 1, 13, 29, 0, 0, berlin)))
+		assert.False(t, u.IsInQuietHours(time.Date(2021, 10, 1, 13, 30, 0, 0, berlin)))
+	})
+	t.Run("spanning midnight", func(t *testing.T) {
+		u := &User{Timezone: "Europe/Berlin", QuietHoursStart: "22:00", QuietHoursEnd: "07:00"}
+		assert.True(t, u.IsInQuietHours(time.Date(2021, 10, 1, 23, 0, 0, 0, berlin)))
+		assert.True(t, u.IsInQuietHours(time.Date(2021, 10, 1, 3, 0, 0, 0, berlin)))
+		assert.False(t, u.IsInQuietHours(time.Date(2021, 10, 1, 7, 0, 0, 0, berlin)))
+		assert.False(t, u.IsInQuietHours(time.Date(2021, 10, 1, 15, 0, 0, 0, berlin)))
+	})
+	t.Run("in the timezone of the user", func(t *testing.T) {
+		u := &User{Timezone: "Europe/Berlin", QuietHoursStart: "22:00", QuietHoursEnd: "07:00"}
+		// 21:30 UTC is 23:30 in Berlin
+		assert.True(t, u.IsInQuietHours(time.Date(2021, 10, 1, 21, 30, 0, 0, time.UTC)))
+	})
+}
+
+func TestUser_GetQuietHoursEndingIn(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	assert.NoError(t, err)
+
+	u := &User{Timezone: "Europe/Berlin", QuietHoursStart: "22:00", QuietHoursEnd: "07:00"}
+
+	start, end, ends := u.GetQuietHoursEndingIn(time.Date(2021, 10, 2, 7, 0, 0, 0, berlin), time.Minute)
+	assert.True(t, ends)
+	assert.Equal(t, time.Date(2021, 10, 1, 22, 0, 0, 0, berlin).Unix(), start.Unix())
+	assert.Equal(t, time.Date(2021, 10, 2, 7, 0, 0, 0, berlin).Unix(), end.Unix())
+
+	_, _, ends = u.GetQuietHoursEndingIn(time.Date(2021, 10, 2, 7, 1, 0, 0, berlin), time.Minute)
+	assert.False(t, ends)
+
+	_, _, ends = u.GetQuietHoursEndingIn(time.Date(2021, 10, 2, 6, 59, 0, 0, berlin), time.Minute)
+	assert.False(t, ends)
+}
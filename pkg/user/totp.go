@@ -104,7 +104,8 @@ func EnrollTOTP(s *xorm.Session, user *User) (t *TOTP, err error) {
 }
 
 // EnableTOTP enables totp for a user. The provided passcode is used to verify the user has a working totp setup.
-func EnableTOTP(s *xorm.Session, passcode *TOTPPasscode) (err error) {
+// It returns a set of recovery codes the user can use if they lose access to their totp device.
+func EnableTOTP(s *xorm.Session, passcode *TOTPPasscode) (codes *TOTPRecoveryCodes, err error) {
 	t, err := ValidateTOTPPasscode(s, passcode)
 	if err != nil {
 		return
@@ -114,15 +115,33 @@ func EnableTOTP(s *xorm.Session, passcode *TOTPPasscode) (err error) {
 		Where("id = ?", t.ID).
 		Cols("enabled").
 		Update(&TOTP{Enabled: true})
-	return
+	if err != nil {
+		return
+	}
+
+	codes, err = GenerateTOTPRecoveryCodes(s, passcode.User)
+	if err != nil {
+		return nil, err
+	}
+
+	return codes, DeleteAllSessionsForUser(s, passcode.User.ID)
 }
 
-// DisableTOTP removes all totp settings for a user.
+// DisableTOTP removes all totp settings and recovery codes for a user.
 func DisableTOTP(s *xorm.Session, user *User) (err error) {
 	_, err = s.
 		Where("user_id = ?", user.ID).
 		Delete(&TOTP{})
-	return
+	if err != nil {
+		return
+	}
+
+	err = deleteTOTPRecoveryCodes(s, user)
+	if err != nil {
+		return
+	}
+
+	return DeleteAllSessionsForUser(s, user.ID)
 }
 
 // ValidateTOTPPasscode validated totp codes of users.
@@ -0,0 +1,146 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package user
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"xorm.io/xorm"
+)
+
+// TOTPRecoveryCode is a one-time code which can be used instead of a totp passcode, for example when a user lost
+// their phone. Only a bcrypt hash of the code is stored, the same way as passwords.
+type TOTPRecoveryCode struct {
+	ID       int64  `xorm:"bigint autoincr not null unique pk" json:"-"`
+	UserID   int64  `xorm:"bigint not null INDEX" json:"-"`
+	CodeHash string `xorm:"varchar(64) not null" json:"-"`
+
+	Created time.Time `xorm:"created not null" json:"-"`
+}
+
+// TableName holds the table name for totp recovery codes
+func (*TOTPRecoveryCode) TableName() string {
+	return "totp_recovery_codes"
+}
+
+// TOTPRecoveryCodes holds newly generated recovery codes. They are only shown once.
+type TOTPRecoveryCodes struct {
+	Codes []string `json:"codes"`
+}
+
+const (
+	totpRecoveryCodeCount = 10
+	// 80 bits of randomness per code
+	totpRecoveryCodeBytes = 10
+)
+
+// newTOTPRecoveryCode returns a random code formatted as four dash-separated groups like 1a2b3-c4d5e-6f7a8-b9c0d.
+func newTOTPRecoveryCode() (string, error) {
+	b := make([]byte, totpRecoveryCodeBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	code := hex.EncodeToString(b)
+	groups := make([]string, 0, len(code)/5)
+	for i := 0; i < len(code); i += 5 {
+		groups = append(groups, code[i:i+5])
+	}
+	return strings.Join(groups, "-"), nil
+}
+
+// Users might type the code with different case or without the dashes.
+func normalizeTOTPRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	code = strings.ReplaceAll(code, "-", "")
+	return strings.ReplaceAll(code, " ", "")
+}
+
+func hashTOTPRecoveryCode(code string) (string, error) {
+	return HashPassword(normalizeTOTPRecoveryCode(code))
+}
+
+// GenerateTOTPRecoveryCodes creates a new set of recovery codes for a user. All previous codes become invalid.
+func GenerateTOTPRecoveryCodes(s *xorm.Session, user *User) (codes *TOTPRecoveryCodes, err error) {
+	_, err = s.
+		Where("user_id = ?", user.ID).
+		Delete(&TOTPRecoveryCode{})
+	if err != nil {
+		return nil, err
+	}
+
+	codes = &TOTPRecoveryCodes{Codes: make([]string, 0, totpRecoveryCodeCount)}
+	recoveryCodes := make([]*TOTPRecoveryCode, 0, totpRecoveryCodeCount)
+	for i := 0; i < totpRecoveryCodeCount; i++ {
+		code, err := newTOTPRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		hash, err := hashTOTPRecoveryCode(code)
+		if err != nil {
+			return nil, err
+		}
+		codes.Codes = append(codes.Codes, code)
+		recoveryCodes = append(recoveryCodes, &TOTPRecoveryCode{
+			UserID:   user.ID,
+			CodeHash: hash,
+		})
+	}
+
+	_, err = s.Insert(&recoveryCodes)
+	return
+}
+
+// UseTOTPRecoveryCode checks if a recovery code is valid for a user and invalidates it.
+func UseTOTPRecoveryCode(s *xorm.Session, user *User, code string) (err error) {
+	recoveryCodes := []*TOTPRecoveryCode{}
+	err = s.
+		Where("user_id = ?", user.ID).
+		Find(&recoveryCodes)
+	if err != nil {
+		return err
+	}
+
+	code = normalizeTOTPRecoveryCode(code)
+	for _, rc := range recoveryCodes {
+		err = bcrypt.CompareHashAndPassword([]byte(rc.CodeHash), []byte(code))
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		_, err = s.
+			Where("id = ?", rc.ID).
+			Delete(&TOTPRecoveryCode{})
+		return err
+	}
+
+	return &ErrInvalidTOTPRecoveryCode{}
+}
+
+func deleteTOTPRecoveryCodes(s *xorm.Session, user *User) (err error) {
+	_, err = s.
+		Where("user_id = ?", user.ID).
+		Delete(&TOTPRecoveryCode{})
+	return
+}
@@ -0,0 +1,128 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package user
+
+import (
+	"strings"
+	"testing"
+
+	"code.vikunja.io/api/pkg/db"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestGenerateTOTPRecoveryCodes(t *testing.T) {
+	db.LoadAndAssertFixtures(t)
+	s := db.NewSession()
+	defer s.Close()
+
+	codes, err := GenerateTOTPRecoveryCodes(s, &User{ID: 1})
+	assert.NoError(t, err)
+	assert.Len(t, codes.Codes, totpRecoveryCodeCount)
+
+	count, err := s.Where("user_id = ?", 1).Count(&TOTPRecoveryCode{})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(totpRecoveryCodeCount), count)
+	assert.Len(t, strings.ReplaceAll(codes.Codes[0], "-", ""), totpRecoveryCodeBytes*2)
+
+	stored := []*TOTPRecoveryCode{}
+	err = s.Where("user_id = ?", 1).OrderBy("id asc").Find(&stored)
+	assert.NoError(t, err)
+	assert.Len(t, stored, totpRecoveryCodeCount)
+	err = bcrypt.CompareHashAndPassword([]byte(stored[0].CodeHash), []byte(normalizeTOTPRecoveryCode(codes.Codes[0])))
+	assert.NoError(t, err)
+	err = bcrypt.CompareHashAndPassword([]byte(stored[0].CodeHash), []byte(normalizeTOTPRecoveryCode(codes.Codes[1])))
+	assert.ErrorIs(t, err, bcrypt.ErrMismatchedHashAndPassword)
+}
+
+func TestUseTOTPRecoveryCode(t *testing.T) {
+	t.Run("normal", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		codes, err := GenerateTOTPRecoveryCodes(s, &User{ID: 1})
+		assert.NoError(t, err)
+
+		err = UseTOTPRecoveryCode(s, &User{ID: 1}, codes.Codes[0])
+		assert.NoError(t, err)
+	})
+	t.Run("different formatting", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		codes, err := GenerateTOTPRecoveryCodes(s, &User{ID: 1})
+		assert.NoError(t, err)
+
+		code := strings.ToUpper(strings.ReplaceAll(codes.Codes[0], "-", ""))
+		err = UseTOTPRecoveryCode(s, &User{ID: 1}, code)
+		assert.NoError(t, err)
+	})
+	t.Run("wrong code", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := GenerateTOTPRecoveryCodes(s, &User{ID: 1})
+		assert.NoError(t, err)
+
+		err = UseTOTPRecoveryCode(s, &User{ID: 1}, "00000-00000-00000-00000")
+		assert.Error(t, err)
+		assert.True(t, IsErrInvalidTOTPRecoveryCode(err))
+	})
+	t.Run("used twice", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		codes, err := GenerateTOTPRecoveryCodes(s, &User{ID: 1})
+		assert.NoError(t, err)
+
+		err = UseTOTPRecoveryCode(s, &User{ID: 1}, codes.Codes[0])
+		assert.NoError(t, err)
+		err = UseTOTPRecoveryCode(s, &User{ID: 1}, codes.Codes[0])
+		assert.Error(t, err)
+		assert.True(t, IsErrInvalidTOTPRecoveryCode(err))
+	})
+	t.Run("code of another user", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		codes, err := GenerateTOTPRecoveryCodes(s, &User{ID: 1})
+		assert.NoError(t, err)
+
+		err = UseTOTPRecoveryCode(s, &User{ID: 2}, codes.Codes[0])
+		assert.Error(t, err)
+		assert.True(t, IsErrInvalidTOTPRecoveryCode(err))
+	})
+	t.Run("regenerated codes invalidate old ones", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		codes, err := GenerateTOTPRecoveryCodes(s, &User{ID: 1})
+		assert.NoError(t, err)
+		_, err = GenerateTOTPRecoveryCodes(s, &User{ID: 1})
+		assert.NoError(t, err)
+
+		err = UseTOTPRecoveryCode(s, &User{ID: 1}, codes.Codes[0])
+		assert.Error(t, err)
+		assert.True(t, IsErrInvalidTOTPRecoveryCode(err))
+	})
+}
@@ -25,6 +25,7 @@ import (
 
 	"code.vikunja.io/api/pkg/config"
 	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/i18n"
 	"code.vikunja.io/api/pkg/log"
 	"code.vikunja.io/api/pkg/modules/keyvalue"
 	"code.vikunja.io/api/pkg/notifications"
@@ -44,6 +45,11 @@ type Login struct {
 	Password string `json:"password"`
 	// The totp passcode of a user. Only needs to be provided when enabled.
 	TOTPPasscode string `json:"totp_passcode"`
+	// A totp recovery code. Can be used instead of the totp passcode, every code can only be used once.
+	TOTPRecoveryCode string `json:"totp_recovery_code"`
+	// The response of a webauthn authenticator. Can be provided instead of a totp passcode if the user has
+	// webauthn credentials.
+	WebAuthn *WebAuthnLogin `json:"webauthn"`
 }
 
 type Status int
@@ -67,6 +73,13 @@ const (
 	StatusDisabled
 )
 
+// These are all intervals a user can get their notifications digest in
+const (
+	DigestIntervalHourly = "hourly"
+	DigestIntervalDaily  = "daily"
+	DigestIntervalWeekly = "weekly"
+)
+
 // User holds information about an user
 type User struct {
 	// The unique, numeric id of this user.
@@ -95,6 +108,15 @@ type User struct {
 	DefaultListID                int64 `xorm:"bigint null index" json:"-"`
 	WeekStart                    int   `xorm:"null" json:"-"`
 
+	DigestInterval string `xorm:"varchar(10) null" json:"-"`
+	DigestHour     int    `xorm:"null" json:"-"`
+
+	Timezone        string `xorm:"varchar(255) null" json:"-"`
+	QuietHoursStart string `xorm:"varchar(5) null" json:"-"`
+	QuietHoursEnd   string `xorm:"varchar(5) null" json:"-"`
+
+	Language string `xorm:"varchar(50) null" json:"-"`
+
 	DeletionScheduledAt      time.Time `xorm:"datetime null" json:"-"`
 	DeletionLastReminderSent time.Time `xorm:"datetime null" json:"-"`
 
@@ -129,6 +151,16 @@ func (u *User) RouteForDB() int64 {
 	return u.ID
 }
 
+// WantsDigest returns whether the user gets their mail notifications as a digest
+func (u *User) WantsDigest() bool {
+	return u.DigestInterval != ""
+}
+
+// GetLanguage returns the language the user wants their notifications in
+func (u *User) GetLanguage() string {
+	return u.Language
+}
+
 // GetID implements the Auth interface
 func (u *User) GetID() int64 {
 	return u.ID
@@ -405,6 +437,34 @@ func GetUserFromClaims(claims jwt.MapClaims) (user *User, err error) {
 	return
 }
 
+// UpdateUserEmailAndName only changes the email address and name of a user, for example when they changed in the
+// directory or identity provider the user logs in with. All other settings of the user are kept.
+func UpdateUserEmailAndName(s *xorm.Session, u *User, email, name string) (err error) {
+	if email != "" && email != u.Email {
+		uu, err := getUser(s, &User{
+			Email:   email,
+			Issuer:  u.Issuer,
+			Subject: u.Subject,
+		}, true)
+		if err != nil && !IsErrUserDoesNotExist(err) {
+			return err
+		}
+		if uu.ID != 0 && uu.ID != u.ID {
+			return &ErrUserEmailExists{Email: email, UserID: uu.ID}
+		}
+		u.Email = email
+	}
+	if name != "" {
+		u.Name = name
+	}
+
+	_, err = s.
+		ID(u.ID).
+		Cols("email", "name").
+		Update(u)
+	return
+}
+
 // UpdateUser updates a user
 func UpdateUser(s *xorm.Session, user *User) (updatedUser *User, err error) {
 
@@ -461,6 +521,26 @@ func UpdateUser(s *xorm.Session, user *User) (updatedUser *User, err error) {
 		}
 	}
 
+	if user.DigestInterval != "" &&
+		user.DigestInterval != DigestIntervalHourly &&
+		user.DigestInterval != DigestIntervalDaily &&
+		user.DigestInterval != DigestIntervalWeekly {
+		return updatedUser, &ErrInvalidDigestInterval{Interval: user.DigestInterval}
+	}
+
+	if user.DigestHour < 0 || user.DigestHour > 23 {
+		return updatedUser, &ErrInvalidDigestHour{Hour: user.DigestHour}
+	}
+
+	err = validateTimezoneAndQuietHours(user)
+	if err != nil {
+		return updatedUser, err
+	}
+
+	if user.Language != "" && !i18n.HasLanguage(user.Language) {
+		return updatedUser, &ErrInvalidLanguage{Language: user.Language}
+	}
+
 	// Update it
 	_, err = s.
 		ID(user.ID).
@@ -477,6 +557,12 @@ func UpdateUser(s *xorm.Session, user *User) (updatedUser *User, err error) {
 			"overdue_tasks_reminders_enabled",
 			"default_list_id",
 			"week_start",
+			"digest_interval",
+			"digest_hour",
+			"timezone",
+			"quiet_hours_start",
+			"quiet_hours_end",
+			"language",
 		).
 		Update(user)
 	if err != nil {
@@ -518,7 +604,8 @@ func UpdateUserPassword(s *xorm.Session, user *User, newPassword string) (err er
 		return err
 	}
 
-	return err
+	// Log the user out everywhere in case the old password was compromised
+	return DeleteAllSessionsForUser(s, user.ID)
 }
 
 // SetStatus sets a users status in the database
@@ -528,5 +615,12 @@ func (u *User) SetStatus(s *xorm.Session, status Status) (err error) {
 		Where("id = ?", u.ID).
 		Cols("status").
 		Update(u)
+	if err != nil {
+		return
+	}
+
+	if status == StatusDisabled {
+		return DeleteAllSessionsForUser(s, u.ID)
+	}
 	return
 }
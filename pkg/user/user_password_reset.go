@@ -76,6 +76,11 @@ func ResetPassword(s *xorm.Session, reset *PasswordReset) (err error) {
 		return
 	}
 
+	err = DeleteAllSessionsForUser(s, user.ID)
+	if err != nil {
+		return
+	}
+
 	// Dont send a mail if no mailer is configured
 	if !config.MailerEnabled.GetBool() {
 		return
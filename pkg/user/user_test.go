@@ -319,6 +319,30 @@ func TestUpdateUser(t *testing.T) {
 		assert.Error(t, err)
 		assert.True(t, IsErrUserDoesNotExist(err))
 	})
+	t.Run("language", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		uuser, err := UpdateUser(s, &User{
+			ID:       1,
+			Language: "de",
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "de", uuser.Language)
+	})
+	t.Run("invalid language", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := UpdateUser(s, &User{
+			ID:       1,
+			Language: "xx",
+		})
+		assert.Error(t, err)
+		assert.True(t, IsErrInvalidLanguage(err))
+	})
 }
 
 func TestUpdateUserPassword(t *testing.T) {
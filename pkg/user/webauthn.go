@@ -0,0 +1,469 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package user
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/log"
+	"code.vikunja.io/api/pkg/modules/keyvalue"
+	"code.vikunja.io/api/pkg/utils"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"xorm.io/xorm"
+)
+
+// WebAuthnCredential is a webauthn credential of a user, like a hardware security key or a passkey.
+type WebAuthnCredential struct {
+	// The unique, numeric id of this credential.
+	ID     int64 `xorm:"bigint autoincr not null unique pk" json:"id" param:"credential"`
+	UserID int64 `xorm:"bigint not null INDEX" json:"-"`
+	// A name the user gave this credential to recognize it later.
+	Name string `xorm:"varchar(250) not null" json:"name" minLength:"1" maxLength:"250"`
+
+	// The credential id as sent by the authenticator, base64url encoded.
+	CredentialID    string   `xorm:"varchar(250) not null unique" json:"-"`
+	PublicKey       []byte   `xorm:"blob not null" json:"-"`
+	AttestationType string   `xorm:"varchar(50) null" json:"-"`
+	Transports      []string `xorm:"JSON null" json:"-"`
+	AAGUID          []byte   `xorm:"blob null" json:"-"`
+	// The signature counter of the authenticator. Used to detect cloned authenticators.
+	SignCount int64 `xorm:"bigint not null default 0" json:"-"`
+
+	// The last time this credential was used to log in.
+	LastUsed time.Time `xorm:"DATETIME null" json:"last_used"`
+	// A timestamp when this credential was created. You cannot change this value.
+	Created time.Time `xorm:"created not null" json:"created"`
+}
+
+// TableName returns the table name for webauthn credentials
+func (*WebAuthnCredential) TableName() string {
+	return "webauthn_credentials"
+}
+
+func (c *WebAuthnCredential) toWebAuthn() (webauthn.Credential, error) {
+	id, err := base64.RawURLEncoding.DecodeString(c.CredentialID)
+	if err != nil {
+		return webauthn.Credential{}, err
+	}
+
+	transports := make([]protocol.AuthenticatorTransport, 0, len(c.Transports))
+	for _, t := range c.Transports {
+		transports = append(transports, protocol.AuthenticatorTransport(t))
+	}
+
+	return webauthn.Credential{
+		ID:              id,
+		PublicKey:       c.PublicKey,
+		AttestationType: c.AttestationType,
+		Transport:       transports,
+		Authenticator: webauthn.Authenticator{
+			AAGUID:    c.AAGUID,
+			SignCount: uint32(c.SignCount),
+		},
+	}, nil
+}
+
+// WebAuthnCeremony is returned when starting a webauthn registration or login. The options need to be passed
+// to the browser's webauthn api, its response needs to be sent back together with the session id.
+type WebAuthnCeremony struct {
+	// The id of this ceremony. Needs to be sent back with the response of the authenticator.
+	SessionID string `json:"session_id"`
+	// The options to pass to navigator.credentials.create() or navigator.credentials.get().
+	Options interface{} `json:"options"`
+}
+
+// WebAuthnRegistration holds the response of an authenticator to finish a registration.
+type WebAuthnRegistration struct {
+	// The session id returned when starting the registration.
+	SessionID string `json:"session_id"`
+	// The name of the new credential.
+	Name string `json:"name"`
+	// The response of navigator.credentials.create().
+	Response json.RawMessage `json:"response" swaggertype:"object"`
+}
+
+// WebAuthnLogin holds the response of an authenticator to finish a login.
+type WebAuthnLogin struct {
+	// The session id returned when starting the login.
+	SessionID string `json:"session_id"`
+	// The response of navigator.credentials.get().
+	Response json.RawMessage `json:"response" swaggertype:"object"`
+}
+
+// webAuthnCeremonySession is what we remember between starting and finishing a ceremony.
+type webAuthnCeremonySession struct {
+	UserID       int64
+	Registration bool
+	// Passwordless logins require the authenticator to verify the user.
+	Passwordless bool
+	Expires      time.Time
+	Data         webauthn.SessionData
+}
+
+// Ceremonies which are not finished within this time need to be started again.
+const webAuthnCeremonyTimeout = 5 * time.Minute
+
+// webAuthnUser implements webauthn.User for a Vikunja user.
+type webAuthnUser struct {
+	user        *User
+	credentials []*WebAuthnCredential
+}
+
+func (u *webAuthnUser) WebAuthnID() []byte {
+	return []byte(strconv.FormatInt(u.user.ID, 10))
+}
+
+func (u *webAuthnUser) WebAuthnName() string {
+	return u.user.Username
+}
+
+func (u *webAuthnUser) WebAuthnDisplayName() string {
+	return u.user.GetName()
+}
+
+func (u *webAuthnUser) WebAuthnIcon() string {
+	return ""
+}
+
+func (u *webAuthnUser) WebAuthnCredentials() []webauthn.Credential {
+	creds := make([]webauthn.Credential, 0, len(u.credentials))
+	for _, c := range u.credentials {
+		cred, err := c.toWebAuthn()
+		if err != nil {
+			log.Errorf("Could not decode webauthn credential %d: %s", c.ID, err)
+			continue
+		}
+		creds = append(creds, cred)
+	}
+	return creds
+}
+
+func getWebAuthnUser(s *xorm.Session, u *User) (wu *webAuthnUser, err error) {
+	credentials, err := GetWebAuthnCredentialsForUser(s, u)
+	if err != nil {
+		return nil, err
+	}
+	return &webAuthnUser{user: u, credentials: credentials}, nil
+}
+
+// getWebAuthn configures the relying party from the frontend url because that's where the browser runs the ceremonies.
+func getWebAuthn() (*webauthn.WebAuthn, error) {
+	frontendURL, err := url.Parse(config.ServiceFrontendurl.GetString())
+	if err != nil {
+		return nil, err
+	}
+	if frontendURL.Hostname() == "" {
+		return nil, fmt.Errorf("webauthn needs service.frontendurl to be configured")
+	}
+
+	return webauthn.New(&webauthn.Config{
+		RPID:          frontendURL.Hostname(),
+		RPDisplayName: "Vikunja",
+		RPOrigin:      frontendURL.Scheme + "://" + frontendURL.Host,
+	})
+}
+
+func getWebAuthnCeremonySessionKey(sessionID string) string {
+	return "webauthn_session_" + sessionID
+}
+
+func startWebAuthnCeremony(userID int64, registration, passwordless bool, options interface{}, data *webauthn.SessionData) (ceremony *WebAuthnCeremony, err error) {
+	sessionID := utils.MakeRandomString(40)
+	err = keyvalue.Put(getWebAuthnCeremonySessionKey(sessionID), &webAuthnCeremonySession{
+		UserID:       userID,
+		Registration: registration,
+		Passwordless: passwordless,
+		Expires:      time.Now().Add(webAuthnCeremonyTimeout),
+		Data:         *data,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &WebAuthnCeremony{
+		SessionID: sessionID,
+		Options:   options,
+	}, nil
+}
+
+// finishWebAuthnCeremony returns the remembered ceremony session. Every session can only be used once.
+// The caller needs to check the session belongs to the user it expects.
+func finishWebAuthnCeremony(sessionID string, registration, passwordless bool) (session *webAuthnCeremonySession, err error) {
+	if sessionID == "" {
+		return nil, &ErrInvalidWebAuthnSession{}
+	}
+
+	key := getWebAuthnCeremonySessionKey(sessionID)
+	session = &webAuthnCeremonySession{}
+	exists, err := keyvalue.GetWithValue(key, session)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, &ErrInvalidWebAuthnSession{}
+	}
+
+	err = keyvalue.Del(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if session.Registration != registration ||
+		session.Passwordless != passwordless ||
+		session.Expires.Before(time.Now()) {
+		return nil, &ErrInvalidWebAuthnSession{}
+	}
+
+	return session, nil
+}
+
+// WebAuthnEnabledForUser checks if a user has at least one webauthn credential.
+func WebAuthnEnabledForUser(s *xorm.Session, u *User) (bool, error) {
+	if !config.ServiceEnableWebAuthn.GetBool() {
+		return false, nil
+	}
+	return s.Where("user_id = ?", u.ID).Exist(&WebAuthnCredential{})
+}
+
+// GetWebAuthnCredentialsForUser returns all webauthn credentials of a user.
+func GetWebAuthnCredentialsForUser(s *xorm.Session, u *User) (credentials []*WebAuthnCredential, err error) {
+	credentials = []*WebAuthnCredential{}
+	err = s.
+		Where("user_id = ?", u.ID).
+		OrderBy("id asc").
+		Find(&credentials)
+	return
+}
+
+// BeginWebAuthnRegistration starts the registration of a new webauthn credential for a user.
+func BeginWebAuthnRegistration(s *xorm.Session, u *User) (ceremony *WebAuthnCeremony, err error) {
+	w, err := getWebAuthn()
+	if err != nil {
+		return nil, err
+	}
+
+	wu, err := getWebAuthnUser(s, u)
+	if err != nil {
+		return nil, err
+	}
+
+	// Prevent registering the same authenticator twice
+	exclusions := []protocol.CredentialDescriptor{}
+	for _, c := range wu.WebAuthnCredentials() {
+		exclusions = append(exclusions, protocol.CredentialDescriptor{
+			Type:         protocol.PublicKeyCredentialType,
+			CredentialID: c.ID,
+			Transport:    c.Transport,
+		})
+	}
+
+	options, data, err := w.BeginRegistration(wu, webauthn.WithExclusions(exclusions))
+	if err != nil {
+		return nil, err
+	}
+
+	return startWebAuthnCeremony(u.ID, true, false, options, data)
+}
+
+// FinishWebAuthnRegistration verifies the response of the authenticator and saves the new credential.
+func FinishWebAuthnRegistration(s *xorm.Session, u *User, registration *WebAuthnRegistration) (credential *WebAuthnCredential, err error) {
+	if registration.Name == "" {
+		return nil, &ErrEmptyWebAuthnCredentialName{}
+	}
+
+	session, err := finishWebAuthnCeremony(registration.SessionID, true, false)
+	if err != nil {
+		return nil, err
+	}
+	if session.UserID != u.ID {
+		return nil, &ErrInvalidWebAuthnSession{}
+	}
+
+	w, err := getWebAuthn()
+	if err != nil {
+		return nil, err
+	}
+
+	wu, err := getWebAuthnUser(s, u)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed, err := protocol.ParseCredentialCreationResponseBody(bytes.NewReader(registration.Response))
+	if err != nil {
+		log.Debugf("Could not parse webauthn registration response for user %d: %s", u.ID, err)
+		return nil, &ErrInvalidWebAuthnResponse{}
+	}
+
+	cred, err := w.CreateCredential(wu, session.Data, parsed)
+	if err != nil {
+		log.Debugf("Could not verify webauthn registration for user %d: %s", u.ID, err)
+		return nil, &ErrInvalidWebAuthnResponse{}
+	}
+
+	transports := make([]string, 0, len(cred.Transport))
+	for _, t := range cred.Transport {
+		transports = append(transports, string(t))
+	}
+
+	credential = &WebAuthnCredential{
+		UserID:          u.ID,
+		Name:            registration.Name,
+		CredentialID:    base64.RawURLEncoding.EncodeToString(cred.ID),
+		PublicKey:       cred.PublicKey,
+		AttestationType: cred.AttestationType,
+		Transports:      transports,
+		AAGUID:          cred.Authenticator.AAGUID,
+		SignCount:       int64(cred.Authenticator.SignCount),
+	}
+	_, err = s.Insert(credential)
+	return
+}
+
+// DeleteWebAuthnCredential removes a webauthn credential of a user.
+func DeleteWebAuthnCredential(s *xorm.Session, u *User, credentialID int64) (err error) {
+	deleted, err := s.
+		Where("id = ? AND user_id = ?", credentialID, u.ID).
+		Delete(&WebAuthnCredential{})
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return &ErrWebAuthnCredentialDoesNotExist{CredentialID: credentialID}
+	}
+	return nil
+}
+
+// BeginWebAuthnLogin starts a webauthn login for a user. Only their credentials are allowed. A passwordless
+// login requires the authenticator to verify the user, for example with a pin or biometrics, otherwise the
+// login can only be used as a second factor.
+func BeginWebAuthnLogin(s *xorm.Session, u *User, passwordless bool) (ceremony *WebAuthnCeremony, err error) {
+	w, err := getWebAuthn()
+	if err != nil {
+		return nil, err
+	}
+
+	wu, err := getWebAuthnUser(s, u)
+	if err != nil {
+		return nil, err
+	}
+	if len(wu.credentials) == 0 {
+		return nil, &ErrWebAuthnCredentialDoesNotExist{}
+	}
+
+	opts := []webauthn.LoginOption{}
+	if passwordless {
+		opts = append(opts, webauthn.WithUserVerification(protocol.VerificationRequired))
+	}
+
+	options, data, err := w.BeginLogin(wu, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return startWebAuthnCeremony(u.ID, false, passwordless, options, data)
+}
+
+func validateWebAuthnLogin(s *xorm.Session, u *User, session *webAuthnCeremonySession, login *WebAuthnLogin) (err error) {
+	w, err := getWebAuthn()
+	if err != nil {
+		return err
+	}
+
+	wu, err := getWebAuthnUser(s, u)
+	if err != nil {
+		return err
+	}
+
+	parsed, err := protocol.ParseCredentialRequestResponseBody(bytes.NewReader(login.Response))
+	if err != nil {
+		log.Debugf("Could not parse webauthn login response for user %d: %s", u.ID, err)
+		return &ErrInvalidWebAuthnResponse{}
+	}
+
+	cred, err := w.ValidateLogin(wu, session.Data, parsed)
+	if err != nil {
+		log.Debugf("Could not verify webauthn login for user %d: %s", u.ID, err)
+		return &ErrInvalidWebAuthnResponse{}
+	}
+
+	return updateWebAuthnCredentialAfterLogin(s, u, cred)
+}
+
+// FinishWebAuthnLogin verifies the response of an authenticator as second factor for a user.
+func FinishWebAuthnLogin(s *xorm.Session, u *User, login *WebAuthnLogin) (err error) {
+	session, err := finishWebAuthnCeremony(login.SessionID, false, false)
+	if err != nil {
+		return err
+	}
+	if session.UserID != u.ID {
+		return &ErrInvalidWebAuthnSession{}
+	}
+
+	return validateWebAuthnLogin(s, u, session, login)
+}
+
+// FinishPasswordlessWebAuthnLogin verifies the response of an authenticator for a passwordless login
+// and returns the user it belongs to.
+func FinishPasswordlessWebAuthnLogin(s *xorm.Session, login *WebAuthnLogin) (u *User, err error) {
+	session, err := finishWebAuthnCeremony(login.SessionID, false, true)
+	if err != nil {
+		return nil, err
+	}
+
+	u, err = GetUserByID(s, session.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = validateWebAuthnLogin(s, u, session, login)
+	if err != nil {
+		return nil, err
+	}
+
+	if u.Status == StatusDisabled {
+		return nil, &ErrAccountDisabled{UserID: u.ID}
+	}
+
+	return u, nil
+}
+
+// updateWebAuthnCredentialAfterLogin stores the new sign counter of the authenticator. If the counter did not
+// increase, the authenticator might have been cloned and we refuse the login.
+func updateWebAuthnCredentialAfterLogin(s *xorm.Session, u *User, cred *webauthn.Credential) (err error) {
+	if cred.Authenticator.CloneWarning {
+		log.Warningf("Sign counter of webauthn credential of user %d did not increase, the authenticator might have been cloned", u.ID)
+		return &ErrInvalidWebAuthnResponse{}
+	}
+
+	_, err = s.
+		Where("credential_id = ? AND user_id = ?", base64.RawURLEncoding.EncodeToString(cred.ID), u.ID).
+		Cols("sign_count", "last_used").
+		Update(&WebAuthnCredential{
+			SignCount: int64(cred.Authenticator.SignCount),
+			LastUsed:  time.Now(),
+		})
+	return
+}
@@ -0,0 +1,193 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package user
+
+import (
+	"testing"
+	"time"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/modules/keyvalue"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebAuthnEnabledForUser(t *testing.T) {
+	config.ServiceEnableWebAuthn.Set(true)
+	defer config.ServiceEnableWebAuthn.Set(false)
+
+	t.Run("with credentials", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		enabled, err := WebAuthnEnabledForUser(s, &User{ID: 1})
+		assert.NoError(t, err)
+		assert.True(t, enabled)
+	})
+	t.Run("without credentials", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		enabled, err := WebAuthnEnabledForUser(s, &User{ID: 3})
+		assert.NoError(t, err)
+		assert.False(t, enabled)
+	})
+	t.Run("disabled", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+		config.ServiceEnableWebAuthn.Set(false)
+		defer config.ServiceEnableWebAuthn.Set(true)
+
+		enabled, err := WebAuthnEnabledForUser(s, &User{ID: 1})
+		assert.NoError(t, err)
+		assert.False(t, enabled)
+	})
+}
+
+func TestBeginWebAuthnRegistration(t *testing.T) {
+	db.LoadAndAssertFixtures(t)
+	s := db.NewSession()
+	defer s.Close()
+	frontendURL := config.ServiceFrontendurl.GetString()
+	config.ServiceFrontendurl.Set("https://vikunja.example.com/")
+	defer config.ServiceFrontendurl.Set(frontendURL)
+
+	ceremony, err := BeginWebAuthnRegistration(s, &User{ID: 1, Username: "user1"})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ceremony.SessionID)
+
+	options, is := ceremony.Options.(*protocol.CredentialCreation)
+	assert.True(t, is)
+	assert.Equal(t, "vikunja.example.com", options.Response.RelyingParty.ID)
+	// The existing credential must not be registered again
+	assert.Len(t, options.Response.CredentialExcludeList, 1)
+}
+
+func TestFinishWebAuthnCeremony(t *testing.T) {
+	start := func(t *testing.T, userID int64, passwordless bool) string {
+		ceremony, err := startWebAuthnCeremony(userID, false, passwordless, nil, &webauthn.SessionData{})
+		assert.NoError(t, err)
+		return ceremony.SessionID
+	}
+
+	t.Run("normal", func(t *testing.T) {
+		sessionID := start(t, 1, false)
+		session, err := finishWebAuthnCeremony(sessionID, false, false)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), session.UserID)
+	})
+	t.Run("used twice", func(t *testing.T) {
+		sessionID := start(t, 1, false)
+		_, err := finishWebAuthnCeremony(sessionID, false, false)
+		assert.NoError(t, err)
+		_, err = finishWebAuthnCeremony(sessionID, false, false)
+		assert.Error(t, err)
+		assert.True(t, IsErrInvalidWebAuthnSession(err))
+	})
+	t.Run("other user", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		sessionID := start(t, 1, false)
+		err := FinishWebAuthnLogin(s, &User{ID: 2}, &WebAuthnLogin{SessionID: sessionID})
+		assert.Error(t, err)
+		assert.True(t, IsErrInvalidWebAuthnSession(err))
+	})
+	t.Run("registration session used for login", func(t *testing.T) {
+		sessionID := start(t, 1, false)
+		_, err := finishWebAuthnCeremony(sessionID, true, false)
+		assert.Error(t, err)
+		assert.True(t, IsErrInvalidWebAuthnSession(err))
+	})
+	t.Run("second factor session used for passwordless login", func(t *testing.T) {
+		sessionID := start(t, 1, false)
+		_, err := finishWebAuthnCeremony(sessionID, false, true)
+		assert.Error(t, err)
+		assert.True(t, IsErrInvalidWebAuthnSession(err))
+	})
+	t.Run("expired", func(t *testing.T) {
+		sessionID := "expiredwebauthnsession"
+		err := keyvalue.Put(getWebAuthnCeremonySessionKey(sessionID), &webAuthnCeremonySession{
+			UserID:  1,
+			Expires: time.Now().Add(-time.Minute),
+		})
+		assert.NoError(t, err)
+		_, err = finishWebAuthnCeremony(sessionID, false, false)
+		assert.Error(t, err)
+		assert.True(t, IsErrInvalidWebAuthnSession(err))
+	})
+}
+
+func TestBeginWebAuthnLogin(t *testing.T) {
+	frontendURL := config.ServiceFrontendurl.GetString()
+	config.ServiceFrontendurl.Set("https://vikunja.example.com/")
+	defer config.ServiceFrontendurl.Set(frontendURL)
+
+	t.Run("passwordless", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		ceremony, err := BeginWebAuthnLogin(s, &User{ID: 1, Username: "user1"}, true)
+		assert.NoError(t, err)
+		options, is := ceremony.Options.(*protocol.CredentialAssertion)
+		assert.True(t, is)
+		assert.Equal(t, protocol.VerificationRequired, options.Response.UserVerification)
+		assert.Len(t, options.Response.AllowedCredentials, 1)
+	})
+	t.Run("without credentials", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := BeginWebAuthnLogin(s, &User{ID: 3, Username: "user3"}, true)
+		assert.Error(t, err)
+		assert.True(t, IsErrWebAuthnCredentialDoesNotExist(err))
+	})
+}
+
+func TestDeleteWebAuthnCredential(t *testing.T) {
+	t.Run("normal", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		err := DeleteWebAuthnCredential(s, &User{ID: 1}, 1)
+		assert.NoError(t, err)
+		err = s.Commit()
+		assert.NoError(t, err)
+		db.AssertMissing(t, "webauthn_credentials", map[string]interface{}{
+			"id": 1,
+		})
+	})
+	t.Run("credential of another user", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		err := DeleteWebAuthnCredential(s, &User{ID: 1}, 2)
+		assert.Error(t, err)
+		assert.True(t, IsErrWebAuthnCredentialDoesNotExist(err))
+	})
+}
@@ -0,0 +1,157 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package utils
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Requests to these ranges could reach services which are not meant to be reachable from the outside, like
+// the database, a cloud provider's metadata endpoint or other services on the same host.
+var nonPublicNetworks = mustParseCIDRs(
+	"0.0.0.0/8",       // "This" network
+	"10.0.0.0/8",      // RFC 1918
+	"100.64.0.0/10",   // Carrier-grade NAT
+	"127.0.0.0/8",     // Loopback
+	"169.254.0.0/16",  // Link-local
+	"172.16.0.0/12",   // RFC 1918
+	"192.0.0.0/24",    // IETF protocol assignments
+	"192.0.2.0/24",    // Documentation
+	"192.88.99.0/24",  // 6to4 relay anycast
+	"192.168.0.0/16",  // RFC 1918
+	"198.18.0.0/15",   // Benchmarking
+	"198.51.100.0/24", // Documentation
+	"203.0.113.0/24",  // Documentation
+	"224.0.0.0/4",     // Multicast
+	"240.0.0.0/4",     // Reserved, including the broadcast address
+	"::/128",          // Unspecified
+	"::1/128",         // Loopback
+	"100::/64",        // Discard
+	"2001:db8::/32",   // Documentation
+	"fc00::/7",        // Unique local
+	"fe80::/10",       // Link-local
+	"fec0::/10",       // Site-local
+	"ff00::/8",        // Multicast
+)
+
+// NAT64 addresses embed an ipv4 address which is checked instead
+var nat64Network = mustParseCIDRs("64:ff9b::/96")[0]
+
+func mustParseCIDRs(cidrs ...string) (networks []*net.IPNet) {
+	for _, c := range cidrs {
+		_, network, err := net.ParseCIDR(c)
+		if err != nil {
+			panic(err)
+		}
+		networks = append(networks, network)
+	}
+	return
+}
+
+// IsPublicIP checks if an ip address is reachable from the internet, i.e. it is not a loopback, link-local,
+// private, multicast or otherwise reserved address.
+func IsPublicIP(ip net.IP) bool {
+	if nat64Network.Contains(ip) {
+		return IsPublicIP(ip.To16()[12:])
+	}
+	for _, network := range nonPublicNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+type hostAllowList struct {
+	hosts    map[string]bool
+	networks []*net.IPNet
+}
+
+// Entries can be host names, ip addresses or CIDR ranges.
+func newHostAllowList(entries []string) *hostAllowList {
+	list := &hostAllowList{hosts: make(map[string]bool)}
+	for _, entry := range entries {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if _, network, err := net.ParseCIDR(entry); err == nil {
+			list.networks = append(list.networks, network)
+			continue
+		}
+		if ip := net.ParseIP(entry); ip != nil {
+			list.networks = append(list.networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(len(ip)*8, len(ip)*8)})
+			continue
+		}
+		list.hosts[entry] = true
+	}
+	return list
+}
+
+func (l *hostAllowList) allowsIP(ip net.IP) bool {
+	for _, network := range l.networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// NewExternalHTTPClient returns an http client for requests to urls provided by users, like webhooks.
+// It refuses to connect to loopback, link-local and private addresses unless the host or its address is in
+// allowedHosts, which can contain host names, ip addresses and CIDR ranges.
+// The check happens when connecting to the resolved address, so it also covers redirects and host names which
+// resolve to a private address. Because of that, the client does not use the proxy from the HTTP_PROXY and
+// HTTPS_PROXY environment variables: it would only ever connect to the proxy and never see the real target.
+func NewExternalHTTPClient(allowedHosts []string) *http.Client {
+	allowList := newHostAllowList(allowedHosts)
+
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}
+	publicOnlyDialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip := net.ParseIP(host)
+			if ip == nil || (!IsPublicIP(ip) && !allowList.allowsIP(ip)) {
+				return fmt.Errorf("refusing to connect to non-public address %s", host)
+			}
+			return nil
+		},
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = func(ctx context.Context, network, address string) (net.Conn, error) {
+		host, _, err := net.SplitHostPort(address)
+		if err == nil && allowList.hosts[strings.ToLower(host)] {
+			return dialer.DialContext(ctx, network, address)
+		}
+		return publicOnlyDialer.DialContext(ctx, network, address)
+	}
+
+	return &http.Client{Transport: transport}
+}
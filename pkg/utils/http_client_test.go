@@ -0,0 +1,97 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package utils
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsPublicIP(t *testing.T) {
+	for ip, public := range map[string]bool{
+		"1.1.1.1":          true,
+		"2606:4700::1111":  true,
+		"127.0.0.1":        false,
+		"10.1.2.3":         false,
+		"172.16.0.1":       false,
+		"192.168.1.1":      false,
+		"169.254.169.254":  false,
+		"0.0.0.0":          false,
+		"::1":              false,
+		"fd00::1":          false,
+		"fe80::1":          false,
+		"::ffff:127.0.0.1": false,
+		"100.64.0.1":       false,
+		"224.0.0.1":        false,
+		"255.255.255.255":  false,
+		"198.18.0.1":       false,
+		"::":               false,
+		"ff02::1":          false,
+		"64:ff9b::a00:1":   false,
+		"64:ff9b::101:101": true,
+	} {
+		assert.Equal(t, public, IsPublicIP(net.ParseIP(ip)), ip)
+	}
+}
+
+func TestNewExternalHTTPClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	t.Run("no proxy", func(t *testing.T) {
+		transport, is := NewExternalHTTPClient(nil).Transport.(*http.Transport)
+		assert.True(t, is)
+		assert.Nil(t, transport.Proxy)
+	})
+	t.Run("private address", func(t *testing.T) {
+		_, err := NewExternalHTTPClient(nil).Get(server.URL)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "non-public address")
+	})
+	t.Run("allowed address", func(t *testing.T) {
+		resp, err := NewExternalHTTPClient([]string{"127.0.0.1"}).Get(server.URL)
+		assert.NoError(t, err)
+		_ = resp.Body.Close()
+	})
+	t.Run("allowed range", func(t *testing.T) {
+		resp, err := NewExternalHTTPClient([]string{"127.0.0.0/8"}).Get(server.URL)
+		assert.NoError(t, err)
+		_ = resp.Body.Close()
+	})
+	t.Run("allowed host name", func(t *testing.T) {
+		resp, err := NewExternalHTTPClient([]string{"localhost"}).Get(strings.Replace(server.URL, "127.0.0.1", "localhost", 1))
+		assert.NoError(t, err)
+		_ = resp.Body.Close()
+	})
+	t.Run("redirect to a private address", func(t *testing.T) {
+		redirecting := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Redirect(w, r, server.URL, http.StatusFound)
+		}))
+		defer redirecting.Close()
+
+		_, err := NewExternalHTTPClient([]string{"localhost"}).Get(strings.Replace(redirecting.URL, "127.0.0.1", "localhost", 1))
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "non-public address")
+	})
+}